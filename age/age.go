@@ -0,0 +1,195 @@
+// Package age implements age (https://age-encryption.org) recipient and identity types backed by eciesgo
+// secp256k1 keys, and the age plugin protocol, so files can be encrypted with the age CLI
+// to secp256k1 recipients through cmd/age-plugin-secp256k1.
+//
+// Recipients are encoded as "age1secp256k11..." (Bech32 of compressed public key),
+// identities as "AGE-PLUGIN-SECP256K1-1..." (Bech32 of private scalar).
+// File key is wrapped in a "secp256k1" stanza the same way native X25519 recipients do it:
+// the argument is base64 compressed ephemeral public key, the body is ChaCha20-Poly1305 encrypted file key,
+// key is HKDF-SHA256 of ECDH shared X coordinate, salted with ephemeral and recipient public keys.
+package age
+
+import (
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	eciesgo "github.com/ecies/go/v2"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// PluginName is the name of the plugin, age CLI runs age-plugin-secp256k1 binary for it
+	PluginName = "secp256k1"
+	// StanzaType is the type of stanzas wrapping file keys for secp256k1 recipients
+	StanzaType = "secp256k1"
+
+	recipientHRP = "age1" + PluginName
+	identityHRP  = "AGE-PLUGIN-SECP256K1-"
+
+	fileKeySize = 16
+	stanzaInfo  = "age-encryption.org/v1/secp256k1"
+)
+
+// ErrIncorrectIdentity is returned by Identity.Unwrap when no stanza is wrapped for the identity
+var ErrIncorrectIdentity = errors.New("incorrect identity for recipient block")
+
+// Stanza is a recipient stanza of age header
+type Stanza struct {
+	Type string
+	Args []string
+	Body []byte
+}
+
+// Recipient wraps file keys for a secp256k1 public key
+type Recipient struct {
+	PublicKey *eciesgo.PublicKey
+}
+
+// ParseRecipient decodes "age1secp256k11..." recipient
+func ParseRecipient(s string) (*Recipient, error) {
+	hrp, data, err := bech32Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("malformed recipient: %w", err)
+	}
+	if hrp != recipientHRP {
+		return nil, fmt.Errorf("malformed recipient: unexpected type %q", hrp)
+	}
+
+	pub, err := eciesgo.NewPublicKeyFromBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("malformed recipient: %w", err)
+	}
+
+	return &Recipient{PublicKey: pub}, nil
+}
+
+// String returns "age1secp256k11..." encoding of the recipient
+func (r *Recipient) String() string {
+	s, _ := bech32Encode(recipientHRP, r.PublicKey.Bytes(true))
+	return s
+}
+
+// Wrap wraps file key for the recipient
+func (r *Recipient) Wrap(fileKey []byte) ([]*Stanza, error) {
+	ek, err := eciesgo.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	defer ek.Zeroize()
+
+	aead, err := stanzaCipher(ek, r.PublicKey, ek.PublicKey, r.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*Stanza{{
+		Type: StanzaType,
+		Args: []string{base64.RawStdEncoding.EncodeToString(ek.PublicKey.Bytes(true))},
+		Body: aead.Seal(nil, make([]byte, aead.NonceSize()), fileKey, nil),
+	}}, nil
+}
+
+// Identity unwraps file keys with a secp256k1 private key
+type Identity struct {
+	PrivateKey *eciesgo.PrivateKey
+}
+
+// GenerateIdentity generates new secp256k1 identity
+func GenerateIdentity() (*Identity, error) {
+	k, err := eciesgo.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Identity{PrivateKey: k}, nil
+}
+
+// ParseIdentity decodes "AGE-PLUGIN-SECP256K1-1..." identity
+func ParseIdentity(s string) (*Identity, error) {
+	hrp, data, err := bech32Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("malformed identity: %w", err)
+	}
+	if hrp != strings.ToLower(identityHRP) {
+		return nil, fmt.Errorf("malformed identity: unexpected type %q", hrp)
+	}
+
+	k, err := eciesgo.NewPrivateKeyFromBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("malformed identity: %w", err)
+	}
+
+	return &Identity{PrivateKey: k}, nil
+}
+
+// String returns "AGE-PLUGIN-SECP256K1-1..." encoding of the identity
+func (i *Identity) String() string {
+	s, _ := bech32Encode(identityHRP, i.PrivateKey.Bytes())
+	return s
+}
+
+// Recipient returns recipient of the identity
+func (i *Identity) Recipient() *Recipient {
+	return &Recipient{PublicKey: i.PrivateKey.PublicKey}
+}
+
+// Unwrap returns file key from the first stanza wrapped for the identity;
+// ErrIncorrectIdentity is returned if there is no such stanza
+func (i *Identity) Unwrap(stanzas []*Stanza) ([]byte, error) {
+	for _, s := range stanzas {
+		if s.Type != StanzaType {
+			continue
+		}
+		if len(s.Args) != 1 {
+			return nil, fmt.Errorf("invalid %s stanza: expected 1 argument, got %d", StanzaType, len(s.Args))
+		}
+
+		b, err := base64.RawStdEncoding.Strict().DecodeString(s.Args[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s stanza: %w", StanzaType, err)
+		}
+		epk, err := eciesgo.NewPublicKeyFromBytes(b)
+		if err != nil || len(b) != 33 {
+			return nil, fmt.Errorf("invalid %s stanza: invalid ephemeral public key", StanzaType)
+		}
+		if len(s.Body) != fileKeySize+chacha20poly1305.Overhead {
+			return nil, fmt.Errorf("invalid %s stanza: invalid body length", StanzaType)
+		}
+
+		aead, err := stanzaCipher(i.PrivateKey, epk, epk, i.PrivateKey.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+
+		fileKey, err := aead.Open(nil, make([]byte, aead.NonceSize()), s.Body, nil)
+		if err != nil {
+			continue
+		}
+
+		return fileKey, nil
+	}
+
+	return nil, ErrIncorrectIdentity
+}
+
+// stanzaCipher derives stanza key from ECDH of priv and peer, salted with ephemeral and recipient public keys
+func stanzaCipher(priv *eciesgo.PrivateKey, peer, ephemeral, recipient *eciesgo.PublicKey) (cipher.AEAD, error) {
+	ss, err := priv.ECDH(peer)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := append(ephemeral.Bytes(true), recipient.Bytes(true)...)
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, ss[1:], salt, []byte(stanzaInfo)), key); err != nil {
+		return nil, fmt.Errorf("cannot read secret from HKDF reader: %w", err)
+	}
+
+	return chacha20poly1305.New(key)
+}
@@ -0,0 +1,81 @@
+package age
+
+import (
+	"bytes"
+	"testing"
+
+	eciesgo "github.com/ecies/go/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBech32(t *testing.T) {
+	// BIP-173 valid strings
+	for _, s := range []string{"A12UEL5L", "abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw", "split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w"} {
+		hrp, data, err := bech32Decode(s)
+		if !assert.NoError(t, err, s) {
+			return
+		}
+		encoded, err := bech32Encode(hrp, data)
+		if !assert.NoError(t, err) {
+			return
+		}
+		if s[0] == 'A' {
+			encoded = "A12UEL5L"
+		}
+		assert.Equal(t, s, encoded)
+	}
+
+	for _, s := range []string{"", "1nwldj5", "pzry9x0s0muk", "A1G7SGD8", "abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxx", "aBcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw"} {
+		_, _, err := bech32Decode(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestRecipientAndIdentity(t *testing.T) {
+	if eciesgo.FIPSMode() {
+		t.Skip("secp256k1 is not approved in FIPS mode")
+	}
+
+	id, err := GenerateIdentity()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	parsedID, err := ParseIdentity(id.String())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, parsedID.PrivateKey.Equals(id.PrivateKey))
+	assert.Regexp(t, "^AGE-PLUGIN-SECP256K1-1[0-9A-Z]+$", id.String())
+
+	rcpt, err := ParseRecipient(id.Recipient().String())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Regexp(t, "^age1secp256k11[0-9a-z]+$", rcpt.String())
+
+	fileKey := bytes.Repeat([]byte{7}, fileKeySize)
+	stanzas, err := rcpt.Wrap(fileKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	other, err := GenerateIdentity()
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = other.Unwrap(stanzas)
+	assert.Equal(t, ErrIncorrectIdentity, err)
+
+	// Stanzas of other types are skipped
+	unwrapped, err := id.Unwrap(append([]*Stanza{{Type: "X25519", Args: []string{"x"}}}, stanzas...))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, fileKey, unwrapped)
+
+	_, err = ParseRecipient(id.String())
+	assert.Error(t, err)
+	_, err = ParseIdentity(rcpt.String())
+	assert.Error(t, err)
+}
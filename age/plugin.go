@@ -0,0 +1,273 @@
+package age
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Stanzas are wrapped at 64 columns, the last line of a body is always shorter
+const stanzaColumns = 64
+
+// RunPlugin runs age plugin protocol state machine ("recipient-v1" or "identity-v1") over r and w,
+// which are stdin and stdout of the plugin binary
+func RunPlugin(stateMachine string, r io.Reader, w io.Writer) error {
+	br := bufio.NewReader(r)
+
+	switch stateMachine {
+	case "recipient-v1":
+		return runRecipientV1(br, w)
+	case "identity-v1":
+		return runIdentityV1(br, w)
+	default:
+		return fmt.Errorf("unknown state machine: %s", stateMachine)
+	}
+}
+
+// runRecipientV1 wraps file keys for recipients and identities sent by age client
+func runRecipientV1(r *bufio.Reader, w io.Writer) error {
+	var (
+		recipients []*Recipient
+		identities int
+		fileKeys   [][]byte
+		failure    *Stanza
+	)
+
+	err := readCommands(r, func(s *Stanza) error {
+		switch s.Type {
+		case "add-recipient":
+			if len(s.Args) != 1 {
+				return fmt.Errorf("add-recipient: expected 1 argument")
+			}
+			rcpt, err := ParseRecipient(s.Args[0])
+			if err != nil {
+				failure = errorStanza(failure, []string{"recipient", strconv.Itoa(len(recipients))}, err)
+				return nil
+			}
+			recipients = append(recipients, rcpt)
+		case "add-identity":
+			if len(s.Args) != 1 {
+				return fmt.Errorf("add-identity: expected 1 argument")
+			}
+			id, err := ParseIdentity(s.Args[0])
+			if err != nil {
+				failure = errorStanza(failure, []string{"identity", strconv.Itoa(identities)}, err)
+				return nil
+			}
+			recipients, identities = append(recipients, id.Recipient()), identities+1
+		case "wrap-file-key":
+			if len(s.Body) != fileKeySize {
+				return fmt.Errorf("wrap-file-key: invalid file key length")
+			}
+			fileKeys = append(fileKeys, s.Body)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if failure != nil {
+		return sendError(r, w, failure)
+	}
+
+	for i, fileKey := range fileKeys {
+		for _, rcpt := range recipients {
+			stanzas, err := rcpt.Wrap(fileKey)
+			if err != nil {
+				return err
+			}
+
+			for _, s := range stanzas {
+				args := append([]string{strconv.Itoa(i), s.Type}, s.Args...)
+				if err := sendCommand(r, w, &Stanza{Type: "recipient-stanza", Args: args, Body: s.Body}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return writeStanza(w, &Stanza{Type: "done"})
+}
+
+// runIdentityV1 unwraps file keys of stanzas sent by age client with identities sent by it
+func runIdentityV1(r *bufio.Reader, w io.Writer) error {
+	var (
+		identities []*Identity
+		files      = map[int][]*Stanza{}
+		order      []int
+		failure    *Stanza
+	)
+
+	err := readCommands(r, func(s *Stanza) error {
+		switch s.Type {
+		case "add-identity":
+			if len(s.Args) != 1 {
+				return fmt.Errorf("add-identity: expected 1 argument")
+			}
+			id, err := ParseIdentity(s.Args[0])
+			if err != nil {
+				failure = errorStanza(failure, []string{"identity", strconv.Itoa(len(identities))}, err)
+				return nil
+			}
+			identities = append(identities, id)
+		case "recipient-stanza":
+			if len(s.Args) < 2 {
+				return fmt.Errorf("recipient-stanza: expected at least 2 arguments")
+			}
+			file, err := strconv.Atoi(s.Args[0])
+			if err != nil || file < 0 {
+				return fmt.Errorf("recipient-stanza: invalid file index %q", s.Args[0])
+			}
+			if _, ok := files[file]; !ok {
+				order = append(order, file)
+			}
+			files[file] = append(files[file], &Stanza{Type: s.Args[1], Args: s.Args[2:], Body: s.Body})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if failure != nil {
+		return sendError(r, w, failure)
+	}
+
+	for _, file := range order {
+		for _, id := range identities {
+			fileKey, err := id.Unwrap(files[file])
+			if err == ErrIncorrectIdentity {
+				continue
+			}
+			if err != nil {
+				return sendError(r, w, errorStanza(nil, []string{"internal"}, fmt.Errorf("file %d: %w", file, err)))
+			}
+
+			if err := sendCommand(r, w, &Stanza{Type: "file-key", Args: []string{strconv.Itoa(file)}, Body: fileKey}); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	return writeStanza(w, &Stanza{Type: "done"})
+}
+
+// readCommands reads phase 1 commands until "done", unknown commands are ignored as the protocol requires
+func readCommands(r *bufio.Reader, handle func(s *Stanza) error) error {
+	for {
+		s, err := readStanza(r)
+		if err != nil {
+			return err
+		}
+		if s.Type == "done" {
+			return nil
+		}
+		if err := handle(s); err != nil {
+			return err
+		}
+	}
+}
+
+// sendCommand sends phase 2 command and waits for client response
+func sendCommand(r *bufio.Reader, w io.Writer, s *Stanza) error {
+	if err := writeStanza(w, s); err != nil {
+		return err
+	}
+
+	resp, err := readStanza(r)
+	if err != nil {
+		return err
+	}
+	if resp.Type != "ok" {
+		return fmt.Errorf("%s command is rejected by client: %s", s.Type, resp.Type)
+	}
+
+	return nil
+}
+
+// errorStanza keeps the first error reported during phase 1, it can only be sent to client in phase 2
+func errorStanza(first *Stanza, args []string, err error) *Stanza {
+	if first != nil {
+		return first
+	}
+
+	return &Stanza{Type: "error", Args: args, Body: []byte(err.Error())}
+}
+
+// sendError sends error command built by errorStanza and returns its message as error; age client aborts after it
+func sendError(r *bufio.Reader, w io.Writer, s *Stanza) error {
+	if err := sendCommand(r, w, s); err != nil {
+		return err
+	}
+
+	return fmt.Errorf("%s %s", strings.Join(s.Args, " "), s.Body)
+}
+
+// readStanza reads "-> type args..." line followed by base64 body lines, the last one is shorter than 64 columns
+func readStanza(r *bufio.Reader) (*Stanza, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "-> ") {
+		return nil, fmt.Errorf("malformed stanza header: %q", line)
+	}
+
+	fields := strings.Split(line[3:], " ")
+	if len(fields) == 0 || fields[0] == "" {
+		return nil, fmt.Errorf("malformed stanza header: %q", line)
+	}
+	s := &Stanza{Type: fields[0], Args: fields[1:]}
+
+	for {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := base64.RawStdEncoding.Strict().DecodeString(line)
+		if err != nil || len(line) > stanzaColumns {
+			return nil, fmt.Errorf("malformed stanza body: %q", line)
+		}
+		s.Body = append(s.Body, b...)
+
+		if len(line) < stanzaColumns {
+			return s, nil
+		}
+	}
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		if err == io.EOF {
+			return "", io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+
+	return strings.TrimSuffix(line, "\n"), nil
+}
+
+// writeStanza writes stanza with body wrapped at 64 columns
+func writeStanza(w io.Writer, s *Stanza) error {
+	var b strings.Builder
+	b.WriteString("-> ")
+	b.WriteString(strings.Join(append([]string{s.Type}, s.Args...), " "))
+	b.WriteByte('\n')
+
+	body := base64.RawStdEncoding.EncodeToString(s.Body)
+	for len(body) >= stanzaColumns {
+		b.WriteString(body[:stanzaColumns])
+		b.WriteByte('\n')
+		body = body[stanzaColumns:]
+	}
+	b.WriteString(body)
+	b.WriteByte('\n')
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,128 @@
+package age
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	eciesgo "github.com/ecies/go/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// testingClient emulates age client side of plugin protocol: sends phase 1 commands,
+// then answers "ok" to every phase 2 command until "done" and returns the received commands
+func testingClient(t *testing.T, stateMachine string, commands []*Stanza) ([]*Stanza, error) {
+	t.Helper()
+
+	pluginIn, clientOut := io.Pipe()
+	clientIn, pluginOut := io.Pipe()
+
+	errs := make(chan error, 1)
+	go func() {
+		err := RunPlugin(stateMachine, pluginIn, pluginOut)
+		pluginIn.Close()
+		pluginOut.Close()
+		errs <- err
+	}()
+
+	go func() {
+		for _, c := range commands {
+			writeStanza(clientOut, c)
+		}
+		writeStanza(clientOut, &Stanza{Type: "done"})
+	}()
+
+	var received []*Stanza
+	r := bufio.NewReader(clientIn)
+	for {
+		s, err := readStanza(r)
+		if err != nil {
+			return received, <-errs
+		}
+		if s.Type == "done" {
+			clientOut.Close()
+			return received, <-errs
+		}
+		received = append(received, s)
+		writeStanza(clientOut, &Stanza{Type: "ok"})
+	}
+}
+
+func TestPluginRoundTrip(t *testing.T) {
+	if eciesgo.FIPSMode() {
+		t.Skip("secp256k1 is not approved in FIPS mode")
+	}
+
+	id, err := GenerateIdentity()
+	if !assert.NoError(t, err) {
+		return
+	}
+	fileKey := bytes.Repeat([]byte{1}, fileKeySize)
+
+	stanzas, err := testingClient(t, "recipient-v1", []*Stanza{
+		{Type: "add-recipient", Args: []string{id.Recipient().String()}},
+		{Type: "grease-command", Args: []string{"x"}},
+		{Type: "wrap-file-key", Body: fileKey},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, stanzas, 1) {
+		return
+	}
+	assert.Equal(t, "recipient-stanza", stanzas[0].Type)
+	assert.Equal(t, []string{"0", StanzaType}, stanzas[0].Args[:2])
+
+	commands := []*Stanza{
+		{Type: "add-identity", Args: []string{id.String()}},
+		{Type: "recipient-stanza", Args: []string{"0", "X25519", "ignored"}, Body: []byte("ignored")},
+		stanzas[0],
+	}
+	responses, err := testingClient(t, "identity-v1", commands)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, responses, 1) {
+		return
+	}
+	assert.Equal(t, "file-key", responses[0].Type)
+	assert.Equal(t, []string{"0"}, responses[0].Args)
+	assert.Equal(t, fileKey, responses[0].Body)
+}
+
+func TestPluginErrors(t *testing.T) {
+	responses, err := testingClient(t, "recipient-v1", []*Stanza{
+		{Type: "add-recipient", Args: []string{"age1secp256k11invalid"}},
+	})
+	assert.Error(t, err)
+	if assert.Len(t, responses, 1) {
+		assert.Equal(t, "error", responses[0].Type)
+		assert.Equal(t, []string{"recipient", "0"}, responses[0].Args)
+	}
+
+	assert.Error(t, RunPlugin("unknown-v1", strings.NewReader(""), io.Discard))
+}
+
+func TestStanzaEncoding(t *testing.T) {
+	for _, n := range []int{0, 1, 47, 48, 49, 96, 200} {
+		var buf bytes.Buffer
+		s := &Stanza{Type: "test", Args: []string{"a", "b"}, Body: bytes.Repeat([]byte{0xab}, n)}
+		if !assert.NoError(t, writeStanza(&buf, s)) {
+			return
+		}
+
+		for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+			assert.True(t, len(line) <= stanzaColumns)
+		}
+
+		decoded, err := readStanza(bufio.NewReader(&buf))
+		if !assert.NoError(t, err, n) {
+			return
+		}
+		assert.Equal(t, s.Type, decoded.Type)
+		assert.Equal(t, s.Args, decoded.Args)
+		assert.Equal(t, len(s.Body), len(decoded.Body))
+	}
+}
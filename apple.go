@@ -0,0 +1,112 @@
+package eciesgo
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"fmt"
+)
+
+// Apple ECIES (SecKeyAlgorithm eciesEncryptionCofactorVariableIVX963SHA256AESGCM) works with NIST curves only,
+// as Secure Enclave keys are P-256, so it is implemented for crypto/ecdsa keys instead of secp256k1 ones.
+// Ciphertext is ephemeral uncompressed public key, AES-GCM ciphertext and 16 bytes tag;
+// X9.63 SHA-256 KDF of the shared X coordinate with ephemeral public key as SharedInfo
+// derives AES key (16 bytes for P-256, 32 bytes for larger curves) followed by 16 bytes IV
+
+// EncryptApple encrypts a passed message with a receiver NIST curve public key,
+// so it can be decrypted with SecKeyCreateDecryptedData on Apple platforms
+func EncryptApple(pubkey *ecdsa.PublicKey, msg []byte) ([]byte, error) {
+	if pubkey == nil || !appleCurveSupported(pubkey.Curve) || !pubkey.Curve.IsOnCurve(pubkey.X, pubkey.Y) {
+		return nil, fmt.Errorf("%w: Apple ECIES requires P-256, P-384 or P-521 key", ErrInvalidPublicKey)
+	}
+
+	ek, err := ecdsa.GenerateKey(pubkey.Curve, randReader)
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate ephemeral key: %w", err)
+	}
+	ephemeral := elliptic.Marshal(pubkey.Curve, ek.X, ek.Y)
+
+	d := zeroPad(ek.D.Bytes(), len(pubkey.Curve.Params().N.Bytes()))
+	defer zeroBytes(d)
+	sx, _ := pubkey.Curve.ScalarMult(pubkey.X, pubkey.Y, d)
+
+	aead, iv, err := appleCipher(pubkey.Curve, sx.Bytes(), ephemeral)
+	if err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(ephemeral, iv, msg, nil), nil
+}
+
+// DecryptApple decrypts a message produced by EncryptApple or SecKeyCreateEncryptedData
+// with a receiver NIST curve private key
+func DecryptApple(privkey *ecdsa.PrivateKey, msg []byte) ([]byte, error) {
+	if privkey == nil || !appleCurveSupported(privkey.Curve) {
+		return nil, fmt.Errorf("%w: Apple ECIES requires P-256, P-384 or P-521 key", ErrInvalidPrivateKey)
+	}
+
+	curve := privkey.Curve
+	l := 1 + 2*((curve.Params().BitSize+7)/8)
+	if len(msg) < l+16 {
+		return nil, ErrCiphertextTooShort
+	}
+
+	ephemeral := msg[:l]
+	ex, ey := elliptic.Unmarshal(curve, ephemeral)
+	if ex == nil {
+		return nil, fmt.Errorf("%w: invalid ephemeral public key", ErrInvalidPublicKey)
+	}
+
+	d := zeroPad(privkey.D.Bytes(), len(curve.Params().N.Bytes()))
+	defer zeroBytes(d)
+	sx, _ := curve.ScalarMult(ex, ey, d)
+
+	aead, iv, err := appleCipher(curve, sx.Bytes(), ephemeral)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aead.Open(nil, iv, msg[l:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAuthenticationFailed, err)
+	}
+
+	return plaintext, nil
+}
+
+// appleCipher derives AES-GCM cipher with 16 bytes nonce and IV from the shared X coordinate
+func appleCipher(curve elliptic.Curve, sx, ephemeral []byte) (cipher.AEAD, []byte, error) {
+	keySize := 32
+	if curve.Params().BitSize == 256 {
+		keySize = 16
+	}
+
+	// Sometimes shared secret coordinate is less than curve size; Big Endian
+	z := zeroPad(sx, (curve.Params().BitSize+7)/8)
+
+	key := make([]byte, keySize+16)
+	x963KDF(sha256.New, z, ephemeral, key)
+
+	block, err := aes.NewCipher(key[:keySize])
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot create new AES block: %w", err)
+	}
+
+	aead, err := cipher.NewGCMWithNonceSize(block, 16)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot create AES GCM: %w", err)
+	}
+
+	return aead, key[keySize:], nil
+}
+
+func appleCurveSupported(curve elliptic.Curve) bool {
+	switch curve {
+	case elliptic.P256(), elliptic.P384(), elliptic.P521():
+		return true
+	default:
+		return false
+	}
+}
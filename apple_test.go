@@ -0,0 +1,101 @@
+package eciesgo
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptAndDecryptApple(t *testing.T) {
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		privkey, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		ciphertext, err := EncryptApple(&privkey.PublicKey, []byte(testingMessage))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		// Ephemeral uncompressed public key, ciphertext and tag
+		l := 1 + 2*((curve.Params().BitSize+7)/8)
+		assert.Equal(t, l+len(testingMessage)+16, len(ciphertext))
+		assert.Equal(t, byte(0x04), ciphertext[0])
+
+		plaintext, err := DecryptApple(privkey, ciphertext)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(plaintext))
+
+		ciphertext[len(ciphertext)-1] ^= 1
+		_, err = DecryptApple(privkey, ciphertext)
+		assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+
+		_, err = DecryptApple(privkey, ciphertext[:l])
+		assert.True(t, errors.Is(err, ErrCiphertextTooShort), err)
+	}
+}
+
+func TestEncryptAppleUnsupportedCurve(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = EncryptApple(&ecdsa.PublicKey{Curve: privkey.Curve, X: privkey.X, Y: privkey.Y}, []byte(testingMessage))
+	assert.True(t, errors.Is(err, ErrInvalidPublicKey), err)
+}
+
+// TestDecryptAppleVectors decrypts known-answer ciphertexts of eciesEncryptionCofactorVariableIVX963SHA256AESGCM
+// from testdata/apple_vectors.json. They were produced outside of this package, with Node.js crypto
+// (ECDH, SHA-256 and AES-GCM of OpenSSL), so the KDF and IV layout are not only checked against EncryptApple;
+// device-captured SecKeyCreateEncryptedData ciphertexts use the same format and can be appended to the file
+func TestDecryptAppleVectors(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "apple_vectors.json"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	var vectors []struct {
+		Curve      string `json:"curve"`
+		PrivateKey string `json:"private_key"`
+		Plaintext  string `json:"plaintext"`
+		Ciphertext string `json:"ciphertext"`
+	}
+	if !assert.NoError(t, json.Unmarshal(data, &vectors)) || !assert.NotEmpty(t, vectors) {
+		return
+	}
+
+	for _, v := range vectors {
+		curve, err := CurveByName(v.Curve)
+		if !assert.NoError(t, err) {
+			return
+		}
+		d, _ := hex.DecodeString(v.PrivateKey)
+		privkey, err := NewPrivateKeyFromBytesCurve(curve, d)
+		if !assert.NoError(t, err) {
+			return
+		}
+		ciphertext, _ := hex.DecodeString(v.Ciphertext)
+
+		plaintext, err := DecryptApple(&ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{Curve: curve, X: privkey.X, Y: privkey.Y},
+			D:         privkey.D,
+		}, ciphertext)
+		if !assert.NoError(t, err, v.Curve) {
+			return
+		}
+		assert.Equal(t, v.Plaintext, hex.EncodeToString(plaintext))
+	}
+}
@@ -0,0 +1,132 @@
+package eciesgo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// Armor layout: begin line, base64 body wrapped at armorLineLength characters,
+// "=" followed by base64 of 3 bytes CRC-24 (RFC 4880) of the data and end line
+const (
+	armorBegin      = "-----BEGIN ECIES MESSAGE-----"
+	armorEnd        = "-----END ECIES MESSAGE-----"
+	armorLineLength = 64
+)
+
+// Armor encodes data as ASCII armored text, which can travel through email, YAML and logs
+func Armor(data []byte) string {
+	body := base64.StdEncoding.EncodeToString(data)
+
+	var sb strings.Builder
+	sb.WriteString(armorBegin)
+	sb.WriteByte('\n')
+	for len(body) > 0 {
+		n := armorLineLength
+		if len(body) < n {
+			n = len(body)
+		}
+		sb.WriteString(body[:n])
+		sb.WriteByte('\n')
+		body = body[n:]
+	}
+
+	crc := crc24(data)
+	sb.WriteByte('=')
+	sb.WriteString(base64.StdEncoding.EncodeToString([]byte{byte(crc >> 16), byte(crc >> 8), byte(crc)}))
+	sb.WriteByte('\n')
+	sb.WriteString(armorEnd)
+	sb.WriteByte('\n')
+
+	return sb.String()
+}
+
+// Dearmor decodes ASCII armored text produced by Armor and verifies its checksum;
+// surrounding whitespace and CRLF line endings are tolerated
+func Dearmor(armored string) ([]byte, error) {
+	lines := strings.Split(strings.TrimSpace(strings.Replace(armored, "\r\n", "\n", -1)), "\n")
+	if len(lines) < 3 || strings.TrimSpace(lines[0]) != armorBegin || strings.TrimSpace(lines[len(lines)-1]) != armorEnd {
+		return nil, fmt.Errorf("%w: missing armor header or footer", ErrInvalidArmor)
+	}
+	lines = lines[1 : len(lines)-1]
+
+	checksum := strings.TrimSpace(lines[len(lines)-1])
+	if !strings.HasPrefix(checksum, "=") {
+		return nil, fmt.Errorf("%w: missing checksum", ErrInvalidArmor)
+	}
+
+	var body strings.Builder
+	for _, line := range lines[:len(lines)-1] {
+		body.WriteString(strings.TrimSpace(line))
+	}
+
+	data, err := base64.StdEncoding.DecodeString(body.String())
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot decode body: %v", ErrInvalidArmor, err)
+	}
+
+	crc, err := base64.StdEncoding.DecodeString(checksum[1:])
+	if err != nil || len(crc) != 3 {
+		return nil, fmt.Errorf("%w: cannot decode checksum", ErrInvalidArmor)
+	}
+
+	expected := crc24(data)
+	if !bytes.Equal(crc, []byte{byte(expected >> 16), byte(expected >> 8), byte(expected)}) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrInvalidArmor)
+	}
+
+	return data, nil
+}
+
+// EncryptArmored encrypts a passed message with a receiver public key and returns ASCII armored ciphertext
+func EncryptArmored(pubkey *PublicKey, msg []byte) (string, error) {
+	return EncryptArmoredConf(pubkey, msg, DefaultConfig())
+}
+
+// EncryptArmoredConf encrypts a passed message with a receiver public key and the passed config,
+// returns ASCII armored ciphertext
+func EncryptArmoredConf(pubkey *PublicKey, msg []byte, config Config) (string, error) {
+	ciphertext, err := EncryptConf(pubkey, msg, config)
+	if err != nil {
+		return "", err
+	}
+
+	return Armor(ciphertext), nil
+}
+
+// DecryptArmored decrypts ASCII armored ciphertext produced by EncryptArmored with a receiver private key
+func DecryptArmored(privkey *PrivateKey, armored string) ([]byte, error) {
+	return DecryptArmoredConf(privkey, armored, DefaultConfig())
+}
+
+// DecryptArmoredConf decrypts ASCII armored ciphertext with a receiver private key and the passed config
+func DecryptArmoredConf(privkey *PrivateKey, armored string, config Config) ([]byte, error) {
+	ciphertext, err := Dearmor(armored)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecryptConf(privkey, ciphertext, config)
+}
+
+// crc24 computes OpenPGP CRC-24 checksum (RFC 4880, section 6.1)
+func crc24(data []byte) uint32 {
+	const (
+		crc24Init = 0xb704ce
+		crc24Poly = 0x1864cfb
+	)
+
+	crc := uint32(crc24Init)
+	for _, b := range data {
+		crc ^= uint32(b) << 16
+		for i := 0; i < 8; i++ {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= crc24Poly
+			}
+		}
+	}
+
+	return crc & 0xffffff
+}
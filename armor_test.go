@@ -0,0 +1,70 @@
+package eciesgo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArmor(t *testing.T) {
+	for _, data := range [][]byte{{}, []byte("hello world"), []byte(strings.Repeat("a", 200))} {
+		armored := Armor(data)
+		for _, line := range strings.Split(armored, "\n") {
+			assert.True(t, len(line) <= armorLineLength || line == armorBegin || line == armorEnd)
+		}
+
+		decoded, err := Dearmor(armored)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, data, decoded)
+
+		decoded, err = Dearmor("\n  " + strings.Replace(armored, "\n", "\r\n", -1))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, data, decoded)
+	}
+
+	// CRC-24 of empty data is the initial value
+	assert.Equal(t, uint32(0xb704ce), crc24(nil))
+}
+
+func TestDearmorErrors(t *testing.T) {
+	armored := Armor([]byte("hello world"))
+
+	for _, s := range []string{
+		"",
+		strings.Replace(armored, armorBegin, "-----BEGIN PGP MESSAGE-----", 1),
+		strings.Replace(armored, armorEnd+"\n", "", 1),
+		strings.Replace(armored, "aGVsbG8", "aGVsbG9", 1),
+		strings.Replace(armored, "\n=", "\n", 1),
+		strings.Replace(armored, "aGVsbG8", "!!!", 1),
+	} {
+		_, err := Dearmor(s)
+		assert.True(t, errors.Is(err, ErrInvalidArmor), err)
+	}
+}
+
+func TestEncryptAndDecryptArmored(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	armored, err := EncryptArmored(privkey.PublicKey, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, strings.HasPrefix(armored, armorBegin))
+
+	plaintext, err := DecryptArmored(privkey, armored)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(plaintext))
+}
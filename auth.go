@@ -0,0 +1,90 @@
+package eciesgo
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// EncryptAuth encrypts a passed message with a receiver public key in sender-authenticated mode:
+// besides ephemeral-static shared point, KDF input includes sender static public key and static-static shared point,
+// so only the owner of the sender private key could produce ciphertext which DecryptAuth accepts.
+// Authentication is implicit and not transferable: the receiver can forge such ciphertexts too
+func EncryptAuth(senderPrivkey *PrivateKey, pubkey *PublicKey, msg []byte) ([]byte, error) {
+	return EncryptAuthConf(senderPrivkey, pubkey, msg, DefaultConfig())
+}
+
+// EncryptAuthConf encrypts a passed message in sender-authenticated mode with the passed config
+func EncryptAuthConf(senderPrivkey *PrivateKey, pubkey *PublicKey, msg []byte, config Config) ([]byte, error) {
+	if config.hpke != nil {
+		return nil, fmt.Errorf("sender authentication is not supported in HPKE mode, use HPKESuite.SetupAuthS")
+	}
+	if config.kdfSharedPointOnly {
+		return nil, fmt.Errorf("sender authentication is not supported with shared point KDF")
+	}
+	if senderPrivkey == nil {
+		return nil, fmt.Errorf("%w: sender private key is empty", ErrInvalidPrivateKey)
+	}
+
+	secret, err := authSecret(senderPrivkey, senderPrivkey.PublicKey, pubkey)
+	if err != nil {
+		return nil, err
+	}
+	if config.wipeSecrets {
+		defer zeroBytes(secret)
+	}
+
+	config.kdfSuffix = secret
+	return EncryptConf(pubkey, msg, config)
+}
+
+// DecryptAuth decrypts a message produced by EncryptAuth with a receiver private key
+// and verifies that it was encrypted by the owner of the sender public key
+func DecryptAuth(privkey *PrivateKey, senderPubkey *PublicKey, msg []byte) ([]byte, error) {
+	return DecryptAuthConf(privkey, senderPubkey, msg, DefaultConfig())
+}
+
+// DecryptAuthConf decrypts a message produced by EncryptAuthConf with the passed config
+func DecryptAuthConf(privkey *PrivateKey, senderPubkey *PublicKey, msg []byte, config Config) ([]byte, error) {
+	if config.hpke != nil {
+		return nil, fmt.Errorf("sender authentication is not supported in HPKE mode, use HPKESuite.SetupAuthR")
+	}
+	if config.kdfSharedPointOnly {
+		return nil, fmt.Errorf("sender authentication is not supported with shared point KDF")
+	}
+	if privkey == nil {
+		return nil, fmt.Errorf("%w: private key is empty", ErrInvalidPrivateKey)
+	}
+
+	secret, err := authSecret(privkey, senderPubkey, senderPubkey)
+	if err != nil {
+		return nil, err
+	}
+	if config.wipeSecrets {
+		defer zeroBytes(secret)
+	}
+
+	config.kdfSuffix = secret
+	return DecryptConf(privkey, msg, config)
+}
+
+// authSecret returns sender public key || 0x04 || Sx || Sy, where S is static-static shared point of priv and peer
+func authSecret(priv *PrivateKey, senderPubkey, peer *PublicKey) ([]byte, error) {
+	sx, sy, err := priv.sharedPoint(peer)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		zeroBigInt(sx)
+		zeroBigInt(sy)
+	}()
+
+	l := len(peer.Curve.Params().P.Bytes())
+
+	var secret bytes.Buffer
+	secret.Write(senderPubkey.Bytes(false))
+	secret.Write([]byte{0x04})
+	secret.Write(zeroPad(sx.Bytes(), l))
+	secret.Write(zeroPad(sy.Bytes(), l))
+
+	return secret.Bytes(), nil
+}
@@ -0,0 +1,63 @@
+package eciesgo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptAuth(t *testing.T) {
+	skipUnapproved(t)
+
+	sender, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	receiver, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	other, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, conf := range []Config{
+		DEFAULT_CONFIG,
+		NewConfig("xchacha20", 0).WithEnvelope(),
+		NewConfig("aes-128-gcm", 16).WithSecretWiping(),
+	} {
+		ct, err := EncryptAuthConf(sender, receiver.PublicKey, []byte(testingMessage), conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		pt, err := DecryptAuthConf(receiver, sender.PublicKey, ct, conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(pt))
+
+		// Claimed sender does not match
+		_, err = DecryptAuthConf(receiver, other.PublicKey, ct, conf)
+		assert.True(t, errors.Is(err, ErrAuthenticationFailed), "%v", err)
+
+		// Plain decryption does not accept authenticated ciphertext
+		_, err = DecryptConf(receiver, ct, conf)
+		assert.Error(t, err)
+	}
+
+	// Authenticated decryption does not accept plain ciphertext
+	ct, err := Encrypt(receiver.PublicKey, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = DecryptAuth(receiver, sender.PublicKey, ct)
+	assert.Error(t, err)
+
+	_, err = EncryptAuth(nil, receiver.PublicKey, []byte(testingMessage))
+	assert.Error(t, err)
+	_, err = DecryptAuth(receiver, nil, ct)
+	assert.Error(t, err)
+}
@@ -0,0 +1,88 @@
+package eciesgo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var base58Radix = big.NewInt(58)
+
+// base58Encode encodes bytes with Bitcoin base58 alphabet
+func base58Encode(b []byte) string {
+	x := new(big.Int).SetBytes(b)
+	mod := new(big.Int)
+
+	var out []byte
+	for x.Sign() > 0 {
+		x.DivMod(x, base58Radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+
+	// Leading zero bytes are encoded as leading '1' characters
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		out = append(out, base58Alphabet[0])
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+
+	return string(out)
+}
+
+// base58Decode decodes string encoded with Bitcoin base58 alphabet
+func base58Decode(s string) ([]byte, error) {
+	x := new(big.Int)
+	for i := 0; i < len(s); i++ {
+		d := bytes.IndexByte([]byte(base58Alphabet), s[i])
+		if d < 0 {
+			return nil, fmt.Errorf("invalid base58 character at offset %d", i)
+		}
+		x.Mul(x, base58Radix)
+		x.Add(x, big.NewInt(int64(d)))
+	}
+
+	var zeros int
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+
+	return append(make([]byte, zeros), x.Bytes()...), nil
+}
+
+// base58CheckEncode appends 4 bytes of double SHA-256 checksum to payload and encodes it with base58
+func base58CheckEncode(payload []byte) string {
+	return base58Encode(append(append([]byte(nil), payload...), base58Checksum(payload)...))
+}
+
+// base58CheckDecode decodes base58 string and verifies its checksum, returns payload
+func base58CheckDecode(s string) ([]byte, error) {
+	b, err := base58Decode(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) < 4 {
+		return nil, fmt.Errorf("base58check string is too short")
+	}
+
+	payload := b[:len(b)-4]
+	if !bytes.Equal(b[len(b)-4:], base58Checksum(payload)) {
+		return nil, fmt.Errorf("invalid base58check checksum")
+	}
+
+	return payload, nil
+}
+
+func base58Checksum(payload []byte) []byte {
+	h := sha256.Sum256(payload)
+	h = sha256.Sum256(h[:])
+	return h[:4]
+}
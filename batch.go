@@ -0,0 +1,92 @@
+package eciesgo
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// EncryptBatch encrypts messages with a receiver public key in parallel with GOMAXPROCS workers;
+// ciphertexts are returned in the order of messages
+func EncryptBatch(pubkey *PublicKey, msgs [][]byte) ([][]byte, error) {
+	return EncryptBatchConf(pubkey, msgs, DefaultConfig(), 0)
+}
+
+// EncryptBatchConf encrypts messages with a receiver public key and the passed config in parallel;
+// workers limits concurrency, GOMAXPROCS is used if it is not positive
+func EncryptBatchConf(pubkey *PublicKey, msgs [][]byte, config Config, workers int) ([][]byte, error) {
+	return runBatch(context.Background(), msgs, workers, func(msg []byte) ([]byte, error) {
+		return EncryptConf(pubkey, msg, config)
+	})
+}
+
+// DecryptBatch decrypts ciphertexts with a receiver private key in parallel with GOMAXPROCS workers;
+// plaintexts are returned in the order of ciphertexts
+func DecryptBatch(privkey *PrivateKey, cts [][]byte) ([][]byte, error) {
+	return DecryptBatchConf(privkey, cts, DefaultConfig(), 0)
+}
+
+// DecryptBatchConf decrypts ciphertexts with a receiver private key and the passed config in parallel;
+// workers limits concurrency, GOMAXPROCS is used if it is not positive
+func DecryptBatchConf(privkey *PrivateKey, cts [][]byte, config Config, workers int) ([][]byte, error) {
+	return runBatch(context.Background(), cts, workers, func(ct []byte) ([]byte, error) {
+		return DecryptConf(privkey, ct, config)
+	})
+}
+
+// runBatch applies f to all inputs with a pool of workers, stops at the first error or once ctx is done
+func runBatch(ctx context.Context, inputs [][]byte, workers int, f func([]byte) ([]byte, error)) ([][]byte, error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(inputs) {
+		workers = len(inputs)
+	}
+
+	outputs := make([][]byte, len(inputs))
+
+	var (
+		next     int64 = -1
+		failed   int32
+		errOnce  sync.Once
+		firstErr error
+		wg       sync.WaitGroup
+	)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for atomic.LoadInt32(&failed) == 0 {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= len(inputs) {
+					return
+				}
+
+				err := ctx.Err()
+				var out []byte
+				if err == nil {
+					out, err = f(inputs[i])
+				}
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = fmt.Errorf("batch item %d: %w", i, err)
+						atomic.StoreInt32(&failed, 1)
+					})
+					return
+				}
+				outputs[i] = out
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return outputs, nil
+}
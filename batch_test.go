@@ -0,0 +1,60 @@
+package eciesgo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptBatchAndDecryptBatch(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	msgs := make([][]byte, 100)
+	for i := range msgs {
+		msgs[i] = []byte(fmt.Sprintf("message %d", i))
+	}
+
+	for _, workers := range []int{0, 1, 7} {
+		cts, err := EncryptBatchConf(privkey.PublicKey, msgs, DEFAULT_CONFIG, workers)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		pts, err := DecryptBatchConf(privkey, cts, DEFAULT_CONFIG, workers)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, msgs, pts)
+	}
+
+	empty, err := EncryptBatch(privkey.PublicKey, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Empty(t, empty)
+}
+
+func TestDecryptBatchError(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	cts, err := EncryptBatch(privkey.PublicKey, [][]byte{[]byte("a"), []byte("b"), []byte("c")})
+	if !assert.NoError(t, err) {
+		return
+	}
+	cts[1] = cts[1][:70]
+
+	_, err = DecryptBatch(privkey, cts)
+	assert.True(t, errors.Is(err, ErrCiphertextTooShort), err)
+}
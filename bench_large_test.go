@@ -0,0 +1,8 @@
+//go:build ecies_bench_large
+// +build ecies_bench_large
+
+package eciesgo
+
+func init() {
+	benchmarkSizes = append(benchmarkSizes, 100<<20)
+}
@@ -0,0 +1,187 @@
+package eciesgo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+// Payload sizes of the benchmark suite; 100MB payload is added with ecies_bench_large build tag
+var benchmarkSizes = []int{1 << 10, 1 << 20}
+
+var benchmarkConfigs = []Config{
+	DEFAULT_CONFIG,
+	NewConfig("aes-128-gcm", 12),
+	NewConfig("xchacha20", 24),
+	NewConfig("chacha20poly1305", 12),
+	NewConfig("aes-256-cbc-hmac-sha256", 16),
+}
+
+func benchmarkName(config Config, size int) string {
+	return fmt.Sprintf("%s/%dKB", config.symmetricAlgorithm, size>>10)
+}
+
+func BenchmarkGenerateKey(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := GenerateKey(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncapsulate(b *testing.B) {
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if err != nil {
+		b.Fatal(err)
+	}
+	ek, err := GenerateKey()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ek.Encapsulate(privkey.PublicKey); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecapsulate(b *testing.B) {
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if err != nil {
+		b.Fatal(err)
+	}
+	ek, err := GenerateKey()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ek.PublicKey.Decapsulate(privkey); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncryptPayload(b *testing.B) {
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, config := range benchmarkConfigs {
+		for _, size := range benchmarkSizes {
+			msg := make([]byte, size)
+
+			b.Run(benchmarkName(config, size), func(b *testing.B) {
+				b.SetBytes(int64(size))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := EncryptConf(privkey.PublicKey, msg, config); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkDecryptPayload(b *testing.B) {
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, config := range benchmarkConfigs {
+		for _, size := range benchmarkSizes {
+			ciphertext, err := EncryptConf(privkey.PublicKey, make([]byte, size), config)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.Run(benchmarkName(config, size), func(b *testing.B) {
+				b.SetBytes(int64(size))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := DecryptConf(privkey, ciphertext, config); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkStream(b *testing.B) {
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, size := range benchmarkSizes {
+		msg := make([]byte, size)
+
+		b.Run(benchmarkName(DEFAULT_CONFIG, size), func(b *testing.B) {
+			var buf bytes.Buffer
+
+			b.SetBytes(int64(size))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				buf.Reset()
+
+				w, err := NewEncryptWriter(&buf, privkey.PublicKey)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if _, err := w.Write(msg); err != nil {
+					b.Fatal(err)
+				}
+				if err := w.Close(); err != nil {
+					b.Fatal(err)
+				}
+
+				r, err := NewDecryptReader(&buf, privkey)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if _, err := io.Copy(ioutil.Discard, r); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkNewPublicKeyFromBytes(b *testing.B) {
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, compressed := range []bool{false, true} {
+		pub := privkey.PublicKey.Bytes(compressed)
+
+		b.Run(fmt.Sprintf("compressed=%t", compressed), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := NewPublicKeyFromBytes(pub); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkNewPrivateKeyFromBytes(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewPrivateKeyFromBytes(testingReceiverPrivkey); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
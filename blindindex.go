@@ -0,0 +1,73 @@
+package eciesgo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// BlindIndex derives equality search tokens of a field stored encrypted with ECIES: token is HMAC-SHA256
+// of the normalized value under a per-field key, so a database finds rows by value comparing tokens without
+// decrypting them. Equal values of the field have equal tokens, which reveals duplicates; tokens are truncated
+// to the configured length, so short ones collide and queries return false positives to be filtered after decryption
+type BlindIndex struct {
+	key    []byte
+	length int
+
+	// Normalize transforms value before computing token, e.g. NormalizeFold for case-insensitive search;
+	// nil uses values as is
+	Normalize func(string) string
+}
+
+// NewBlindIndex creates blind index of the named field with its key derived from the root key with HKDF-SHA256,
+// so one root key serves every field and tokens of different fields are unrelated; length is token length
+// in bytes from 4 to 32
+func NewBlindIndex(rootKey []byte, field string, length int) (*BlindIndex, error) {
+	if len(rootKey) < 16 {
+		return nil, fmt.Errorf("blind index root key is too short: %d", len(rootKey))
+	}
+	if field == "" {
+		return nil, fmt.Errorf("blind index field is empty")
+	}
+	if length < 4 || length > sha256.Size {
+		return nil, fmt.Errorf("invalid blind index length: %d", length)
+	}
+
+	key := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, rootKey, nil, []byte("ecies/blind-index/"+field)), key); err != nil {
+		return nil, fmt.Errorf("cannot read blind index key from HKDF reader: %w", err)
+	}
+
+	return &BlindIndex{key: key, length: length}, nil
+}
+
+// Token returns search token of the value
+func (b *BlindIndex) Token(value string) []byte {
+	if b.Normalize != nil {
+		value = b.Normalize(value)
+	}
+
+	mac := hmac.New(sha256.New, b.key)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)[:b.length]
+}
+
+// EncryptField encrypts value with a receiver public key and the passed config and returns it with its token,
+// which is stored alongside ciphertext; ciphertext holds the original value, not the normalized one
+func (b *BlindIndex) EncryptField(pubkey *PublicKey, value string, config Config) (ct []byte, token []byte, err error) {
+	ct, err = EncryptConf(pubkey, []byte(value), config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ct, b.Token(value), nil
+}
+
+// NormalizeFold trims surrounding whitespace and lowers case, e.g. for email addresses
+func NormalizeFold(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
@@ -0,0 +1,61 @@
+package eciesgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlindIndex(t *testing.T) {
+	skipUnapproved(t)
+
+	rootKey := []byte("0123456789abcdef0123456789abcdef")
+
+	email, err := NewBlindIndex(rootKey, "email", 16)
+	if !assert.NoError(t, err) {
+		return
+	}
+	email.Normalize = NormalizeFold
+	phone, err := NewBlindIndex(rootKey, "phone", 16)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	token := email.Token("alice@example.com")
+	assert.Len(t, token, 16)
+	assert.Equal(t, token, email.Token("  Alice@Example.com "))
+	assert.NotEqual(t, token, email.Token("bob@example.com"))
+
+	// Fields have independent keys
+	assert.NotEqual(t, token, phone.Token("alice@example.com"))
+
+	// Another root key gives other tokens
+	other, err := NewBlindIndex([]byte("fedcba9876543210fedcba9876543210"), "email", 16)
+	if !assert.NoError(t, err) {
+		return
+	}
+	other.Normalize = NormalizeFold
+	assert.NotEqual(t, token, other.Token("alice@example.com"))
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	ct, fieldToken, err := email.EncryptField(privkey.PublicKey, "Alice@example.com", DEFAULT_CONFIG)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, token, fieldToken)
+	pt, err := Decrypt(privkey, ct)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "Alice@example.com", string(pt))
+
+	_, err = NewBlindIndex(rootKey[:8], "email", 16)
+	assert.Error(t, err)
+	_, err = NewBlindIndex(rootKey, "", 16)
+	assert.Error(t, err)
+	_, err = NewBlindIndex(rootKey, "email", 33)
+	assert.Error(t, err)
+}
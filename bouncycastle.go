@@ -0,0 +1,178 @@
+package eciesgo
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+)
+
+// BouncyCastleParams describes BouncyCastle IESEngine parameters (IESParameterSpec);
+// zero value matches BouncyCastle "ECIES" cipher defaults
+type BouncyCastleParams struct {
+	// Cipher is "" for XOR stream cipher ("ECIES"), "aes-128-cbc" or "aes-256-cbc" ("ECIESwithAES-CBC")
+	Cipher string
+	// IV is CBC initialization vector (IESParameterSpec nonce), zero IV is used if it is empty
+	IV []byte
+	// Derivation is KDF derivation vector (P1)
+	Derivation []byte
+	// Encoding is MAC encoding vector (P2)
+	Encoding []byte
+	// MACKeySize is HMAC-SHA1 key length in bytes, 16 by default
+	MACKeySize int
+}
+
+// EncryptBouncyCastle encrypts a passed message with a receiver public key compatibly with BouncyCastle IESEngine:
+// KDF2 (SHA-1) of ephemeral public key and shared X coordinate derives cipher and HMAC-SHA1 keys,
+// output is ephemeral uncompressed public key, ciphertext and MAC
+func EncryptBouncyCastle(pubkey *PublicKey, msg []byte, params BouncyCastleParams) ([]byte, error) {
+	ek, err := GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	sx, _, err := ek.sharedPoint(pubkey)
+	if err != nil {
+		return nil, err
+	}
+
+	v := ek.PublicKey.Bytes(false)
+	encKey, macKey, err := bouncyCastleKeys(v, sx.Bytes(), len(msg), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var c []byte
+	if params.Cipher == "" {
+		c = xorBytes(msg, encKey)
+	} else {
+		block, err := aes.NewCipher(encKey)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create new AES block: %w", err)
+		}
+		iv, err := bouncyCastleIV(params)
+		if err != nil {
+			return nil, err
+		}
+
+		c = pkcs7Pad(msg, block.BlockSize())
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(c, c)
+	}
+
+	ct := append(v, c...)
+	return append(ct, bouncyCastleMAC(macKey, c, params)...), nil
+}
+
+// DecryptBouncyCastle decrypts a message produced by EncryptBouncyCastle or BouncyCastle IESEngine
+// with a receiver private key and the same parameters
+func DecryptBouncyCastle(privkey *PrivateKey, msg []byte, params BouncyCastleParams) ([]byte, error) {
+	if len(msg) < 65+sha1.Size {
+		return nil, ErrCiphertextTooShort
+	}
+
+	ephemeral, err := NewPublicKeyFromBytes(msg[:65])
+	if err != nil {
+		return nil, err
+	}
+
+	sx, _, err := privkey.sharedPoint(ephemeral)
+	if err != nil {
+		return nil, err
+	}
+
+	c, tag := msg[65:len(msg)-sha1.Size], msg[len(msg)-sha1.Size:]
+	encKey, macKey, err := bouncyCastleKeys(msg[:65], sx.Bytes(), len(c), params)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hmac.Equal(tag, bouncyCastleMAC(macKey, c, params)) {
+		return nil, fmt.Errorf("%w: invalid MAC", ErrAuthenticationFailed)
+	}
+
+	if params.Cipher == "" {
+		return xorBytes(c, encKey), nil
+	}
+
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create new AES block: %w", err)
+	}
+	if len(c) == 0 || len(c)%block.BlockSize() != 0 {
+		return nil, fmt.Errorf("%w: ciphertext is not a multiple of block size", ErrCiphertextTooShort)
+	}
+	iv, err := bouncyCastleIV(params)
+	if err != nil {
+		return nil, err
+	}
+
+	pt := make([]byte, len(c))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(pt, c)
+
+	return pkcs7Unpad(pt, block.BlockSize())
+}
+
+// bouncyCastleKeys derives cipher and MAC keys with KDF2 (SHA-1) of V || Z and derivation vector;
+// in XOR mode MAC key goes first and cipher key has message length, as IESEngine does when V is not empty
+func bouncyCastleKeys(v, sx []byte, msgLength int, params BouncyCastleParams) (encKey, macKey []byte, err error) {
+	macKeySize := params.MACKeySize
+	if macKeySize == 0 {
+		macKeySize = 16
+	}
+
+	var encKeySize int
+	switch params.Cipher {
+	case "":
+		encKeySize = msgLength
+	case "aes-128-cbc":
+		encKeySize = 16
+	case "aes-256-cbc":
+		encKeySize = 32
+	default:
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedCipher, params.Cipher)
+	}
+
+	// Sometimes shared secret coordinate is less than 32 bytes; Big Endian
+	z := zeroPad(sx, 32)
+
+	k := make([]byte, encKeySize+macKeySize)
+	x963KDF(sha1.New, append(append([]byte(nil), v...), z...), params.Derivation, k)
+
+	if params.Cipher == "" {
+		return k[macKeySize:], k[:macKeySize], nil
+	}
+	return k[:encKeySize], k[encKeySize:], nil
+}
+
+// bouncyCastleMAC computes HMAC-SHA1 of ciphertext, encoding vector and its 8 bytes big endian length in bits
+func bouncyCastleMAC(key, c []byte, params BouncyCastleParams) []byte {
+	var l2 [8]byte
+	binary.BigEndian.PutUint64(l2[:], uint64(len(params.Encoding))*8)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(c)
+	mac.Write(params.Encoding)
+	mac.Write(l2[:])
+	return mac.Sum(nil)
+}
+
+func bouncyCastleIV(params BouncyCastleParams) ([]byte, error) {
+	switch len(params.IV) {
+	case 0:
+		return make([]byte, aes.BlockSize), nil
+	case aes.BlockSize:
+		return params.IV, nil
+	default:
+		return nil, fmt.Errorf("invalid IV length: %d", len(params.IV))
+	}
+}
+
+func xorBytes(a, b []byte) []byte {
+	out := make([]byte, len(a))
+	for i := range out {
+		out[i] = a[i] ^ b[i]
+	}
+	return out
+}
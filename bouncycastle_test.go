@@ -0,0 +1,110 @@
+package eciesgo
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptAndDecryptBouncyCastle(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, params := range []BouncyCastleParams{
+		{},
+		{Derivation: []byte("derivation"), Encoding: []byte("encoding"), MACKeySize: 20},
+		{Cipher: "aes-128-cbc"},
+		{Cipher: "aes-256-cbc", IV: make([]byte, 16)},
+	} {
+		for _, msg := range []string{"", testingMessage, testingJsonMessage} {
+			ciphertext, err := EncryptBouncyCastle(privkey.PublicKey, []byte(msg), params)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			plaintext, err := DecryptBouncyCastle(privkey, ciphertext, params)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, msg, string(plaintext))
+
+			ciphertext[70] ^= 1
+			_, err = DecryptBouncyCastle(privkey, ciphertext, params)
+			assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+		}
+	}
+
+	// XOR mode does not expand the message: ephemeral key, ciphertext and HMAC-SHA1
+	ciphertext, err := EncryptBouncyCastle(privkey.PublicKey, []byte(testingMessage), BouncyCastleParams{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 65+len(testingMessage)+20, len(ciphertext))
+
+	_, err = EncryptBouncyCastle(privkey.PublicKey, []byte(testingMessage), BouncyCastleParams{Cipher: "des-cbc"})
+	assert.True(t, errors.Is(err, ErrUnsupportedCipher), err)
+}
+
+// TestDecryptBouncyCastleVectors decrypts known-answer ciphertexts from testdata/bouncycastle_vectors.json
+// for the default "ECIES" (XOR stream) and "ECIESwithAES-CBC" configurations. They were produced outside
+// of this package with Node.js crypto following IESEngine (ECDHBasicAgreement, KDF2BytesGenerator and
+// HMac with SHA-1), so the key split, length tag and padding are not only checked against EncryptBouncyCastle;
+// ciphertexts of Java IESEngine use the same format and can be appended to the file
+func TestDecryptBouncyCastleVectors(t *testing.T) {
+	skipUnapproved(t)
+
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "bouncycastle_vectors.json"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	var vectors []struct {
+		Cipher     string `json:"cipher"`
+		IV         string `json:"iv"`
+		Derivation string `json:"derivation"`
+		Encoding   string `json:"encoding"`
+		MACKeySize int    `json:"mac_key_size"`
+		PrivateKey string `json:"private_key"`
+		Plaintext  string `json:"plaintext"`
+		Ciphertext string `json:"ciphertext"`
+	}
+	if !assert.NoError(t, json.Unmarshal(data, &vectors)) || !assert.NotEmpty(t, vectors) {
+		return
+	}
+
+	decode := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	for _, v := range vectors {
+		privkey, err := NewPrivateKeyFromHex(v.PrivateKey)
+		if !assert.NoError(t, err) {
+			return
+		}
+		params := BouncyCastleParams{
+			Cipher:     v.Cipher,
+			IV:         decode(v.IV),
+			Derivation: decode(v.Derivation),
+			Encoding:   decode(v.Encoding),
+			MACKeySize: v.MACKeySize,
+		}
+
+		plaintext, err := DecryptBouncyCastle(privkey, decode(v.Ciphertext), params)
+		if !assert.NoError(t, err, v.Cipher) {
+			return
+		}
+		assert.Equal(t, v.Plaintext, hex.EncodeToString(plaintext))
+	}
+}
@@ -0,0 +1,183 @@
+package eciesgo
+
+import (
+	"crypto/elliptic"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// Brainpool curves (RFC 5639) for interoperability with European (eIDAS, BSI TR-03111) systems;
+// they work with GenerateKeyCurve, NewPrivateKeyFromBytesCurve, NewPublicKeyFromBytesCurve, Encrypt and Decrypt.
+// Arithmetic uses generic elliptic.CurveParams methods, which are not constant time
+
+var (
+	brainpoolOnce sync.Once
+	brainpoolP256 *rcurve
+	brainpoolP384 *rcurve
+)
+
+// BrainpoolP256r1 returns elliptic.Curve implementing brainpoolP256r1
+func BrainpoolP256r1() elliptic.Curve {
+	brainpoolOnce.Do(initBrainpool)
+	return brainpoolP256
+}
+
+// BrainpoolP384r1 returns elliptic.Curve implementing brainpoolP384r1
+func BrainpoolP384r1() elliptic.Curve {
+	brainpoolOnce.Do(initBrainpool)
+	return brainpoolP384
+}
+
+func initBrainpool() {
+	brainpoolP256 = newRCurve(&elliptic.CurveParams{
+		Name:    "brainpoolP256r1",
+		BitSize: 256,
+		P:       hexInt("a9fb57dba1eea9bc3e660a909d838d726e3bf623d52620282013481d1f6e5377"),
+		N:       hexInt("a9fb57dba1eea9bc3e660a909d838d718c397aa3b561a6f7901e0e82974856a7"),
+		B:       hexInt("26dc5c6ce94a4b44f330b5d9bbd77cbf958416295cf7e1ce6bccdc18ff8c07b6"),
+		Gx:      hexInt("8bd2aeb9cb7e57cb2c4b482ffc81b7afb9de27e1e3bd23c23a4453bd9ace3262"),
+		Gy:      hexInt("547ef835c3dac4fd97f8461a14611dc9c27745132ded8e545c1d54c72f046997"),
+	}, hexInt("662c61c430d84ea4fe66a7733d0b76b7bf93ebc4af2f49256ae58101fee92b04"),
+		hexInt("3e2d4bd9597b58639ae7aa669cab9837cf5cf20a2c852d10f655668dfc150ef0"))
+
+	brainpoolP384 = newRCurve(&elliptic.CurveParams{
+		Name:    "brainpoolP384r1",
+		BitSize: 384,
+		P: hexInt("8cb91e82a3386d280f5d6f7e50e641df152f7109ed5456b412b1da197fb71123" +
+			"acd3a729901d1a71874700133107ec53"),
+		N: hexInt("8cb91e82a3386d280f5d6f7e50e641df152f7109ed5456b31f166e6cac0425a7" +
+			"cf3ab6af6b7fc3103b883202e9046565"),
+		B: hexInt("04a8c7dd22ce28268b39b55416f0447c2fb77de107dcd2a62e880ea53eeb62d5" +
+			"7cb4390295dbc9943ab78696fa504c11"),
+		Gx: hexInt("1d1c64f068cf45ffa2a63a81b7c13f6b8847a3e77ef14fe3db7fcafe0cbd10e8" +
+			"e826e03436d646aaef87b2e247d4af1e"),
+		Gy: hexInt("8abe1d7520f9c2a45cb1eb8e95cfd55262b70b29feec5864e19c054ff9912928" +
+			"0e4646217791811142820341263c5315"),
+	}, hexInt("7f519eada7bda81bd826dba647910f8c4b9346ed8ccdc64e4b1abd11756dce1d"+
+		"2074aa263b88805ced70355a33b471ee"),
+		hexInt("41dfe8dd399331f7166a66076734a89cd0d2bcdb7d068e44e1f378f41ecbae97"+
+			"d2d63dbc87bccddccc5da39e8589291c"))
+}
+
+// rcurve is Brainpool curve, which arithmetic is done on its isomorphic twisted curve with a = -3 (RFC 5639,
+// section 3): point (x, y) maps to (x·z², y·z³). Params describe the curve itself, its a is not -3,
+// so they must not be used with a = -3 formulas
+type rcurve struct {
+	params  *elliptic.CurveParams
+	twisted *elliptic.CurveParams
+
+	z2, z3, z2inv, z3inv *big.Int
+}
+
+// newRCurve creates curve from its parameters, B coefficient of twisted curve and isomorphism parameter z
+func newRCurve(params *elliptic.CurveParams, twistedB, z *big.Int) *rcurve {
+	p := params.P
+	c := &rcurve{
+		params: params,
+		z2:     new(big.Int).Exp(z, big.NewInt(2), p),
+		z3:     new(big.Int).Exp(z, big.NewInt(3), p),
+	}
+	c.z2inv = new(big.Int).ModInverse(c.z2, p)
+	c.z3inv = new(big.Int).ModInverse(c.z3, p)
+
+	gx, gy := c.toTwisted(params.Gx, params.Gy)
+	c.twisted = &elliptic.CurveParams{
+		Name:    params.Name + "-twisted",
+		BitSize: params.BitSize,
+		P:       p,
+		N:       params.N,
+		B:       twistedB,
+		Gx:      gx,
+		Gy:      gy,
+	}
+
+	return c
+}
+
+func (c *rcurve) Params() *elliptic.CurveParams {
+	return c.params
+}
+
+func (c *rcurve) IsOnCurve(x, y *big.Int) bool {
+	p := c.params.P
+	if x.Sign() < 0 || y.Sign() < 0 || x.Cmp(p) >= 0 || y.Cmp(p) >= 0 {
+		return false
+	}
+
+	return c.twisted.IsOnCurve(c.toTwisted(x, y))
+}
+
+func (c *rcurve) Add(x1, y1, x2, y2 *big.Int) (x, y *big.Int) {
+	tx1, ty1 := c.toTwisted(x1, y1)
+	tx2, ty2 := c.toTwisted(x2, y2)
+	return c.fromTwisted(c.twisted.Add(tx1, ty1, tx2, ty2))
+}
+
+func (c *rcurve) Double(x1, y1 *big.Int) (x, y *big.Int) {
+	return c.fromTwisted(c.twisted.Double(c.toTwisted(x1, y1)))
+}
+
+func (c *rcurve) ScalarMult(x1, y1 *big.Int, k []byte) (x, y *big.Int) {
+	tx, ty := c.toTwisted(x1, y1)
+	return c.fromTwisted(c.twisted.ScalarMult(tx, ty, k))
+}
+
+func (c *rcurve) ScalarBaseMult(k []byte) (x, y *big.Int) {
+	return c.fromTwisted(c.twisted.ScalarBaseMult(k))
+}
+
+// decompressY computes Y coordinate with the given parity on the twisted curve
+func (c *rcurve) decompressY(x *big.Int, odd bool) (*big.Int, error) {
+	p := c.params.P
+
+	// y² = x³ - 3x + b on the twisted curve
+	tx := new(big.Int).Mul(x, c.z2)
+	tx.Mod(tx, p)
+	rhs := new(big.Int).Mul(tx, tx)
+	rhs.Mul(rhs, tx)
+	rhs.Sub(rhs, new(big.Int).Lsh(tx, 1))
+	rhs.Sub(rhs, tx)
+	rhs.Add(rhs, c.twisted.B)
+	rhs.Mod(rhs, p)
+
+	y := new(big.Int)
+	if y.ModSqrt(rhs, p) == nil {
+		return nil, fmt.Errorf("point is not on curve")
+	}
+	y.Mul(y, c.z3inv)
+	y.Mod(y, p)
+	if (y.Bit(0) == 1) != odd {
+		y.Sub(p, y)
+	}
+
+	return y, nil
+}
+
+func (c *rcurve) toTwisted(x, y *big.Int) (*big.Int, *big.Int) {
+	return c.mapPoint(x, y, c.z2, c.z3)
+}
+
+func (c *rcurve) fromTwisted(x, y *big.Int) (*big.Int, *big.Int) {
+	return c.mapPoint(x, y, c.z2inv, c.z3inv)
+}
+
+// mapPoint multiplies coordinates by the isomorphism factors, point at infinity (0, 0) maps to itself
+func (c *rcurve) mapPoint(x, y, fx, fy *big.Int) (*big.Int, *big.Int) {
+	p := c.params.P
+
+	mx := new(big.Int).Mul(x, fx)
+	mx.Mod(mx, p)
+	my := new(big.Int).Mul(y, fy)
+	my.Mod(my, p)
+
+	return mx, my
+}
+
+func hexInt(s string) *big.Int {
+	x, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("invalid hex integer " + s)
+	}
+	return x
+}
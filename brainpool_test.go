@@ -0,0 +1,93 @@
+package eciesgo
+
+import (
+	"crypto/elliptic"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBrainpool(t *testing.T) {
+	skipUnapproved(t)
+
+	// Keys and shared secrets generated with OpenSSL
+	vectors := []struct {
+		curve                    elliptic.Curve
+		priv, pub, peer, xShared string
+	}{
+		{
+			curve: BrainpoolP256r1(),
+			priv:  "46e6d9dd1629d2dab98f8332a9bf10fdeddacc101bb9b3e351b2f5c7ea45cf79",
+			pub: "04a3f6970950d4024ea3337bdd9a701b6a76a2101a560213422ccb903f7175a47f" +
+				"4773af8f8fbf1d7b35d85bc778cb181e2c85c8a94fd455cd3eb5f71906bf9e7c",
+			peer: "04590c94833f7a2b741da79924be801797c5c7f3c45d48cc2430b0f154d21483f7" +
+				"41f298abcb9fab089f0933c6cb568b052b14bec88990975aaef876808c6b3eab",
+			xShared: "756e5cef9d600c70eea9d973506363b37f56b406b22af3270bb4d72bab03e235",
+		},
+		{
+			curve: BrainpoolP384r1(),
+			priv:  "48161f36790b02113fb7bf0751714d7ae343a550e385041a018d08a12bd61c3546f1ed153c8a8279ae3b18ae9d827e0e",
+			pub: "047365b3f8a1da415c4bae001fc6fd800d3a63e2677af3b2bcac723a14ad35f269baa675d123792a7891944fde2ea6ca29" +
+				"04831303a757591629014c0d098ffcccb1ff7fb120b574036503d12f2336f991ea06f3e1298978b3c2f5e566daad41c8",
+			peer: "047656cf220524415ad18a9dbd17e40e498e5a7db4ddc930c9aa69cfc65abbda1190340cc02409565a6bb9537725eceb15" +
+				"05d3cfcd0d38d776f4e0404e6f0ca7833ca2643ac3dfd003034aab0dda39d9f263075b061255515e3b3c0c5d3c7e91b3",
+			xShared: "1f3c52f9f3a73f886fee6458334f470627343dfd1ac9cc552713daeda653214a6a0c3bc473b1ce9cfde80269c2a17d4e",
+		},
+	}
+
+	for _, v := range vectors {
+		t.Run(v.curve.Params().Name, func(t *testing.T) {
+			params := v.curve.Params()
+			assert.True(t, v.curve.IsOnCurve(params.Gx, params.Gy))
+			x, y := v.curve.ScalarBaseMult(params.N.Bytes())
+			assert.True(t, x.Sign() == 0 && y.Sign() == 0)
+
+			privBytes, _ := hex.DecodeString(v.priv)
+			privkey, err := NewPrivateKeyFromBytesCurve(v.curve, privBytes)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, v.pub, hex.EncodeToString(privkey.PublicKey.Bytes(false)))
+
+			peerBytes, _ := hex.DecodeString(v.peer)
+			peer, err := NewPublicKeyFromBytesCurve(v.curve, peerBytes)
+			if !assert.NoError(t, err) {
+				return
+			}
+			ss, err := privkey.ECDHX(peer)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, v.xShared, hex.EncodeToString(ss))
+
+			// Compressed encoding
+			compressed, err := NewPublicKeyFromBytesCurve(v.curve, peer.Bytes(true))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.True(t, compressed.Equals(peer))
+
+			// Point of the twisted curve is not on the curve
+			tx, ty := v.curve.(*rcurve).toTwisted(peer.X, peer.Y)
+			assert.False(t, v.curve.IsOnCurve(tx, ty))
+
+			// Encryption round trip
+			ct, err := Encrypt(privkey.PublicKey, []byte(testingMessage))
+			if !assert.NoError(t, err) {
+				return
+			}
+			pt, err := Decrypt(privkey, ct)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, testingMessage, string(pt))
+
+			generated, err := GenerateKeyCurve(v.curve)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.NoError(t, generated.Validate())
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package eciesgo
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+// cbcHMAC is encrypt-then-MAC AES-256-CBC with HMAC-SHA256 ("aes-256-cbc-hmac-sha256") for legacy interoperability;
+// 64 bytes key is AES key followed by MAC key, nonce is 16 bytes IV, plaintext is PKCS#7 padded,
+// tag is HMAC-SHA256 of additional data, IV, ciphertext and 8 bytes big endian additional data length in bits
+type cbcHMAC struct {
+	block  cipher.Block
+	macKey []byte
+}
+
+func newCBCHMAC(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key[:32])
+	if err != nil {
+		return nil, fmt.Errorf("cannot create new AES block: %w", err)
+	}
+
+	return &cbcHMAC{block: block, macKey: key[32:]}, nil
+}
+
+func (c *cbcHMAC) NonceSize() int {
+	return aes.BlockSize
+}
+
+// Overhead returns tag length; padding makes sealed message longer, see sealedLength
+func (c *cbcHMAC) Overhead() int {
+	return sha256.Size
+}
+
+func (c *cbcHMAC) sealedLength(n int) int {
+	return n - n%aes.BlockSize + aes.BlockSize + sha256.Size
+}
+
+func (c *cbcHMAC) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != aes.BlockSize {
+		panic("eciesgo: incorrect nonce length given to AES-CBC")
+	}
+
+	padded := pkcs7Pad(plaintext, aes.BlockSize)
+	ret, out := sliceForAppend(dst, len(padded)+sha256.Size)
+
+	cipher.NewCBCEncrypter(c.block, nonce).CryptBlocks(out, padded)
+	copy(out[len(padded):], c.tag(nonce, out[:len(padded)], additionalData))
+
+	return ret
+}
+
+func (c *cbcHMAC) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != aes.BlockSize {
+		return nil, fmt.Errorf("incorrect nonce length: %d", len(nonce))
+	}
+	if len(ciphertext) < aes.BlockSize+sha256.Size || (len(ciphertext)-sha256.Size)%aes.BlockSize != 0 {
+		return nil, ErrCiphertextTooShort
+	}
+
+	body, tag := ciphertext[:len(ciphertext)-sha256.Size], ciphertext[len(ciphertext)-sha256.Size:]
+
+	// MAC is verified in constant time before decryption, so padding errors are not observable
+	if !hmac.Equal(tag, c.tag(nonce, body, additionalData)) {
+		return nil, fmt.Errorf("message authentication failed")
+	}
+
+	ret, out := sliceForAppend(dst, len(body))
+	cipher.NewCBCDecrypter(c.block, nonce).CryptBlocks(out, body)
+
+	plaintext, err := pkcs7Unpad(out, aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return ret[:len(ret)-len(out)+len(plaintext)], nil
+}
+
+func (c *cbcHMAC) tag(iv, ciphertext, additionalData []byte) []byte {
+	var al [8]byte
+	binary.BigEndian.PutUint64(al[:], uint64(len(additionalData))*8)
+
+	mac := hmac.New(sha256.New, c.macKey)
+	mac.Write(additionalData)
+	mac.Write(iv)
+	mac.Write(ciphertext)
+	mac.Write(al[:])
+	return mac.Sum(nil)
+}
+
+// sealedLength returns length of ciphertext and tag which AEAD produces for n bytes of plaintext
+func sealedLength(aead cipher.AEAD, n int) int {
+	if p, ok := aead.(interface{ sealedLength(int) int }); ok {
+		return p.sealedLength(n)
+	}
+
+	return n + aead.Overhead()
+}
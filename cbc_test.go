@@ -0,0 +1,87 @@
+package eciesgo
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptAndDecryptCBCHMAC(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	conf := NewConfig("aes-256-cbc-hmac-sha256", 16)
+	for _, c := range []Config{conf, conf.WithKeyCommitment(), conf.WithEnvelope()} {
+		for _, n := range []int{0, 1, 15, 16, 17, 1000} {
+			msg := bytes.Repeat([]byte{'a'}, n)
+
+			ciphertext, err := EncryptConf(privkey.PublicKey, msg, c)
+			if !assert.NoError(t, err) {
+				return
+			}
+			plaintext, err := DecryptConf(privkey, ciphertext, c)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, msg, plaintext)
+
+			ciphertext[len(ciphertext)-1] ^= 1
+			_, err = DecryptConf(privkey, ciphertext, c)
+			assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+		}
+	}
+
+	multi, err := EncryptMultiConf([]*PublicKey{privkey.PublicKey}, []byte(testingMessage), conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	plaintext, err := DecryptMultiConf(privkey, multi, conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(plaintext))
+
+	var stream bytes.Buffer
+	w, err := NewEncryptWriterConf(&stream, privkey.PublicKey, conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	msg := strings.Repeat(testingMessage, 10000)
+	if _, err := w.Write([]byte(msg)); !assert.NoError(t, err) || !assert.NoError(t, w.Close()) {
+		return
+	}
+	r, err := NewDecryptReaderConf(&stream, privkey, conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	plaintext, err = ioutil.ReadAll(r)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, msg, string(plaintext))
+}
+
+func TestCBCHMACLayout(t *testing.T) {
+	skipUnapproved(t)
+
+	key := bytes.Repeat([]byte{1}, 64)
+	conf := NewConfig("aes-256-cbc-hmac-sha256", 16)
+
+	// IV, HMAC-SHA256 tag and PKCS#7 padded ciphertext
+	ct, err := EncryptSymm(key, []byte(testingMessage), conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 16+32+16, len(ct))
+
+	_, err = EncryptSymm(key[:32], []byte(testingMessage), conf)
+	assert.Error(t, err)
+}
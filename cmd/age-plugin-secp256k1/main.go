@@ -0,0 +1,50 @@
+// Command age-plugin-secp256k1 is age plugin for secp256k1 recipients and identities.
+//
+// Generate identity:
+//
+//	age-plugin-secp256k1 --generate > key.txt
+//
+// Then encrypt to the printed recipient and decrypt with the identity file:
+//
+//	age -r age1secp256k11... -o secret.age secret.txt
+//	age -d -i key.txt secret.age
+//
+// age runs the binary with --age-plugin flag itself, it must be in PATH.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ecies/go/v2/age"
+)
+
+func main() {
+	stateMachine := flag.String("age-plugin", "", "run age plugin state machine (set by age)")
+	generate := flag.Bool("generate", false, "generate new identity")
+	flag.Parse()
+
+	switch {
+	case *stateMachine != "":
+		if err := age.RunPlugin(*stateMachine, os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case *generate:
+		id, err := age.GenerateIdentity()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("# created: %s\n", time.Now().Format(time.RFC3339))
+		fmt.Printf("# public key: %s\n", id.Recipient())
+		fmt.Println(id)
+		fmt.Fprintf(os.Stderr, "Public key: %s\n", id.Recipient())
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,363 @@
+// Command ecies encrypts and decrypts data with the same implementation Go services use.
+//
+// Generate private key and derive its public key:
+//
+//	ecies keygen -o key.txt
+//	ecies pubkey -k key.txt
+//
+// Encrypt stdin to one or more receivers and decrypt it back:
+//
+//	ecies encrypt -r 04... < secret.txt > secret.ecies
+//	ecies decrypt -k key.txt < secret.ecies
+//
+// Single receiver messages are encrypted in chunks, in constant memory; messages for several receivers
+// (-r repeated or -R file) are read into memory and must be decrypted with -multi.
+// With -a messages are ASCII armored (and read into memory as well).
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	eciesgo "github.com/ecies/go/v2"
+)
+
+const usage = `Usage:
+    ecies keygen [-o OUTPUT]
+    ecies pubkey [-k KEY] [-c] [-o OUTPUT]
+    ecies encrypt (-r RECIPIENT)... [-R RECIPIENTS_FILE] [-a] [-i INPUT] [-o OUTPUT]
+    ecies decrypt -k KEY [-multi] [-a] [-i INPUT] [-o OUTPUT]
+
+Keys are hex encoded; INPUT and OUTPUT default to stdin and stdout.
+Run "ecies <command> -h" for command flags.
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintf(os.Stderr, "ecies: %v\n", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// run executes a command with stdin and stdout used when input or output files are not set
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return flag.ErrHelp
+	}
+
+	switch args[0] {
+	case "keygen":
+		return keygen(args[1:], stdout)
+	case "pubkey":
+		return pubkey(args[1:], stdin, stdout)
+	case "encrypt":
+		return encrypt(args[1:], stdin, stdout)
+	case "decrypt":
+		return decrypt(args[1:], stdin, stdout)
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(os.Stderr, usage)
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+func keygen(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("keygen", flag.ContinueOnError)
+	output := fs.String("o", "", "write private key to file (created with 0600 permissions)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	k, err := eciesgo.GenerateKey()
+	if err != nil {
+		return err
+	}
+	defer k.Zeroize()
+
+	if *output == "" {
+		_, err = fmt.Fprintln(stdout, k.Hex())
+		return err
+	}
+
+	if err := ioutil.WriteFile(*output, []byte(k.Hex()+"\n"), 0600); err != nil {
+		return fmt.Errorf("cannot write private key: %w", err)
+	}
+	_, err = fmt.Fprintln(stdout, k.PublicKey.Hex(false))
+	return err
+}
+
+func pubkey(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("pubkey", flag.ContinueOnError)
+	keyPath := fs.String("k", "", "private key file (default stdin)")
+	compressed := fs.Bool("c", false, "print compressed public key")
+	output := fs.String("o", "", "output file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	k, err := readPrivateKey(*keyPath, stdin)
+	if err != nil {
+		return err
+	}
+	defer k.Zeroize()
+
+	w, finish, err := openOutput(*output, stdout)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, k.PublicKey.Hex(*compressed))
+
+	return finish(err)
+}
+
+func encrypt(args []string, stdin io.Reader, stdout io.Writer) error {
+	var recipients stringList
+	fs := flag.NewFlagSet("encrypt", flag.ContinueOnError)
+	fs.Var(&recipients, "r", "receiver public key (can be repeated)")
+	recipientsFile := fs.String("R", "", "file with receiver public keys, one per line")
+	armor := fs.Bool("a", false, "ASCII armor output")
+	input := fs.String("i", "", "input file")
+	output := fs.String("o", "", "output file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *recipientsFile != "" {
+		lines, err := readLines(*recipientsFile)
+		if err != nil {
+			return err
+		}
+		recipients = append(recipients, lines...)
+	}
+	if len(recipients) == 0 {
+		return errors.New("encrypt: at least one receiver is required")
+	}
+
+	pubkeys := make([]*eciesgo.PublicKey, len(recipients))
+	for i, r := range recipients {
+		pub, err := eciesgo.NewPublicKeyFromHex(r)
+		if err != nil {
+			return fmt.Errorf("invalid receiver %q: %w", r, err)
+		}
+		pubkeys[i] = pub
+	}
+
+	r, closeInput, err := openInput(*input, stdin)
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+
+	w, finish, err := openOutput(*output, stdout)
+	if err != nil {
+		return err
+	}
+
+	return finish(encryptTo(w, r, pubkeys, *armor))
+}
+
+// encryptTo streams r to a single receiver in chunks; several receivers and armor require whole message in memory
+func encryptTo(w io.Writer, r io.Reader, pubkeys []*eciesgo.PublicKey, armor bool) error {
+	if armor {
+		var buf bytes.Buffer
+		if err := encryptTo(&buf, r, pubkeys, false); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, eciesgo.Armor(buf.Bytes()))
+		return err
+	}
+
+	if len(pubkeys) == 1 {
+		ew, err := eciesgo.NewEncryptWriter(w, pubkeys[0])
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(ew, r); err != nil {
+			return err
+		}
+		return ew.Close()
+	}
+
+	msg, err := ioutil.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("cannot read input: %w", err)
+	}
+	ct, err := eciesgo.EncryptMulti(pubkeys, msg)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(ct)
+	return err
+}
+
+func decrypt(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("decrypt", flag.ContinueOnError)
+	keyPath := fs.String("k", "", "private key file")
+	multi := fs.Bool("multi", false, "input is encrypted for several receivers")
+	armor := fs.Bool("a", false, "input is ASCII armored")
+	input := fs.String("i", "", "input file")
+	output := fs.String("o", "", "output file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *keyPath == "" {
+		return errors.New("decrypt: private key file is required")
+	}
+
+	k, err := readPrivateKey(*keyPath, nil)
+	if err != nil {
+		return err
+	}
+	defer k.Zeroize()
+
+	r, closeInput, err := openInput(*input, stdin)
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+
+	if *armor {
+		armored, err := ioutil.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("cannot read input: %w", err)
+		}
+		ct, err := eciesgo.Dearmor(string(armored))
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(ct)
+	}
+
+	w, finish, err := openOutput(*output, stdout)
+	if err != nil {
+		return err
+	}
+
+	return finish(decryptTo(w, r, k, *multi))
+}
+
+func decryptTo(w io.Writer, r io.Reader, k *eciesgo.PrivateKey, multi bool) error {
+	if multi {
+		ct, err := ioutil.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("cannot read input: %w", err)
+		}
+		msg, err := eciesgo.DecryptMulti(k, ct)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(msg)
+		return err
+	}
+
+	dr, err := eciesgo.NewDecryptReader(r, k)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, dr)
+	return err
+}
+
+// readPrivateKey reads hex encoded private key from path, or from stdin if path is empty
+func readPrivateKey(path string, stdin io.Reader) (*eciesgo.PrivateKey, error) {
+	var (
+		b   []byte
+		err error
+	)
+	if path == "" {
+		b, err = ioutil.ReadAll(stdin)
+	} else {
+		b, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read private key: %w", err)
+	}
+
+	k, err := eciesgo.NewPrivateKeyFromHex(strings.TrimSpace(string(b)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+
+	return k, nil
+}
+
+// readLines reads non-empty lines of a file, lines starting with "#" are comments
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, s.Err()
+}
+
+func openInput(path string, stdin io.Reader) (io.Reader, func() error, error) {
+	if path == "" || path == "-" {
+		return stdin, func() error { return nil }, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return f, f.Close, nil
+}
+
+// openOutput opens output file, finish closes it and removes the file if err is not nil,
+// so partially written output does not remain
+func openOutput(path string, stdout io.Writer) (io.Writer, func(err error) error, error) {
+	if path == "" || path == "-" {
+		return stdout, func(err error) error { return err }, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	finish := func(err error) error {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(path)
+		}
+		return err
+	}
+
+	return f, finish, nil
+}
+
+// stringList is repeatable string flag
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	eciesgo "github.com/ecies/go/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	if eciesgo.FIPSMode() {
+		t.Skip("secp256k1 is not approved in FIPS mode")
+	}
+
+	dir, err := ioutil.TempDir("", "ecies")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	var pubkeys []string
+	for _, name := range []string{"k1", "k2"} {
+		var out bytes.Buffer
+		if !assert.NoError(t, run([]string{"keygen", "-o", filepath.Join(dir, name)}, nil, &out)) {
+			return
+		}
+		pubkeys = append(pubkeys, strings.TrimSpace(out.String()))
+
+		out.Reset()
+		if !assert.NoError(t, run([]string{"pubkey", "-k", filepath.Join(dir, name)}, nil, &out)) {
+			return
+		}
+		assert.Equal(t, pubkeys[len(pubkeys)-1], strings.TrimSpace(out.String()))
+	}
+
+	msg := bytes.Repeat([]byte("ecies"), 30000)
+	for _, tc := range []struct {
+		encrypt, decrypt []string
+	}{
+		{[]string{"-r", pubkeys[0]}, []string{"-k", filepath.Join(dir, "k1")}},
+		{[]string{"-r", pubkeys[0], "-a"}, []string{"-k", filepath.Join(dir, "k1"), "-a"}},
+		{[]string{"-r", pubkeys[0], "-r", pubkeys[1]}, []string{"-k", filepath.Join(dir, "k2"), "-multi"}},
+		{[]string{"-r", pubkeys[0], "-r", pubkeys[1], "-a"}, []string{"-k", filepath.Join(dir, "k1"), "-multi", "-a"}},
+	} {
+		var ct, pt bytes.Buffer
+		if !assert.NoError(t, run(append([]string{"encrypt"}, tc.encrypt...), bytes.NewReader(msg), &ct)) {
+			return
+		}
+		if !assert.NoError(t, run(append([]string{"decrypt"}, tc.decrypt...), &ct, &pt)) {
+			return
+		}
+		assert.Equal(t, msg, pt.Bytes())
+	}
+
+	// Output file is removed after failed decryption
+	var ct bytes.Buffer
+	if !assert.NoError(t, run([]string{"encrypt", "-r", pubkeys[0]}, bytes.NewReader(msg), &ct)) {
+		return
+	}
+	out := filepath.Join(dir, "out")
+	assert.Error(t, run([]string{"decrypt", "-k", filepath.Join(dir, "k2"), "-o", out}, &ct, nil))
+	_, err = os.Stat(out)
+	assert.True(t, os.IsNotExist(err))
+
+	assert.Error(t, run([]string{"encrypt"}, nil, nil))
+	assert.Error(t, run([]string{"unknown"}, nil, nil))
+}
@@ -0,0 +1,64 @@
+package eciesgo
+
+import (
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// Length of key commitment appended to authentication tag
+const commitmentLength = 32
+
+// WithKeyCommitment returns copy of config which makes symmetric encryption key-committing:
+// encryption key and commitment are derived from the shared key with HKDF-SHA256,
+// commitment is appended to authentication tag and checked before decryption,
+// so a ciphertext cannot be opened under two different keys (partitioning oracle attacks)
+func (c Config) WithKeyCommitment() Config {
+	c.keyCommitment = true
+	return c
+}
+
+// commitKey derives encryption key of the same length and commitment from key
+func commitKey(key []byte) (encKey []byte, commitment []byte, err error) {
+	out := make([]byte, len(key)+commitmentLength)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, key, nil, []byte("ecies/key-commitment")), out); err != nil {
+		return nil, nil, fmt.Errorf("cannot read key commitment from HKDF reader: %w", err)
+	}
+
+	return out[:len(key)], out[len(key):], nil
+}
+
+// committingAEAD appends key commitment to the sealed message of the underlying AEAD
+type committingAEAD struct {
+	cipher.AEAD
+	commitment []byte
+}
+
+func (c *committingAEAD) Overhead() int {
+	return c.AEAD.Overhead() + commitmentLength
+}
+
+func (c *committingAEAD) sealedLength(n int) int {
+	return sealedLength(c.AEAD, n) + commitmentLength
+}
+
+func (c *committingAEAD) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	return append(c.AEAD.Seal(dst, nonce, plaintext, additionalData), c.commitment...)
+}
+
+func (c *committingAEAD) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < commitmentLength {
+		return nil, ErrCiphertextTooShort
+	}
+
+	body, commitment := ciphertext[:len(ciphertext)-commitmentLength], ciphertext[len(ciphertext)-commitmentLength:]
+	if subtle.ConstantTimeCompare(commitment, c.commitment) != 1 {
+		return nil, fmt.Errorf("key commitment mismatch")
+	}
+
+	return c.AEAD.Open(dst, nonce, body, additionalData)
+}
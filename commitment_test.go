@@ -0,0 +1,74 @@
+package eciesgo
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptAndDecryptKeyCommitment(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, plainConf := range []Config{DEFAULT_CONFIG, NewConfig("xchacha20", 0), NewConfig("aes-128-gcm", 12)} {
+		conf := plainConf.WithKeyCommitment()
+
+		ciphertext, err := EncryptConf(privkey.PublicKey, []byte(testingMessage), conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		plain, err := EncryptConf(privkey.PublicKey, []byte(testingMessage), plainConf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, len(plain)+commitmentLength, len(ciphertext))
+
+		plaintext, err := DecryptConf(privkey, ciphertext, conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(plaintext))
+
+		// Commitment is checked by the receiver
+		_, err = DecryptConf(privkey, ciphertext, plainConf)
+		assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+
+		enveloped, err := EncryptConf(privkey.PublicKey, []byte(testingMessage), conf.WithEnvelope())
+		if !assert.NoError(t, err) {
+			return
+		}
+		e, err := ParseEnvelope(enveloped)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.True(t, e.KeyCommitment)
+		plaintext, err = DecryptConf(privkey, enveloped, DEFAULT_CONFIG.WithEnvelope())
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(plaintext))
+	}
+}
+
+func TestCommittingAEAD(t *testing.T) {
+	conf := DEFAULT_CONFIG.WithKeyCommitment()
+	key1, key2 := bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 32)
+
+	ct, err := EncryptSymm(key1, []byte(testingMessage), conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = DecryptSymm(key2, ct, conf)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+
+	ct[len(ct)-1] ^= 1
+	_, err = DecryptSymm(key1, ct, conf)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+}
@@ -0,0 +1,180 @@
+package eciesgo
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"time"
+)
+
+// ContextDecapsulator is Decapsulator which accepts context, e.g. to propagate deadlines to remote KMS
+type ContextDecapsulator interface {
+	Decapsulator
+	SharedPointContext(ctx context.Context, pub *PublicKey) (x, y *big.Int, err error)
+}
+
+// EncryptContext encrypts a passed message with a receiver public key and the passed config,
+// it fails without encryption if ctx is already done; encryption is traced with the config tracer
+func EncryptContext(ctx context.Context, pubkey *PublicKey, msg []byte, config Config) ([]byte, error) {
+	var ct []byte
+	err := config.trace(ctx, "ecies.Encrypt", OperationEncrypt, func(ctx context.Context, config Config) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var err error
+		ct, err = EncryptConf(pubkey, msg, config)
+		return err
+	})
+
+	return ct, err
+}
+
+// DecryptContext decrypts a passed message with a receiver private key and the passed config,
+// it fails without decryption if ctx is already done; decryption is traced with the config tracer
+func DecryptContext(ctx context.Context, privkey *PrivateKey, msg []byte, config Config) ([]byte, error) {
+	var pt []byte
+	err := config.trace(ctx, "ecies.Decrypt", OperationDecrypt, func(ctx context.Context, config Config) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var err error
+		pt, err = DecryptConf(privkey, msg, config)
+		return err
+	})
+
+	return pt, err
+}
+
+// DecryptWithContext decrypts a passed message delegating private key operation to the decapsulator;
+// ctx is passed to the decapsulator if it implements ContextDecapsulator, decryption is traced
+// with the config tracer and ctx holds its span
+func DecryptWithContext(ctx context.Context, d Decapsulator, msg []byte, config Config) ([]byte, error) {
+	var pt []byte
+	err := config.trace(ctx, "ecies.Decrypt", OperationDecrypt, func(ctx context.Context, config Config) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if cd, ok := d.(ContextDecapsulator); ok {
+			d = DecapsulatorFunc(func(pub *PublicKey) (x, y *big.Int, err error) {
+				return cd.SharedPointContext(ctx, pub)
+			})
+		}
+
+		var err error
+		pt, err = DecryptWithConf(d, msg, config)
+		return err
+	})
+
+	return pt, err
+}
+
+// GenerateKeyContext generates key pair on the config curve (see WithCurve), secp256k1 if it is not set;
+// generation is reported to the config observer and traced with the config tracer
+func GenerateKeyContext(ctx context.Context, config Config) (*PrivateKey, error) {
+	var k *PrivateKey
+	err := config.trace(ctx, "ecies.GenerateKey", OperationGenerateKey, func(ctx context.Context, config Config) (err error) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		curve, err := configCurve(config)
+		if err != nil {
+			return err
+		}
+		if curve == nil {
+			curve = getCurve()
+		}
+		defer config.observe(OperationGenerateKey, curve, 0, time.Now(), &err)
+
+		if curve == getCurve() {
+			k, err = GenerateKey()
+		} else {
+			k, err = GenerateKeyCurve(curve)
+		}
+		return err
+	})
+
+	return k, err
+}
+
+// NewEncryptWriterContext returns writer which encrypts data written to it with a receiver public key
+// and the passed config; writes fail once ctx is done
+func NewEncryptWriterContext(ctx context.Context, w io.Writer, pubkey *PublicKey, config Config) (io.WriteCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s, err := newEncryptWriter(w, pubkey, config)
+	if err != nil {
+		return nil, err
+	}
+	s.ctx = ctx
+
+	return s, nil
+}
+
+// NewDecryptReaderContext returns reader which decrypts chunked ciphertext from r with a receiver private key
+// and the passed config; reads fail once ctx is done
+func NewDecryptReaderContext(ctx context.Context, r io.Reader, privkey *PrivateKey, config Config) (io.Reader, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s, err := newDecryptReader(r, privkey, config)
+	if err != nil {
+		return nil, err
+	}
+	s.ctx = ctx
+
+	return s, nil
+}
+
+// EncryptFileContext encrypts file at srcPath with a receiver public key and the passed config,
+// writes chunked ciphertext to dstPath; dstPath is removed if ctx is done before encryption completes
+func EncryptFileContext(ctx context.Context, pubkey *PublicKey, srcPath, dstPath string, config Config) error {
+	return transformFile(srcPath, dstPath, func(src io.Reader, dst io.Writer) error {
+		w, err := NewEncryptWriterContext(ctx, dst, pubkey, config)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.Copy(w, src); err != nil {
+			return err
+		}
+
+		return w.Close()
+	})
+}
+
+// DecryptFileContext decrypts file produced by EncryptFileConf with a receiver private key and the passed config;
+// dstPath is removed if decryption fails or ctx is done before it completes
+func DecryptFileContext(ctx context.Context, privkey *PrivateKey, srcPath, dstPath string, config Config) error {
+	return transformFile(srcPath, dstPath, func(src io.Reader, dst io.Writer) error {
+		r, err := NewDecryptReaderContext(ctx, src, privkey, config)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(dst, r)
+		return err
+	})
+}
+
+// EncryptBatchContext encrypts messages with a receiver public key and the passed config in parallel;
+// workers stop taking new messages once ctx is done
+func EncryptBatchContext(ctx context.Context, pubkey *PublicKey, msgs [][]byte, config Config, workers int) ([][]byte, error) {
+	return runBatch(ctx, msgs, workers, func(msg []byte) ([]byte, error) {
+		return EncryptConf(pubkey, msg, config)
+	})
+}
+
+// DecryptBatchContext decrypts ciphertexts with a receiver private key and the passed config in parallel;
+// workers stop taking new ciphertexts once ctx is done
+func DecryptBatchContext(ctx context.Context, privkey *PrivateKey, cts [][]byte, config Config, workers int) ([][]byte, error) {
+	return runBatch(ctx, cts, workers, func(ct []byte) ([]byte, error) {
+		return DecryptConf(privkey, ct, config)
+	})
+}
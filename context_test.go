@@ -0,0 +1,132 @@
+package eciesgo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type contextKey struct{}
+
+// testContextDecapsulator checks that context is passed to it
+type testContextDecapsulator struct {
+	*PrivateKey
+	t *testing.T
+}
+
+func (d testContextDecapsulator) SharedPointContext(ctx context.Context, pub *PublicKey) (x, y *big.Int, err error) {
+	assert.Equal(d.t, "value", ctx.Value(contextKey{}))
+	return d.SharedPoint(pub)
+}
+
+func TestEncryptAndDecryptContext(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ctx := context.WithValue(context.Background(), contextKey{}, "value")
+	ciphertext, err := EncryptContext(ctx, privkey.PublicKey, []byte(testingMessage), DEFAULT_CONFIG)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	plaintext, err := DecryptContext(ctx, privkey, ciphertext, DEFAULT_CONFIG)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(plaintext))
+
+	plaintext, err = DecryptWithContext(ctx, testContextDecapsulator{privkey, t}, ciphertext, DEFAULT_CONFIG)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(plaintext))
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = EncryptContext(cancelled, privkey.PublicKey, []byte(testingMessage), DEFAULT_CONFIG)
+	assert.True(t, errors.Is(err, context.Canceled), err)
+	_, err = DecryptContext(cancelled, privkey, ciphertext, DEFAULT_CONFIG)
+	assert.True(t, errors.Is(err, context.Canceled), err)
+}
+
+func TestEncryptWriterContext(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var buf bytes.Buffer
+	w, err := NewEncryptWriterContext(ctx, &buf, privkey.PublicKey, DEFAULT_CONFIG)
+	if !assert.NoError(t, err) {
+		return
+	}
+	// Full chunk is written once more data comes, as the last chunk is written on Close
+	if _, err := w.Write(make([]byte, streamChunkSize+1)); !assert.NoError(t, err) {
+		return
+	}
+
+	cancel()
+	_, err = w.Write(make([]byte, streamChunkSize))
+	assert.True(t, errors.Is(err, context.Canceled), err)
+
+	// Reader stops at the first chunk after cancellation
+	readCtx, readCancel := context.WithCancel(context.Background())
+	defer readCancel()
+
+	r, err := NewDecryptReaderContext(readCtx, &buf, privkey, DEFAULT_CONFIG)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if _, err := r.Read(make([]byte, 16)); !assert.NoError(t, err) {
+		return
+	}
+
+	readCancel()
+	_, err = r.Read(make([]byte, streamChunkSize))
+	assert.NoError(t, err)
+	_, err = r.Read(make([]byte, streamChunkSize))
+	assert.True(t, errors.Is(err, context.Canceled), err)
+}
+
+func TestEncryptBatchContext(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	msgs := make([][]byte, 16)
+	for i := range msgs {
+		msgs[i] = []byte(testingMessage)
+	}
+
+	cts, err := EncryptBatchContext(context.Background(), privkey.PublicKey, msgs, DEFAULT_CONFIG, 4)
+	if !assert.NoError(t, err) {
+		return
+	}
+	pts, err := DecryptBatchContext(context.Background(), privkey, cts, DEFAULT_CONFIG, 4)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, msgs, pts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = EncryptBatchContext(ctx, privkey.PublicKey, msgs, DEFAULT_CONFIG, 4)
+	assert.True(t, errors.Is(err, context.Canceled), err)
+}
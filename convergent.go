@@ -0,0 +1,74 @@
+package eciesgo
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// Convergent encryption makes ciphertext a function of the plaintext, so deduplicating storage detects
+// duplicate blocks without decrypting them: content key is derived from the message and a convergence secret,
+// nonce is derived from the content key. It is opt-in and has inherent tradeoffs:
+//   - anyone with the secret confirms whether a guessed message (e.g. a known document with a few unknown fields)
+//     is stored, so the secret must be kept among parties who are allowed to deduplicate with each other;
+//   - equal blocks are visible as equal to the storage, which learns access and duplication patterns;
+//   - content key is the only secret of a block, share it with recipients separately, e.g. with Encrypt.
+//
+// Use Encrypt, which is randomized, unless deduplication is required
+
+// ConvergentKey derives content key of the message from the convergence secret with HKDF-SHA256,
+// its length is the key size of the config cipher
+func ConvergentKey(secret, msg []byte, config Config) ([]byte, error) {
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("convergence secret is empty")
+	}
+	if config.hpke != nil {
+		return nil, fmt.Errorf("convergent encryption is not supported in HPKE mode")
+	}
+
+	keySize, err := symmKeySize(config)
+	if err != nil {
+		return nil, err
+	}
+
+	key := make([]byte, keySize)
+	info := []byte("ecies/convergent-key/" + config.symmetricAlgorithm)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, msg, secret, info), key); err != nil {
+		return nil, fmt.Errorf("cannot read convergent key from HKDF reader: %w", err)
+	}
+
+	return key, nil
+}
+
+// EncryptConvergent deterministically encrypts a passed message with the config cipher, equal messages under equal
+// secret and config have equal ciphertexts; returns ciphertext (nonce, tag and ciphertext as of EncryptSymm)
+// and content key. See the tradeoffs above
+func EncryptConvergent(secret, msg []byte, config Config) (ct []byte, key []byte, err error) {
+	if err := checkPlaintextSize(config, len(msg)); err != nil {
+		return nil, nil, err
+	}
+
+	key, err = ConvergentKey(secret, msg, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Content key encrypts a single message, so the nonce derived from it is unique
+	ct, err = EncryptSymm(key, msg, config.WithNonceSource(DeterministicNonceSource))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ct, key, nil
+}
+
+// DecryptConvergent decrypts ciphertext produced by EncryptConvergent with its content key
+func DecryptConvergent(key, ct []byte, config Config) ([]byte, error) {
+	if config.hpke != nil {
+		return nil, fmt.Errorf("convergent encryption is not supported in HPKE mode")
+	}
+
+	return DecryptSymm(key, ct, config)
+}
@@ -0,0 +1,57 @@
+package eciesgo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptConvergent(t *testing.T) {
+	skipUnapproved(t)
+
+	secret := []byte("convergence secret")
+
+	for _, config := range []Config{DEFAULT_CONFIG, NewConfig("xchacha20", 0), NewConfig("aes-256-cbc-hmac-sha256", 0)} {
+		ct1, key1, err := EncryptConvergent(secret, []byte(testingMessage), config)
+		if !assert.NoError(t, err) {
+			return
+		}
+		ct2, key2, err := EncryptConvergent(secret, []byte(testingMessage), config)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		// Equal messages are deduplicated
+		assert.Equal(t, ct1, ct2)
+		assert.Equal(t, key1, key2)
+
+		pt, err := DecryptConvergent(key1, ct1, config)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(pt))
+
+		// Other message or secret gives other ciphertext
+		ct3, key3, err := EncryptConvergent(secret, []byte(testingMessage+"!"), config)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.NotEqual(t, key1, key3)
+		assert.NotEqual(t, ct1, ct3)
+
+		ct4, _, err := EncryptConvergent([]byte("another secret"), []byte(testingMessage), config)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.NotEqual(t, ct1, ct4)
+
+		_, err = DecryptConvergent(key3, ct1, config)
+		assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+	}
+
+	_, _, err := EncryptConvergent(nil, []byte(testingMessage), DEFAULT_CONFIG)
+	assert.Error(t, err)
+	_, _, err = EncryptConvergent(secret, []byte(testingMessage), DEFAULT_CONFIG.WithHPKE(HPKESuite{KDF: HPKEKDFHKDFSHA256, AEAD: HPKEAEADAES128GCM}))
+	assert.Error(t, err)
+}
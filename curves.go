@@ -0,0 +1,184 @@
+package eciesgo
+
+import (
+	"crypto/elliptic"
+	"fmt"
+	"sync"
+)
+
+// curveRegistry maps curve names to curves; names are also JWK "crv" names and test vector curves.
+// IDs identify curves in binary formats (envelope header, binary keys, keystore and key shares)
+var curveRegistry = struct {
+	sync.RWMutex
+	once   sync.Once
+	names  []string
+	curves map[string]elliptic.Curve
+	ids    map[string]byte
+}{}
+
+// MinUserCurveID is the smallest ID of curves registered with RegisterCurveID, smaller ones are reserved
+// for curves of the package
+const MinUserCurveID = 0x80
+
+// registerBuiltinCurves registers curves supported by the package; never reuse or renumber their IDs
+func registerBuiltinCurves() {
+	curveRegistry.curves = make(map[string]elliptic.Curve)
+	curveRegistry.ids = make(map[string]byte)
+	for _, c := range []struct {
+		name  string
+		id    byte
+		curve elliptic.Curve
+	}{
+		{"secp256k1", 0x01, getCurve()},
+		{"P-256", 0x02, elliptic.P256()},
+		{"P-384", 0x03, elliptic.P384()},
+		{"P-521", 0x04, elliptic.P521()},
+		{"brainpoolP256r1", 0x05, BrainpoolP256r1()},
+		{"brainpoolP384r1", 0x06, BrainpoolP384r1()},
+	} {
+		curveRegistry.names = append(curveRegistry.names, c.name)
+		curveRegistry.curves[c.name] = c.curve
+		curveRegistry.ids[c.name] = c.id
+	}
+}
+
+// RegisterCurve makes third-party short Weierstrass curve implementation available by name to CurveByName,
+// Config.WithCurve, JWK and test vectors; names of registered curves cannot be reused.
+// Only elliptic.Curve is accepted, as keys are points with affine coordinates (crypto/ecdh keys are converted
+// with NewPublicKeyFromECDH and NewPrivateKeyFromECDH).
+// The curve has no ID, so its keys are rejected by binary formats, use RegisterCurveID for them
+func RegisterCurve(name string, curve elliptic.Curve) error {
+	return registerCurve(name, 0, curve)
+}
+
+// RegisterCurveID registers curve as RegisterCurve does and assigns it ID written to envelope header,
+// binary keys, keystore and key shares; ID must be at least MinUserCurveID and cannot be reused
+func RegisterCurveID(name string, id byte, curve elliptic.Curve) error {
+	if id < MinUserCurveID {
+		return fmt.Errorf("curve %s ID %#x is reserved", name, id)
+	}
+
+	return registerCurve(name, id, curve)
+}
+
+// registerCurve registers curve with the ID, which is zero for curves without ID
+func registerCurve(name string, id byte, curve elliptic.Curve) error {
+	if name == "" {
+		return fmt.Errorf("curve name is empty")
+	}
+	if curve == nil {
+		return fmt.Errorf("curve %s is empty", name)
+	}
+	params := curve.Params()
+	if params == nil || params.P == nil || params.N == nil || params.Gx == nil || params.Gy == nil {
+		return fmt.Errorf("curve %s has no domain parameters", name)
+	}
+	if params.N.Sign() <= 0 || !curve.IsOnCurve(params.Gx, params.Gy) {
+		return fmt.Errorf("curve %s has invalid domain parameters", name)
+	}
+
+	curveRegistry.once.Do(registerBuiltinCurves)
+	curveRegistry.Lock()
+	defer curveRegistry.Unlock()
+
+	if _, ok := curveRegistry.curves[name]; ok {
+		return fmt.Errorf("curve %s is already registered", name)
+	}
+	if id != 0 {
+		for n, i := range curveRegistry.ids {
+			if i == id {
+				return fmt.Errorf("curve %s ID %#x is already registered for %s", name, id, n)
+			}
+		}
+		curveRegistry.ids[name] = id
+	}
+	curveRegistry.names = append(curveRegistry.names, name)
+	curveRegistry.curves[name] = curve
+
+	return nil
+}
+
+// CurveByName returns registered curve: "secp256k1", "P-256", "P-384", "P-521", "brainpoolP256r1",
+// "brainpoolP384r1" or one added with RegisterCurve
+func CurveByName(name string) (elliptic.Curve, error) {
+	curveRegistry.once.Do(registerBuiltinCurves)
+	curveRegistry.RLock()
+	defer curveRegistry.RUnlock()
+
+	curve, ok := curveRegistry.curves[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurve, name)
+	}
+
+	return curve, nil
+}
+
+// CurveName returns name the curve is registered with; curves are compared by domain parameters,
+// so keys of another implementation of a registered curve are named as well
+func CurveName(curve elliptic.Curve) (string, error) {
+	curveRegistry.once.Do(registerBuiltinCurves)
+	curveRegistry.RLock()
+	defer curveRegistry.RUnlock()
+
+	for _, name := range curveRegistry.names {
+		if sameCurve(curve, curveRegistry.curves[name]) {
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s is not registered", ErrUnsupportedCurve, curve.Params().Name)
+}
+
+// curveID returns ID of the registered curve, curves without ID are not supported
+func curveID(curve elliptic.Curve) (byte, error) {
+	if curve == nil {
+		return 0, fmt.Errorf("%w: curve is empty", ErrUnsupportedCurve)
+	}
+
+	name, err := CurveName(curve)
+	if err != nil {
+		return 0, err
+	}
+
+	curveRegistry.RLock()
+	defer curveRegistry.RUnlock()
+
+	id, ok := curveRegistry.ids[name]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s has no ID", ErrUnsupportedCurve, name)
+	}
+
+	return id, nil
+}
+
+// curveByID returns name and curve registered with the ID
+func curveByID(id byte) (string, elliptic.Curve, error) {
+	curveRegistry.once.Do(registerBuiltinCurves)
+	curveRegistry.RLock()
+	defer curveRegistry.RUnlock()
+
+	for name, i := range curveRegistry.ids {
+		if i == id {
+			return name, curveRegistry.curves[name], nil
+		}
+	}
+
+	return "", nil, fmt.Errorf("%w: unknown curve ID %d", ErrUnsupportedCurve, id)
+}
+
+// WithCurve returns copy of config with receiver curve set by registered name: encryption rejects public keys
+// of other curves, decryption with Decapsulator parses ephemeral keys of the curve (instead of secp256k1)
+// and CiphertextLength counts its point size
+func (c Config) WithCurve(name string) Config {
+	c.curve = name
+	return c
+}
+
+// configCurve returns curve set with WithCurve, or nil if it is not set
+func configCurve(conf Config) (elliptic.Curve, error) {
+	if conf.curve == "" {
+		return nil, nil
+	}
+
+	return CurveByName(conf.curve)
+}
@@ -0,0 +1,153 @@
+package eciesgo
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurveRegistry(t *testing.T) {
+	for _, name := range []string{"secp256k1", "P-256", "P-384", "P-521", "brainpoolP256r1", "brainpoolP384r1"} {
+		curve, err := CurveByName(name)
+		if !assert.NoError(t, err, name) {
+			return
+		}
+		n, err := CurveName(curve)
+		assert.NoError(t, err)
+		assert.Equal(t, name, n)
+	}
+
+	_, err := CurveByName("curve25519")
+	assert.True(t, errors.Is(err, ErrUnsupportedCurve), err)
+
+	assert.Error(t, RegisterCurve("", elliptic.P256()))
+	assert.Error(t, RegisterCurve("P-256", elliptic.P256()))
+	assert.Error(t, RegisterCurve("empty", nil))
+
+	invalid := *elliptic.P256().Params()
+	invalid.Name = "invalid"
+	invalid.Gy = new(big.Int).Add(invalid.Gy, big.NewInt(1))
+	assert.Error(t, RegisterCurve(invalid.Name, &invalid))
+}
+
+func TestRegisterCurve(t *testing.T) {
+	skipUnapproved(t)
+
+	// Twisted Brainpool curve has a = -3, so generic CurveParams implementation works for it
+	twisted := BrainpoolP256r1().(*rcurve).twisted
+	if _, err := CurveByName("brainpoolP256t1"); err != nil {
+		if !assert.NoError(t, RegisterCurve("brainpoolP256t1", twisted)) {
+			return
+		}
+	}
+
+	privkey, err := GenerateKeyCurve(twisted)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// JWK uses registered name
+	b, err := privkey.JWK()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, string(b), `"crv":"brainpoolP256t1"`)
+	decoded, err := NewPrivateKeyFromJWK(b)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, decoded.Equals(privkey))
+
+	config := DEFAULT_CONFIG.WithCurve("brainpoolP256t1")
+	ct, err := EncryptConf(privkey.PublicKey, []byte(testingMessage), config)
+	if !assert.NoError(t, err) {
+		return
+	}
+	pt, err := DecryptWithConf(DecapsulatorFunc(privkey.SharedPoint), ct, config)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(pt))
+}
+
+func TestConfig_WithCurve(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Public key of another curve is rejected
+	_, err = EncryptConf(privkey.PublicKey, []byte(testingMessage), DEFAULT_CONFIG.WithCurve("P-256"))
+	assert.True(t, errors.Is(err, ErrInvalidPublicKey), err)
+	_, err = EncryptConf(privkey.PublicKey, []byte(testingMessage), DEFAULT_CONFIG.WithCurve("unknown"))
+	assert.True(t, errors.Is(err, ErrUnsupportedCurve), err)
+
+	// Ciphertext length accounts for point size
+	l256, err := CiphertextLength(DEFAULT_CONFIG, 10)
+	if !assert.NoError(t, err) {
+		return
+	}
+	l384, err := CiphertextLength(DEFAULT_CONFIG.WithCurve("P-384"), 10)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, l256+32, l384)
+
+	p384, err := GenerateKeyCurve(elliptic.P384())
+	if !assert.NoError(t, err) {
+		return
+	}
+	ct, err := EncryptConf(p384.PublicKey, make([]byte, 10), DEFAULT_CONFIG.WithCurve("P-384"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, ct, l384)
+}
+
+func TestRegisterCurveID(t *testing.T) {
+	skipUnapproved(t)
+
+	for name, id := range map[string]byte{"secp256k1": 0x01, "P-256": 0x02, "P-384": 0x03, "P-521": 0x04,
+		"brainpoolP256r1": 0x05, "brainpoolP384r1": 0x06} {
+		curve, err := CurveByName(name)
+		if !assert.NoError(t, err) {
+			return
+		}
+		i, err := curveID(curve)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, id, i, name)
+	}
+
+	// Twisted Brainpool curve is not registered by other tests, which register the 256-bit one without ID
+	twisted := BrainpoolP384r1().(*rcurve).twisted
+	assert.Error(t, RegisterCurveID("reserved", 0x07, twisted))
+	assert.Error(t, RegisterCurveID("brainpoolP384r1", 0x81, twisted))
+	if _, err := CurveByName("brainpoolP384t1"); err != nil {
+		if !assert.NoError(t, RegisterCurveID("brainpoolP384t1", 0x80, twisted)) {
+			return
+		}
+	}
+	assert.Error(t, RegisterCurveID("another", 0x80, elliptic.P256()))
+
+	privkey, err := GenerateKeyCurve(twisted)
+	if !assert.NoError(t, err) {
+		return
+	}
+	b, err := privkey.MarshalBinary()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, byte(0x80), b[1])
+	var decoded PrivateKey
+	if !assert.NoError(t, decoded.UnmarshalBinary(b)) {
+		return
+	}
+	assert.True(t, privkey.PublicKey.Equals(decoded.PublicKey))
+}
@@ -0,0 +1,64 @@
+package eciesgo
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Decapsulator performs the private key operation of key decapsulation: multiplication of sender ephemeral
+// public key by receiver private scalar; implement it to keep the private key in HSM, TPM or cloud KMS.
+// Y coordinate may be nil if the backend returns X coordinate only (e.g. PKCS#11 ECDH1_DERIVE),
+// then both candidate points are tried during decryption
+type Decapsulator interface {
+	SharedPoint(pub *PublicKey) (x, y *big.Int, err error)
+}
+
+// DecapsulatorFunc is an adapter to allow the use of ordinary functions as Decapsulator
+type DecapsulatorFunc func(pub *PublicKey) (x, y *big.Int, err error)
+
+// SharedPoint calls f(pub)
+func (f DecapsulatorFunc) SharedPoint(pub *PublicKey) (x, y *big.Int, err error) {
+	return f(pub)
+}
+
+// SharedPoint multiplies validated public key by private key scalar, makes PrivateKey a Decapsulator
+func (k *PrivateKey) SharedPoint(pub *PublicKey) (x, y *big.Int, err error) {
+	return k.sharedPoint(pub)
+}
+
+// DecryptWithConf decrypts a passed message delegating private key operation to the decapsulator
+func DecryptWithConf(d Decapsulator, msg []byte, config Config) (_ []byte, err error) {
+	if d == nil {
+		return nil, fmt.Errorf("decapsulator is empty")
+	}
+
+	if privkey, ok := d.(*PrivateKey); ok {
+		return DecryptConf(privkey, msg, config)
+	}
+
+	curve, _ := configCurve(config)
+	if curve == nil {
+		curve = getCurve()
+	}
+	defer config.observe(OperationDecrypt, curve, len(msg), time.Now(), &err)
+
+	if err := checkCiphertextSize(config, curve, len(msg)); err != nil {
+		return nil, err
+	}
+
+	msg, config, err = openEnvelope(msg, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.hpke != nil {
+		return nil, fmt.Errorf("HPKE mode requires private key")
+	}
+
+	return decryptAppend(nil, d, msg, config)
+}
+
+func DecryptWith(d Decapsulator, msg []byte) ([]byte, error) {
+	return DecryptWithConf(d, msg, DefaultConfig())
+}
@@ -0,0 +1,70 @@
+package eciesgo
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecryptWith(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Backend which returns X coordinate only, like most HSMs do
+	xOnly := DecapsulatorFunc(func(pub *PublicKey) (*big.Int, *big.Int, error) {
+		x, _, err := privkey.SharedPoint(pub)
+		return x, nil, err
+	})
+	// Backend which returns the whole point
+	point := DecapsulatorFunc(privkey.SharedPoint)
+
+	for _, conf := range []Config{DEFAULT_CONFIG, NewConfig("xchacha20", 0).WithEnvelope()} {
+		// Repeat to hit both Y candidates
+		for i := 0; i < 8; i++ {
+			ciphertext, err := EncryptConf(privkey.PublicKey, []byte(testingMessage), conf)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			for _, d := range []Decapsulator{privkey, xOnly, point} {
+				plaintext, err := DecryptWithConf(d, ciphertext, conf)
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.Equal(t, testingMessage, string(plaintext))
+			}
+		}
+	}
+}
+
+func TestDecryptWithWrongKey(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	other, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ciphertext, err := Encrypt(privkey.PublicKey, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = DecryptWith(DecapsulatorFunc(func(pub *PublicKey) (*big.Int, *big.Int, error) {
+		x, _, err := other.SharedPoint(pub)
+		return x, nil, err
+	}), ciphertext)
+	assert.Error(t, err)
+
+	_, err = DecryptWith(nil, ciphertext)
+	assert.Error(t, err)
+}
@@ -0,0 +1,257 @@
+//go:build ecies_dudect
+// +build ecies_dudect
+
+package eciesgo
+
+import (
+	"crypto/rand"
+	"math"
+	"math/big"
+	"sort"
+	"testing"
+	"time"
+)
+
+// Statistical constant time tests in the style of dudect (Reparaz, Balasch, Verbauwhede, 2017):
+// execution times for two classes of secret inputs, fixed and random, are compared with Welch's t-test.
+// They are slow and sensitive to machine load, so they run with -tags ecies_dudect only;
+// run them on an idle machine, both with and without CGO
+
+const (
+	// dudectLeakThreshold is |t| above which timings differ with overwhelming probability
+	dudectLeakThreshold = 10
+	// dudectWarnThreshold is |t| above which a leak is suspected and more measurements are needed
+	dudectWarnThreshold = 4.5
+	// dudectCropPercentile drops slowest measurements, which are mostly interrupts and scheduling
+	dudectCropPercentile = 0.9
+)
+
+// welch accumulates running mean and variance of two classes (Welford's algorithm)
+type welch struct {
+	n, mean, m2 [2]float64
+}
+
+func (w *welch) push(class int, x float64) {
+	w.n[class]++
+	delta := x - w.mean[class]
+	w.mean[class] += delta / w.n[class]
+	w.m2[class] += delta * (x - w.mean[class])
+}
+
+// t returns Welch's t statistic of the two classes
+func (w *welch) t() float64 {
+	v0 := w.m2[0] / (w.n[0] - 1)
+	v1 := w.m2[1] / (w.n[1] - 1)
+	return (w.mean[0] - w.mean[1]) / math.Sqrt(v0/w.n[0]+v1/w.n[1])
+}
+
+// dudect runs measure n times in random class order; prepare(i, class) sets up input i of the class
+// before measurements start, measure(i) is the timed operation, repeated inner times per measurement
+func dudect(t *testing.T, n, inner int, prepare func(i, class int), measure func(i int)) {
+	classes := make([]byte, n)
+	if _, err := rand.Read(classes); err != nil {
+		t.Fatal(err)
+	}
+	for i := range classes {
+		classes[i] &= 1
+		prepare(i, int(classes[i]))
+	}
+
+	// Warm up caches and branch predictors
+	for i := 0; i < n/10; i++ {
+		measure(i)
+	}
+
+	timings := make([]float64, n)
+	for i := range timings {
+		start := time.Now()
+		for j := 0; j < inner; j++ {
+			measure(i)
+		}
+		timings[i] = float64(time.Since(start))
+	}
+
+	sorted := append([]float64(nil), timings...)
+	sort.Float64s(sorted)
+	crop := sorted[int(float64(n-1)*dudectCropPercentile)]
+
+	var w welch
+	for i, x := range timings {
+		if x <= crop {
+			w.push(int(classes[i]), x)
+		}
+	}
+
+	tt := w.t()
+	t.Logf("t = %.2f, means %.0fns and %.0fns, %.0f and %.0f measurements",
+		tt, w.mean[0]/float64(inner), w.mean[1]/float64(inner), w.n[0], w.n[1])
+	switch {
+	case math.Abs(tt) > dudectLeakThreshold:
+		t.Errorf("timing depends on secret input: |t| = %.2f", math.Abs(tt))
+	case math.Abs(tt) > dudectWarnThreshold:
+		t.Logf("timing leak is suspected, repeat with more measurements")
+	}
+}
+
+// TestDudectScalarMult checks KEM scalar multiplication with a fixed low Hamming weight scalar against random ones
+func TestDudectScalarMult(t *testing.T) {
+	peer, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 4000
+	fixed := make([]byte, 32)
+	fixed[31] = 1
+	scalars := make([][]byte, n)
+
+	dudect(t, n, 1, func(i, class int) {
+		if class == 0 {
+			scalars[i] = fixed
+			return
+		}
+		k, err := GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		scalars[i] = k.Bytes()
+	}, func(i int) {
+		scalarMult(peer.Curve, peer.X, peer.Y, scalars[i])
+	})
+}
+
+// TestDudectScalarBaseMult checks base point multiplication of key generation and signature nonces
+// with a fixed low Hamming weight scalar against random ones
+func TestDudectScalarBaseMult(t *testing.T) {
+	curve := getCurve()
+
+	const n = 4000
+	fixed := make([]byte, 32)
+	fixed[31] = 1
+	scalars := make([][]byte, n)
+
+	dudect(t, n, 1, func(i, class int) {
+		if class == 0 {
+			scalars[i] = fixed
+			return
+		}
+		k, err := GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		scalars[i] = k.Bytes()
+	}, func(i int) {
+		scalarBaseMult(curve, scalars[i])
+	})
+}
+
+// TestDudectSecretInverse checks signature nonce inversion of a fixed small nonce against random ones
+func TestDudectSecretInverse(t *testing.T) {
+	curve := getCurve()
+
+	const n = 4000
+	fixed := big.NewInt(1)
+	nonces := make([]*big.Int, n)
+
+	dudect(t, n, 1, func(i, class int) {
+		if class == 0 {
+			nonces[i] = fixed
+			return
+		}
+		k, err := GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		nonces[i] = k.D
+	}, func(i int) {
+		if _, err := secretInverse(curve, nonces[i]); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// TestDudectEquals checks private key comparison of equal keys against keys differing in the first byte
+func TestDudectEquals(t *testing.T) {
+	k, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 20000
+	others := make([]*PrivateKey, n)
+
+	dudect(t, n, 100, func(i, class int) {
+		d := new(big.Int).Set(k.D)
+		if class == 1 {
+			d.SetBit(d, 255-i%8, d.Bit(255-i%8)^1)
+		}
+		others[i] = &PrivateKey{PublicKey: k.PublicKey, D: d}
+	}, func(i int) {
+		k.Equals(others[i])
+	})
+}
+
+// TestDudectScalarEqual checks Scalar comparison of equal scalars against random ones
+func TestDudectScalarEqual(t *testing.T) {
+	k, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := k.Scalar()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 20000
+	others := make([]*Scalar, n)
+
+	dudect(t, n, 100, func(i, class int) {
+		if class == 0 {
+			others[i] = s.Add(s).Add(s.Negate())
+			return
+		}
+		other, err := GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if others[i], err = other.Scalar(); err != nil {
+			t.Fatal(err)
+		}
+	}, func(i int) {
+		s.Equal(others[i])
+	})
+}
+
+// TestDudectDecompressY checks secp256k1 point decompression of a fixed X coordinate against random ones
+func TestDudectDecompressY(t *testing.T) {
+	k, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 20000
+	xs := make([]*big.Int, n)
+	odd := make([]bool, n)
+
+	dudect(t, n, 10, func(i, class int) {
+		if class == 0 {
+			xs[i], odd[i] = k.X, k.Y.Bit(0) == 1
+			return
+		}
+		other, err := GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Random X coordinates with leading zero bytes are rare, keep the fixed width of class 0
+		for other.X.BitLen() <= 248 {
+			if other, err = GenerateKey(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		xs[i], odd[i] = other.X, other.Y.Bit(0) == 1
+	}, func(i int) {
+		if _, err := decompressY(k.Curve, xs[i], odd[i]); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
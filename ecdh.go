@@ -0,0 +1,99 @@
+//go:build go1.20
+// +build go1.20
+
+package eciesgo
+
+import (
+	"crypto/ecdh"
+	"crypto/elliptic"
+	"fmt"
+	"math/big"
+)
+
+// ecdhCurve returns crypto/ecdh counterpart of the curve;
+// crypto/ecdh supports only NIST curves, secp256k1 keys cannot be converted
+func ecdhCurve(curve elliptic.Curve) (ecdh.Curve, error) {
+	switch curve {
+	case elliptic.P256():
+		return ecdh.P256(), nil
+	case elliptic.P384():
+		return ecdh.P384(), nil
+	case elliptic.P521():
+		return ecdh.P521(), nil
+	default:
+		return nil, fmt.Errorf("curve %s is not supported by crypto/ecdh", curve.Params().Name)
+	}
+}
+
+func ellipticCurve(curve ecdh.Curve) (elliptic.Curve, error) {
+	switch curve {
+	case ecdh.P256():
+		return elliptic.P256(), nil
+	case ecdh.P384():
+		return elliptic.P384(), nil
+	case ecdh.P521():
+		return elliptic.P521(), nil
+	default:
+		return nil, fmt.Errorf("curve %s is not a short Weierstrass curve", curve)
+	}
+}
+
+// ToECDH converts public key to crypto/ecdh public key
+func (k *PublicKey) ToECDH() (*ecdh.PublicKey, error) {
+	curve, err := ecdhCurve(k.Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	l := (k.Curve.Params().BitSize + 7) / 8
+	b := make([]byte, 1+2*l)
+	b[0] = 0x04
+	putBytes(b[1:1+l], k.X.Bytes())
+	putBytes(b[1+l:], k.Y.Bytes())
+
+	return curve.NewPublicKey(b)
+}
+
+// ToECDH converts private key to crypto/ecdh private key
+func (k *PrivateKey) ToECDH() (*ecdh.PrivateKey, error) {
+	curve, err := ecdhCurve(k.Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, (k.Curve.Params().BitSize+7)/8)
+	putBytes(b, k.D.Bytes())
+
+	return curve.NewPrivateKey(b)
+}
+
+// NewPublicKeyFromECDH converts crypto/ecdh public key to PublicKey instance
+func NewPublicKeyFromECDH(pub *ecdh.PublicKey) (*PublicKey, error) {
+	curve, err := ellipticCurve(pub.Curve())
+	if err != nil {
+		return nil, err
+	}
+
+	// Uncompressed point encoding
+	b := pub.Bytes()
+	l := (len(b) - 1) / 2
+
+	return &PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(b[1 : 1+l]),
+		Y:     new(big.Int).SetBytes(b[1+l:]),
+	}, nil
+}
+
+// NewPrivateKeyFromECDH converts crypto/ecdh private key to PrivateKey instance
+func NewPrivateKeyFromECDH(priv *ecdh.PrivateKey) (*PrivateKey, error) {
+	pub, err := NewPublicKeyFromECDH(priv.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+
+	return &PrivateKey{
+		PublicKey: pub,
+		D:         new(big.Int).SetBytes(priv.Bytes()),
+	}, nil
+}
@@ -0,0 +1,77 @@
+//go:build go1.20
+// +build go1.20
+
+package eciesgo
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrivateKey_ToECDH(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// secp256k1 is not supported by crypto/ecdh
+	_, err = privkey.ToECDH()
+	assert.Error(t, err)
+	_, err = privkey.PublicKey.ToECDH()
+	assert.Error(t, err)
+}
+
+func TestNewPrivateKeyFromECDH(t *testing.T) {
+	ecdhPrivkey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ecdhPeer, err := ecdh.P256().GenerateKey(rand.Reader)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	privkey, err := NewPrivateKeyFromECDH(ecdhPrivkey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	peer, err := NewPublicKeyFromECDH(ecdhPeer.PublicKey())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Shared secret matches crypto/ecdh one
+	expected, err := ecdhPrivkey.ECDH(ecdhPeer.PublicKey())
+	if !assert.NoError(t, err) {
+		return
+	}
+	ss, err := privkey.ECDH(peer)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, expected, ss[1:])
+
+	x, err := privkey.ECDHX(peer)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, expected, x)
+
+	// Round trip
+	converted, err := privkey.ToECDH()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, ecdhPrivkey.Equal(converted))
+
+	convertedPub, err := peer.ToECDH()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, ecdhPeer.PublicKey().Equal(convertedPub))
+}
@@ -1,81 +1,505 @@
 package eciesgo
 
 import (
-	"bytes"
+	"crypto/elliptic"
+	"crypto/sha256"
 	"fmt"
+	"io"
 	"math/big"
+	"sync/atomic"
+	"time"
 )
 
 type Config struct {
 	symmetricAlgorithm   string
 	symmetricNonceLength int
+
+	kdfFunction string
+	kdfHash     string
+	kdfSalt     []byte
+	kdfInfo     []byte
+	// passwordKDF holds cost parameters of "scrypt" and "argon2id" KDFs, see WithPasswordKDF
+	passwordKDF *PasswordKDFParams
+
+	// kdfSharedPointOnly leaves ephemeral public key out of the KDF input, see WithSharedPointKDF
+	kdfSharedPointOnly bool
+	// kdfKeySeparation expands KDF output into independent labeled keys, see WithKeySeparation
+	kdfKeySeparation bool
+
+	hpke        *HPKESuite
+	envelope    bool
+	nonceSource NonceSource
+
+	keyCommitment bool
+	wipeSecrets   bool
+
+	// kdfSuffix is appended to the KDF input: static shared point in sender-authenticated mode (see EncryptAuth),
+	// ML-KEM shared secret in hybrid mode (see EncryptHybrid)
+	kdfSuffix []byte
+	// kdfLength overrides KDF output length, which is the symmetric key size by default; see DeriveKeys
+	kdfLength int
+
+	// observer receives reports of encryption and decryption, see WithObserver
+	observer Observer
+	// tracer starts spans around operations called with context, see WithTracer
+	tracer Tracer
+
+	// curve is the registered name of receiver curve, see WithCurve
+	curve string
+
+	// maxMessageSize limits plaintext length, 0 means no limit; see WithMaxMessageSize
+	maxMessageSize int
+	// trustStore holds keys encryption is allowed to, see WithTrustStore
+	trustStore *TrustStore
+	// keyResolver finds recipient keys of EncryptTo, see WithKeyResolver
+	keyResolver KeyResolver
+
+	rand io.Reader
 }
 
-var DEFAULT_CONFIG = Config{symmetricAlgorithm: "aes-256-gcm", symmetricNonceLength: 16}
+// DEFAULT_CONFIG holds library defaults; functions without config argument use DefaultConfig, which is DEFAULT_CONFIG
+// until SetDefaultConfig is called. Assigning to DEFAULT_CONFIG while other goroutines encrypt is a data race
+var DEFAULT_CONFIG = Config{symmetricAlgorithm: "aes-256-gcm", symmetricNonceLength: 16, kdfHash: "sha256"}
+
+// defaultConfig holds config set with SetDefaultConfig
+var defaultConfig atomic.Value
+
+// DefaultConfig returns config used by functions without config argument (Encrypt, Decrypt, NewEncryptWriter etc.)
+func DefaultConfig() Config {
+	if config, ok := defaultConfig.Load().(Config); ok {
+		return config
+	}
+
+	return DEFAULT_CONFIG
+}
+
+// SetDefaultConfig atomically replaces config used by functions without config argument; it is safe to call
+// concurrently with encryption, calls in progress keep the config they started with.
+// To use different settings per call, pass config to Conf (or Context) variants of the functions instead
+func SetDefaultConfig(config Config) {
+	defaultConfig.Store(config)
+}
+
+// NewConfig returns config with the given symmetric algorithm ("aes-256-gcm", "aes-192-gcm", "aes-128-gcm",
+// "xchacha20", "chacha20poly1305" or legacy "aes-256-cbc-hmac-sha256") and nonce length
+// (used by AES-GCM only, ChaCha20 ciphers have fixed nonce lengths of 24 and 12 bytes, AES-CBC has 16 bytes IV);
+// other parameters are taken from DEFAULT_CONFIG
+func NewConfig(symmetricAlgorithm string, symmetricNonceLength int) Config {
+	config := DEFAULT_CONFIG
+	config.symmetricAlgorithm = symmetricAlgorithm
+	config.symmetricNonceLength = symmetricNonceLength
+	return config
+}
+
+// WithKDFHash returns copy of config with HKDF hash function set ("sha256", "sha512" or "blake2b")
+func (c Config) WithKDFHash(hash string) Config {
+	c.kdfHash = hash
+	return c
+}
+
+// WithKDF returns copy of config with key derivation function set: "hkdf" (default, RFC 5869)
+// or "x963" (ANSI X9.63 counter mode KDF, used by Apple and BouncyCastle ECIES);
+// both use the configured hash, X9.63 takes KDF info as SharedInfo and ignores salt.
+// Memory-hard "scrypt" and "argon2id" are meant for low-entropy secrets, see WithPasswordKDF
+func (c Config) WithKDF(function string) Config {
+	c.kdfFunction = function
+	return c
+}
+
+// WithPasswordKDF returns copy of config with memory-hard KDF ("scrypt" or "argon2id", taken from params.Algorithm)
+// and its cost parameters set, for keys derived from low-entropy secrets such as passwords and PINs;
+// it requires KDF salt and ignores hash and info. WithKDF("scrypt") or WithKDF("argon2id") alone uses
+// DEFAULT_SCRYPT_PARAMS or DEFAULT_ARGON2ID_PARAMS
+func (c Config) WithPasswordKDF(params PasswordKDFParams) Config {
+	c.kdfFunction = params.Algorithm
+	c.passwordKDF = &params
+	return c
+}
+
+// WithSharedPointKDF returns copy of config which derives keys from the shared point only, leaving
+// the ephemeral public key out of the KDF input. It is an anonymity profile for drop box style receivers:
+// ECIES ciphertexts carry nothing about the sender and do not reveal the receiver public key anyway, and with
+// this option the key is not bound to a particular ephemeral key encoding, so ciphertexts are not tied to
+// the way it was constructed. Combine it with the default format, since envelope header reveals the parameters;
+// sender-authenticated mode is rejected, as it identifies the sender by design
+func (c Config) WithSharedPointKDF() Config {
+	c.kdfSharedPointOnly = true
+	return c
+}
+
+// WithKeySeparation returns copy of config which treats KDF output as a master key and expands it with HKDF
+// (the configured hash) into independent keys with domain separation labels: encryption key, MAC key
+// of "aes-256-cbc-hmac-sha256" and key commitment, instead of slicing them from a single output
+// or deriving commitment from the encryption key itself
+func (c Config) WithKeySeparation() Config {
+	c.kdfKeySeparation = true
+	return c
+}
+
+// WithKDFSalt returns copy of config with HKDF salt set
+func (c Config) WithKDFSalt(salt []byte) Config {
+	c.kdfSalt = append([]byte(nil), salt...)
+	return c
+}
+
+// WithKDFInfo returns copy of config with HKDF info (context string) set;
+// use it for domain separation of derived keys
+func (c Config) WithKDFInfo(info []byte) Config {
+	c.kdfInfo = append([]byte(nil), info...)
+	return c
+}
+
+// WithHPKE returns copy of config which makes Encrypt and Decrypt use HPKE (RFC 9180) base mode
+// with the given suite; KDF info is used as HPKE info, ciphertext is encapsulated key followed by sealed message
+func (c Config) WithHPKE(suite HPKESuite) Config {
+	c.hpke = &suite
+	return c
+}
 
 // Encrypt encrypts a passed message with a receiver public key, returns ciphertext or encryption error
 func EncryptConf(pubkey *PublicKey, msg []byte, config Config) ([]byte, error) {
-	var ct bytes.Buffer
+	return EncryptAppendConf(nil, pubkey, msg, config)
+}
+
+func Encrypt(pubkey *PublicKey, msg []byte) ([]byte, error) {
+	return EncryptConf(pubkey, msg, DefaultConfig())
+}
+
+// EncryptAppendConf encrypts a passed message with a receiver public key and appends ciphertext to dst;
+// if dst has enough capacity, no allocation for ciphertext is made
+func EncryptAppendConf(dst []byte, pubkey *PublicKey, msg []byte, config Config) (_ []byte, err error) {
+	defer config.observe(OperationEncrypt, keyCurve(pubkey), len(msg), time.Now(), &err)
 
-	// Generate ephemeral key
-	ek, err := GenerateKey()
+	if err := checkPlaintextSize(config, len(msg)); err != nil {
+		return nil, err
+	}
+	if err := checkTrusted(config, pubkey); err != nil {
+		return nil, err
+	}
+
+	dst, config, err = appendEnvelope(dst, keyCurve(pubkey), config)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.hpke != nil {
+		return encryptHPKE(dst, pubkey, msg, config)
+	}
+
+	ek, err := generateEphemeralKey(pubkey, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.wipeSecrets {
+		defer ek.Zeroize()
+	}
+
+	return encryptWithEphemeral(dst, ek, pubkey, msg, config)
+}
+
+// generateEphemeralKey generates ephemeral key on the receiver curve, secp256k1 one if the receiver key is empty
+func generateEphemeralKey(pubkey *PublicKey, config Config) (*PrivateKey, error) {
+	curve := getCurve()
+	if pubkey != nil && pubkey.Curve != nil {
+		curve = pubkey.Curve
+	}
+
+	return generateKeyCurve(curve, config.random())
+}
+
+// EncryptWithEphemeral encrypts a passed message with a receiver public key using the passed ephemeral private key
+// instead of a generated one, for protocols which commit to the ephemeral key beforehand; ciphertext format is
+// the same as of Encrypt. Ephemeral key must be secret and must not be reused, the caller wipes it
+func EncryptWithEphemeral(ephemeral *PrivateKey, pubkey *PublicKey, msg []byte) ([]byte, error) {
+	return EncryptWithEphemeralConf(ephemeral, pubkey, msg, DefaultConfig())
+}
+
+// EncryptWithEphemeralConf encrypts a passed message with a receiver public key, the passed ephemeral private key
+// and config; HPKE mode is not supported, as it generates ephemeral key itself
+func EncryptWithEphemeralConf(ephemeral *PrivateKey, pubkey *PublicKey, msg []byte, config Config) (_ []byte, err error) {
+	defer config.observe(OperationEncrypt, keyCurve(pubkey), len(msg), time.Now(), &err)
+
+	if config.hpke != nil {
+		return nil, fmt.Errorf("external ephemeral key is not supported in HPKE mode")
+	}
+	if ephemeral == nil || ephemeral.PublicKey == nil || ephemeral.D == nil {
+		return nil, fmt.Errorf("%w: ephemeral key is empty", ErrInvalidPrivateKey)
+	}
+	if err := checkPlaintextSize(config, len(msg)); err != nil {
+		return nil, err
+	}
+	if err := checkTrusted(config, pubkey); err != nil {
+		return nil, err
+	}
+
+	dst, config, err := appendEnvelope(nil, keyCurve(pubkey), config)
 	if err != nil {
 		return nil, err
 	}
 
-	ct.Write(ek.PublicKey.Bytes(false))
+	return encryptWithEphemeral(dst, ephemeral, pubkey, msg, config)
+}
+
+// encryptWithEphemeral appends ephemeral public key, nonce, tag and ciphertext to dst
+func encryptWithEphemeral(dst []byte, ek *PrivateKey, pubkey *PublicKey, msg []byte, config Config) ([]byte, error) {
+	curve, err := configCurve(config)
+	if err != nil {
+		return nil, err
+	}
+	if curve != nil && pubkey != nil && pubkey.Curve != nil && !sameCurve(curve, pubkey.Curve) {
+		return nil, fmt.Errorf("%w: curve does not match config curve %s", ErrInvalidPublicKey, config.curve)
+	}
 
 	// Derive shared secret
-	ss, err := ek.Encapsulate(pubkey)
+	ss, err := ek.encapsulate(pubkey, config)
 	if err != nil {
 		return nil, err
 	}
+	if config.wipeSecrets {
+		defer zeroBytes(ss)
+	}
 
-	// Symmetrical encryption
-	ciphertext, err := EncryptSymm(ss, msg, config)
+	aead, err := generateSymmCipher(ss, config)
 	if err != nil {
 		return nil, err
 	}
 
-	ct.Write(ciphertext)
-	return ct.Bytes(), nil
+	nonceSize, tagSize, sealedSize := aead.NonceSize(), aead.Overhead(), sealedLength(aead, len(msg))
+	ephemeralSize := 1 + 2*((ek.Curve.Params().BitSize+7)/8)
+	ret, out := sliceForAppend(dst, ephemeralSize+nonceSize+sealedSize)
+
+	// Ephemeral public key
+	copy(out, ek.PublicKey.Bytes(false))
+
+	nonce := out[ephemeralSize : ephemeralSize+nonceSize]
+	if err := generateNonce(config, ss, nonce); err != nil {
+		return nil, err
+	}
+
+	// Symmetrical encryption, Seal produces ciphertext || tag, while tag goes first in our format
+	sealed := aead.Seal(out[ephemeralSize+nonceSize:ephemeralSize+nonceSize], nonce, msg, nil)
+
+	var buf [sha256.Size + commitmentLength]byte
+	body := sealedSize - tagSize
+	tag := append(buf[:0], sealed[body:]...)
+	copy(sealed[tagSize:], sealed[:body])
+	copy(sealed, tag)
+
+	return ret, nil
 }
 
-func Encrypt(pubkey *PublicKey, msg []byte) ([]byte, error) {
-	return EncryptConf(pubkey, msg, DEFAULT_CONFIG)
+func EncryptAppend(dst []byte, pubkey *PublicKey, msg []byte) ([]byte, error) {
+	return EncryptAppendConf(dst, pubkey, msg, DefaultConfig())
 }
 
 // Decrypt decrypts a passed message with a receiver private key, returns plaintext or decryption error
 func DecryptConf(privkey *PrivateKey, msg []byte, config Config) ([]byte, error) {
-	if len(msg) <= (1 + 32 + 32) {
-		return nil, fmt.Errorf("invalid length of message")
+	return DecryptAppendConf(nil, privkey, msg, config)
+}
+
+func Decrypt(privkey *PrivateKey, msg []byte) ([]byte, error) {
+	return DecryptConf(privkey, msg, DefaultConfig())
+}
+
+// DecryptAppendConf decrypts a passed message with a receiver private key and appends plaintext to dst;
+// if dst has enough capacity, no allocation for plaintext is made
+func DecryptAppendConf(dst []byte, privkey *PrivateKey, msg []byte, config Config) (_ []byte, err error) {
+	defer config.observe(OperationDecrypt, privateKeyCurve(privkey), len(msg), time.Now(), &err)
+
+	if err := checkCiphertextSize(config, privateKeyCurve(privkey), len(msg)); err != nil {
+		return nil, err
+	}
+
+	msg, config, err = openEnvelope(msg, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.hpke != nil {
+		return decryptHPKE(dst, privkey, msg, config)
+	}
+
+	if privkey == nil {
+		return nil, fmt.Errorf("%w: private key is empty", ErrInvalidPrivateKey)
+	}
+
+	return decryptAppend(dst, privkey, msg, config)
+}
+
+// ephemeralCurve returns curve of the ephemeral key decapsulated by d: ephemeral key is on the receiver curve,
+// external decapsulators are secp256k1 unless config curve (e.g. from envelope) is set; private keys of
+// another curve than the config one are rejected
+func ephemeralCurve(d Decapsulator, config Config) (elliptic.Curve, error) {
+	curve, err := configCurve(config)
+	if err != nil {
+		return nil, err
+	}
+	if privkey, ok := d.(*PrivateKey); ok && privkey != nil && privkey.PublicKey != nil && privkey.Curve != nil {
+		if curve != nil && !sameCurve(curve, privkey.Curve) {
+			return nil, fmt.Errorf("%w: curve does not match config curve %s", ErrInvalidPrivateKey, config.curve)
+		}
+		curve = privkey.Curve
+	}
+	if curve == nil {
+		curve = getCurve()
+	}
+
+	return curve, nil
+}
+
+// decryptAppend decrypts a passed message delegating shared point computation to the decapsulator
+func decryptAppend(dst []byte, d Decapsulator, msg []byte, config Config) ([]byte, error) {
+	curve, err := ephemeralCurve(d, config)
+	if err != nil {
+		return nil, err
+	}
+	l := (curve.Params().BitSize + 7) / 8
+	ephemeralSize := 1 + 2*l
+
+	if len(msg) <= ephemeralSize {
+		return nil, newParseError(ErrCiphertextTooShort, "ciphertext", len(msg), "ephemeral public key is truncated")
 	}
 
 	// Ephemeral sender public key
 	ethPubkey := &PublicKey{
-		Curve: getCurve(),
-		X:     new(big.Int).SetBytes(msg[1:33]),
-		Y:     new(big.Int).SetBytes(msg[33:65]),
+		Curve: curve,
+		X:     new(big.Int).SetBytes(msg[1 : 1+l]),
+		Y:     new(big.Int).SetBytes(msg[1+l : ephemeralSize]),
 	}
 
-	// Derive shared secret
-	ss, err := ethPubkey.Decapsulate(privkey)
+	// Derive shared secret; there are two candidates if decapsulator returns X coordinate only
+	keys, err := ethPubkey.decapsulateWith(d, config)
 	if err != nil {
 		return nil, err
 	}
+	if config.wipeSecrets {
+		defer func() {
+			for _, ss := range keys {
+				zeroBytes(ss)
+			}
+		}()
+	}
 
 	// Shift message
-	msg = msg[65:]
+	msg = msg[ephemeralSize:]
+
+	for i, ss := range keys {
+		aead, err := generateSymmCipher(ss, config)
+		if err != nil {
+			return nil, err
+		}
+
+		nonceSize, tagSize := aead.NonceSize(), aead.Overhead()
+		if len(msg) <= (nonceSize + tagSize) {
+			return nil, newParseError(ErrCiphertextTooShort, "ciphertext", ephemeralSize+len(msg), "nonce or tag is truncated")
+		}
 
-	// Symmetrical decryption
-	plaintext, err := DecryptSymm(ss, msg, config)
+		nonce := msg[:nonceSize]
+		tag := msg[nonceSize : nonceSize+tagSize]
+		ct := msg[nonceSize+tagSize:]
+
+		// Create Golang-accepted ciphertext right in the destination buffer and decrypt it in place
+		ret, out := sliceForAppend(dst, len(ct)+tagSize)
+		copy(out, ct)
+		copy(out[len(ct):], tag)
+
+		plaintext, err := aead.Open(out[:0], nonce, out, nil)
+		if err != nil {
+			if i < len(keys)-1 {
+				continue
+			}
+			return nil, fmt.Errorf("%w: %v", ErrAuthenticationFailed, err)
+		}
+
+		return ret[:len(dst)+len(plaintext)], nil
+	}
+
+	return nil, ErrAuthenticationFailed
+}
+
+// DecryptInPlace decrypts a passed message with a receiver private key reusing the message buffer for plaintext,
+// which is returned as a prefix of msg, so large (e.g. memory-mapped) ciphertexts are decrypted without
+// another allocation of their size; msg must be writable and its content is overwritten even if decryption fails
+func DecryptInPlace(privkey *PrivateKey, msg []byte) ([]byte, error) {
+	return DecryptInPlaceConf(privkey, msg, DefaultConfig())
+}
+
+// DecryptInPlaceConf decrypts a passed message in place with a receiver private key and the passed config,
+// see DecryptInPlace; HPKE mode is not supported
+func DecryptInPlaceConf(privkey *PrivateKey, msg []byte, config Config) (_ []byte, err error) {
+	defer config.observe(OperationDecrypt, privateKeyCurve(privkey), len(msg), time.Now(), &err)
+
+	if err := checkCiphertextSize(config, privateKeyCurve(privkey), len(msg)); err != nil {
+		return nil, err
+	}
+
+	buf := msg
+	msg, config, err = openEnvelope(msg, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.hpke != nil {
+		return nil, fmt.Errorf("in-place decryption is not supported in HPKE mode")
+	}
+	if privkey == nil || privkey.PublicKey == nil || privkey.Curve == nil {
+		return nil, fmt.Errorf("%w: private key is empty", ErrInvalidPrivateKey)
+	}
+
+	curve, err := ephemeralCurve(privkey, config)
 	if err != nil {
 		return nil, err
 	}
+	l := (curve.Params().BitSize + 7) / 8
+	ephemeralSize := 1 + 2*l
+	if len(msg) <= ephemeralSize {
+		return nil, newParseError(ErrCiphertextTooShort, "ciphertext", len(msg), "ephemeral public key is truncated")
+	}
+
+	ethPubkey := &PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(msg[1 : 1+l]),
+		Y:     new(big.Int).SetBytes(msg[1+l : ephemeralSize]),
+	}
+	ss, err := ethPubkey.decapsulate(privkey, config)
+	if err != nil {
+		return nil, err
+	}
+	if config.wipeSecrets {
+		defer zeroBytes(ss)
+	}
+
+	aead, err := generateSymmCipher(ss, config)
+	if err != nil {
+		return nil, err
+	}
+
+	msg = msg[ephemeralSize:]
+	nonceSize, tagSize := aead.NonceSize(), aead.Overhead()
+	if len(msg) <= (nonceSize + tagSize) {
+		return nil, newParseError(ErrCiphertextTooShort, "ciphertext", ephemeralSize+len(msg), "nonce or tag is truncated")
+	}
+
+	// Nonce and tag are saved before ciphertext is moved over them to the start of the buffer
+	// and followed by the tag, as Open expects
+	var tagBuf [sha256.Size + commitmentLength]byte
+	nonce := append([]byte(nil), msg[:nonceSize]...)
+	tag := append(tagBuf[:0], msg[nonceSize:nonceSize+tagSize]...)
+	ct := msg[nonceSize+tagSize:]
+
+	out := buf[:len(ct)+tagSize]
+	copy(out, ct)
+	copy(out[len(ct):], tag)
+
+	plaintext, err := aead.Open(out[:0], nonce, out, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAuthenticationFailed, err)
+	}
 
 	return plaintext, nil
 }
 
-func Decrypt(privkey *PrivateKey, msg []byte) ([]byte, error) {
-	return DecryptConf(privkey, msg, DEFAULT_CONFIG)
+func DecryptAppend(dst []byte, privkey *PrivateKey, msg []byte) ([]byte, error) {
+	return DecryptAppendConf(dst, privkey, msg, DefaultConfig())
 }
@@ -1,16 +1,21 @@
 package eciesgo
 
 import (
+	"bytes"
+	"crypto/elliptic"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"io"
 	"io/ioutil"
 	"math/big"
 	"net/http"
 	"net/url"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/chacha20poly1305"
 	"golang.org/x/crypto/hkdf"
 )
 
@@ -23,12 +28,17 @@ const pythonBackend = "https://eciespydemo-1-d5397785.deta.app/"
 var testingReceiverPrivkey = []byte{51, 37, 145, 156, 66, 168, 189, 189, 176, 19, 177, 30, 148, 104, 25, 140, 155, 42, 248, 190, 121, 110, 16, 174, 143, 148, 72, 129, 94, 113, 219, 58}
 
 func TestGenerateKey(t *testing.T) {
+	skipUnapproved(t)
+
 	_, err := GenerateKey()
 	assert.NoError(t, err)
 }
 
 func BenchmarkEncrypt(b *testing.B) {
-	privkey := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if err != nil {
+		b.Fatal(err)
+	}
 
 	msg := []byte(testingJsonMessage)
 	for i := 0; i < b.N; i++ {
@@ -40,7 +50,10 @@ func BenchmarkEncrypt(b *testing.B) {
 }
 
 func BenchmarkDecrypt(b *testing.B) {
-	privkey := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if err != nil {
+		b.Fatal(err)
+	}
 	msg := []byte(testingJsonMessage)
 
 	ciphertext, err := Encrypt(privkey.PublicKey, msg)
@@ -56,8 +69,81 @@ func BenchmarkDecrypt(b *testing.B) {
 	}
 }
 
+func BenchmarkEncryptAppend(b *testing.B) {
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if err != nil {
+		b.Fatal(err)
+	}
+	msg := []byte(testingJsonMessage)
+	buf := make([]byte, 0, 1024)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, err := EncryptAppend(buf[:0], privkey.PublicKey, msg)
+		if err != nil {
+			b.Fail()
+		}
+	}
+}
+
+func BenchmarkDecryptAppend(b *testing.B) {
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if err != nil {
+		b.Fatal(err)
+	}
+	msg := []byte(testingJsonMessage)
+	buf := make([]byte, 0, 1024)
+
+	ciphertext, err := Encrypt(privkey.PublicKey, msg)
+	if err != nil {
+		b.Fail()
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, err := DecryptAppend(buf[:0], privkey, ciphertext)
+		if err != nil {
+			b.Fail()
+		}
+	}
+}
+
+func TestEncryptAppendAndDecryptAppend(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	prefix := []byte("prefix")
+
+	ciphertext, err := EncryptAppend(append(make([]byte, 0, 256), prefix...), privkey.PublicKey, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Equal(t, prefix, ciphertext[:len(prefix)]) {
+		return
+	}
+
+	plaintext, err := DecryptAppend(prefix, privkey, ciphertext[len(prefix):])
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, string(prefix)+testingMessage, string(plaintext))
+
+	// Compatible with EncryptSymm based layout
+	plaintext, err = Decrypt(privkey, ciphertext[len(prefix):])
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(plaintext))
+}
+
 func testEncryptAndDecryptParameters(conf Config, t *testing.T) {
-	privkey := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if !assert.NoError(t, err) {
+		return
+	}
 
 	ciphertext, err := EncryptConf(privkey.PublicKey, []byte(testingMessage), conf)
 	if !assert.NoError(t, err) {
@@ -73,13 +159,41 @@ func testEncryptAndDecryptParameters(conf Config, t *testing.T) {
 }
 
 func TestEncryptAndDecrypt(t *testing.T) {
+	skipUnapproved(t)
+
 	testEncryptAndDecryptParameters(DEFAULT_CONFIG, t)
 	testEncryptAndDecryptParameters(Config{symmetricAlgorithm: "aes-256-gcm", symmetricNonceLength: 12}, t)
 	testEncryptAndDecryptParameters(Config{symmetricAlgorithm: "aes-256-gcm", symmetricNonceLength: 16}, t)
 	testEncryptAndDecryptParameters(Config{symmetricAlgorithm: "xchacha20"}, t)
+	testEncryptAndDecryptParameters(Config{symmetricAlgorithm: "chacha20poly1305"}, t)
+	testEncryptAndDecryptParameters(DEFAULT_CONFIG.WithKDFHash("sha512"), t)
+	testEncryptAndDecryptParameters(DEFAULT_CONFIG.WithKDFHash("blake2b").WithKDFSalt([]byte("salt")), t)
+	testEncryptAndDecryptParameters(NewConfig("xchacha20", 0).WithKDFInfo([]byte("context")), t)
+}
+
+func TestDecryptWithDifferentKDFInfo(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ciphertext, err := EncryptConf(privkey.PublicKey, []byte(testingMessage), DEFAULT_CONFIG.WithKDFInfo([]byte("a")))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = DecryptConf(privkey, ciphertext, DEFAULT_CONFIG.WithKDFInfo([]byte("b")))
+	assert.Error(t, err)
+
+	_, err = EncryptConf(privkey.PublicKey, []byte(testingMessage), DEFAULT_CONFIG.WithKDFHash("md5"))
+	assert.Error(t, err)
 }
 
 func TestPublicKeyDecompression(t *testing.T) {
+	skipUnapproved(t)
+
 	// Generate public key
 	privkey, err := GenerateKey()
 	if !assert.NoError(t, err) {
@@ -97,6 +211,8 @@ func TestPublicKeyDecompression(t *testing.T) {
 }
 
 func TestKEM(t *testing.T) {
+	skipUnapproved(t)
+
 	derived := make([]byte, 32)
 	kdf := hkdf.New(sha256.New, []byte("secret"), nil, nil)
 	if _, err := io.ReadFull(kdf, derived); err != nil {
@@ -109,8 +225,14 @@ func TestKEM(t *testing.T) {
 		}
 	}
 
-	k1 := NewPrivateKeyFromBytes(new(big.Int).SetInt64(2).Bytes())
-	k2 := NewPrivateKeyFromBytes(new(big.Int).SetInt64(3).Bytes())
+	k1, err := NewPrivateKeyFromBytesReduced(new(big.Int).SetInt64(2).Bytes())
+	if !assert.NoError(t, err) {
+		return
+	}
+	k2, err := NewPrivateKeyFromBytesReduced(new(big.Int).SetInt64(3).Bytes())
+	if !assert.NoError(t, err) {
+		return
+	}
 
 	sk1, err := k1.Encapsulate(k2.PublicKey)
 	if !assert.NoError(t, err) {
@@ -133,6 +255,8 @@ func TestKEM(t *testing.T) {
 }
 
 func TestDecryptAgainstPythonVersion(t *testing.T) {
+	skipUnapproved(t)
+
 	prv, err := NewPrivateKeyFromHex(testingReceiverPrivkeyHex)
 	if !assert.NoError(t, err) {
 		return
@@ -172,6 +296,8 @@ func TestDecryptAgainstPythonVersion(t *testing.T) {
 }
 
 func TestEncryptAgainstPythonVersion(t *testing.T) {
+	skipUnapproved(t)
+
 	prv, err := NewPrivateKeyFromHex(testingReceiverPrivkeyHex)
 	if !assert.NoError(t, err) {
 		return
@@ -206,7 +332,10 @@ func TestEncryptAgainstPythonVersion(t *testing.T) {
 }
 
 func BenchmarkEncryptAndDecrypt(b *testing.B) {
-	privkey := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if err != nil {
+		b.Fatal(err)
+	}
 
 	for i := 0; i < b.N; i++ {
 		ciphertext, err := Encrypt(privkey.PublicKey, []byte(testingMessage))
@@ -220,3 +349,567 @@ func BenchmarkEncryptAndDecrypt(b *testing.B) {
 		}
 	}
 }
+
+func TestEncryptAndDecryptEnvelope(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	conf := NewConfig("xchacha20", 24).WithKDFHash("sha512").WithEnvelope()
+	ciphertext, err := EncryptConf(privkey.PublicKey, []byte(testingMessage), conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	e, err := ParseEnvelope(ciphertext)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "secp256k1", e.Curve)
+	assert.Equal(t, "xchacha20", e.SymmetricAlgorithm)
+	assert.Equal(t, 24, e.SymmetricNonceLength)
+	assert.Equal(t, "sha512", e.KDFHash)
+
+	// Receiver does not need to know sender parameters
+	plaintext, err := DecryptConf(privkey, ciphertext, DEFAULT_CONFIG.WithEnvelope())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(plaintext))
+
+	plaintext, err = DecryptConf(privkey, e.Ciphertext, e.Config())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(plaintext))
+
+	// Not enveloped message
+	ciphertext, err = Encrypt(privkey.PublicKey, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = DecryptConf(privkey, ciphertext, DEFAULT_CONFIG.WithEnvelope())
+	assert.Error(t, err)
+
+	// Unknown version
+	_, err = ParseEnvelope([]byte("ECIE\x02\x01\x01\x10\x01"))
+	assert.Error(t, err)
+}
+
+func TestEnvelopeCurves(t *testing.T) {
+	skipUnapproved(t)
+
+	for _, name := range []string{"secp256k1", "P-256", "P-384", "P-521", "brainpoolP256r1", "brainpoolP384r1"} {
+		curve, err := CurveByName(name)
+		if !assert.NoError(t, err) {
+			return
+		}
+		privkey, err := GenerateKeyCurve(curve)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		ciphertext, err := EncryptConf(privkey.PublicKey, []byte(testingMessage), DEFAULT_CONFIG.WithEnvelope())
+		if !assert.NoError(t, err, name) {
+			return
+		}
+		e, err := ParseEnvelope(ciphertext)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, name, e.Curve)
+
+		plaintext, err := DecryptConf(privkey, ciphertext, DEFAULT_CONFIG.WithEnvelope())
+		if !assert.NoError(t, err, name) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(plaintext))
+	}
+
+	// Private key of another curve than the envelope one is rejected
+	p384, err := GenerateKeyCurve(elliptic.P384())
+	if !assert.NoError(t, err) {
+		return
+	}
+	p256, err := GenerateKeyCurve(elliptic.P256())
+	if !assert.NoError(t, err) {
+		return
+	}
+	ciphertext, err := EncryptConf(p384.PublicKey, []byte(testingMessage), DEFAULT_CONFIG.WithEnvelope())
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = DecryptConf(p256, ciphertext, DEFAULT_CONFIG.WithEnvelope())
+	assert.True(t, errors.Is(err, ErrInvalidPrivateKey), err)
+
+	// Curves without ID cannot be enveloped
+	twisted := BrainpoolP256r1().(*rcurve).twisted
+	if _, err := CurveByName("brainpoolP256t1"); err != nil {
+		if !assert.NoError(t, RegisterCurve("brainpoolP256t1", twisted)) {
+			return
+		}
+	}
+	privkey, err := GenerateKeyCurve(twisted)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = EncryptConf(privkey.PublicKey, []byte(testingMessage), DEFAULT_CONFIG.WithEnvelope())
+	assert.True(t, errors.Is(err, ErrUnsupportedCurve), err)
+
+	_, err = ParseEnvelope([]byte("ECIE\x01\x09\x01\x10\x01"))
+	assert.True(t, errors.Is(err, ErrInvalidEnvelope), err)
+}
+
+func TestEncryptSymmChaCha20Poly1305(t *testing.T) {
+	skipUnapproved(t)
+
+	key := make([]byte, chacha20poly1305.KeySize)
+	conf := NewConfig("chacha20poly1305", 0)
+
+	ct, err := EncryptSymm(key, []byte(testingMessage), conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, chacha20poly1305.NonceSize+16+len(testingMessage), len(ct))
+
+	// Standard IETF ChaCha20-Poly1305 opens it once tag is moved to the end
+	aead, err := chacha20poly1305.New(key)
+	if !assert.NoError(t, err) {
+		return
+	}
+	nonce, tag, body := ct[:12], ct[12:28], ct[28:]
+	pt, err := aead.Open(nil, nonce, append(append([]byte(nil), body...), tag...), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(pt))
+}
+
+func TestEncryptAndDecryptAESKeySizes(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for algorithm, keySize := range map[string]int{"aes-128-gcm": 16, "aes-192-gcm": 24} {
+		conf := NewConfig(algorithm, 12)
+
+		ss, err := privkey.encapsulate(privkey.PublicKey, conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, keySize, len(ss))
+
+		for _, c := range []Config{conf, conf.WithEnvelope()} {
+			ciphertext, err := EncryptConf(privkey.PublicKey, []byte(testingMessage), c)
+			if !assert.NoError(t, err) {
+				return
+			}
+			plaintext, err := DecryptConf(privkey, ciphertext, c)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, testingMessage, string(plaintext))
+		}
+
+		multi, err := EncryptMultiConf([]*PublicKey{privkey.PublicKey}, []byte(testingMessage), conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		plaintext, err := DecryptMultiConf(privkey, multi, conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(plaintext))
+
+		// Key of another cipher is rejected
+		_, err = EncryptSymm(make([]byte, 32), []byte(testingMessage), conf)
+		assert.Error(t, err)
+	}
+}
+
+// NIST CAVS ANSI X9.63 KDF test vectors (SHA-256)
+func TestX963KDF(t *testing.T) {
+	for _, v := range []struct{ z, sharedInfo, key string }{
+		{
+			"96c05619d56c328ab95fe84b18264b08725b85e33fd34f08",
+			"",
+			"443024c3dae66b95e6f5670601558f71",
+		},
+		{
+			"22518b10e70f2a3f243810ae3254139efbee04aa57c7af7d",
+			"75eef81aa3041e33b80971203d2c0c52",
+			"c498af77161cc59f2962b9a713e2b215152d139766ce34a776df11866a69bf2e52a13d9c7c6fc878c50c5ea0bc7b00e0da2447cfd874f6cf92f30d0097111485500c90c3af8b487872d04685d14c8d1dc8d7fa08beb0ce0ababc11f0bd496269142d43525a78e5bc79a17f59676a5706dc54d54d4d1f0bd7e386128ec26afc21",
+		},
+	} {
+		z, _ := hex.DecodeString(v.z)
+		sharedInfo, _ := hex.DecodeString(v.sharedInfo)
+		key := make([]byte, len(v.key)/2)
+		x963KDF(sha256.New, z, sharedInfo, key)
+		assert.Equal(t, v.key, hex.EncodeToString(key))
+	}
+}
+
+func TestEncryptAndDecryptX963(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	conf := DEFAULT_CONFIG.WithKDF("x963").WithKDFInfo([]byte("shared info"))
+	ciphertext, err := EncryptConf(privkey.PublicKey, []byte(testingMessage), conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	plaintext, err := DecryptConf(privkey, ciphertext, conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(plaintext))
+
+	// HKDF derives another key
+	_, err = DecryptConf(privkey, ciphertext, DEFAULT_CONFIG.WithKDFInfo([]byte("shared info")))
+	assert.Error(t, err)
+
+	ciphertext, err = EncryptConf(privkey.PublicKey, []byte(testingMessage), conf.WithEnvelope())
+	if !assert.NoError(t, err) {
+		return
+	}
+	e, err := ParseEnvelope(ciphertext)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "x963", e.KDF)
+	plaintext, err = DecryptConf(privkey, ciphertext, DEFAULT_CONFIG.WithKDFInfo([]byte("shared info")).WithEnvelope())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(plaintext))
+
+	_, err = EncryptConf(privkey.PublicKey, []byte(testingMessage), DEFAULT_CONFIG.WithKDF("pbkdf2"))
+	assert.True(t, errors.Is(err, ErrUnsupportedKDF), err)
+}
+
+func TestEncryptAndDecryptSharedPointKDF(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	conf := DEFAULT_CONFIG.WithSharedPointKDF()
+
+	ciphertext, err := EncryptConf(privkey.PublicKey, []byte(testingMessage), conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	plaintext, err := DecryptConf(privkey, ciphertext, conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(plaintext))
+
+	_, err = DecryptConf(privkey, ciphertext, DEFAULT_CONFIG)
+	assert.Error(t, err)
+
+	// KDF input is uncompressed shared point only
+	ephemeral, key, err := EncapsulateConf(privkey.PublicKey, conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ek, err := NewPublicKeyFromBytes(ephemeral)
+	if !assert.NoError(t, err) {
+		return
+	}
+	sx, sy := privkey.Curve.ScalarMult(ek.X, ek.Y, privkey.D.Bytes())
+	expected, err := kdf(append([]byte{0x04}, append(zeroPad(sx.Bytes(), 32), zeroPad(sy.Bytes(), 32)...)...), DEFAULT_CONFIG)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, expected, key)
+
+	// Envelope carries the option
+	ciphertext, err = EncryptConf(privkey.PublicKey, []byte(testingMessage), conf.WithEnvelope())
+	if !assert.NoError(t, err) {
+		return
+	}
+	e, err := ParseEnvelope(ciphertext)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, e.SharedPointKDF)
+	assert.Equal(t, "sha256", e.KDFHash)
+	plaintext, err = DecryptConf(privkey, ciphertext, DEFAULT_CONFIG.WithEnvelope())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(plaintext))
+
+	// Sender-authenticated mode identifies the sender
+	_, err = EncryptAuthConf(privkey, privkey.PublicKey, []byte(testingMessage), conf)
+	assert.Error(t, err)
+	_, err = DecryptAuthConf(privkey, privkey.PublicKey, ciphertext, conf)
+	assert.Error(t, err)
+}
+
+func TestEncryptAndDecryptKeySeparation(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, plainConf := range []Config{
+		DEFAULT_CONFIG,
+		NewConfig("aes-256-cbc-hmac-sha256", 16),
+		NewConfig("xchacha20", 0).WithKDFHash("sha512").WithKeyCommitment(),
+	} {
+		conf := plainConf.WithKeySeparation()
+
+		ciphertext, err := EncryptConf(privkey.PublicKey, []byte(testingMessage), conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		plaintext, err := DecryptConf(privkey, ciphertext, conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(plaintext))
+
+		_, err = DecryptConf(privkey, ciphertext, plainConf)
+		assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+
+		// Envelope carries the option
+		enveloped, err := EncryptConf(privkey.PublicKey, []byte(testingMessage), conf.WithEnvelope())
+		if !assert.NoError(t, err) {
+			return
+		}
+		e, err := ParseEnvelope(enveloped)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.True(t, e.KeySeparation)
+		assert.Equal(t, plainConf.symmetricAlgorithm, e.SymmetricAlgorithm)
+		assert.Equal(t, plainConf.keyCommitment, e.KeyCommitment)
+		plaintext, err = DecryptConf(privkey, enveloped, DEFAULT_CONFIG.WithEnvelope())
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(plaintext))
+	}
+
+	// CBC keys are expanded from the master key with separate labels
+	master := bytes.Repeat([]byte{1}, 64)
+	conf := NewConfig("aes-256-cbc-hmac-sha256", 16)
+	ct, err := EncryptSymm(master, []byte(testingMessage), conf.WithKeySeparation())
+	if !assert.NoError(t, err) {
+		return
+	}
+	encKey := make([]byte, 32)
+	macKey := make([]byte, 32)
+	io.ReadFull(hkdf.Expand(sha256.New, master, []byte("ecies/encryption-key")), encKey)
+	io.ReadFull(hkdf.Expand(sha256.New, master, []byte("ecies/mac-key")), macKey)
+	plaintext, err := DecryptSymm(append(encKey, macKey...), ct, conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(plaintext))
+}
+
+func TestEncryptWithEphemeral(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ephemeral, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, conf := range []Config{DEFAULT_CONFIG, NewConfig("xchacha20", 0).WithEnvelope()} {
+		ciphertext, err := EncryptWithEphemeralConf(ephemeral, privkey.PublicKey, []byte(testingMessage), conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		plaintext, err := DecryptConf(privkey, ciphertext, conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(plaintext))
+	}
+
+	// Ciphertext carries the committed ephemeral key and matches the one Encrypt produces from the same randomness
+	random, nonce := bytes.Repeat([]byte{1}, 64), bytes.Repeat([]byte{2}, 16)
+	r := bytes.NewReader(random)
+	committed, err := generateKey(r)
+	if !assert.NoError(t, err) {
+		return
+	}
+	random = random[:len(random)-r.Len()]
+	ciphertext, err := EncryptWithEphemeralConf(committed, privkey.PublicKey, []byte(testingMessage),
+		DEFAULT_CONFIG.WithRand(bytes.NewReader(nonce)))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, committed.PublicKey.Bytes(false), ciphertext[:65])
+	expected, err := EncryptConf(privkey.PublicKey, []byte(testingMessage),
+		DEFAULT_CONFIG.WithRand(bytes.NewReader(append(random, nonce...))))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, expected, ciphertext)
+
+	// Ephemeral key is not wiped
+	_, err = EncryptWithEphemeral(ephemeral, privkey.PublicKey, []byte(testingMessage))
+	assert.NoError(t, err)
+
+	_, err = EncryptWithEphemeral(nil, privkey.PublicKey, []byte(testingMessage))
+	assert.True(t, errors.Is(err, ErrInvalidPrivateKey), err)
+	_, err = EncryptWithEphemeralConf(ephemeral, privkey.PublicKey, []byte(testingMessage),
+		DEFAULT_CONFIG.WithHPKE(HPKESuite{KDF: HPKEKDFHKDFSHA256, AEAD: HPKEAEADAES128GCM}))
+	assert.Error(t, err)
+}
+
+func TestSetDefaultConfig(t *testing.T) {
+	skipUnapproved(t)
+
+	defer SetDefaultConfig(DEFAULT_CONFIG)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, DEFAULT_CONFIG, DefaultConfig())
+
+	conf := NewConfig("xchacha20", 0).WithKDFHash("sha512")
+	SetDefaultConfig(conf)
+	assert.Equal(t, conf, DefaultConfig())
+
+	ciphertext, err := Encrypt(privkey.PublicKey, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+	plaintext, err := DecryptConf(privkey, ciphertext, conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(plaintext))
+
+	// Per-call config is not affected by the default one
+	_, err = DecryptConf(privkey, ciphertext, DEFAULT_CONFIG)
+	assert.Error(t, err)
+
+	// Default config can be swapped while other goroutines encrypt
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				SetDefaultConfig(DEFAULT_CONFIG)
+				SetDefaultConfig(conf)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				ct, err := Encrypt(privkey.PublicKey, []byte(testingMessage))
+				if !assert.NoError(t, err) {
+					return
+				}
+				if _, err := DecryptConf(privkey, ct, conf); err != nil {
+					_, err = DecryptConf(privkey, ct, DEFAULT_CONFIG)
+					assert.NoError(t, err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDecryptInPlace(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	msg := bytes.Repeat([]byte(testingMessage), 1000)
+
+	for _, conf := range []Config{
+		DEFAULT_CONFIG,
+		NewConfig("xchacha20", 0).WithEnvelope(),
+		NewConfig("aes-256-cbc-hmac-sha256", 16).WithKeyCommitment(),
+		DEFAULT_CONFIG.WithKeySeparation().WithSecretWiping(),
+	} {
+		ciphertext, err := EncryptConf(privkey.PublicKey, msg, conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		plaintext, err := DecryptInPlaceConf(privkey, ciphertext, conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, msg, plaintext)
+		assert.True(t, &plaintext[0] == &ciphertext[0], "plaintext must reuse ciphertext buffer")
+
+		// Tampered message fails
+		ciphertext, err = EncryptConf(privkey.PublicKey, msg, conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		ciphertext[len(ciphertext)-1] ^= 1
+		_, err = DecryptInPlaceConf(privkey, ciphertext, conf)
+		assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+	}
+
+	// Short messages are decrypted too
+	ciphertext, err := Encrypt(privkey.PublicKey, []byte("x"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	plaintext, err := DecryptInPlace(privkey, ciphertext)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "x", string(plaintext))
+
+	// Buffer is overwritten by the decryption above
+	ciphertext, err = Encrypt(privkey.PublicKey, []byte("x"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = DecryptInPlace(privkey, ciphertext[:70])
+	assert.True(t, errors.Is(err, ErrCiphertextTooShort), err)
+	_, err = DecryptInPlace(nil, ciphertext)
+	assert.True(t, errors.Is(err, ErrInvalidPrivateKey), err)
+	_, err = DecryptInPlaceConf(privkey, ciphertext, DEFAULT_CONFIG.WithHPKE(HPKESuite{KDF: HPKEKDFHKDFSHA256, AEAD: HPKEAEADAES128GCM}))
+	assert.Error(t, err)
+
+	// Ephemeral key curve is resolved as in DecryptConf, so both accept the same inputs
+	p384, err := GenerateKeyCurve(elliptic.P384())
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, conf := range []Config{DEFAULT_CONFIG, DEFAULT_CONFIG.WithCurve("P-384"), DEFAULT_CONFIG.WithCurve("P-256")} {
+		ciphertext, err := EncryptConf(p384.PublicKey, []byte(testingMessage), DEFAULT_CONFIG)
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, errDecrypt := DecryptConf(p384, ciphertext, conf)
+		_, err = DecryptInPlaceConf(p384, ciphertext, conf)
+		assert.Equal(t, errDecrypt == nil, err == nil, "%v, %v", errDecrypt, err)
+		assert.True(t, errors.Is(err, ErrInvalidPrivateKey) == errors.Is(errDecrypt, ErrInvalidPrivateKey), err)
+	}
+}
@@ -0,0 +1,83 @@
+package eciesgo
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"fmt"
+	"math/big"
+)
+
+var (
+	// Field prime of Curve25519, 2^255 - 19
+	ed25519P = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	// Edwards curve constant d = -121665 / 121666
+	ed25519D = func() *big.Int {
+		d := new(big.Int).ModInverse(big.NewInt(121666), ed25519P)
+		d.Mul(d, big.NewInt(-121665))
+		return d.Mod(d, ed25519P)
+	}()
+)
+
+// NewX25519PublicKeyFromEd25519 converts Ed25519 public key to X25519 public key (birational map u = (1 + y) / (1 - y)),
+// so a single identity key can be used both for signatures and encryption
+func NewX25519PublicKeyFromEd25519(pub ed25519.PublicKey) (*X25519PublicKey, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: length is %d, expected %d", ErrInvalidPublicKey, len(pub), ed25519.PublicKeySize)
+	}
+
+	// Little endian y coordinate, the highest bit is the sign of x
+	le := make([]byte, len(pub))
+	for i, b := range pub {
+		le[len(pub)-1-i] = b
+	}
+	le[0] &= 0x7f
+	y := new(big.Int).SetBytes(le)
+
+	if y.Cmp(ed25519P) >= 0 {
+		return nil, fmt.Errorf("%w: y coordinate is out of range", ErrInvalidPublicKey)
+	}
+
+	// Point must decompress: x^2 = (y^2 - 1) / (d y^2 + 1)
+	y2 := new(big.Int).Mul(y, y)
+	num := new(big.Int).Sub(y2, big.NewInt(1))
+	den := new(big.Int).Mul(ed25519D, y2)
+	den.Add(den, big.NewInt(1))
+	x2 := num.Mul(num, new(big.Int).ModInverse(den.Mod(den, ed25519P), ed25519P))
+	if new(big.Int).ModSqrt(x2.Mod(x2, ed25519P), ed25519P) == nil {
+		return nil, fmt.Errorf("%w: point is not on curve", ErrInvalidPublicKey)
+	}
+
+	// Identity point maps to infinity
+	oneMinusY := new(big.Int).Sub(big.NewInt(1), y)
+	oneMinusY.Mod(oneMinusY, ed25519P)
+	if oneMinusY.Sign() == 0 {
+		return nil, fmt.Errorf("%w: point is identity", ErrInvalidPublicKey)
+	}
+
+	u := new(big.Int).Add(big.NewInt(1), y)
+	u.Mul(u, new(big.Int).ModInverse(oneMinusY, ed25519P))
+	u.Mod(u, ed25519P)
+
+	b := zeroPad(u.Bytes(), 32)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+
+	return &X25519PublicKey{b: b}, nil
+}
+
+// NewX25519PrivateKeyFromEd25519 converts Ed25519 private key to X25519 private key;
+// X25519 scalar is the first half of SHA-512 of Ed25519 seed, as Ed25519 signing scalar is
+func NewX25519PrivateKeyFromEd25519(priv ed25519.PrivateKey) (*X25519PrivateKey, error) {
+	if len(priv) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("%w: length is %d, expected %d", ErrInvalidPrivateKey, len(priv), ed25519.PrivateKeySize)
+	}
+
+	h := sha512.Sum512(priv.Seed())
+	d := h[:32]
+	d[0] &= 248
+	d[31] &= 127
+	d[31] |= 64
+
+	return NewX25519PrivateKeyFromBytes(d)
+}
@@ -0,0 +1,80 @@
+package eciesgo
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestX25519FromEd25519(t *testing.T) {
+	skipUnapproved(t)
+
+	for i := 0; i < 8; i++ {
+		edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		pub, err := NewX25519PublicKeyFromEd25519(edPub)
+		if !assert.NoError(t, err) {
+			return
+		}
+		priv, err := NewX25519PrivateKeyFromEd25519(edPriv)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.True(t, pub.Equals(priv.X25519PublicKey))
+
+		ciphertext, err := EncryptX25519(pub, []byte(testingMessage))
+		if !assert.NoError(t, err) {
+			return
+		}
+		plaintext, err := DecryptX25519(priv, ciphertext)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(plaintext))
+	}
+}
+
+func TestX25519FromEd25519Invalid(t *testing.T) {
+	// Identity point, y = 1
+	identity := make([]byte, 32)
+	identity[0] = 1
+	_, err := NewX25519PublicKeyFromEd25519(identity)
+	assert.Error(t, err)
+
+	// y = p is not canonical
+	nonCanonical := []byte{
+		0xed, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f,
+	}
+	_, err = NewX25519PublicKeyFromEd25519(nonCanonical)
+	assert.Error(t, err)
+
+	_, err = NewX25519PublicKeyFromEd25519(make([]byte, 31))
+	assert.Error(t, err)
+	_, err = NewX25519PrivateKeyFromEd25519(make([]byte, 32))
+	assert.Error(t, err)
+}
+
+// libsodium ed25519_convert test vector
+func TestX25519FromEd25519Vector(t *testing.T) {
+	seed, _ := hex.DecodeString("421151a459faeade3d247115f94aedae42318124095afabe4d1451a559faedee")
+	edPriv := ed25519.NewKeyFromSeed(seed)
+
+	pub, err := NewX25519PublicKeyFromEd25519(edPriv.Public().(ed25519.PublicKey))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "f1814f0e8ff1043d8a44d25babff3cedcae6c22c3edaa48f857ae70de2baae50", pub.Hex())
+
+	priv, err := NewX25519PrivateKeyFromEd25519(edPriv)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "8052030376d47112be7f73ed7a019293dd12ad910b654455798b4667d73de166", priv.Hex())
+}
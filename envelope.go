@@ -0,0 +1,218 @@
+package eciesgo
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"fmt"
+)
+
+// Envelope header layout: magic (4 bytes), version, curve ID, cipher ID, nonce length, KDF ID
+const (
+	envelopeVersion      = 1
+	envelopeHeaderLength = 9
+)
+
+var envelopeMagic = []byte("ECIE")
+
+// Identifiers of algorithms written to envelope header; never reuse or renumber them.
+// Curve IDs are the curve registry ones (see RegisterCurveID)
+var (
+	envelopeKDFs    = map[string]byte{"sha256": 0x01, "sha512": 0x02, "blake2b": 0x03}
+	envelopeCiphers = map[string]byte{
+		"aes-256-gcm":      0x01,
+		"xchacha20":        0x02,
+		"chacha20poly1305": 0x03,
+		"aes-128-gcm":      0x04,
+		"aes-192-gcm":      0x05,
+
+		"aes-256-cbc-hmac-sha256": 0x06,
+	}
+)
+
+// Flags set in cipher ID if symmetric encryption is key-committing or keys are separated,
+// and in KDF ID if X9.63 KDF is used instead of HKDF or if KDF input is the shared point only
+const (
+	envelopeKeyCommitmentFlag = 0x80
+	envelopeKeySeparationFlag = 0x40
+	envelopeX963Flag          = 0x80
+	envelopeSharedPointFlag   = 0x40
+)
+
+// Envelope is parsed header of self-describing ciphertext produced with Config.WithEnvelope
+type Envelope struct {
+	Version              byte
+	Curve                string
+	SymmetricAlgorithm   string
+	SymmetricNonceLength int
+	KDF                  string
+	KDFHash              string
+	KeyCommitment        bool
+	SharedPointKDF       bool
+	KeySeparation        bool
+
+	// Ciphertext is the message without envelope header
+	Ciphertext []byte
+}
+
+// WithEnvelope returns copy of config which prepends self-describing header
+// (magic bytes, version, curve, cipher and KDF identifiers) to ciphertexts;
+// on decryption parameters from the header take precedence over the config ones, KDF salt and info are kept
+func (c Config) WithEnvelope() Config {
+	c.envelope = true
+	return c
+}
+
+// ParseEnvelope parses envelope header of a message
+func ParseEnvelope(msg []byte) (*Envelope, error) {
+	if len(msg) < envelopeHeaderLength {
+		return nil, newParseError(ErrInvalidEnvelope, "envelope", len(msg), "message is too short")
+	}
+	if !bytes.Equal(msg[:len(envelopeMagic)], envelopeMagic) {
+		return nil, newParseError(ErrInvalidEnvelope, "envelope", 0, "message is not enveloped")
+	}
+
+	// Header fields follow the magic, offset of h[i] is len(envelopeMagic)+i
+	h := msg[len(envelopeMagic):envelopeHeaderLength]
+	if h[0] != envelopeVersion {
+		return nil, newParseError(ErrInvalidEnvelope, "envelope", len(envelopeMagic), fmt.Sprintf("unsupported version %d", h[0]))
+	}
+
+	curve, _, err := curveByID(h[1])
+	if err != nil {
+		return nil, newParseError(ErrInvalidEnvelope, "envelope", len(envelopeMagic)+1, fmt.Sprintf("unknown curve ID %d", h[1]))
+	}
+
+	e := &Envelope{
+		Version:              h[0],
+		Curve:                curve,
+		SymmetricAlgorithm:   lookupEnvelopeID(envelopeCiphers, h[2]&^(envelopeKeyCommitmentFlag|envelopeKeySeparationFlag)),
+		SymmetricNonceLength: int(h[3]),
+		KDF:                  "hkdf",
+		KDFHash:              lookupEnvelopeID(envelopeKDFs, h[4]&^(envelopeX963Flag|envelopeSharedPointFlag)),
+		KeyCommitment:        h[2]&envelopeKeyCommitmentFlag != 0,
+		SharedPointKDF:       h[4]&envelopeSharedPointFlag != 0,
+		KeySeparation:        h[2]&envelopeKeySeparationFlag != 0,
+		Ciphertext:           msg[envelopeHeaderLength:],
+	}
+	if h[4]&envelopeX963Flag != 0 {
+		e.KDF = "x963"
+	}
+
+	switch {
+	case e.SymmetricAlgorithm == "":
+		return nil, newParseError(ErrUnsupportedCipher, "envelope", len(envelopeMagic)+2, fmt.Sprintf("unknown cipher ID %d", h[2]))
+	case e.KDFHash == "":
+		return nil, newParseError(ErrUnsupportedKDF, "envelope", len(envelopeMagic)+4, fmt.Sprintf("unknown KDF ID %d", h[4]))
+	}
+
+	return e, nil
+}
+
+// Config returns config with parameters described by the envelope
+func (e *Envelope) Config() Config {
+	return e.apply(DEFAULT_CONFIG)
+}
+
+// apply overrides config parameters with the envelope ones;
+// curve is set, so decryption rejects private keys of another curve
+func (e *Envelope) apply(config Config) Config {
+	config.curve = e.Curve
+	config.symmetricAlgorithm = e.SymmetricAlgorithm
+	config.symmetricNonceLength = e.SymmetricNonceLength
+	config.kdfFunction = e.KDF
+	config.kdfHash = e.KDFHash
+	config.keyCommitment = e.KeyCommitment
+	config.kdfSharedPointOnly = e.SharedPointKDF
+	config.kdfKeySeparation = e.KeySeparation
+	config.envelope = false
+	return config
+}
+
+// appendEnvelope appends envelope header with the receiver curve to dst if config requires it;
+// nil curve is the config one or secp256k1. Returned config does not require envelope,
+// so it can be passed to nested encryption
+func appendEnvelope(dst []byte, curve elliptic.Curve, config Config) ([]byte, Config, error) {
+	if !config.envelope {
+		return dst, config, nil
+	}
+	config.envelope = false
+
+	if config.hpke != nil {
+		return nil, config, fmt.Errorf("envelope is not supported in HPKE mode")
+	}
+
+	if curve == nil {
+		c, err := configCurve(config)
+		if err != nil {
+			return nil, config, err
+		}
+		curve = c
+	}
+	if curve == nil {
+		curve = getCurve()
+	}
+	id, err := curveID(curve)
+	if err != nil {
+		return nil, config, err
+	}
+
+	kdfHash := config.kdfHash
+	if kdfHash == "" {
+		kdfHash = "sha256"
+	}
+
+	cipher, cipherOk := envelopeCiphers[config.symmetricAlgorithm]
+	kdf, kdfOk := envelopeKDFs[kdfHash]
+	switch {
+	case !cipherOk:
+		return nil, config, fmt.Errorf("%w: %s has no envelope ID", ErrUnsupportedCipher, config.symmetricAlgorithm)
+	case !kdfOk:
+		return nil, config, fmt.Errorf("%w: %s has no envelope ID", ErrUnsupportedKDF, kdfHash)
+	case config.kdfFunction != "" && config.kdfFunction != "hkdf" && config.kdfFunction != "x963":
+		return nil, config, fmt.Errorf("%w: %s has no envelope ID", ErrUnsupportedKDF, config.kdfFunction)
+	case config.symmetricNonceLength < 0 || config.symmetricNonceLength > 0xff:
+		return nil, config, fmt.Errorf("invalid nonce length: %d", config.symmetricNonceLength)
+	}
+
+	if config.keyCommitment {
+		cipher |= envelopeKeyCommitmentFlag
+	}
+	if config.kdfKeySeparation {
+		cipher |= envelopeKeySeparationFlag
+	}
+	if config.kdfFunction == "x963" {
+		kdf |= envelopeX963Flag
+	}
+	if config.kdfSharedPointOnly {
+		kdf |= envelopeSharedPointFlag
+	}
+
+	dst = append(dst, envelopeMagic...)
+	dst = append(dst, envelopeVersion, id, cipher, byte(config.symmetricNonceLength), kdf)
+
+	return dst, config, nil
+}
+
+// openEnvelope strips envelope header from msg if config requires it and returns config described by the header
+func openEnvelope(msg []byte, config Config) ([]byte, Config, error) {
+	if !config.envelope {
+		return msg, config, nil
+	}
+
+	e, err := ParseEnvelope(msg)
+	if err != nil {
+		return nil, config, err
+	}
+
+	return e.Ciphertext, e.apply(config), nil
+}
+
+func lookupEnvelopeID(ids map[string]byte, id byte) string {
+	for name, i := range ids {
+		if i == id {
+			return name
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,344 @@
+package eciesgo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Forward-secure encryption with time-bucketed recipient keys: recipient generates a key pair per epoch
+// (fixed-length time period), publishes public keys ahead and deletes private keys once epochs leave
+// the retention window, so messages of expired epochs cannot be decrypted even if the recipient is later
+// compromised. Ciphertext is 8 bytes big endian epoch followed by Encrypt output
+
+// EpochSchedule divides time into epochs of Period length counted from Start
+type EpochSchedule struct {
+	Start  time.Time     `json:"start"`
+	Period time.Duration `json:"period"`
+}
+
+// Epoch returns epoch number of the time
+func (s EpochSchedule) Epoch(t time.Time) (uint64, error) {
+	if s.Period <= 0 {
+		return 0, fmt.Errorf("invalid epoch period: %s", s.Period)
+	}
+	if t.Before(s.Start) {
+		return 0, fmt.Errorf("time %s is before epoch schedule start %s", t, s.Start)
+	}
+
+	return uint64(t.Sub(s.Start) / s.Period), nil
+}
+
+// Bounds returns start and end of the epoch
+func (s EpochSchedule) Bounds(epoch uint64) (start, end time.Time) {
+	start = s.Start.Add(time.Duration(epoch) * s.Period)
+	return start, start.Add(s.Period)
+}
+
+// EpochPublicKeys is a set of recipient public keys published ahead, it is JSON serializable
+type EpochPublicKeys struct {
+	Schedule EpochSchedule         `json:"schedule"`
+	Keys     map[uint64]*PublicKey `json:"keys"`
+}
+
+// Encrypt encrypts a passed message with the public key of the current epoch and the passed config
+func (p *EpochPublicKeys) Encrypt(msg []byte, config Config) ([]byte, error) {
+	return p.EncryptAt(time.Now(), msg, config)
+}
+
+// EncryptAt encrypts a passed message with the public key of the epoch of t and the passed config;
+// it fails if the recipient published no key for the epoch
+func (p *EpochPublicKeys) EncryptAt(t time.Time, msg []byte, config Config) ([]byte, error) {
+	epoch, err := p.Schedule.Epoch(t)
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := p.Keys[epoch]
+	if !ok {
+		return nil, fmt.Errorf("%w: no key is published for epoch %d", ErrInvalidPublicKey, epoch)
+	}
+
+	var prefix [8]byte
+	binary.BigEndian.PutUint64(prefix[:], epoch)
+	return EncryptAppendConf(prefix[:], pub, msg, config)
+}
+
+// EpochKeyring holds recipient private keys of epochs; keys of epochs before the retention window
+// (current epoch and Retention previous ones) are wiped by Expire, which Decrypt calls as well.
+// It is safe for concurrent use, decryptions run without holding the lock
+type EpochKeyring struct {
+	mu        sync.Mutex
+	schedule  EpochSchedule
+	retention uint64
+	keys      map[uint64]*epochKey
+
+	// now returns current time, it is replaced in tests
+	now func() time.Time
+}
+
+// epochKey is a held key with the number of decryptions using it; key expired during decryption
+// is wiped by the last of them
+type epochKey struct {
+	key     *PrivateKey
+	refs    int
+	expired bool
+}
+
+// NewEpochKeyring creates empty keyring; retention is the number of previous epochs which messages
+// are still decrypted, e.g. to tolerate delivery delays and clock skew of senders
+func NewEpochKeyring(schedule EpochSchedule, retention int) (*EpochKeyring, error) {
+	if schedule.Period <= 0 {
+		return nil, fmt.Errorf("invalid epoch period: %s", schedule.Period)
+	}
+	if retention < 0 {
+		return nil, fmt.Errorf("invalid epoch retention: %d", retention)
+	}
+
+	return &EpochKeyring{
+		schedule:  schedule,
+		retention: uint64(retention),
+		keys:      make(map[uint64]*epochKey),
+		now:       time.Now,
+	}, nil
+}
+
+// GenerateAhead generates missing keys of the current epoch and count-1 following ones, and returns public keys
+// of all held epochs which are not expired, to be published to senders
+func (r *EpochKeyring) GenerateAhead(count int) (*EpochPublicKeys, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, err := r.schedule.Epoch(r.now())
+	if err != nil {
+		return nil, err
+	}
+	r.expire(current)
+
+	for epoch := current; epoch < current+uint64(count); epoch++ {
+		if _, ok := r.keys[epoch]; ok {
+			continue
+		}
+		k, err := GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		r.keys[epoch] = &epochKey{key: k}
+	}
+
+	pub := &EpochPublicKeys{Schedule: r.schedule, Keys: make(map[uint64]*PublicKey, len(r.keys))}
+	for epoch, k := range r.keys {
+		pub.Keys[epoch] = k.key.PublicKey
+	}
+
+	return pub, nil
+}
+
+// Epochs returns sorted epochs of held keys
+func (r *EpochKeyring) Epochs() []uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.epochs()
+}
+
+func (r *EpochKeyring) epochs() []uint64 {
+	epochs := make([]uint64, 0, len(r.keys))
+	for epoch := range r.keys {
+		epochs = append(epochs, epoch)
+	}
+	sort.Slice(epochs, func(i, j int) bool {
+		return epochs[i] < epochs[j]
+	})
+
+	return epochs
+}
+
+// Expire wipes keys of epochs before the retention window and returns their number;
+// keys used by running decryptions are removed at once and wiped when the decryptions finish
+func (r *EpochKeyring) Expire() (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, err := r.schedule.Epoch(r.now())
+	if err != nil {
+		return 0, err
+	}
+
+	return r.expire(current), nil
+}
+
+func (r *EpochKeyring) expire(current uint64) int {
+	var n int
+	for epoch, k := range r.keys {
+		if r.expired(epoch, current) {
+			k.expired = true
+			if k.refs == 0 {
+				k.key.Zeroize()
+			}
+			delete(r.keys, epoch)
+			n++
+		}
+	}
+
+	return n
+}
+
+func (r *EpochKeyring) expired(epoch, current uint64) bool {
+	return current > r.retention && epoch < current-r.retention
+}
+
+// Decrypt decrypts message produced by EpochPublicKeys.Encrypt with the key of its epoch and the passed config;
+// messages of expired epochs fail with ErrKeyExpired
+func (r *EpochKeyring) Decrypt(msg []byte, config Config) ([]byte, error) {
+	if len(msg) < 8 {
+		return nil, newParseError(ErrCiphertextTooShort, "epoch ciphertext", len(msg), "epoch is truncated")
+	}
+
+	k, err := r.acquire(binary.BigEndian.Uint64(msg))
+	if err != nil {
+		return nil, err
+	}
+	defer r.release(k)
+
+	return DecryptConf(k.key, msg[8:], config)
+}
+
+// acquire returns key of the epoch which is not wiped until it is released
+func (r *EpochKeyring) acquire(epoch uint64) (*epochKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, err := r.schedule.Epoch(r.now())
+	if err != nil {
+		return nil, err
+	}
+	r.expire(current)
+
+	k, ok := r.keys[epoch]
+	if !ok {
+		if r.expired(epoch, current) {
+			return nil, fmt.Errorf("%w: epoch %d", ErrKeyExpired, epoch)
+		}
+		return nil, fmt.Errorf("%w: no key for epoch %d", ErrInvalidPrivateKey, epoch)
+	}
+
+	k.refs++
+	return k, nil
+}
+
+// release wipes the key if it expired while it was used and this is the last user
+func (r *EpochKeyring) release(k *epochKey) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k.refs--
+	if k.refs == 0 && k.expired {
+		k.key.Zeroize()
+	}
+}
+
+// Keyring binary layout: version byte, schedule start (8 bytes Unix seconds and 4 bytes nanoseconds),
+// period in nanoseconds (8 bytes), retention (8 bytes), number of keys (4 bytes) and for every key
+// its epoch (8 bytes) and 32 bytes secp256k1 scalar; numbers are big endian
+const (
+	epochKeyringVersion      = 1
+	epochKeyringHeaderLength = 1 + 8 + 4 + 8 + 8 + 4
+	epochKeyringKeyLength    = 8 + 32
+)
+
+// MarshalBinary exports schedule, retention and keys of epochs which are not expired, so the keyring survives
+// restarts; implements encoding.BinaryMarshaler. Output holds private keys in plaintext, so it has to be
+// encrypted before it is stored, e.g. with EncryptWithPassword, and wiped after
+func (r *EpochKeyring) MarshalBinary() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if current, err := r.schedule.Epoch(r.now()); err == nil {
+		r.expire(current)
+	}
+
+	data := make([]byte, epochKeyringHeaderLength, epochKeyringHeaderLength+len(r.keys)*epochKeyringKeyLength)
+	data[0] = epochKeyringVersion
+	binary.BigEndian.PutUint64(data[1:], uint64(r.schedule.Start.Unix()))
+	binary.BigEndian.PutUint32(data[9:], uint32(r.schedule.Start.Nanosecond()))
+	binary.BigEndian.PutUint64(data[13:], uint64(r.schedule.Period))
+	binary.BigEndian.PutUint64(data[21:], r.retention)
+	binary.BigEndian.PutUint32(data[29:], uint32(len(r.keys)))
+
+	for _, epoch := range r.epochs() {
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], epoch)
+		d := r.keys[epoch].key.Bytes()
+		data = append(append(data, b[:]...), d...)
+		zeroBytes(d)
+	}
+
+	return data, nil
+}
+
+// UnmarshalBinary imports keyring exported with MarshalBinary, keys of epochs expired since the export are wiped;
+// implements encoding.BinaryUnmarshaler
+func (r *EpochKeyring) UnmarshalBinary(data []byte) error {
+	if len(data) < epochKeyringHeaderLength {
+		return fmt.Errorf("epoch keyring is truncated")
+	}
+	if data[0] != epochKeyringVersion {
+		return fmt.Errorf("unsupported epoch keyring version: %d", data[0])
+	}
+
+	schedule := EpochSchedule{
+		Start:  time.Unix(int64(binary.BigEndian.Uint64(data[1:])), int64(binary.BigEndian.Uint32(data[9:]))).UTC(),
+		Period: time.Duration(binary.BigEndian.Uint64(data[13:])),
+	}
+	retention := binary.BigEndian.Uint64(data[21:])
+	if schedule.Period <= 0 {
+		return fmt.Errorf("invalid epoch period: %s", schedule.Period)
+	}
+
+	n := binary.BigEndian.Uint32(data[29:])
+	data = data[epochKeyringHeaderLength:]
+	if uint64(len(data)) != uint64(n)*epochKeyringKeyLength {
+		return fmt.Errorf("invalid epoch keyring length %d for %d keys", len(data), n)
+	}
+
+	keys := make(map[uint64]*epochKey, n)
+	for ; len(data) > 0; data = data[epochKeyringKeyLength:] {
+		epoch := binary.BigEndian.Uint64(data)
+		k, err := NewPrivateKeyFromBytes(data[8:epochKeyringKeyLength])
+		if err == nil {
+			err = k.Validate()
+		}
+		if err == nil && keys[epoch] != nil {
+			err = fmt.Errorf("duplicate key of epoch %d", epoch)
+		}
+		if err != nil {
+			for _, k := range keys {
+				k.key.Zeroize()
+			}
+			return fmt.Errorf("invalid key of epoch %d: %w", epoch, err)
+		}
+		keys[epoch] = &epochKey{key: k}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, k := range r.keys {
+		k.expired = true
+		if k.refs == 0 {
+			k.key.Zeroize()
+		}
+	}
+	r.schedule, r.retention, r.keys = schedule, retention, keys
+	if r.now == nil {
+		r.now = time.Now
+	}
+
+	if current, err := r.schedule.Epoch(r.now()); err == nil {
+		r.expire(current)
+	}
+
+	return nil
+}
@@ -0,0 +1,240 @@
+package eciesgo
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEpochSchedule(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := EpochSchedule{Start: start, Period: 24 * time.Hour}
+
+	epoch, err := s.Epoch(start.Add(49 * time.Hour))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, uint64(2), epoch)
+	from, to := s.Bounds(2)
+	assert.Equal(t, start.Add(48*time.Hour), from)
+	assert.Equal(t, start.Add(72*time.Hour), to)
+
+	_, err = s.Epoch(start.Add(-time.Second))
+	assert.Error(t, err)
+	_, err = EpochSchedule{Start: start}.Epoch(start)
+	assert.Error(t, err)
+}
+
+func TestEpochKeyring(t *testing.T) {
+	skipUnapproved(t)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(time.Hour)
+
+	ring, err := NewEpochKeyring(EpochSchedule{Start: start, Period: 24 * time.Hour}, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ring.now = func() time.Time { return now }
+
+	pub, err := ring.GenerateAhead(3)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []uint64{0, 1, 2}, ring.Epochs())
+
+	// Published keys survive JSON round trip
+	b, err := json.Marshal(pub)
+	if !assert.NoError(t, err) {
+		return
+	}
+	var published EpochPublicKeys
+	if !assert.NoError(t, json.Unmarshal(b, &published)) {
+		return
+	}
+
+	ct0, err := published.EncryptAt(now, []byte(testingMessage), DEFAULT_CONFIG)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ct1, err := published.EncryptAt(now.Add(24*time.Hour), []byte(testingMessage), DEFAULT_CONFIG)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = published.EncryptAt(now.Add(72*time.Hour), []byte(testingMessage), DEFAULT_CONFIG)
+	assert.True(t, errors.Is(err, ErrInvalidPublicKey), err)
+
+	pt, err := ring.Decrypt(ct0, DEFAULT_CONFIG)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(pt))
+
+	// Previous epoch is within retention
+	now = now.Add(24 * time.Hour)
+	_, err = ring.Decrypt(ct0, DEFAULT_CONFIG)
+	assert.NoError(t, err)
+
+	// Keys age out on decryption
+	now = now.Add(24 * time.Hour)
+	_, err = ring.Decrypt(ct0, DEFAULT_CONFIG)
+	assert.True(t, errors.Is(err, ErrKeyExpired), err)
+	assert.Equal(t, []uint64{1, 2}, ring.Epochs())
+	_, err = ring.Decrypt(ct1, DEFAULT_CONFIG)
+	assert.NoError(t, err)
+
+	// Keys are generated ahead as time passes
+	pub, err = ring.GenerateAhead(2)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, pub.Keys, 3)
+	assert.Equal(t, []uint64{1, 2, 3}, ring.Epochs())
+
+	now = now.Add(48 * time.Hour)
+	n, err := ring.Expire()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 2, n)
+	_, err = ring.Decrypt(ct1, DEFAULT_CONFIG)
+	assert.True(t, errors.Is(err, ErrKeyExpired), err)
+
+	_, err = ring.Decrypt(ct0[:4], DEFAULT_CONFIG)
+	assert.True(t, errors.Is(err, ErrCiphertextTooShort), err)
+
+	_, err = NewEpochKeyring(EpochSchedule{Start: start}, 1)
+	assert.Error(t, err)
+}
+
+func TestEpochKeyringMarshalBinary(t *testing.T) {
+	skipUnapproved(t)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(time.Hour)
+	clock := func() time.Time { return now }
+
+	ring, err := NewEpochKeyring(EpochSchedule{Start: start, Period: time.Hour}, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ring.now = clock
+	pub, err := ring.GenerateAhead(3)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ct, err := pub.EncryptAt(now.Add(time.Hour), []byte(testingMessage), DEFAULT_CONFIG)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	data, err := ring.MarshalBinary()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, data, epochKeyringHeaderLength+3*epochKeyringKeyLength)
+
+	restored := &EpochKeyring{now: clock}
+	if !assert.NoError(t, restored.UnmarshalBinary(data)) {
+		return
+	}
+	assert.True(t, start.Equal(restored.schedule.Start))
+	assert.Equal(t, time.Hour, restored.schedule.Period)
+	assert.Equal(t, uint64(1), restored.retention)
+	assert.Equal(t, []uint64{1, 2, 3}, restored.Epochs())
+	pt, err := restored.Decrypt(ct, DEFAULT_CONFIG)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(pt))
+
+	// Keys expired since the export are wiped on import
+	now = now.Add(3 * time.Hour)
+	restored = &EpochKeyring{now: clock}
+	if !assert.NoError(t, restored.UnmarshalBinary(data)) {
+		return
+	}
+	assert.Equal(t, []uint64{3}, restored.Epochs())
+	_, err = restored.Decrypt(ct, DEFAULT_CONFIG)
+	assert.True(t, errors.Is(err, ErrKeyExpired), err)
+
+	for name, invalid := range map[string][]byte{
+		"truncated header": data[:epochKeyringHeaderLength-1],
+		"truncated key":    data[:len(data)-1],
+		"version":          append([]byte{2}, data[1:]...),
+		"zero key":         append(append([]byte(nil), data[:epochKeyringHeaderLength+8]...), make([]byte, 32+2*epochKeyringKeyLength)...),
+	} {
+		assert.Error(t, restored.UnmarshalBinary(invalid), name)
+	}
+}
+
+func TestEpochKeyringExpireDuringDecryption(t *testing.T) {
+	skipUnapproved(t)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	ring, err := NewEpochKeyring(EpochSchedule{Start: start, Period: time.Hour}, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ring.now = func() time.Time { return now }
+	if _, err := ring.GenerateAhead(1); !assert.NoError(t, err) {
+		return
+	}
+
+	// Key in use is removed by Expire, which does not wait for decryption, and wiped when it is released
+	k, err := ring.acquire(0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	now = now.Add(time.Hour)
+	n, err := ring.Expire()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 1, n)
+	assert.Empty(t, ring.Epochs())
+	assert.False(t, k.key.IsZero())
+
+	ring.release(k)
+	assert.True(t, k.key.IsZero())
+}
+
+func TestEpochKeyringConcurrentDecrypt(t *testing.T) {
+	skipUnapproved(t)
+
+	ring, err := NewEpochKeyring(EpochSchedule{Start: time.Now().Add(-time.Minute), Period: time.Hour}, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	pub, err := ring.GenerateAhead(1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ct, err := pub.Encrypt([]byte(testingMessage), DEFAULT_CONFIG)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ring.Decrypt(ct, DEFAULT_CONFIG)
+			errs <- err
+			ring.Expire()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+}
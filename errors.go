@@ -0,0 +1,66 @@
+package eciesgo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors which failures are wrapped with; compare them with errors.Is
+var (
+	// ErrInvalidPublicKey is returned for malformed public keys and points which are not on curve
+	ErrInvalidPublicKey = errors.New("invalid public key")
+	// ErrInvalidPrivateKey is returned for private keys of wrong length or out of range scalars
+	ErrInvalidPrivateKey = errors.New("invalid private key")
+	// ErrCiphertextTooShort is returned for truncated ciphertexts and malformed ciphertext structure
+	ErrCiphertextTooShort = errors.New("invalid length of message")
+	// ErrAuthenticationFailed is returned when ciphertext was tampered with or is encrypted to another key
+	ErrAuthenticationFailed = errors.New("cannot decrypt ciphertext")
+	// ErrUnsupportedCipher is returned for unknown symmetric algorithms
+	ErrUnsupportedCipher = errors.New("unknown cipher")
+	// ErrUnsupportedKDF is returned for unknown key derivation functions
+	ErrUnsupportedKDF = errors.New("unknown KDF")
+	// ErrUnsupportedCurve is returned for curves which are not registered, see RegisterCurve
+	ErrUnsupportedCurve = errors.New("unknown curve")
+	// ErrMessageTooLarge is returned for messages above the configured limit (see WithMaxMessageSize)
+	// or the cipher limit
+	ErrMessageTooLarge = errors.New("message is too large")
+	// ErrUntrustedKey is returned for receiver keys which are not pinned in the trust store, see WithTrustStore
+	ErrUntrustedKey = errors.New("untrusted key")
+	// ErrKeyExpired is returned for messages of epochs which keys are deleted, see EpochKeyring
+	ErrKeyExpired = errors.New("key is expired")
+	// ErrInvalidEnvelope is returned for messages without valid envelope header
+	ErrInvalidEnvelope = errors.New("invalid envelope")
+	// ErrInvalidArmor is returned for malformed ASCII armored messages and checksum mismatches
+	ErrInvalidArmor = errors.New("invalid armor")
+	// ErrInvalidCapsule is returned for malformed proxy re-encryption capsules
+	ErrInvalidCapsule = errors.New("invalid capsule")
+	// ErrNoiseHandshake is returned for Noise handshake messages which cannot be processed
+	ErrNoiseHandshake = errors.New("noise handshake failed")
+	// ErrNotApproved is returned in FIPS mode (fips build tag) for primitives which are not FIPS 140 approved
+	ErrNotApproved = errors.New("not approved in FIPS mode")
+)
+
+// ParseError describes malformed input with the byte offset where parsing failed;
+// it wraps one of the sentinel errors, so errors.Is works with it as well as errors.As
+type ParseError struct {
+	// Input is the kind of parsed data, e.g. "public key" or "envelope"
+	Input string
+	// Offset is the byte offset in the input where parsing failed
+	Offset int
+	// Reason describes the failure
+	Reason string
+	// Err is the sentinel error
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%v: %s at offset %d: %s", e.Err, e.Input, e.Offset, e.Reason)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+func newParseError(err error, input string, offset int, reason string) *ParseError {
+	return &ParseError{Input: input, Offset: offset, Reason: reason, Err: err}
+}
@@ -0,0 +1,143 @@
+package eciesgo
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecryptErrors(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	other, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ciphertext, err := Encrypt(privkey.PublicKey, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = Decrypt(other, ciphertext)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+
+	tampered := append([]byte(nil), ciphertext...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = Decrypt(privkey, tampered)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+
+	_, err = Decrypt(privkey, ciphertext[:65])
+	assert.True(t, errors.Is(err, ErrCiphertextTooShort), err)
+	_, err = Decrypt(privkey, ciphertext[:70])
+	assert.True(t, errors.Is(err, ErrCiphertextTooShort), err)
+
+	invalid := append([]byte(nil), ciphertext...)
+	invalid[10] ^= 1
+	_, err = Decrypt(privkey, invalid)
+	assert.True(t, errors.Is(err, ErrInvalidPublicKey), err)
+
+	_, err = Decrypt(nil, ciphertext)
+	assert.True(t, errors.Is(err, ErrInvalidPrivateKey), err)
+
+	_, err = DecryptConf(privkey, ciphertext, NewConfig("aes-128-ocb", 12))
+	assert.True(t, errors.Is(err, ErrUnsupportedCipher), err)
+
+	_, err = DecryptConf(privkey, ciphertext, DEFAULT_CONFIG.WithKDFHash("md5"))
+	assert.True(t, errors.Is(err, ErrUnsupportedKDF), err)
+
+	_, err = DecryptConf(privkey, ciphertext, DEFAULT_CONFIG.WithEnvelope())
+	assert.True(t, errors.Is(err, ErrInvalidEnvelope), err)
+
+	_, err = NewPublicKeyFromBytes([]byte{0x02, 0x01})
+	assert.True(t, errors.Is(err, ErrInvalidPublicKey), err)
+
+	_, err = NewPrivateKeyFromBytes(make([]byte, 32))
+	assert.True(t, errors.Is(err, ErrInvalidPrivateKey), err)
+}
+
+func TestDecryptReaderErrors(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var ct bytes.Buffer
+	w, err := NewEncryptWriter(&ct, privkey.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if _, err := w.Write([]byte(testingMessage)); !assert.NoError(t, err) {
+		return
+	}
+	if !assert.NoError(t, w.Close()) {
+		return
+	}
+
+	r, err := NewDecryptReader(bytes.NewReader(ct.Bytes()[:ct.Len()-1]), privkey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = ioutil.ReadAll(r)
+	assert.True(t, errors.Is(err, ErrCiphertextTooShort), err)
+
+	_, err = NewDecryptReader(bytes.NewReader(ct.Bytes()[:10]), privkey)
+	assert.True(t, errors.Is(err, ErrCiphertextTooShort), err)
+}
+
+func TestParseError(t *testing.T) {
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	uncompressed := privkey.PublicKey.Bytes(false)
+	offCurve := append([]byte(nil), uncompressed...)
+	offCurve[64] ^= 1
+	yOutOfRange := append([]byte(nil), uncompressed...)
+	for i := 33; i < 65; i++ {
+		yOutOfRange[i] = 0xff
+	}
+
+	for _, v := range []struct {
+		b      []byte
+		offset int
+	}{
+		{nil, 0},
+		{[]byte{0x05}, 0},
+		{uncompressed[:64], 64},
+		{offCurve, 1},
+		{yOutOfRange, 33},
+	} {
+		_, err := NewPublicKeyFromBytes(v.b)
+
+		var parseErr *ParseError
+		if !assert.True(t, errors.As(err, &parseErr), err) {
+			return
+		}
+		assert.Equal(t, "public key", parseErr.Input)
+		assert.Equal(t, v.offset, parseErr.Offset)
+		assert.True(t, errors.Is(err, ErrInvalidPublicKey))
+	}
+
+	_, err = ParseEnvelope([]byte("ECIE\x01\x01\x7f\x10\x01"))
+	var parseErr *ParseError
+	if !assert.True(t, errors.As(err, &parseErr), err) {
+		return
+	}
+	assert.Equal(t, 6, parseErr.Offset)
+	assert.True(t, errors.Is(err, ErrUnsupportedCipher))
+
+	_, err = Decrypt(privkey, uncompressed)
+	assert.True(t, errors.As(err, &parseErr), err)
+	assert.True(t, errors.Is(err, ErrCiphertextTooShort))
+}
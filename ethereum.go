@@ -0,0 +1,160 @@
+package eciesgo
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/sha3"
+)
+
+// EthereumAddress returns 20 bytes Ethereum address of the public key: last bytes of Keccak-256 of uncompressed point
+func (k *PublicKey) EthereumAddress() []byte {
+	return keccak256(k.Bytes(false)[1:])[12:]
+}
+
+// EthereumAddressHex returns "0x" prefixed Ethereum address with EIP-55 mixed-case checksum
+func (k *PublicKey) EthereumAddressHex() string {
+	addr := []byte(hex.EncodeToString(k.EthereumAddress()))
+	h := keccak256(addr)
+
+	for i, c := range addr {
+		// Letter is uppercased if corresponding nibble of address hash is 8 or more
+		nibble := h[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if c >= 'a' && nibble&0x0f >= 8 {
+			addr[i] = c - 'a' + 'A'
+		}
+	}
+
+	return "0x" + string(addr)
+}
+
+// Web3 Secret Storage (geth keystore, version 3) file layout
+type keystoreJSON struct {
+	Address string `json:"address"`
+	Crypto  struct {
+		Cipher       string `json:"cipher"`
+		CipherText   string `json:"ciphertext"`
+		CipherParams struct {
+			IV string `json:"iv"`
+		} `json:"cipherparams"`
+		KDF       string `json:"kdf"`
+		KDFParams struct {
+			DKLen int    `json:"dklen"`
+			Salt  string `json:"salt"`
+			N     int    `json:"n"`
+			R     int    `json:"r"`
+			P     int    `json:"p"`
+			C     int    `json:"c"`
+			PRF   string `json:"prf"`
+		} `json:"kdfparams"`
+		MAC string `json:"mac"`
+	} `json:"crypto"`
+	Version int `json:"version"`
+}
+
+// NewPrivateKeyFromKeystore decrypts private key from geth keystore (Web3 Secret Storage version 3) JSON
+// with the given password; scrypt and PBKDF2 key derivation and aes-128-ctr cipher are supported
+func NewPrivateKeyFromKeystore(keystore []byte, password string) (*PrivateKey, error) {
+	var ks keystoreJSON
+	if err := json.Unmarshal(keystore, &ks); err != nil {
+		return nil, fmt.Errorf("cannot parse keystore: %w", err)
+	}
+	if ks.Version != 3 {
+		return nil, fmt.Errorf("unsupported keystore version: %d", ks.Version)
+	}
+	if ks.Crypto.Cipher != "aes-128-ctr" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCipher, ks.Crypto.Cipher)
+	}
+
+	salt, err := hex.DecodeString(ks.Crypto.KDFParams.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode keystore salt: %w", err)
+	}
+	iv, err := hex.DecodeString(ks.Crypto.CipherParams.IV)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode keystore iv: %w", err)
+	}
+	ciphertext, err := hex.DecodeString(ks.Crypto.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode keystore ciphertext: %w", err)
+	}
+	mac, err := hex.DecodeString(ks.Crypto.MAC)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode keystore mac: %w", err)
+	}
+
+	params := ks.Crypto.KDFParams
+	if params.DKLen < 32 {
+		return nil, fmt.Errorf("invalid keystore derived key length: %d", params.DKLen)
+	}
+
+	var dk []byte
+	switch ks.Crypto.KDF {
+	case "scrypt":
+		dk, err = scrypt.Key([]byte(password), salt, params.N, params.R, params.P, params.DKLen)
+		if err != nil {
+			return nil, fmt.Errorf("cannot derive keystore key: %w", err)
+		}
+	case "pbkdf2":
+		if params.PRF != "hmac-sha256" {
+			return nil, fmt.Errorf("%w: pbkdf2 prf %s", ErrUnsupportedKDF, params.PRF)
+		}
+		if params.C <= 0 {
+			return nil, fmt.Errorf("invalid pbkdf2 iteration count: %d", params.C)
+		}
+		dk = pbkdf2.Key([]byte(password), salt, params.C, params.DKLen, sha256.New)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedKDF, ks.Crypto.KDF)
+	}
+
+	// MAC is Keccak-256 of the second half of derived key and ciphertext
+	if subtle.ConstantTimeCompare(keccak256(dk[16:32], ciphertext), mac) != 1 {
+		return nil, fmt.Errorf("%w: invalid keystore password", ErrAuthenticationFailed)
+	}
+
+	block, err := aes.NewCipher(dk[:16])
+	if err != nil {
+		return nil, fmt.Errorf("cannot create new AES block: %w", err)
+	}
+	if len(iv) != block.BlockSize() {
+		return nil, fmt.Errorf("invalid keystore iv length: %d", len(iv))
+	}
+
+	priv := make([]byte, len(ciphertext))
+	cipher.NewCTR(block, iv).XORKeyStream(priv, ciphertext)
+
+	k, err := NewPrivateKeyFromBytes(priv)
+	if err != nil {
+		return nil, err
+	}
+
+	if ks.Address != "" {
+		addr, err := hex.DecodeString(strings.TrimPrefix(ks.Address, "0x"))
+		if err != nil || !bytes.Equal(addr, k.EthereumAddress()) {
+			return nil, fmt.Errorf("%w: keystore address does not match the key", ErrInvalidPrivateKey)
+		}
+	}
+
+	return k, nil
+}
+
+func keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+
+	return h.Sum(nil)
+}
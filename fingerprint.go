@@ -0,0 +1,26 @@
+package eciesgo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Length of the truncated SHA-256 digest used as key fingerprint
+const fingerprintLength = 16
+
+// FingerprintBytes returns stable key identifier: SHA-256 of compressed public key encoding truncated to 16 bytes;
+// it is promoted to PrivateKey, so a key pair has the same fingerprint on both sides
+func (k *PublicKey) FingerprintBytes() []byte {
+	h := sha256.Sum256(k.Bytes(true))
+	return h[:fingerprintLength]
+}
+
+// Fingerprint returns hex encoded key fingerprint, suitable for logging, routing and key pinning
+func (k *PublicKey) Fingerprint() string {
+	return hex.EncodeToString(k.FingerprintBytes())
+}
+
+// FingerprintMultibase returns key fingerprint in multibase base58btc encoding ("z" prefix)
+func (k *PublicKey) FingerprintMultibase() string {
+	return "z" + base58Encode(k.FingerprintBytes())
+}
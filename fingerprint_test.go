@@ -0,0 +1,40 @@
+package eciesgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprint(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	other, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	fp := privkey.PublicKey.Fingerprint()
+	assert.Equal(t, 2*fingerprintLength, len(fp))
+	assert.Equal(t, fp, privkey.Fingerprint())
+	assert.NotEqual(t, fp, other.Fingerprint())
+
+	// Fingerprint does not depend on the encoding the key was parsed from
+	pub, err := NewPublicKeyFromBytes(privkey.PublicKey.Bytes(false))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, fp, pub.Fingerprint())
+
+	mb := pub.FingerprintMultibase()
+	assert.Equal(t, byte('z'), mb[0])
+	decoded, err := base58Decode(mb[1:])
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, pub.FingerprintBytes(), decoded)
+}
@@ -0,0 +1,63 @@
+package eciesgo
+
+import (
+	"crypto/elliptic"
+	"fmt"
+)
+
+// FIPSMode reports whether the package is built with the fips tag; then it is restricted to FIPS 140 approved
+// primitives: P-256 and P-384 keys, HKDF with SHA-2 and AES-GCM, and other options fail with ErrNotApproved.
+// The tag does not make the package validated by itself, build it with a validated toolchain
+// (GOEXPERIMENT=boringcrypto or GOFIPS140 since Go 1.24) to get the primitives from a validated module
+func FIPSMode() bool {
+	return fipsMode
+}
+
+// checkFIPSCurve rejects curves other than P-256 and P-384 in FIPS mode
+func checkFIPSCurve(curve elliptic.Curve) error {
+	if !fipsMode || sameCurve(curve, elliptic.P256()) || sameCurve(curve, elliptic.P384()) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: curve %s", ErrNotApproved, curve.Params().Name)
+}
+
+// checkFIPSConfig rejects ciphers other than AES-GCM and KDFs other than HKDF with SHA-2 in FIPS mode
+func checkFIPSConfig(conf Config) error {
+	if !fipsMode {
+		return nil
+	}
+
+	switch conf.symmetricAlgorithm {
+	case "aes-128-gcm", "aes-192-gcm", "aes-256-gcm":
+	default:
+		return fmt.Errorf("%w: cipher %s", ErrNotApproved, conf.symmetricAlgorithm)
+	}
+
+	switch conf.kdfFunction {
+	case "", "hkdf":
+	default:
+		return fmt.Errorf("%w: KDF %s", ErrNotApproved, conf.kdfFunction)
+	}
+
+	switch conf.kdfHash {
+	case "", "sha256", "sha512":
+	default:
+		return fmt.Errorf("%w: KDF hash %s", ErrNotApproved, conf.kdfHash)
+	}
+
+	if conf.hpke != nil {
+		return checkFIPS("HPKE")
+	}
+
+	return nil
+}
+
+// checkFIPS rejects the named primitive in FIPS mode
+func checkFIPS(primitive string) error {
+	if !fipsMode {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrNotApproved, primitive)
+}
@@ -0,0 +1,6 @@
+//go:build !fips
+// +build !fips
+
+package eciesgo
+
+const fipsMode = false
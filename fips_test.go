@@ -0,0 +1,129 @@
+package eciesgo
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptNISTCurves(t *testing.T) {
+	curves := []elliptic.Curve{elliptic.P256(), elliptic.P384()}
+	if !FIPSMode() {
+		curves = append(curves, elliptic.P521())
+	}
+
+	for _, curve := range curves {
+		t.Run(curve.Params().Name, func(t *testing.T) {
+			privkey, err := GenerateKeyCurve(curve)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			l := (curve.Params().BitSize + 7) / 8
+			assert.Len(t, privkey.PublicKey.Bytes(false), 1+2*l)
+			assert.Len(t, privkey.PublicKey.Bytes(true), 1+l)
+
+			ct, err := Encrypt(privkey.PublicKey, []byte(testingMessage))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Len(t, ct, 1+2*l+16+16+len(testingMessage))
+
+			pt, err := Decrypt(privkey, ct)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, testingMessage, string(pt))
+
+			pt, err = DecryptInPlace(privkey, ct)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, testingMessage, string(pt))
+
+			// Key of another curve cannot decrypt
+			other, err := GenerateKeyCurve(elliptic.P256())
+			if curve == elliptic.P256() {
+				other, err = GenerateKeyCurve(elliptic.P384())
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			ct, err = Encrypt(privkey.PublicKey, []byte(testingMessage))
+			if !assert.NoError(t, err) {
+				return
+			}
+			_, err = Decrypt(other, ct)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFIPSMode(t *testing.T) {
+	checks := map[string]func() error{
+		"secp256k1": func() error {
+			_, err := GenerateKey()
+			return err
+		},
+		"xchacha20": func() error {
+			_, err := generateSymmCipher(make([]byte, 32), NewConfig("xchacha20", 24))
+			return err
+		},
+		"x963": func() error {
+			_, err := kdf(make([]byte, 32), DEFAULT_CONFIG.WithKDF("x963"))
+			return err
+		},
+		"blake2b": func() error {
+			_, err := kdf(make([]byte, 32), DEFAULT_CONFIG.WithKDFHash("blake2b"))
+			return err
+		},
+		"scrypt": func() error {
+			_, err := EncryptWithPassword([]byte("password"), []byte(testingMessage))
+			return err
+		},
+		"X25519": func() error {
+			k, err := GenerateX25519Key()
+			if err != nil {
+				return err
+			}
+			_, err = EncryptX25519(k.X25519PublicKey, []byte(testingMessage))
+			return err
+		},
+	}
+
+	for name, check := range checks {
+		err := check()
+		if FIPSMode() {
+			assert.True(t, errors.Is(err, ErrNotApproved), "%s: %v", name, err)
+		} else {
+			assert.NoError(t, err, name)
+		}
+	}
+
+	// Approved configuration works in both modes
+	privkey, err := GenerateKeyCurve(elliptic.P256())
+	if !assert.NoError(t, err) {
+		return
+	}
+	config := DEFAULT_CONFIG.WithKDFHash("sha512").WithEnvelope()
+	ct, err := EncryptConf(privkey.PublicKey, []byte(testingMessage), config)
+	if !assert.NoError(t, err) {
+		return
+	}
+	pt, err := DecryptConf(privkey, ct, config)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(pt))
+}
+
+// skipUnapproved skips test of primitives which fail with ErrNotApproved in FIPS mode, most often secp256k1 keys
+// of the default config; TestFIPSMode checks that they are rejected
+func skipUnapproved(t testing.TB) {
+	t.Helper()
+	if FIPSMode() {
+		t.Skip("uses primitives not approved in FIPS mode")
+	}
+}
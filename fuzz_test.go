@@ -0,0 +1,98 @@
+//go:build go1.18
+// +build go1.18
+
+package eciesgo
+
+import (
+	"bytes"
+	"testing"
+)
+
+func FuzzNewPublicKeyFromBytes(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0x04})
+	f.Add(make([]byte, 65))
+	f.Add(hexToBytes(testingReceiverPubkeyHex))
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		pub, err := NewPublicKeyFromBytes(b)
+		if err != nil {
+			return
+		}
+
+		if !pub.IsOnCurve(pub.X, pub.Y) {
+			t.Fatal("parsed point is not on curve")
+		}
+
+		// Only canonical encodings are accepted, so they round trip
+		if !bytes.Equal(pub.Bytes(len(b) == 33), b) {
+			t.Fatal("encoding does not round trip")
+		}
+	})
+}
+
+func FuzzNewPrivateKeyFromBytes(f *testing.F) {
+	f.Add([]byte{})
+	f.Add(make([]byte, 32))
+	f.Add(testingReceiverPrivkey)
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		priv, err := NewPrivateKeyFromBytes(b)
+		if err != nil {
+			return
+		}
+
+		if !bytes.Equal(priv.Bytes(), b) {
+			t.Fatal("encoding does not round trip")
+		}
+	})
+}
+
+func FuzzDecrypt(f *testing.F) {
+	skipUnapproved(f)
+
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	ciphertext, err := Encrypt(privkey.PublicKey, []byte(testingMessage))
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(ciphertext)
+	f.Add(ciphertext[:65])
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		// Ciphertext is random, so seed of another fuzzing process may be valid as well
+		pt, err := Decrypt(privkey, b)
+		if err == nil && string(pt) != testingMessage {
+			t.Fatalf("forged ciphertext is decrypted: %x", pt)
+		}
+	})
+}
+
+func FuzzParseEnvelope(f *testing.F) {
+	f.Add([]byte("ECIE\x01\x01\x01\x10\x01"))
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		e, err := ParseEnvelope(b)
+		if err != nil {
+			return
+		}
+
+		if len(e.Ciphertext) != len(b)-envelopeHeaderLength {
+			t.Fatal("invalid ciphertext length")
+		}
+	})
+}
+
+func hexToBytes(s string) []byte {
+	b, err := NewPublicKeyFromHex(s)
+	if err != nil {
+		panic(err)
+	}
+	return b.Bytes(false)
+}
@@ -0,0 +1,284 @@
+package eciesgo
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ripemd160"
+)
+
+// HardenedKeyStart is the index of the first hardened child key (BIP32)
+const HardenedKeyStart uint32 = 0x80000000
+
+// BIP32 serialization versions
+var (
+	versionMainnetPrivate = []byte{0x04, 0x88, 0xad, 0xe4} // xprv
+	versionMainnetPublic  = []byte{0x04, 0x88, 0xb2, 0x1e} // xpub
+	versionTestnetPrivate = []byte{0x04, 0x35, 0x83, 0x94} // tprv
+	versionTestnetPublic  = []byte{0x04, 0x35, 0x87, 0xcf} // tpub
+)
+
+// ExtendedKey is BIP32 hierarchical deterministic key: private or public key with chain code
+type ExtendedKey struct {
+	privkey   *PrivateKey
+	pubkey    *PublicKey
+	chainCode []byte
+
+	depth             uint8
+	parentFingerprint []byte
+	childNumber       uint32
+	testnet           bool
+}
+
+// NewMasterKey derives master extended private key from seed (16 to 64 bytes long)
+func NewMasterKey(seed []byte) (*ExtendedKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("invalid seed length: %d", len(seed))
+	}
+
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	i := mac.Sum(nil)
+
+	privkey, err := NewPrivateKeyFromBytes(i[:32])
+	if err != nil {
+		return nil, fmt.Errorf("invalid master key, use another seed: %w", err)
+	}
+
+	return &ExtendedKey{
+		privkey:           privkey,
+		pubkey:            privkey.PublicKey,
+		chainCode:         i[32:],
+		parentFingerprint: make([]byte, 4),
+	}, nil
+}
+
+// NewExtendedKeyFromString parses base58 serialized extended key (xprv, xpub, tprv or tpub)
+func NewExtendedKeyFromString(s string) (*ExtendedKey, error) {
+	b, err := base58CheckDecode(s)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode extended key: %w", err)
+	}
+
+	if len(b) != 78 {
+		return nil, fmt.Errorf("invalid extended key length: %d", len(b))
+	}
+
+	k := &ExtendedKey{
+		depth:             b[4],
+		parentFingerprint: append([]byte(nil), b[5:9]...),
+		childNumber:       binary.BigEndian.Uint32(b[9:13]),
+		chainCode:         append([]byte(nil), b[13:45]...),
+	}
+
+	version, keyData := b[:4], b[45:]
+	private := bytes.Equal(version, versionMainnetPrivate) || bytes.Equal(version, versionTestnetPrivate)
+	k.testnet = bytes.Equal(version, versionTestnetPrivate) || bytes.Equal(version, versionTestnetPublic)
+	if !private && !k.testnet && !bytes.Equal(version, versionMainnetPublic) {
+		return nil, fmt.Errorf("unknown extended key version: %x", version)
+	}
+
+	if private {
+		if keyData[0] != 0x00 {
+			return nil, fmt.Errorf("invalid private key data")
+		}
+
+		if k.privkey, err = NewPrivateKeyFromBytes(keyData[1:]); err != nil {
+			return nil, err
+		}
+		k.pubkey = k.privkey.PublicKey
+	} else {
+		if k.pubkey, err = NewPublicKeyFromBytes(keyData); err != nil {
+			return nil, err
+		}
+	}
+
+	return k, nil
+}
+
+// IsPrivate reports whether the extended key holds private key
+func (k *ExtendedKey) IsPrivate() bool {
+	return k.privkey != nil
+}
+
+// PrivateKey returns private key of the extended key or error for public extended keys
+func (k *ExtendedKey) PrivateKey() (*PrivateKey, error) {
+	if k.privkey == nil {
+		return nil, fmt.Errorf("extended key is public")
+	}
+
+	return k.privkey, nil
+}
+
+// PublicKey returns public key of the extended key
+func (k *ExtendedKey) PublicKey() *PublicKey {
+	return k.pubkey
+}
+
+// Depth returns number of derivation steps from the master key
+func (k *ExtendedKey) Depth() uint8 {
+	return k.depth
+}
+
+// ChildNumber returns index of the key in its parent
+func (k *ExtendedKey) ChildNumber() uint32 {
+	return k.childNumber
+}
+
+// Fingerprint returns BIP32 key fingerprint, first 4 bytes of HASH160 of compressed public key
+func (k *ExtendedKey) Fingerprint() []byte {
+	sha := sha256.Sum256(k.pubkey.Bytes(true))
+	h := ripemd160.New()
+	h.Write(sha[:])
+	return h.Sum(nil)[:4]
+}
+
+// Neuter returns public extended key corresponding to the extended key
+func (k *ExtendedKey) Neuter() *ExtendedKey {
+	n := *k
+	n.privkey = nil
+	return &n
+}
+
+// Child derives child extended key with the given index;
+// indexes starting from HardenedKeyStart produce hardened keys, which require private extended key
+func (k *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	if k.depth == 0xff {
+		return nil, fmt.Errorf("maximal derivation depth is reached")
+	}
+
+	hardened := index >= HardenedKeyStart
+	if hardened && k.privkey == nil {
+		return nil, fmt.Errorf("cannot derive hardened child from public extended key")
+	}
+
+	data := make([]byte, 0, 37)
+	if hardened {
+		data = append(data, 0x00)
+		data = append(data, k.privkey.Bytes()...)
+	} else {
+		data = append(data, k.pubkey.Bytes(true)...)
+	}
+	data = append(data, byte(index>>24), byte(index>>16), byte(index>>8), byte(index))
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	i := mac.Sum(nil)
+
+	il, err := NewScalarFromBytes(i[:32])
+	if err != nil {
+		return nil, fmt.Errorf("invalid child key, use next index")
+	}
+	defer il.Zeroize()
+
+	child := &ExtendedKey{
+		chainCode:         i[32:],
+		depth:             k.depth + 1,
+		parentFingerprint: k.Fingerprint(),
+		childNumber:       index,
+		testnet:           k.testnet,
+	}
+
+	if k.privkey != nil {
+		parent, err := k.privkey.Scalar()
+		if err != nil {
+			return nil, err
+		}
+		d := il.Add(parent)
+		parent.Zeroize()
+		defer d.Zeroize()
+		if d.IsZero() {
+			return nil, fmt.Errorf("invalid child key, use next index")
+		}
+
+		child.privkey = newPrivateKey(getCurve(), d.big())
+		child.pubkey = child.privkey.PublicKey
+	} else {
+		parent, err := k.pubkey.Point()
+		if err != nil {
+			return nil, err
+		}
+		ilG, err := ScalarBaseMult(il)
+		if err != nil {
+			return nil, fmt.Errorf("invalid child key, use next index")
+		}
+		p, err := ilG.Add(parent)
+		if err != nil {
+			return nil, fmt.Errorf("invalid child key, use next index")
+		}
+
+		child.pubkey = p.PublicKey()
+	}
+
+	return child, nil
+}
+
+// DerivePath derives descendant extended key by path like m/44'/0'/0'/0/0;
+// hardened indexes are marked with ' or H suffix
+func (k *ExtendedKey) DerivePath(path string) (*ExtendedKey, error) {
+	segments := strings.Split(path, "/")
+	if segments[0] != "m" {
+		return nil, fmt.Errorf("derivation path must start with m")
+	}
+
+	key := k
+	for _, segment := range segments[1:] {
+		var offset uint32
+		if strings.HasSuffix(segment, "'") || strings.HasSuffix(segment, "H") || strings.HasSuffix(segment, "h") {
+			offset = HardenedKeyStart
+			segment = segment[:len(segment)-1]
+		}
+
+		index, err := strconv.ParseUint(segment, 10, 32)
+		if err != nil || uint32(index) >= HardenedKeyStart {
+			return nil, fmt.Errorf("invalid derivation path segment: %s", segment)
+		}
+
+		if key, err = key.Child(uint32(index) + offset); err != nil {
+			return nil, err
+		}
+	}
+
+	return key, nil
+}
+
+// String returns base58 serialization of the extended key (xprv or xpub)
+func (k *ExtendedKey) String() string {
+	b := make([]byte, 0, 78)
+
+	switch {
+	case k.privkey != nil && k.testnet:
+		b = append(b, versionTestnetPrivate...)
+	case k.privkey != nil:
+		b = append(b, versionMainnetPrivate...)
+	case k.testnet:
+		b = append(b, versionTestnetPublic...)
+	default:
+		b = append(b, versionMainnetPublic...)
+	}
+
+	b = append(b, k.depth)
+	b = append(b, k.parentFingerprint...)
+	b = append(b, byte(k.childNumber>>24), byte(k.childNumber>>16), byte(k.childNumber>>8), byte(k.childNumber))
+	b = append(b, k.chainCode...)
+
+	if k.privkey != nil {
+		b = append(b, 0x00)
+		b = append(b, k.privkey.Bytes()...)
+	} else {
+		b = append(b, k.pubkey.Bytes(true)...)
+	}
+
+	return base58CheckEncode(b)
+}
+
+// BIP44Path returns BIP44 derivation path m/44'/coin'/account'/change/index
+func BIP44Path(coinType, account, change, index uint32) string {
+	return fmt.Sprintf("m/44'/%d'/%d'/%d/%d", coinType, account, change, index)
+}
@@ -0,0 +1,137 @@
+package eciesgo
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// BIP32 test vector 1
+const testingHDSeed = "000102030405060708090a0b0c0d0e0f"
+
+var testingHDVectors = []struct {
+	path, xprv, xpub string
+}{
+	{
+		"m",
+		"xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi",
+		"xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8",
+	},
+	{
+		"m/0H",
+		"xprv9uHRZZhk6KAJC1avXpDAp4MDc3sQKNxDiPvvkX8Br5ngLNv1TxvUxt4cV1rGL5hj6KCesnDYUhd7oWgT11eZG7XnxHrnYeSvkzY7d2bhkJ7",
+		"xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw",
+	},
+	{
+		"m/0H/1/2H/2/1000000000",
+		"",
+		"xpub6H1LXWLaKsWFhvm6RVpEL9P4KfRZSW7abD2ttkWP3SSQvnyA8FSVqNTEcYFgJS2UaFcxupHiYkro49S8yGasTvXEYBVPamhGW6cFJodrTHy",
+	},
+}
+
+func TestExtendedKeyVectors(t *testing.T) {
+	skipUnapproved(t)
+
+	seed, _ := hex.DecodeString(testingHDSeed)
+	master, err := NewMasterKey(seed)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, v := range testingHDVectors {
+		k, err := master.DerivePath(v.path)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		if v.xprv != "" {
+			assert.Equal(t, v.xprv, k.String())
+		}
+		assert.Equal(t, v.xpub, k.Neuter().String())
+	}
+}
+
+func TestExtendedKeyPublicDerivation(t *testing.T) {
+	skipUnapproved(t)
+
+	seed, _ := hex.DecodeString(testingHDSeed)
+	master, err := NewMasterKey(seed)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	account, err := master.DerivePath("m/0H")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	private, err := account.DerivePath("m/1/2")
+	if !assert.NoError(t, err) {
+		return
+	}
+	public, err := account.Neuter().DerivePath("m/1/2")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, private.PublicKey().Equals(public.PublicKey()))
+	assert.False(t, public.IsPrivate())
+
+	_, err = account.Neuter().Child(HardenedKeyStart)
+	assert.Error(t, err)
+}
+
+func TestExtendedKeyFromString(t *testing.T) {
+	for _, v := range testingHDVectors {
+		for _, s := range []string{v.xprv, v.xpub} {
+			if s == "" {
+				continue
+			}
+
+			k, err := NewExtendedKeyFromString(s)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, s, k.String())
+		}
+	}
+
+	_, err := NewExtendedKeyFromString(testingHDVectors[0].xpub[:110] + "1")
+	assert.Error(t, err)
+}
+
+func TestDerivePathErrors(t *testing.T) {
+	skipUnapproved(t)
+
+	seed, _ := hex.DecodeString(testingHDSeed)
+	master, err := NewMasterKey(seed)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, path := range []string{"", "44'/0'", "m/x", "m/2147483648", "m//0"} {
+		_, err = master.DerivePath(path)
+		assert.Error(t, err, path)
+	}
+
+	k, err := master.DerivePath(BIP44Path(60, 0, 0, 0))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, uint8(5), k.Depth())
+}
+
+func TestBase58(t *testing.T) {
+	for _, b := range [][]byte{{}, {0}, {0, 0, 1}, []byte("hello world")} {
+		decoded, err := base58Decode(base58Encode(b))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, b, decoded)
+	}
+
+	assert.Equal(t, "StV1DL6CwTryKyV", base58Encode([]byte("hello world")))
+
+	_, err := base58Decode("0OIl")
+	assert.Error(t, err)
+}
@@ -0,0 +1,648 @@
+package eciesgo
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+// HPKE (RFC 9180) modes
+const (
+	HPKEModeBase    byte = 0x00
+	HPKEModePSK     byte = 0x01
+	HPKEModeAuth    byte = 0x02
+	HPKEModeAuthPSK byte = 0x03
+)
+
+// HPKE KDF identifiers
+const (
+	HPKEKDFHKDFSHA256 uint16 = 0x0001
+	HPKEKDFHKDFSHA384 uint16 = 0x0002
+	HPKEKDFHKDFSHA512 uint16 = 0x0003
+)
+
+// HPKE AEAD identifiers
+const (
+	HPKEAEADAES128GCM        uint16 = 0x0001
+	HPKEAEADAES256GCM        uint16 = 0x0002
+	HPKEAEADChaCha20Poly1305 uint16 = 0x0003
+	HPKEAEADExportOnly       uint16 = 0xffff
+)
+
+// HPKE KEM identifiers: DHKEMs of RFC 9180 and DHKEM(secp256k1, HKDF-SHA256) of draft-wahby-cfrg-hpke-kem-secp256k1
+const (
+	HPKEKEMP256HKDFSHA256      uint16 = 0x0010
+	HPKEKEMP384HKDFSHA384      uint16 = 0x0011
+	HPKEKEMP521HKDFSHA512      uint16 = 0x0012
+	HPKEKEMSecp256k1HKDFSHA256 uint16 = 0x0016
+	HPKEKEMX25519HKDFSHA256    uint16 = 0x0020
+)
+
+// HPKESuite is HPKE cipher suite; KEM is determined by the receiver key: secp256k1, P-256, P-384 and P-521
+// keys (*PublicKey, *PrivateKey) or X25519 keys (*X25519PublicKey, *X25519PrivateKey)
+type HPKESuite struct {
+	KDF  uint16
+	AEAD uint16
+}
+
+// HPKEPublicKey is HPKE receiver or sender public key: *PublicKey of secp256k1, P-256, P-384 or P-521 curve
+// or *X25519PublicKey
+type HPKEPublicKey interface {
+	hpkeKEM() (*hpkeKEM, error)
+	// hpkeBytes returns SerializePublicKey encoding
+	hpkeBytes() []byte
+}
+
+// HPKEPrivateKey is HPKE receiver or sender private key: *PrivateKey or *X25519PrivateKey
+type HPKEPrivateKey interface {
+	HPKEPublicKey
+	// hpkeDH computes DH with public key in SerializePublicKey encoding
+	hpkeDH(pkm []byte) ([]byte, error)
+}
+
+// HPKEContext is HPKE encryption context established by one of the Setup functions
+type HPKEContext struct {
+	suite          []byte
+	kdf            func() hash.Hash
+	aead           cipher.AEAD
+	baseNonce      []byte
+	exporterSecret []byte
+	seq            uint64
+}
+
+// hpkeKEM is DHKEM (RFC 9180, section 4.1) over elliptic curve or X25519 (curve is nil)
+type hpkeKEM struct {
+	id    uint16
+	curve elliptic.Curve
+	kdf   func() hash.Hash
+	// npk is length of encapsulated key and serialized public key
+	npk int
+}
+
+var hpkeX25519KEM = &hpkeKEM{id: HPKEKEMX25519HKDFSHA256, kdf: sha256.New, npk: curve25519.PointSize}
+
+func newHPKEKEM(curve elliptic.Curve) (*hpkeKEM, error) {
+	kem := &hpkeKEM{curve: curve, npk: 1 + 2*((curve.Params().BitSize+7)/8)}
+
+	switch {
+	case sameCurve(curve, getCurve()):
+		kem.id, kem.kdf = HPKEKEMSecp256k1HKDFSHA256, sha256.New
+	case curve == elliptic.P256():
+		kem.id, kem.kdf = HPKEKEMP256HKDFSHA256, sha256.New
+	case curve == elliptic.P384():
+		kem.id, kem.kdf = HPKEKEMP384HKDFSHA384, sha512.New384
+	case curve == elliptic.P521():
+		kem.id, kem.kdf = HPKEKEMP521HKDFSHA512, sha512.New
+	default:
+		return nil, fmt.Errorf("no HPKE KEM for curve %s", curve.Params().Name)
+	}
+
+	return kem, nil
+}
+
+func (k *PublicKey) hpkeKEM() (*hpkeKEM, error) {
+	if k == nil || k.Curve == nil {
+		return nil, fmt.Errorf("%w: public key is empty", ErrInvalidPublicKey)
+	}
+
+	return newHPKEKEM(k.Curve)
+}
+
+func (k *PublicKey) hpkeBytes() []byte {
+	return k.Bytes(false)
+}
+
+func (k *PrivateKey) hpkeKEM() (*hpkeKEM, error) {
+	if k == nil || k.PublicKey == nil || k.D == nil {
+		return nil, fmt.Errorf("%w: private key is empty", ErrInvalidPrivateKey)
+	}
+
+	return k.PublicKey.hpkeKEM()
+}
+
+// hpkeDH returns X coordinate of the shared point
+func (k *PrivateKey) hpkeDH(pkm []byte) ([]byte, error) {
+	pub, err := NewPublicKeyFromBytesCurve(k.Curve, pkm)
+	if err != nil {
+		return nil, err
+	}
+
+	sx, _, err := k.sharedPoint(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	return zeroPad(sx.Bytes(), (k.Curve.Params().BitSize+7)/8), nil
+}
+
+func (k *X25519PublicKey) hpkeKEM() (*hpkeKEM, error) {
+	if k == nil {
+		return nil, fmt.Errorf("%w: public key is empty", ErrInvalidPublicKey)
+	}
+
+	return hpkeX25519KEM, nil
+}
+
+func (k *X25519PublicKey) hpkeBytes() []byte {
+	return k.Bytes()
+}
+
+func (k *X25519PrivateKey) hpkeKEM() (*hpkeKEM, error) {
+	if k == nil || k.X25519PublicKey == nil {
+		return nil, fmt.Errorf("%w: private key is empty", ErrInvalidPrivateKey)
+	}
+
+	return hpkeX25519KEM, nil
+}
+
+// hpkeDH returns X25519 shared secret, all-zero output of low order points is rejected
+func (k *X25519PrivateKey) hpkeDH(pkm []byte) ([]byte, error) {
+	pub, err := NewX25519PublicKeyFromBytes(pkm)
+	if err != nil {
+		return nil, err
+	}
+
+	return k.ECDH(pub)
+}
+
+func (kem *hpkeKEM) suiteID() []byte {
+	return []byte{'K', 'E', 'M', byte(kem.id >> 8), byte(kem.id)}
+}
+
+// generateKeyPair generates ephemeral key pair reading randomness from rand
+func (kem *hpkeKEM) generateKeyPair(rand io.Reader) (HPKEPrivateKey, error) {
+	if kem.curve != nil {
+		return generateKeyCurve(kem.curve, rand)
+	}
+
+	d := make([]byte, curve25519.ScalarSize)
+	defer zeroBytes(d)
+	if _, err := io.ReadFull(rand, d); err != nil {
+		return nil, fmt.Errorf("cannot generate key pair: %w", err)
+	}
+
+	return NewX25519PrivateKeyFromBytes(d)
+}
+
+// deriveKeyPair implements DeriveKeyPair (RFC 9180, section 7.1.3)
+func (kem *hpkeKEM) deriveKeyPair(ikm []byte) (HPKEPrivateKey, error) {
+	prk := labeledExtract(kem.kdf, kem.suiteID(), nil, "dkp_prk", ikm)
+	defer zeroBytes(prk)
+
+	if kem.curve == nil {
+		sk := labeledExpand(kem.kdf, kem.suiteID(), prk, "sk", nil, curve25519.ScalarSize)
+		defer zeroBytes(sk)
+		return NewX25519PrivateKeyFromBytes(sk)
+	}
+
+	n := kem.curve.Params().N
+	for counter := 0; counter < 256; counter++ {
+		candidate := labeledExpand(kem.kdf, kem.suiteID(), prk, "candidate", []byte{byte(counter)}, len(n.Bytes()))
+		candidate[0] &= byte(0xff >> uint(len(candidate)*8-n.BitLen()))
+
+		d := new(big.Int).SetBytes(candidate)
+		zeroBytes(candidate)
+		if d.Sign() > 0 && d.Cmp(n) < 0 {
+			return newPrivateKey(kem.curve, d), nil
+		}
+	}
+
+	return nil, fmt.Errorf("cannot derive key pair")
+}
+
+// encap implements Encap and AuthEncap (if sender private key is not nil) with ephemeral key skE
+func (kem *hpkeKEM) encap(pkR HPKEPublicKey, skS, skE HPKEPrivateKey) (sharedSecret, enc []byte, err error) {
+	pkRm := pkR.hpkeBytes()
+	dh, err := skE.hpkeDH(pkRm)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer zeroBytes(dh)
+
+	enc = skE.hpkeBytes()
+	kemContext := append(append([]byte(nil), enc...), pkRm...)
+
+	if skS != nil {
+		dhS, err := skS.hpkeDH(pkRm)
+		if err != nil {
+			return nil, nil, err
+		}
+		dh = append(dh, dhS...)
+		zeroBytes(dhS)
+		kemContext = append(kemContext, skS.hpkeBytes()...)
+	}
+
+	return kem.extractAndExpand(dh, kemContext), enc, nil
+}
+
+// decap implements Decap and AuthDecap (if sender public key is not nil)
+func (kem *hpkeKEM) decap(enc []byte, skR HPKEPrivateKey, pkS HPKEPublicKey) ([]byte, error) {
+	if len(enc) != kem.npk {
+		return nil, fmt.Errorf("%w: encapsulated key length is %d, expected %d", ErrInvalidPublicKey, len(enc), kem.npk)
+	}
+
+	dh, err := skR.hpkeDH(enc)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(dh)
+
+	kemContext := append(append([]byte(nil), enc...), skR.hpkeBytes()...)
+
+	if pkS != nil {
+		dhS, err := skR.hpkeDH(pkS.hpkeBytes())
+		if err != nil {
+			return nil, err
+		}
+		dh = append(dh, dhS...)
+		zeroBytes(dhS)
+		kemContext = append(kemContext, pkS.hpkeBytes()...)
+	}
+
+	return kem.extractAndExpand(dh, kemContext), nil
+}
+
+func (kem *hpkeKEM) extractAndExpand(dh, kemContext []byte) []byte {
+	prk := labeledExtract(kem.kdf, kem.suiteID(), nil, "eae_prk", dh)
+	defer zeroBytes(prk)
+
+	return labeledExpand(kem.kdf, kem.suiteID(), prk, "shared_secret", kemContext, kem.kdf().Size())
+}
+
+// DeriveHPKEKeyPair deterministically derives secp256k1 key pair from input keying material (RFC 9180, 7.1.3)
+func DeriveHPKEKeyPair(ikm []byte) (*PrivateKey, error) {
+	return DeriveHPKEKeyPairCurve(getCurve(), ikm)
+}
+
+// DeriveHPKEKeyPairCurve deterministically derives key pair of the curve KEM (secp256k1, P-256, P-384 or P-521)
+// from input keying material
+func DeriveHPKEKeyPairCurve(curve elliptic.Curve, ikm []byte) (*PrivateKey, error) {
+	if curve == nil {
+		return nil, fmt.Errorf("curve is empty")
+	}
+	kem, err := newHPKEKEM(curve)
+	if err != nil {
+		return nil, err
+	}
+
+	sk, err := kem.deriveKeyPair(ikm)
+	if err != nil {
+		return nil, err
+	}
+
+	return sk.(*PrivateKey), nil
+}
+
+// DeriveHPKEX25519KeyPair deterministically derives DHKEM(X25519, HKDF-SHA256) key pair from input keying material
+func DeriveHPKEX25519KeyPair(ikm []byte) (*X25519PrivateKey, error) {
+	sk, err := hpkeX25519KEM.deriveKeyPair(ikm)
+	if err != nil {
+		return nil, err
+	}
+
+	return sk.(*X25519PrivateKey), nil
+}
+
+// SetupBaseS establishes sender context in base mode, returns encapsulated key and context
+func (s HPKESuite) SetupBaseS(pkR HPKEPublicKey, info []byte) ([]byte, *HPKEContext, error) {
+	return s.setupS(HPKEModeBase, pkR, info, nil, nil, nil, randReader)
+}
+
+// SetupBaseR establishes receiver context in base mode
+func (s HPKESuite) SetupBaseR(enc []byte, skR HPKEPrivateKey, info []byte) (*HPKEContext, error) {
+	return s.setupR(HPKEModeBase, enc, skR, info, nil, nil, nil)
+}
+
+// SetupPSKS establishes sender context in PSK mode, returns encapsulated key and context
+func (s HPKESuite) SetupPSKS(pkR HPKEPublicKey, info, psk, pskID []byte) ([]byte, *HPKEContext, error) {
+	return s.setupS(HPKEModePSK, pkR, info, psk, pskID, nil, randReader)
+}
+
+// SetupPSKR establishes receiver context in PSK mode
+func (s HPKESuite) SetupPSKR(enc []byte, skR HPKEPrivateKey, info, psk, pskID []byte) (*HPKEContext, error) {
+	return s.setupR(HPKEModePSK, enc, skR, info, psk, pskID, nil)
+}
+
+// SetupAuthS establishes sender context in Auth mode, returns encapsulated key and context
+func (s HPKESuite) SetupAuthS(pkR HPKEPublicKey, info []byte, skS HPKEPrivateKey) ([]byte, *HPKEContext, error) {
+	if skS == nil {
+		return nil, nil, fmt.Errorf("%w: private key is empty", ErrInvalidPrivateKey)
+	}
+	return s.setupS(HPKEModeAuth, pkR, info, nil, nil, skS, randReader)
+}
+
+// SetupAuthR establishes receiver context in Auth mode
+func (s HPKESuite) SetupAuthR(enc []byte, skR HPKEPrivateKey, info []byte, pkS HPKEPublicKey) (*HPKEContext, error) {
+	if pkS == nil {
+		return nil, fmt.Errorf("%w: public key is empty", ErrInvalidPublicKey)
+	}
+	return s.setupR(HPKEModeAuth, enc, skR, info, nil, nil, pkS)
+}
+
+// SetupAuthPSKS establishes sender context in AuthPSK mode, returns encapsulated key and context
+func (s HPKESuite) SetupAuthPSKS(pkR HPKEPublicKey, info, psk, pskID []byte, skS HPKEPrivateKey) ([]byte, *HPKEContext, error) {
+	if skS == nil {
+		return nil, nil, fmt.Errorf("%w: private key is empty", ErrInvalidPrivateKey)
+	}
+	return s.setupS(HPKEModeAuthPSK, pkR, info, psk, pskID, skS, randReader)
+}
+
+// SetupAuthPSKR establishes receiver context in AuthPSK mode
+func (s HPKESuite) SetupAuthPSKR(enc []byte, skR HPKEPrivateKey, info, psk, pskID []byte, pkS HPKEPublicKey) (*HPKEContext, error) {
+	if pkS == nil {
+		return nil, fmt.Errorf("%w: public key is empty", ErrInvalidPublicKey)
+	}
+	return s.setupR(HPKEModeAuthPSK, enc, skR, info, psk, pskID, pkS)
+}
+
+// hpkeKEMs returns KEM of the receiver key, sender key must be of the same KEM
+func hpkeKEMs(receiver, sender HPKEPublicKey) (*hpkeKEM, error) {
+	if receiver == nil {
+		return nil, fmt.Errorf("%w: receiver key is empty", ErrInvalidPublicKey)
+	}
+	kem, err := receiver.hpkeKEM()
+	if err != nil {
+		return nil, err
+	}
+
+	if sender != nil {
+		senderKEM, err := sender.hpkeKEM()
+		if err != nil {
+			return nil, err
+		}
+		if senderKEM.id != kem.id {
+			return nil, fmt.Errorf("sender key KEM %#04x differs from receiver key KEM %#04x", senderKEM.id, kem.id)
+		}
+	}
+
+	return kem, nil
+}
+
+func (s HPKESuite) setupS(mode byte, pkR HPKEPublicKey, info, psk, pskID []byte, skS HPKEPrivateKey, rand io.Reader) ([]byte, *HPKEContext, error) {
+	if err := checkFIPS("HPKE"); err != nil {
+		return nil, nil, err
+	}
+
+	kem, err := hpkeKEMs(pkR, skS)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	skE, err := kem.generateKeyPair(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s.setupSEphemeral(kem, mode, pkR, info, psk, pskID, skS, skE)
+}
+
+// setupSEphemeral establishes sender context with the given ephemeral key, e.g. derived one of test vectors
+func (s HPKESuite) setupSEphemeral(kem *hpkeKEM, mode byte, pkR HPKEPublicKey, info, psk, pskID []byte, skS, skE HPKEPrivateKey) ([]byte, *HPKEContext, error) {
+	sharedSecret, enc, err := kem.encap(pkR, skS, skE)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer zeroBytes(sharedSecret)
+
+	ctx, err := s.keySchedule(kem, mode, sharedSecret, info, psk, pskID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return enc, ctx, nil
+}
+
+func (s HPKESuite) setupR(mode byte, enc []byte, skR HPKEPrivateKey, info, psk, pskID []byte, pkS HPKEPublicKey) (*HPKEContext, error) {
+	if err := checkFIPS("HPKE"); err != nil {
+		return nil, err
+	}
+	if skR == nil {
+		return nil, fmt.Errorf("%w: private key is empty", ErrInvalidPrivateKey)
+	}
+
+	kem, err := hpkeKEMs(skR, pkS)
+	if err != nil {
+		return nil, err
+	}
+
+	sharedSecret, err := kem.decap(enc, skR, pkS)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(sharedSecret)
+
+	return s.keySchedule(kem, mode, sharedSecret, info, psk, pskID)
+}
+
+func (s HPKESuite) keySchedule(kem *hpkeKEM, mode byte, sharedSecret, info, psk, pskID []byte) (*HPKEContext, error) {
+	gotPSK, gotPSKID := len(psk) != 0, len(pskID) != 0
+	if gotPSK != gotPSKID {
+		return nil, fmt.Errorf("inconsistent PSK inputs")
+	}
+	if gotPSK && (mode == HPKEModeBase || mode == HPKEModeAuth) {
+		return nil, fmt.Errorf("PSK input provided when not needed")
+	}
+	if !gotPSK && (mode == HPKEModePSK || mode == HPKEModeAuthPSK) {
+		return nil, fmt.Errorf("missing required PSK input")
+	}
+
+	var kdf func() hash.Hash
+	switch s.KDF {
+	case HPKEKDFHKDFSHA256:
+		kdf = sha256.New
+	case HPKEKDFHKDFSHA384:
+		kdf = sha512.New384
+	case HPKEKDFHKDFSHA512:
+		kdf = sha512.New
+	default:
+		return nil, fmt.Errorf("%w: HPKE KDF %#04x", ErrUnsupportedKDF, s.KDF)
+	}
+
+	var nk int
+	switch s.AEAD {
+	case HPKEAEADAES128GCM:
+		nk = 16
+	case HPKEAEADAES256GCM, HPKEAEADChaCha20Poly1305:
+		nk = 32
+	case HPKEAEADExportOnly:
+		nk = 0
+	default:
+		return nil, fmt.Errorf("%w: HPKE AEAD %#04x", ErrUnsupportedCipher, s.AEAD)
+	}
+
+	suite := make([]byte, 10)
+	copy(suite, "HPKE")
+	binary.BigEndian.PutUint16(suite[4:], kem.id)
+	binary.BigEndian.PutUint16(suite[6:], s.KDF)
+	binary.BigEndian.PutUint16(suite[8:], s.AEAD)
+
+	pskIDHash := labeledExtract(kdf, suite, nil, "psk_id_hash", pskID)
+	infoHash := labeledExtract(kdf, suite, nil, "info_hash", info)
+	keyScheduleContext := append(append([]byte{mode}, pskIDHash...), infoHash...)
+
+	secret := labeledExtract(kdf, suite, sharedSecret, "secret", psk)
+
+	ctx := &HPKEContext{
+		suite:          suite,
+		kdf:            kdf,
+		exporterSecret: labeledExpand(kdf, suite, secret, "exp", keyScheduleContext, kdf().Size()),
+	}
+
+	if s.AEAD == HPKEAEADExportOnly {
+		return ctx, nil
+	}
+
+	key := labeledExpand(kdf, suite, secret, "key", keyScheduleContext, nk)
+	if s.AEAD == HPKEAEADChaCha20Poly1305 {
+		aead, err := chacha20poly1305.New(key)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create ChaCha20-Poly1305: %w", err)
+		}
+		ctx.aead = aead
+	} else {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create new AES block: %w", err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create AES GCM: %w", err)
+		}
+		ctx.aead = aead
+	}
+
+	ctx.baseNonce = labeledExpand(kdf, suite, secret, "base_nonce", keyScheduleContext, ctx.aead.NonceSize())
+
+	return ctx, nil
+}
+
+func (c *HPKEContext) nextNonce() ([]byte, error) {
+	if c.aead == nil {
+		return nil, fmt.Errorf("context is export-only")
+	}
+	if c.seq == ^uint64(0) {
+		return nil, fmt.Errorf("message limit reached")
+	}
+
+	nonce := append([]byte(nil), c.baseNonce...)
+	for i := 0; i < 8; i++ {
+		nonce[len(nonce)-1-i] ^= byte(c.seq >> (8 * uint(i)))
+	}
+	c.seq++
+
+	return nonce, nil
+}
+
+// Seal encrypts plaintext with additional data; context sequence number is incremented
+func (c *HPKEContext) Seal(aad, pt []byte) ([]byte, error) {
+	nonce, err := c.nextNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.aead.Seal(nil, nonce, pt, aad), nil
+}
+
+// Open decrypts ciphertext with additional data; context sequence number is incremented only on success
+func (c *HPKEContext) Open(aad, ct []byte) ([]byte, error) {
+	nonce, err := c.nextNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	pt, err := c.aead.Open(nil, nonce, ct, aad)
+	if err != nil {
+		c.seq--
+		return nil, fmt.Errorf("%w: %v", ErrAuthenticationFailed, err)
+	}
+
+	return pt, nil
+}
+
+// Export derives secret of the given length from the context exporter secret;
+// length must not exceed 255 times the KDF hash size
+func (c *HPKEContext) Export(exporterContext []byte, length int) ([]byte, error) {
+	if limit := 255 * c.kdf().Size(); length < 0 || length > limit {
+		return nil, fmt.Errorf("invalid HPKE export length %d, maximum is %d", length, limit)
+	}
+
+	return labeledExpand(c.kdf, c.suite, c.exporterSecret, "sec", exporterContext, length), nil
+}
+
+func encryptHPKE(dst []byte, pubkey *PublicKey, msg []byte, config Config) ([]byte, error) {
+	enc, ctx, err := config.hpke.setupS(HPKEModeBase, pubkey, config.kdfInfo, nil, nil, nil, config.random())
+	if err != nil {
+		return nil, err
+	}
+
+	ct, err := ctx.Seal(nil, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(append(dst, enc...), ct...), nil
+}
+
+func decryptHPKE(dst []byte, privkey *PrivateKey, msg []byte, config Config) ([]byte, error) {
+	if privkey == nil {
+		return nil, fmt.Errorf("%w: private key is empty", ErrInvalidPrivateKey)
+	}
+
+	kem, err := privkey.hpkeKEM()
+	if err != nil {
+		return nil, err
+	}
+	nenc := kem.npk
+	if len(msg) < nenc {
+		return nil, ErrCiphertextTooShort
+	}
+
+	ctx, err := config.hpke.SetupBaseR(msg[:nenc], privkey, config.kdfInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	pt, err := ctx.Open(nil, msg[nenc:])
+	if err != nil {
+		return nil, err
+	}
+
+	return append(dst, pt...), nil
+}
+
+func labeledExtract(kdf func() hash.Hash, suite, salt []byte, label string, ikm []byte) []byte {
+	labeled := make([]byte, 0, 7+len(suite)+len(label)+len(ikm))
+	labeled = append(labeled, "HPKE-v1"...)
+	labeled = append(labeled, suite...)
+	labeled = append(labeled, label...)
+	labeled = append(labeled, ikm...)
+
+	return hkdf.Extract(kdf, labeled, salt)
+}
+
+func labeledExpand(kdf func() hash.Hash, suite, prk []byte, label string, info []byte, length int) []byte {
+	labeled := make([]byte, 0, 9+len(suite)+len(label)+len(info))
+	labeled = append(labeled, byte(length>>8), byte(length))
+	labeled = append(labeled, "HPKE-v1"...)
+	labeled = append(labeled, suite...)
+	labeled = append(labeled, label...)
+	labeled = append(labeled, info...)
+
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.Expand(kdf, prk, labeled), out); err != nil {
+		panic("HPKE expand length is too big")
+	}
+
+	return out
+}
@@ -0,0 +1,451 @@
+package eciesgo
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/sha3"
+)
+
+var testingHPKESuites = []HPKESuite{
+	{KDF: HPKEKDFHKDFSHA256, AEAD: HPKEAEADAES128GCM},
+	{KDF: HPKEKDFHKDFSHA384, AEAD: HPKEAEADAES256GCM},
+	{KDF: HPKEKDFHKDFSHA512, AEAD: HPKEAEADChaCha20Poly1305},
+}
+
+func TestHPKEModes(t *testing.T) {
+	skipUnapproved(t)
+
+	skR, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	skS, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	info, psk, pskID := []byte("info"), []byte("0123456789abcdef0123456789abcdef"), []byte("psk id")
+
+	for _, suite := range testingHPKESuites {
+		type setup struct {
+			s func() ([]byte, *HPKEContext, error)
+			r func(enc []byte) (*HPKEContext, error)
+		}
+
+		for _, m := range []setup{
+			{
+				func() ([]byte, *HPKEContext, error) { return suite.SetupBaseS(skR.PublicKey, info) },
+				func(enc []byte) (*HPKEContext, error) { return suite.SetupBaseR(enc, skR, info) },
+			},
+			{
+				func() ([]byte, *HPKEContext, error) { return suite.SetupPSKS(skR.PublicKey, info, psk, pskID) },
+				func(enc []byte) (*HPKEContext, error) { return suite.SetupPSKR(enc, skR, info, psk, pskID) },
+			},
+			{
+				func() ([]byte, *HPKEContext, error) { return suite.SetupAuthS(skR.PublicKey, info, skS) },
+				func(enc []byte) (*HPKEContext, error) { return suite.SetupAuthR(enc, skR, info, skS.PublicKey) },
+			},
+			{
+				func() ([]byte, *HPKEContext, error) { return suite.SetupAuthPSKS(skR.PublicKey, info, psk, pskID, skS) },
+				func(enc []byte) (*HPKEContext, error) {
+					return suite.SetupAuthPSKR(enc, skR, info, psk, pskID, skS.PublicKey)
+				},
+			},
+		} {
+			enc, sender, err := m.s()
+			if !assert.NoError(t, err) {
+				return
+			}
+			receiver, err := m.r(enc)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			for i := 0; i < 3; i++ {
+				ct, err := sender.Seal([]byte("aad"), []byte(testingMessage))
+				if !assert.NoError(t, err) {
+					return
+				}
+				pt, err := receiver.Open([]byte("aad"), ct)
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.Equal(t, testingMessage, string(pt))
+			}
+
+			exported, err := sender.Export([]byte("ctx"), 42)
+			if !assert.NoError(t, err) {
+				return
+			}
+			expected, err := receiver.Export([]byte("ctx"), 42)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, exported)
+		}
+	}
+}
+
+func TestHPKEFailures(t *testing.T) {
+	skipUnapproved(t)
+
+	skR, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	skS, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	suite := testingHPKESuites[0]
+
+	// Wrong PSK
+	enc, sender, err := suite.SetupPSKS(skR.PublicKey, nil, []byte("psk"), []byte("id"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	receiver, err := suite.SetupPSKR(enc, skR, nil, []byte("other psk"), []byte("id"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	ct, err := sender.Seal(nil, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = receiver.Open(nil, ct)
+	assert.Error(t, err)
+
+	// Wrong sender
+	enc, sender, err = suite.SetupAuthS(skR.PublicKey, nil, skS)
+	if !assert.NoError(t, err) {
+		return
+	}
+	receiver, err = suite.SetupAuthR(enc, skR, nil, skR.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ct, err = sender.Seal(nil, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = receiver.Open(nil, ct)
+	assert.Error(t, err)
+
+	// PSK inputs validation
+	_, _, err = suite.SetupPSKS(skR.PublicKey, nil, nil, nil)
+	assert.Error(t, err)
+	_, _, err = suite.SetupPSKS(skR.PublicKey, nil, []byte("psk"), nil)
+	assert.Error(t, err)
+
+	// Export-only context cannot seal
+	_, sender, err = HPKESuite{KDF: HPKEKDFHKDFSHA256, AEAD: HPKEAEADExportOnly}.SetupBaseS(skR.PublicKey, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = sender.Seal(nil, []byte(testingMessage))
+	assert.Error(t, err)
+
+	// Export length is limited to 255 hash sizes
+	_, err = sender.Export(nil, 255*32)
+	assert.NoError(t, err)
+	_, err = sender.Export(nil, 255*32+1)
+	assert.Error(t, err)
+	_, err = sender.Export(nil, -1)
+	assert.Error(t, err)
+
+	// Sender key must be of the receiver KEM
+	p256, err := GenerateKeyCurve(elliptic.P256())
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, _, err = suite.SetupAuthS(skR.PublicKey, nil, p256)
+	assert.Error(t, err)
+
+	// Empty keys
+	_, _, err = suite.SetupBaseS((*PublicKey)(nil), nil)
+	assert.True(t, errors.Is(err, ErrInvalidPublicKey), err)
+	_, err = suite.SetupBaseR(enc, (*X25519PrivateKey)(nil), nil)
+	assert.True(t, errors.Is(err, ErrInvalidPrivateKey), err)
+}
+
+func TestHPKEKEMs(t *testing.T) {
+	skipUnapproved(t)
+
+	p256, err := GenerateKeyCurve(elliptic.P256())
+	if !assert.NoError(t, err) {
+		return
+	}
+	p521, err := GenerateKeyCurve(elliptic.P521())
+	if !assert.NoError(t, err) {
+		return
+	}
+	x25519, err := GenerateX25519Key()
+	if !assert.NoError(t, err) {
+		return
+	}
+	x25519Sender, err := GenerateX25519Key()
+	if !assert.NoError(t, err) {
+		return
+	}
+	suite := testingHPKESuites[0]
+
+	for _, skR := range []HPKEPrivateKey{p256, p521, x25519} {
+		enc, sender, err := suite.SetupBaseS(skR, []byte("info"))
+		if !assert.NoError(t, err) {
+			return
+		}
+		receiver, err := suite.SetupBaseR(enc, skR, []byte("info"))
+		if !assert.NoError(t, err) {
+			return
+		}
+		ct, err := sender.Seal(nil, []byte(testingMessage))
+		if !assert.NoError(t, err) {
+			return
+		}
+		pt, err := receiver.Open(nil, ct)
+		assert.NoError(t, err)
+		assert.Equal(t, testingMessage, string(pt))
+	}
+
+	enc, sender, err := suite.SetupAuthS(x25519.X25519PublicKey, nil, x25519Sender)
+	if !assert.NoError(t, err) {
+		return
+	}
+	receiver, err := suite.SetupAuthR(enc, x25519, nil, x25519Sender.X25519PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ct, err := sender.Seal(nil, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = receiver.Open(nil, ct)
+	assert.NoError(t, err)
+
+	// Encrypt and Decrypt in HPKE mode with NIST curve keys
+	ct, err = EncryptConf(p256.PublicKey, []byte(testingMessage), DEFAULT_CONFIG.WithHPKE(suite))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, byte(0x04), ct[0])
+	pt, err := DecryptConf(p256, ct, DEFAULT_CONFIG.WithHPKE(suite))
+	assert.NoError(t, err)
+	assert.Equal(t, testingMessage, string(pt))
+}
+
+// hpkeVector is RFC 9180 test vector; encryptions and exports are accumulated into SHAKE128 hashes
+// as in Go crypto/hpke tests, so the file stays small: 1000 messages and exports are drawn from SHAKE128
+type hpkeVector struct {
+	Mode           byte   `json:"mode"`
+	KEM            uint16 `json:"kem_id"`
+	KDF            uint16 `json:"kdf_id"`
+	AEAD           uint16 `json:"aead_id"`
+	Info           string `json:"info"`
+	IkmE           string `json:"ikmE"`
+	IkmR           string `json:"ikmR"`
+	SkRm           string `json:"skRm"`
+	PkRm           string `json:"pkRm"`
+	Enc            string `json:"enc"`
+	AccEncryptions string `json:"encryptions_accumulated"`
+	AccExports     string `json:"exports_accumulated"`
+}
+
+func testingHPKEKEM(id uint16) (*hpkeKEM, error) {
+	switch id {
+	case HPKEKEMP256HKDFSHA256:
+		return newHPKEKEM(elliptic.P256())
+	case HPKEKEMP384HKDFSHA384:
+		return newHPKEKEM(elliptic.P384())
+	case HPKEKEMP521HKDFSHA512:
+		return newHPKEKEM(elliptic.P521())
+	case HPKEKEMX25519HKDFSHA256:
+		return hpkeX25519KEM, nil
+	default:
+		return nil, fmt.Errorf("unknown KEM %#04x", id)
+	}
+}
+
+func hpkePrivateKeyBytes(sk HPKEPrivateKey) []byte {
+	switch k := sk.(type) {
+	case *PrivateKey:
+		return k.Bytes()
+	case *X25519PrivateKey:
+		return k.Bytes()
+	default:
+		return nil
+	}
+}
+
+// drawHPKEInput reads length prefixed input from SHAKE128 stream
+func drawHPKEInput(r io.Reader) []byte {
+	l := make([]byte, 1)
+	r.Read(l)
+	b := make([]byte, l[0])
+	r.Read(b)
+	return b
+}
+
+func TestHPKEVectors(t *testing.T) {
+	skipUnapproved(t)
+
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "hpke_rfc9180.json"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	var vectors []hpkeVector
+	if !assert.NoError(t, json.Unmarshal(data, &vectors)) {
+		return
+	}
+
+	for _, v := range vectors {
+		v := v
+		t.Run(fmt.Sprintf("mode %d kem %#04x kdf %#04x aead %#04x", v.Mode, v.KEM, v.KDF, v.AEAD), func(t *testing.T) {
+			kem, err := testingHPKEKEM(v.KEM)
+			if !assert.NoError(t, err) {
+				return
+			}
+			suite := HPKESuite{KDF: v.KDF, AEAD: v.AEAD}
+			info, _ := hex.DecodeString(v.Info)
+			ikmE, _ := hex.DecodeString(v.IkmE)
+			ikmR, _ := hex.DecodeString(v.IkmR)
+
+			skR, err := kem.deriveKeyPair(ikmR)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, v.SkRm, hex.EncodeToString(hpkePrivateKeyBytes(skR)))
+			assert.Equal(t, v.PkRm, hex.EncodeToString(skR.hpkeBytes()))
+
+			skE, err := kem.deriveKeyPair(ikmE)
+			if !assert.NoError(t, err) {
+				return
+			}
+			enc, sender, err := suite.setupSEphemeral(kem, v.Mode, skR, info, nil, nil, nil, skE)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, v.Enc, hex.EncodeToString(enc))
+
+			receiver, err := suite.setupR(v.Mode, enc, skR, info, nil, nil, nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			if v.AEAD != HPKEAEADExportOnly {
+				source, sink := sha3.NewShake128(), sha3.NewShake128()
+				for i := 0; i < 1000; i++ {
+					aad, pt := drawHPKEInput(source), drawHPKEInput(source)
+					ct, err := sender.Seal(aad, pt)
+					if !assert.NoError(t, err) {
+						return
+					}
+					sink.Write(ct)
+					opened, err := receiver.Open(aad, ct)
+					if !assert.NoError(t, err) || !assert.True(t, bytes.Equal(pt, opened)) {
+						return
+					}
+				}
+				acc := make([]byte, 16)
+				sink.Read(acc)
+				assert.Equal(t, v.AccEncryptions, hex.EncodeToString(acc))
+			}
+
+			source, sink := sha3.NewShake128(), sha3.NewShake128()
+			for l := 0; l < 1000; l++ {
+				exporterContext := drawHPKEInput(source)
+				exported, err := sender.Export(exporterContext, l)
+				if !assert.NoError(t, err) {
+					return
+				}
+				sink.Write(exported)
+				expected, err := receiver.Export(exporterContext, l)
+				if !assert.NoError(t, err) || !assert.Equal(t, expected, exported) {
+					return
+				}
+			}
+			acc := make([]byte, 16)
+			sink.Read(acc)
+			assert.Equal(t, v.AccExports, hex.EncodeToString(acc))
+		})
+	}
+}
+
+// TestHPKEVectorA11 checks DHKEM(X25519, HKDF-SHA256), HKDF-SHA256, AES-128-GCM base mode vector
+// of RFC 9180 Appendix A.1.1 with its literal ciphertext and exported values
+func TestHPKEVectorA11(t *testing.T) {
+	decode := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	skE, err := DeriveHPKEX25519KeyPair(decode("7268600d403fce431561aef583ee1613527cff655c1343f29812e66706df3234"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "52c4a758a802cd8b936eceea314432798d5baf2d7e9235dc084ab1b9cfa2f736", skE.Hex())
+	skR, err := NewX25519PrivateKeyFromHex("4612c550263fc8ad58375df3f557aac531d26850903e55a9f23f21d8534e8ac8")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	suite := HPKESuite{KDF: HPKEKDFHKDFSHA256, AEAD: HPKEAEADAES128GCM}
+	info := decode("4f6465206f6e2061204772656369616e2055726e")
+	enc, sender, err := suite.setupSEphemeral(hpkeX25519KEM, HPKEModeBase, skR, info, nil, nil, nil, skE)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "37fda3567bdbd628e88668c3c8d7e97d1d1253b6d4ea6d44c150f741f1bf4431", hex.EncodeToString(enc))
+
+	ct, err := sender.Seal(decode("436f756e742d30"), decode("4265617574792069732074727574682c20747275746820626561757479"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "f938558b5d72f1a23810b4be2ab4f84331acc02fc97babc53a52ae8218a355a96d8770ac83d07bea87e13c512a",
+		hex.EncodeToString(ct))
+
+	for _, e := range []struct {
+		context, value string
+	}{
+		{"", "3853fe2b4035195a573ffc53856e77058e15d9ea064de3e59f4961d0095250ee"},
+		{"00", "2e8f0b54673c7029649d4eb9d5e33bf1872cf76d623ff164ac185da9e88c21a5"},
+		{"54657374436f6e74657874", "e9e43065102c3836401bed8c3c3c75ae46be1639869391d62c61f1ec7af54931"},
+	} {
+		exported, err := sender.Export(decode(e.context), 32)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, e.value, hex.EncodeToString(exported))
+	}
+}
+
+func TestEncryptAndDecryptHPKE(t *testing.T) {
+	skipUnapproved(t)
+
+	for _, suite := range testingHPKESuites {
+		testEncryptAndDecryptParameters(DEFAULT_CONFIG.WithHPKE(suite).WithKDFInfo([]byte("info")), t)
+	}
+}
+
+func TestDeriveHPKEKeyPair(t *testing.T) {
+	k1, err := DeriveHPKEKeyPair([]byte("input keying material"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	k2, err := DeriveHPKEKeyPair([]byte("input keying material"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.True(t, k1.Equals(k2))
+	assert.True(t, k1.IsOnCurve(k1.X, k1.Y))
+}
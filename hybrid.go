@@ -0,0 +1,167 @@
+//go:build go1.24
+// +build go1.24
+
+package eciesgo
+
+import (
+	"crypto/mlkem"
+	"fmt"
+)
+
+// Hybrid ciphertext layout: optional envelope header, ML-KEM-768 ciphertext (1088 bytes) and ECIES ciphertext,
+// which key is derived from both EC shared point and ML-KEM shared secret (appended to the KDF input);
+// the message stays confidential until both secp256k1 and ML-KEM are broken
+
+// HybridPrivateKey is secp256k1 private key paired with ML-KEM-768 decapsulation key
+type HybridPrivateKey struct {
+	EC  *PrivateKey
+	KEM *mlkem.DecapsulationKey768
+}
+
+// HybridPublicKey is secp256k1 public key paired with ML-KEM-768 encapsulation key
+type HybridPublicKey struct {
+	EC  *PublicKey
+	KEM *mlkem.EncapsulationKey768
+}
+
+// GenerateHybridKey generates secp256k1 and ML-KEM-768 key pairs
+func GenerateHybridKey() (*HybridPrivateKey, error) {
+	ec, err := GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	kem, err := mlkem.GenerateKey768()
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate ML-KEM key: %w", err)
+	}
+
+	return &HybridPrivateKey{EC: ec, KEM: kem}, nil
+}
+
+// NewHybridPrivateKeyFromBytes decodes 32 bytes secp256k1 private key followed by 64 bytes ML-KEM-768 seed
+func NewHybridPrivateKeyFromBytes(b []byte) (*HybridPrivateKey, error) {
+	if len(b) != 32+mlkem.SeedSize {
+		return nil, fmt.Errorf("%w: invalid hybrid private key length %d", ErrInvalidPrivateKey, len(b))
+	}
+
+	ec, err := NewPrivateKeyFromBytes(b[:32])
+	if err != nil {
+		return nil, err
+	}
+
+	kem, err := mlkem.NewDecapsulationKey768(b[32:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
+	}
+
+	return &HybridPrivateKey{EC: ec, KEM: kem}, nil
+}
+
+// Bytes returns 32 bytes secp256k1 private key followed by 64 bytes ML-KEM-768 seed
+func (k *HybridPrivateKey) Bytes() []byte {
+	return append(k.EC.Bytes(), k.KEM.Bytes()...)
+}
+
+// Public returns hybrid public key of the private key
+func (k *HybridPrivateKey) Public() *HybridPublicKey {
+	return &HybridPublicKey{EC: k.EC.PublicKey, KEM: k.KEM.EncapsulationKey()}
+}
+
+// NewHybridPublicKeyFromBytes decodes 33 bytes compressed secp256k1 public key followed by
+// 1184 bytes ML-KEM-768 encapsulation key
+func NewHybridPublicKeyFromBytes(b []byte) (*HybridPublicKey, error) {
+	if len(b) != 33+mlkem.EncapsulationKeySize768 {
+		return nil, fmt.Errorf("%w: invalid hybrid public key length %d", ErrInvalidPublicKey, len(b))
+	}
+
+	ec, err := NewPublicKeyFromBytes(b[:33])
+	if err != nil {
+		return nil, err
+	}
+
+	kem, err := mlkem.NewEncapsulationKey768(b[33:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+
+	return &HybridPublicKey{EC: ec, KEM: kem}, nil
+}
+
+// Bytes returns 33 bytes compressed secp256k1 public key followed by 1184 bytes ML-KEM-768 encapsulation key
+func (k *HybridPublicKey) Bytes() []byte {
+	return append(k.EC.Bytes(true), k.KEM.Bytes()...)
+}
+
+// EncryptHybrid encrypts a passed message with a receiver hybrid public key
+func EncryptHybrid(pubkey *HybridPublicKey, msg []byte) ([]byte, error) {
+	return EncryptHybridConf(pubkey, msg, DefaultConfig())
+}
+
+// EncryptHybridConf encrypts a passed message with a receiver hybrid public key and the passed config;
+// ML-KEM encapsulation always reads crypto/rand, so WithRand does not make it deterministic
+func EncryptHybridConf(pubkey *HybridPublicKey, msg []byte, config Config) ([]byte, error) {
+	if config.hpke != nil {
+		return nil, fmt.Errorf("hybrid encryption is not supported in HPKE mode")
+	}
+	if pubkey == nil || pubkey.EC == nil || pubkey.KEM == nil {
+		return nil, fmt.Errorf("%w: public key is empty", ErrInvalidPublicKey)
+	}
+	if err := checkPlaintextSize(config, len(msg)); err != nil {
+		return nil, err
+	}
+
+	ct, config, err := appendEnvelope(nil, keyCurve(pubkey.EC), config)
+	if err != nil {
+		return nil, err
+	}
+
+	ss, kemCiphertext := pubkey.KEM.Encapsulate()
+	if config.wipeSecrets {
+		defer zeroBytes(ss)
+	}
+	config.kdfSuffix = ss
+
+	return EncryptAppendConf(append(ct, kemCiphertext...), pubkey.EC, msg, config)
+}
+
+// DecryptHybrid decrypts a passed message with a receiver hybrid private key
+func DecryptHybrid(privkey *HybridPrivateKey, msg []byte) ([]byte, error) {
+	return DecryptHybridConf(privkey, msg, DefaultConfig())
+}
+
+// DecryptHybridConf decrypts a passed message with a receiver hybrid private key and the passed config
+func DecryptHybridConf(privkey *HybridPrivateKey, msg []byte, config Config) ([]byte, error) {
+	if config.hpke != nil {
+		return nil, fmt.Errorf("hybrid encryption is not supported in HPKE mode")
+	}
+	if privkey == nil || privkey.EC == nil || privkey.KEM == nil {
+		return nil, fmt.Errorf("%w: private key is empty", ErrInvalidPrivateKey)
+	}
+	// ML-KEM ciphertext precedes ECIES one, envelope header is counted by checkCiphertextSize
+	if err := checkCiphertextSize(config, privateKeyCurve(privkey.EC), len(msg)-mlkem.CiphertextSize768); err != nil {
+		return nil, err
+	}
+
+	msg, config, err := openEnvelope(msg, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(msg) < mlkem.CiphertextSize768 {
+		return nil, newParseError(ErrCiphertextTooShort, "ciphertext", len(msg), "ML-KEM ciphertext is truncated")
+	}
+
+	// ML-KEM decapsulation implicitly rejects invalid ciphertexts with pseudorandom secret,
+	// which makes symmetric decryption fail
+	ss, err := privkey.KEM.Decapsulate(msg[:mlkem.CiphertextSize768])
+	if err != nil {
+		return nil, fmt.Errorf("cannot decapsulate ML-KEM ciphertext: %w", err)
+	}
+	if config.wipeSecrets {
+		defer zeroBytes(ss)
+	}
+	config.kdfSuffix = ss
+
+	return decryptAppend(nil, privkey.EC, msg[mlkem.CiphertextSize768:], config)
+}
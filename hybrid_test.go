@@ -0,0 +1,113 @@
+//go:build go1.24
+// +build go1.24
+
+package eciesgo
+
+import (
+	"crypto/mlkem"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptAndDecryptHybrid(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateHybridKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, conf := range []Config{
+		DEFAULT_CONFIG,
+		NewConfig("xchacha20", 24).WithEnvelope(),
+		DEFAULT_CONFIG.WithSharedPointKDF().WithSecretWiping(),
+	} {
+		ciphertext, err := EncryptHybridConf(privkey.Public(), []byte(testingMessage), conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		plaintext, err := DecryptHybridConf(privkey, ciphertext, conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(plaintext))
+	}
+
+	ciphertext, err := EncryptHybrid(privkey.Public(), []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Both secrets are required: EC part alone is not decryptable
+	_, err = Decrypt(privkey.EC, ciphertext[mlkem.CiphertextSize768:])
+	assert.Error(t, err)
+
+	other, err := GenerateHybridKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = DecryptHybrid(&HybridPrivateKey{EC: privkey.EC, KEM: other.KEM}, ciphertext)
+	assert.Error(t, err)
+	_, err = DecryptHybrid(&HybridPrivateKey{EC: other.EC, KEM: privkey.KEM}, ciphertext)
+	assert.Error(t, err)
+
+	tampered := append([]byte(nil), ciphertext...)
+	tampered[0] ^= 1
+	_, err = DecryptHybrid(privkey, tampered)
+	assert.Error(t, err)
+
+	_, err = DecryptHybrid(privkey, ciphertext[:100])
+	assert.True(t, errors.Is(err, ErrCiphertextTooShort))
+
+	_, err = EncryptHybridConf(privkey.Public(), []byte(testingMessage), DEFAULT_CONFIG.WithHPKE(HPKESuite{KDF: HPKEKDFHKDFSHA256, AEAD: HPKEAEADAES128GCM}))
+	assert.Error(t, err)
+}
+
+func TestHybridKeyEncoding(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateHybridKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	decodedPriv, err := NewHybridPrivateKeyFromBytes(privkey.Bytes())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, decodedPriv.EC.Equals(privkey.EC))
+	assert.Equal(t, privkey.KEM.Bytes(), decodedPriv.KEM.Bytes())
+
+	pubBytes := privkey.Public().Bytes()
+	assert.Len(t, pubBytes, 33+mlkem.EncapsulationKeySize768)
+	decodedPub, err := NewHybridPublicKeyFromBytes(pubBytes)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ciphertext, err := EncryptHybrid(decodedPub, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+	plaintext, err := DecryptHybrid(decodedPriv, ciphertext)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(plaintext))
+
+	_, err = NewHybridPrivateKeyFromBytes(privkey.Bytes()[1:])
+	assert.True(t, errors.Is(err, ErrInvalidPrivateKey))
+	_, err = NewHybridPublicKeyFromBytes(pubBytes[1:])
+	assert.True(t, errors.Is(err, ErrInvalidPublicKey))
+
+	// ML-KEM encapsulation key with non-canonical coefficients
+	invalid := append([]byte(nil), pubBytes...)
+	for i := 33; i < 33+12; i++ {
+		invalid[i] = 0xff
+	}
+	_, err = NewHybridPublicKeyFromBytes(invalid)
+	assert.True(t, errors.Is(err, ErrInvalidPublicKey))
+}
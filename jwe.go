@@ -0,0 +1,205 @@
+package eciesgo
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jweHeader is protected header of JWE produced with ECDH-ES key agreement (RFC 7518, section 4.6)
+type jweHeader struct {
+	Alg  string          `json:"alg"`
+	Enc  string          `json:"enc"`
+	EPK  *jwk            `json:"epk"`
+	APU  string          `json:"apu,omitempty"`
+	APV  string          `json:"apv,omitempty"`
+	Crit json.RawMessage `json:"crit,omitempty"`
+}
+
+// jweKeySizes are key sizes of supported JWE content encryption algorithms
+var jweKeySizes = map[string]int{"A128GCM": 16, "A192GCM": 24, "A256GCM": 32}
+
+// EncryptJWE encrypts a message to the receiver public key and returns JWE compact serialization
+// with "ECDH-ES" key agreement and "A256GCM" content encryption; secp256k1 (RFC 8812) and NIST curves are supported
+func EncryptJWE(pubkey *PublicKey, msg []byte) (string, error) {
+	return EncryptJWEWithParties(pubkey, msg, nil, nil)
+}
+
+// EncryptJWEWithParties is EncryptJWE with agreement PartyUInfo and PartyVInfo (apu and apv header parameters)
+func EncryptJWEWithParties(pubkey *PublicKey, msg, apu, apv []byte) (string, error) {
+	if err := pubkey.Validate(); err != nil {
+		return "", err
+	}
+
+	ek, err := generateKeyCurve(pubkey.Curve, randReader)
+	if err != nil {
+		return "", err
+	}
+	defer ek.Zeroize()
+
+	epk, err := publicJWK(ek.PublicKey)
+	if err != nil {
+		return "", err
+	}
+
+	header := jweHeader{
+		Alg: "ECDH-ES",
+		Enc: "A256GCM",
+		EPK: epk,
+		APU: base64.RawURLEncoding.EncodeToString(apu),
+		APV: base64.RawURLEncoding.EncodeToString(apv),
+	}
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("cannot encode JWE header: %w", err)
+	}
+	protected := base64.RawURLEncoding.EncodeToString(headerJSON)
+
+	aead, err := jweCipher(ek, pubkey, header.Enc, apu, apv)
+	if err != nil {
+		return "", err
+	}
+
+	iv := make([]byte, aead.NonceSize())
+	if err := randomBytes(iv); err != nil {
+		return "", fmt.Errorf("cannot read random bytes for IV: %w", err)
+	}
+
+	sealed := aead.Seal(nil, iv, msg, []byte(protected))
+	ct, tag := sealed[:len(msg)], sealed[len(msg):]
+
+	return strings.Join([]string{
+		protected,
+		"",
+		base64.RawURLEncoding.EncodeToString(iv),
+		base64.RawURLEncoding.EncodeToString(ct),
+		base64.RawURLEncoding.EncodeToString(tag),
+	}, "."), nil
+}
+
+// DecryptJWE decrypts JWE compact serialization with "ECDH-ES" key agreement
+// and "A128GCM", "A192GCM" or "A256GCM" content encryption
+func DecryptJWE(privkey *PrivateKey, token string) ([]byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("%w: JWE must have 5 parts, got %d", ErrCiphertextTooShort, len(parts))
+	}
+	if parts[1] != "" {
+		return nil, fmt.Errorf("%w: encrypted key must be empty for ECDH-ES", ErrCiphertextTooShort)
+	}
+
+	decoded := make([][]byte, len(parts))
+	for i, p := range parts {
+		b, err := base64.RawURLEncoding.DecodeString(p)
+		if err != nil {
+			return nil, fmt.Errorf("%w: cannot decode JWE part %d: %v", ErrCiphertextTooShort, i, err)
+		}
+		decoded[i] = b
+	}
+
+	var header jweHeader
+	if err := json.Unmarshal(decoded[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: cannot decode JWE header: %v", ErrCiphertextTooShort, err)
+	}
+	switch {
+	case header.Alg != "ECDH-ES":
+		return nil, fmt.Errorf("unsupported JWE algorithm: %s", header.Alg)
+	case jweKeySizes[header.Enc] == 0:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCipher, header.Enc)
+	case header.Crit != nil:
+		return nil, fmt.Errorf("unsupported critical JWE header parameters: %s", header.Crit)
+	case header.EPK == nil:
+		return nil, fmt.Errorf("%w: JWE header has no ephemeral public key", ErrInvalidPublicKey)
+	}
+
+	epk, err := header.EPK.publicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	apu, err := base64.RawURLEncoding.DecodeString(header.APU)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode apu: %w", err)
+	}
+	apv, err := base64.RawURLEncoding.DecodeString(header.APV)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode apv: %w", err)
+	}
+
+	aead, err := jweCipher(privkey, epk, header.Enc, apu, apv)
+	if err != nil {
+		return nil, err
+	}
+
+	iv, ct, tag := decoded[2], decoded[3], decoded[4]
+	if len(iv) != aead.NonceSize() || len(tag) != aead.Overhead() {
+		return nil, fmt.Errorf("%w: invalid IV or tag length", ErrCiphertextTooShort)
+	}
+
+	pt, err := aead.Open(nil, iv, bytes.Join([][]byte{ct, tag}, nil), []byte(parts[0]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAuthenticationFailed, err)
+	}
+
+	return pt, nil
+}
+
+// jweCipher derives content encryption key with Concat KDF over ECDH shared X coordinate (RFC 7518, section 4.6.2)
+func jweCipher(priv *PrivateKey, pub *PublicKey, enc string, apu, apv []byte) (cipher.AEAD, error) {
+	sx, sy, err := priv.sharedPoint(pub)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		zeroBigInt(sx)
+		zeroBigInt(sy)
+	}()
+
+	z := zeroPad(sx.Bytes(), len(pub.Curve.Params().P.Bytes()))
+	defer zeroBytes(z)
+
+	key := concatKDF(z, enc, apu, apv, jweKeySizes[enc])
+	defer zeroBytes(key)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create new AES block: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// concatKDF is NIST SP 800-56A Concat KDF with SHA-256 and OtherInfo built as in RFC 7518, section 4.6.2
+func concatKDF(z []byte, algorithmID string, apu, apv []byte, keySize int) []byte {
+	var otherInfo bytes.Buffer
+	for _, field := range [][]byte{[]byte(algorithmID), apu, apv} {
+		var l [4]byte
+		binary.BigEndian.PutUint32(l[:], uint32(len(field)))
+		otherInfo.Write(l[:])
+		otherInfo.Write(field)
+	}
+
+	var keyBits [4]byte
+	binary.BigEndian.PutUint32(keyBits[:], uint32(keySize*8))
+	otherInfo.Write(keyBits[:])
+
+	key := make([]byte, keySize)
+	var counter [4]byte
+	for i, out := uint32(1), key; len(out) > 0; i++ {
+		binary.BigEndian.PutUint32(counter[:], i)
+
+		h := sha256.New()
+		h.Write(counter[:])
+		h.Write(z)
+		h.Write(otherInfo.Bytes())
+		out = out[copy(out, h.Sum(nil)):]
+	}
+
+	return key
+}
@@ -0,0 +1,100 @@
+package eciesgo
+
+import (
+	"crypto/elliptic"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testingJWK decodes private JWK used in tests
+func testingJWK(t *testing.T, k jwk) *PrivateKey {
+	pub, err := k.publicKey()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	d, _ := base64.RawURLEncoding.DecodeString(k.D)
+	return &PrivateKey{PublicKey: pub, D: new(big.Int).SetBytes(d)}
+}
+
+// RFC 7518, appendix C
+func TestConcatKDF(t *testing.T) {
+	alice := testingJWK(t, jwk{
+		Kty: "EC", Crv: "P-256",
+		X: "gI0GAILBdu7T53akrFmMyGcsF3n5dO7MmwNBHKW5SV0",
+		Y: "SLW_xSffzlPWrHEVI30DHM_4egVwt3NQqeUD7nMFpps",
+		D: "0_NxaRPUMQoAJt50Gz8YiTr8gRTwyEaCumd-MToTmIo",
+	})
+	bob := testingJWK(t, jwk{
+		Kty: "EC", Crv: "P-256",
+		X: "weNJy2HscCSM6AEDTDg04biOvhFhyyWvOHQfeF_PxMQ",
+		Y: "e8lnCO-AlStT-NJVX-crhB7QRYhiix03illJOVAOyck",
+		D: "VEmDZpDXXK8p8N0Cndsxs924q6nS1RXFASRl6BfUqdw",
+	})
+
+	sx, _, err := alice.sharedPoint(bob.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	key := concatKDF(zeroPad(sx.Bytes(), 32), "A128GCM", []byte("Alice"), []byte("Bob"), 16)
+	assert.Equal(t, "VqqN6vgjbSBcIijNcacQGg", base64.RawURLEncoding.EncodeToString(key))
+}
+
+func TestEncryptJWE(t *testing.T) {
+	skipUnapproved(t)
+
+	secp256k1Key, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	p256Key, err := GenerateKeyCurve(elliptic.P256())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, privkey := range []*PrivateKey{secp256k1Key, p256Key} {
+		token, err := EncryptJWEWithParties(privkey.PublicKey, []byte(testingMessage), []byte("Alice"), []byte("Bob"))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, 4, strings.Count(token, "."))
+
+		pt, err := DecryptJWE(privkey, token)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(pt))
+
+		// Protected header is authenticated
+		parts := strings.Split(token, ".")
+		header, _ := base64.RawURLEncoding.DecodeString(parts[0])
+		parts[0] = base64.RawURLEncoding.EncodeToString([]byte(strings.Replace(string(header), `"apv":"Qm9i"`, `"apv":"Qm9j"`, 1)))
+		_, err = DecryptJWE(privkey, strings.Join(parts, "."))
+		assert.True(t, errors.Is(err, ErrAuthenticationFailed), "%v", err)
+	}
+
+	token, err := EncryptJWE(secp256k1Key.PublicKey, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Key on another curve is rejected
+	_, err = DecryptJWE(p256Key, token)
+	assert.Error(t, err)
+
+	for _, invalid := range []string{
+		"",
+		strings.Replace(token, "..", ".AAAA.", 1),
+		token[:len(token)-2],
+		base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RSA-OAEP","enc":"A256GCM"}`)) + "..AA.AA.AA",
+		base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"ECDH-ES","enc":"A256GCM"}`)) + "..AA.AA.AA",
+	} {
+		_, err = DecryptJWE(secp256k1Key, invalid)
+		assert.Error(t, err)
+	}
+}
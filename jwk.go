@@ -0,0 +1,153 @@
+package eciesgo
+
+import (
+	"crypto/elliptic"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// jwk is EC JSON Web Key (RFC 7518, section 6.2)
+type jwk struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	D   string `json:"d,omitempty"`
+}
+
+// jwkCurveName returns JWK "crv" name of the curve, which is its registered name (see RegisterCurve)
+func jwkCurveName(curve elliptic.Curve) (string, error) {
+	name, err := CurveName(curve)
+	if err != nil {
+		return "", fmt.Errorf("curve has no JWK name: %w", err)
+	}
+
+	return name, nil
+}
+
+// jwkCurve returns curve by JWK "crv" name
+func jwkCurve(name string) (elliptic.Curve, error) {
+	curve, err := CurveByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported JWK curve: %w", err)
+	}
+
+	return curve, nil
+}
+
+// publicJWK encodes public key as JWK, coordinates are padded to the field size
+func publicJWK(pub *PublicKey) (*jwk, error) {
+	crv, err := jwkCurveName(pub.Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	l := len(pub.Curve.Params().P.Bytes())
+	return &jwk{
+		Kty: "EC",
+		Crv: crv,
+		X:   base64.RawURLEncoding.EncodeToString(zeroPad(pub.X.Bytes(), l)),
+		Y:   base64.RawURLEncoding.EncodeToString(zeroPad(pub.Y.Bytes(), l)),
+	}, nil
+}
+
+// publicKey decodes and validates public part of JWK
+func (k *jwk) publicKey() (*PublicKey, error) {
+	if k.Kty != "EC" {
+		return nil, fmt.Errorf("%w: unsupported JWK key type %s", ErrInvalidPublicKey, k.Kty)
+	}
+
+	curve, err := jwkCurve(k.Crv)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+
+	l := len(curve.Params().P.Bytes())
+	x, errX := base64.RawURLEncoding.DecodeString(k.X)
+	y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+	if errX != nil || errY != nil || len(x) != l || len(y) != l {
+		return nil, fmt.Errorf("%w: invalid JWK coordinates", ErrInvalidPublicKey)
+	}
+
+	pub := &PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	if err := pub.Validate(); err != nil {
+		return nil, err
+	}
+
+	return pub, nil
+}
+
+// JWK returns public key as EC JSON Web Key (RFC 7517), e.g. {"kty":"EC","crv":"secp256k1","x":"...","y":"..."};
+// secp256k1 (RFC 8812) and NIST curves are supported
+func (k *PublicKey) JWK() ([]byte, error) {
+	if err := k.Validate(); err != nil {
+		return nil, err
+	}
+
+	key, err := publicJWK(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(key)
+}
+
+// JWK returns private key as EC JSON Web Key with private scalar "d"
+func (k *PrivateKey) JWK() ([]byte, error) {
+	if err := k.Validate(); err != nil {
+		return nil, err
+	}
+
+	key, err := publicJWK(k.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	key.D = base64.RawURLEncoding.EncodeToString(zeroPad(k.D.Bytes(), len(k.Curve.Params().N.Bytes())))
+
+	return json.Marshal(key)
+}
+
+// NewPublicKeyFromJWK decodes and validates EC JSON Web Key; private scalar is ignored if present
+func NewPublicKeyFromJWK(b []byte) (*PublicKey, error) {
+	var key jwk
+	if err := json.Unmarshal(b, &key); err != nil {
+		return nil, fmt.Errorf("%w: cannot decode JWK: %v", ErrInvalidPublicKey, err)
+	}
+
+	return key.publicKey()
+}
+
+// NewPrivateKeyFromJWK decodes EC JSON Web Key with private scalar "d"
+// and checks that public coordinates match it
+func NewPrivateKeyFromJWK(b []byte) (*PrivateKey, error) {
+	var key jwk
+	if err := json.Unmarshal(b, &key); err != nil {
+		return nil, fmt.Errorf("%w: cannot decode JWK: %v", ErrInvalidPrivateKey, err)
+	}
+
+	pub, err := key.publicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	n := pub.Curve.Params().N
+	d, err := base64.RawURLEncoding.DecodeString(key.D)
+	if err != nil || len(d) != len(n.Bytes()) {
+		return nil, fmt.Errorf("%w: invalid JWK private scalar", ErrInvalidPrivateKey)
+	}
+
+	priv := &PrivateKey{PublicKey: pub, D: new(big.Int).SetBytes(d)}
+	zeroBytes(d)
+	if err := priv.Validate(); err != nil {
+		return nil, err
+	}
+
+	x, y := scalarBaseMult(pub.Curve, priv.Bytes())
+	if x.Cmp(pub.X) != 0 || y.Cmp(pub.Y) != 0 {
+		return nil, fmt.Errorf("%w: JWK public key does not match private scalar", ErrInvalidPrivateKey)
+	}
+
+	return priv, nil
+}
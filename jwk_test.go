@@ -0,0 +1,87 @@
+package eciesgo
+
+import (
+	"crypto/elliptic"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWK(t *testing.T) {
+	skipUnapproved(t)
+
+	secp256k1Key, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	p384Key, err := GenerateKeyCurve(elliptic.P384())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for crv, privkey := range map[string]*PrivateKey{"secp256k1": secp256k1Key, "P-384": p384Key} {
+		b, err := privkey.PublicKey.JWK()
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		var fields map[string]string
+		if !assert.NoError(t, json.Unmarshal(b, &fields)) {
+			return
+		}
+		assert.Equal(t, "EC", fields["kty"])
+		assert.Equal(t, crv, fields["crv"])
+		assert.NotContains(t, fields, "d")
+
+		pub, err := NewPublicKeyFromJWK(b)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.True(t, pub.Equals(privkey.PublicKey))
+
+		b, err = privkey.JWK()
+		if !assert.NoError(t, err) {
+			return
+		}
+		imported, err := NewPrivateKeyFromJWK(b)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.True(t, imported.Equals(privkey))
+		assert.True(t, imported.PublicKey.Equals(privkey.PublicKey))
+	}
+
+	// Public key without private scalar
+	b, _ := secp256k1Key.PublicKey.JWK()
+	_, err = NewPrivateKeyFromJWK(b)
+	assert.True(t, errors.Is(err, ErrInvalidPrivateKey), "%v", err)
+
+	// Private scalar does not match public coordinates
+	other, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	var mismatched jwk
+	b, _ = secp256k1Key.JWK()
+	json.Unmarshal(b, &mismatched)
+	b, _ = other.JWK()
+	var otherKey jwk
+	json.Unmarshal(b, &otherKey)
+	mismatched.D = otherKey.D
+	b, _ = json.Marshal(mismatched)
+	_, err = NewPrivateKeyFromJWK(b)
+	assert.True(t, errors.Is(err, ErrInvalidPrivateKey), "%v", err)
+
+	for _, invalid := range []string{
+		``,
+		`{"kty":"RSA","n":"AQAB","e":"AQAB"}`,
+		`{"kty":"EC","crv":"P-192","x":"AA","y":"AA"}`,
+		`{"kty":"EC","crv":"secp256k1","x":"AA","y":"AA"}`,
+		`{"kty":"EC","crv":"secp256k1","x":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA","y":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"}`,
+	} {
+		_, err = NewPublicKeyFromJWK([]byte(invalid))
+		assert.True(t, errors.Is(err, ErrInvalidPublicKey), "%s: %v", invalid, err)
+	}
+}
@@ -0,0 +1,131 @@
+package eciesgo
+
+import (
+	"fmt"
+)
+
+// Encapsulate generates ephemeral key pair and derives symmetric key for the receiver public key;
+// returns 65 bytes uncompressed ephemeral public key, which must be sent to the receiver, and the symmetric key.
+// Ephemeral private key never leaves the function and is wiped before return
+func Encapsulate(pubkey *PublicKey) (ephemeral, key []byte, err error) {
+	return EncapsulateConf(pubkey, DefaultConfig())
+}
+
+// EncapsulateConf generates ephemeral key pair and derives symmetric key for the receiver public key
+// with KDF and symmetric key size taken from the passed config
+func EncapsulateConf(pubkey *PublicKey, config Config) (ephemeral, key []byte, err error) {
+	if err := checkTrusted(config, pubkey); err != nil {
+		return nil, nil, err
+	}
+
+	ek, err := generateKey(config.random())
+	if err != nil {
+		return nil, nil, err
+	}
+	defer ek.Zeroize()
+
+	key, err = ek.encapsulate(pubkey, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ek.PublicKey.Bytes(false), key, nil
+}
+
+// Decapsulate derives symmetric key from the ephemeral public key produced by Encapsulate
+// and the receiver private key; ephemeral public key may be compressed or uncompressed
+func Decapsulate(privkey *PrivateKey, ephemeral []byte) ([]byte, error) {
+	return DecapsulateConf(privkey, ephemeral, DefaultConfig())
+}
+
+// DecapsulateConf derives symmetric key from the ephemeral public key and the receiver private key
+// with KDF and symmetric key size taken from the passed config
+func DecapsulateConf(privkey *PrivateKey, ephemeral []byte, config Config) ([]byte, error) {
+	pub, err := NewPublicKeyFromBytes(ephemeral)
+	if err != nil {
+		return nil, err
+	}
+
+	return pub.decapsulate(privkey, config)
+}
+
+// EncapsulateKeys works like EncapsulateConf, but derives several keys of the given lengths
+// from a single KDF output instead of one symmetric key
+func EncapsulateKeys(pubkey *PublicKey, config Config, lengths ...int) (ephemeral []byte, keys [][]byte, err error) {
+	total, err := totalKeyLength(lengths)
+	if err != nil {
+		return nil, nil, err
+	}
+	config.kdfLength = total
+
+	ephemeral, key, err := EncapsulateConf(pubkey, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ephemeral, splitKeys(key, lengths), nil
+}
+
+// DecapsulateKeys derives keys produced by EncapsulateKeys with the same config and lengths
+func DecapsulateKeys(privkey *PrivateKey, ephemeral []byte, config Config, lengths ...int) ([][]byte, error) {
+	total, err := totalKeyLength(lengths)
+	if err != nil {
+		return nil, err
+	}
+	config.kdfLength = total
+
+	key, err := DecapsulateConf(privkey, ephemeral, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return splitKeys(key, lengths), nil
+}
+
+// DeriveKeys derives keys of the given lengths from secret with the default KDF (HKDF-SHA256)
+func DeriveKeys(secret []byte, lengths ...int) ([][]byte, error) {
+	return DeriveKeysConf(secret, DefaultConfig(), lengths...)
+}
+
+// DeriveKeysConf derives keys of the given lengths from secret with KDF function, hash, salt and info
+// taken from the passed config; keys are consecutive parts of a single KDF output,
+// so e.g. lengths 32, 32, 16 give encryption key, MAC key and IV seed
+func DeriveKeysConf(secret []byte, config Config, lengths ...int) ([][]byte, error) {
+	total, err := totalKeyLength(lengths)
+	if err != nil {
+		return nil, err
+	}
+	config.kdfLength = total
+
+	key, err := kdf(secret, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return splitKeys(key, lengths), nil
+}
+
+func totalKeyLength(lengths []int) (int, error) {
+	total := 0
+	for _, l := range lengths {
+		if l <= 0 {
+			return 0, fmt.Errorf("invalid key length: %d", l)
+		}
+		total += l
+	}
+	if total == 0 {
+		return 0, fmt.Errorf("at least one key length is required")
+	}
+
+	return total, nil
+}
+
+// splitKeys cuts key into consecutive parts of the given lengths, capacity of each part is limited to its length
+func splitKeys(key []byte, lengths []int) [][]byte {
+	keys := make([][]byte, len(lengths))
+	for i, l := range lengths {
+		keys[i], key = key[:l:l], key[l:]
+	}
+
+	return keys
+}
@@ -0,0 +1,152 @@
+package eciesgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncapsulateDecapsulate(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ephemeral, key, err := Encapsulate(privkey.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, ephemeral, 65)
+	assert.Len(t, key, 32)
+
+	decapsulated, err := Decapsulate(privkey, ephemeral)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, key, decapsulated)
+
+	// Compressed ephemeral key gives the same symmetric key
+	pub, err := NewPublicKeyFromBytes(ephemeral)
+	if !assert.NoError(t, err) {
+		return
+	}
+	decapsulated, err = Decapsulate(privkey, pub.Bytes(true))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, key, decapsulated)
+
+	// Key size follows the config
+	conf := NewConfig("aes-128-gcm", 16)
+	ephemeral, key, err = EncapsulateConf(privkey.PublicKey, conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	decapsulated, err = DecapsulateConf(privkey, ephemeral, conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, key, decapsulated)
+	assert.Len(t, key, 16)
+
+	other, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	decapsulated, err = Decapsulate(other, ephemeral)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotEqual(t, key, decapsulated)
+
+	_, err = Decapsulate(privkey, ephemeral[:64])
+	assert.Error(t, err)
+	_, _, err = Encapsulate(nil)
+	assert.Error(t, err)
+}
+
+func TestEncapsulateDecapsulateKeys(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ephemeral, keys, err := EncapsulateKeys(privkey.PublicKey, DEFAULT_CONFIG, 32, 32, 16)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, keys, 3) {
+		return
+	}
+	assert.Len(t, keys[0], 32)
+	assert.Len(t, keys[1], 32)
+	assert.Len(t, keys[2], 16)
+	assert.NotEqual(t, keys[0], keys[1])
+
+	decapsulated, err := DecapsulateKeys(privkey, ephemeral, DEFAULT_CONFIG, 32, 32, 16)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, keys, decapsulated)
+
+	// The first key of a single length is the usual symmetric key
+	key, err := DecapsulateConf(privkey, ephemeral, DEFAULT_CONFIG)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, keys[0], key)
+
+	_, _, err = EncapsulateKeys(privkey.PublicKey, DEFAULT_CONFIG)
+	assert.Error(t, err)
+	_, err = DecapsulateKeys(privkey, ephemeral, DEFAULT_CONFIG, 32, 0)
+	assert.Error(t, err)
+}
+
+func TestDeriveKeys(t *testing.T) {
+	skipUnapproved(t)
+
+	secret := []byte("shared secret")
+
+	for _, conf := range []Config{
+		DEFAULT_CONFIG,
+		DEFAULT_CONFIG.WithKDFHash("sha512").WithKDFInfo([]byte("protocol v1")),
+		DEFAULT_CONFIG.WithKDF("x963"),
+	} {
+		keys, err := DeriveKeysConf(secret, conf, 32, 32, 16)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		// Keys are consecutive parts of a single KDF output
+		whole, err := DeriveKeysConf(secret, conf, 80)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, whole[0], append(append(append([]byte(nil), keys[0]...), keys[1]...), keys[2]...))
+
+		// Appending to a key does not overwrite the next one
+		next := append([]byte(nil), keys[1]...)
+		_ = append(keys[0], 0xff)
+		assert.Equal(t, next, keys[1])
+	}
+
+	keys, err := DeriveKeys(secret, 32)
+	if !assert.NoError(t, err) {
+		return
+	}
+	key, err := kdf(secret, DEFAULT_CONFIG)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, key, keys[0])
+
+	// HKDF output is limited to 255 hash blocks
+	_, err = DeriveKeys(secret, 255*32+1)
+	assert.Error(t, err)
+	_, err = DeriveKeys(secret, -1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,100 @@
+package eciesgo
+
+import (
+	"container/list"
+	"crypto/subtle"
+	"sync"
+)
+
+// PublicKeyCache is a thread-safe LRU cache of parsed and validated public keys
+// keyed on their compressed encoding
+type PublicKeyCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	items map[string]*list.Element
+}
+
+type publicKeyCacheEntry struct {
+	key    string
+	pubkey *PublicKey
+}
+
+// NewPublicKeyCache returns cache which keeps at most size most recently used public keys
+func NewPublicKeyCache(size int) *PublicKeyCache {
+	if size < 1 {
+		size = 1
+	}
+
+	return &PublicKeyCache{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+// FromBytesCached parses and validates public key like NewPublicKeyFromBytes,
+// but returns cached instance for already validated keys;
+// returned public key is shared between callers and must not be modified
+func (c *PublicKeyCache) FromBytesCached(b []byte) (*PublicKey, error) {
+	key, ok := compressedCacheKey(b)
+	if !ok {
+		return NewPublicKeyFromBytes(b)
+	}
+
+	c.mu.Lock()
+	if e, ok := c.items[key]; ok {
+		pubkey := e.Value.(*publicKeyCacheEntry).pubkey
+
+		// Uncompressed encoding shares cache key with the compressed one, so Y must be compared as well
+		if len(b) == 33 || subtle.ConstantTimeCompare(pubkey.Bytes(false), b) == 1 {
+			c.order.MoveToFront(e)
+			c.mu.Unlock()
+			return pubkey, nil
+		}
+	}
+	c.mu.Unlock()
+
+	// Parse outside of the lock, concurrent parsing of the same key is harmless
+	pubkey, err := NewPublicKeyFromBytes(b)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		c.order.MoveToFront(e)
+		return e.Value.(*publicKeyCacheEntry).pubkey, nil
+	}
+
+	c.items[key] = c.order.PushFront(&publicKeyCacheEntry{key: key, pubkey: pubkey})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*publicKeyCacheEntry).key)
+	}
+
+	return pubkey, nil
+}
+
+// Len returns number of cached public keys
+func (c *PublicKeyCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// compressedCacheKey returns compressed encoding of public key raw bytes without validating them
+func compressedCacheKey(b []byte) (string, bool) {
+	switch {
+	case len(b) == 33 && (b[0] == 0x02 || b[0] == 0x03):
+		return string(b), true
+	case len(b) == 65 && b[0] == 0x04:
+		return string(append([]byte{0x02 | b[64]&1}, b[1:33]...)), true
+	default:
+		return "", false
+	}
+}
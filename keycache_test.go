@@ -0,0 +1,80 @@
+package eciesgo
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicKeyCache(t *testing.T) {
+	skipUnapproved(t)
+
+	cache := NewPublicKeyCache(2)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	k1, err := cache.FromBytesCached(privkey.PublicKey.Bytes(true))
+	if !assert.NoError(t, err) {
+		return
+	}
+	k2, err := cache.FromBytesCached(privkey.PublicKey.Bytes(false))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, k1 == k2)
+	assert.True(t, k1.Equals(privkey.PublicKey))
+	assert.Equal(t, 1, cache.Len())
+
+	// Uncompressed encoding with wrong Y must not hit the cache
+	b := privkey.PublicKey.Bytes(false)
+	b[40] ^= 1
+	_, err = cache.FromBytesCached(b)
+	assert.Error(t, err)
+
+	_, err = cache.FromBytesCached([]byte{0x02})
+	assert.Error(t, err)
+
+	// Eviction
+	for i := 0; i < 3; i++ {
+		k, err := GenerateKey()
+		if !assert.NoError(t, err) {
+			return
+		}
+		if _, err := cache.FromBytesCached(k.PublicKey.Bytes(true)); !assert.NoError(t, err) {
+			return
+		}
+	}
+	assert.Equal(t, 2, cache.Len())
+}
+
+func TestPublicKeyCacheConcurrent(t *testing.T) {
+	skipUnapproved(t)
+
+	cache := NewPublicKeyCache(16)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	b := privkey.PublicKey.Bytes(true)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				k, err := cache.FromBytesCached(b)
+				if err != nil || !k.Equals(privkey.PublicKey) {
+					t.Error("unexpected cached key")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,229 @@
+package eciesgo
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Encrypted key layout: magic (4 bytes), version, KDF ID, KDF parameters (9 bytes), salt (16 bytes),
+// XChaCha20-Poly1305 nonce (24 bytes) and sealed private key; everything before the sealed key is authenticated
+// as additional data. Argon2id parameters are time (4 bytes), memory in KiB (4 bytes) and threads,
+// scrypt ones are N (4 bytes), r (4 bytes) and p.
+// Exported keys are of version 2, which adds curve ID (see RegisterCurveID) after the nonce; version 1 keys,
+// which have no curve ID, are secp256k1 ones
+const (
+	encryptedKeyVersion      = 1
+	encryptedKeyCurveVersion = 2
+	encryptedKeyArgon2id     = 1
+	encryptedKeyScrypt       = 2
+	encryptedKeySaltLength   = 16
+	encryptedKeyHeaderLength = 4 + 1 + 1 + 4 + 4 + 1 + encryptedKeySaltLength + chacha20poly1305.NonceSizeX
+)
+
+var encryptedKeyMagic = []byte("ECIK")
+
+// Limits of Argon2id and scrypt parameters accepted on import, protect from blobs crafted to exhaust memory or CPU
+const (
+	maxArgon2Time   = 64
+	maxArgon2Memory = 4 * 1024 * 1024
+	maxScryptN      = 1 << 20
+	maxScryptMemory = 1 << 30
+	maxScryptP      = 16
+)
+
+// Argon2Params are Argon2id key derivation parameters; Memory is in KiB
+type Argon2Params struct {
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+}
+
+// DefaultArgon2Params are second recommended option of RFC 9106: 3 passes over 64 MiB with 4 lanes
+var DefaultArgon2Params = Argon2Params{Time: 3, Memory: 64 * 1024, Threads: 4}
+
+func (p Argon2Params) validate() error {
+	switch {
+	case p.Time == 0 || p.Time > maxArgon2Time:
+		return fmt.Errorf("argon2 time %d is out of range", p.Time)
+	case p.Threads == 0:
+		return fmt.Errorf("argon2 threads must be positive")
+	case p.Memory < 8*uint32(p.Threads) || p.Memory > maxArgon2Memory:
+		return fmt.Errorf("argon2 memory %d KiB is out of range", p.Memory)
+	}
+
+	return nil
+}
+
+// validatePasswordKDF checks that memory-hard KDF parameters are within import limits
+func validatePasswordKDF(params PasswordKDFParams) error {
+	switch params.Algorithm {
+	case "argon2id":
+		return Argon2Params{Time: params.Time, Memory: params.Memory, Threads: params.Threads}.validate()
+	case "scrypt":
+		switch {
+		case params.N <= 1 || params.N > maxScryptN || params.N&(params.N-1) != 0:
+			return fmt.Errorf("scrypt N %d is not a power of two in range", params.N)
+		case params.R <= 0 || 128*params.N*params.R > maxScryptMemory:
+			return fmt.Errorf("scrypt r %d is out of range", params.R)
+		case params.P <= 0 || params.P > maxScryptP:
+			return fmt.Errorf("scrypt p %d is out of range", params.P)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedKDF, params.Algorithm)
+	}
+}
+
+// Export encrypts private key with a password for storage at rest: key is derived with Argon2id
+// and the private key is sealed with XChaCha20-Poly1305; use DefaultArgon2Params unless you have measured better ones
+func (k *PrivateKey) Export(password []byte, params Argon2Params) ([]byte, error) {
+	return k.ExportWithKDF(password, PasswordKDFParams{
+		Algorithm: "argon2id",
+		Time:      params.Time,
+		Memory:    params.Memory,
+		Threads:   params.Threads,
+	})
+}
+
+// ExportWithKDF works like Export with the passed memory-hard KDF, "argon2id" or "scrypt";
+// keys of curves without ID are not supported
+func (k *PrivateKey) ExportWithKDF(password []byte, params PasswordKDFParams) ([]byte, error) {
+	if err := k.Validate(); err != nil {
+		return nil, err
+	}
+
+	id, err := curveID(k.Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	header, aead, err := newPasswordHeader(encryptedKeyMagic, encryptedKeyCurveVersion, password, params, DEFAULT_CONFIG)
+	if err != nil {
+		return nil, err
+	}
+	nonce := header[encryptedKeyHeaderLength-chacha20poly1305.NonceSizeX:]
+	header = append(header, id)
+
+	priv := k.Bytes()
+	defer zeroBytes(priv)
+
+	return aead.Seal(header, nonce, priv, header), nil
+}
+
+// ImportEncryptedKey decrypts private key exported with PrivateKey.Export or PrivateKey.ExportWithKDF
+func ImportEncryptedKey(blob, password []byte) (*PrivateKey, error) {
+	version, curve, headerLength := byte(encryptedKeyVersion), getCurve(), encryptedKeyHeaderLength
+	if len(blob) > encryptedKeyHeaderLength && blob[4] == encryptedKeyCurveVersion {
+		_, c, err := curveByID(blob[encryptedKeyHeaderLength])
+		if err != nil {
+			return nil, newParseError(ErrInvalidPrivateKey, "encrypted key", encryptedKeyHeaderLength, err.Error())
+		}
+		version, curve, headerLength = encryptedKeyCurveVersion, c, encryptedKeyHeaderLength+1
+	}
+
+	header, aead, err := openPasswordHeader(encryptedKeyMagic, version, blob, password, ErrInvalidPrivateKey, "encrypted key")
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := header[encryptedKeyHeaderLength-chacha20poly1305.NonceSizeX:]
+	priv, err := aead.Open(nil, nonce, blob[headerLength:], blob[:headerLength])
+	if err != nil {
+		return nil, fmt.Errorf("%w: wrong password or corrupted key", ErrAuthenticationFailed)
+	}
+	defer zeroBytes(priv)
+
+	return NewPrivateKeyFromBytesCurve(curve, priv)
+}
+
+// newPasswordHeader validates KDF parameters, generates random salt and nonce and returns header
+// of encrypted key layout with the given magic and version and the cipher keyed with password
+func newPasswordHeader(magic []byte, version byte, password []byte, params PasswordKDFParams, config Config) ([]byte, cipher.AEAD, error) {
+	if err := validatePasswordKDF(params); err != nil {
+		return nil, nil, err
+	}
+
+	header := make([]byte, encryptedKeyHeaderLength)
+	copy(header, magic)
+	header[4] = version
+	switch params.Algorithm {
+	case "argon2id":
+		header[5] = encryptedKeyArgon2id
+		binary.BigEndian.PutUint32(header[6:10], params.Time)
+		binary.BigEndian.PutUint32(header[10:14], params.Memory)
+		header[14] = params.Threads
+	case "scrypt":
+		header[5] = encryptedKeyScrypt
+		binary.BigEndian.PutUint32(header[6:10], uint32(params.N))
+		binary.BigEndian.PutUint32(header[10:14], uint32(params.R))
+		header[14] = byte(params.P)
+	}
+	if err := randomBytesConf(config, header[15:]); err != nil {
+		return nil, nil, fmt.Errorf("cannot read random bytes for salt and nonce: %w", err)
+	}
+
+	aead, err := encryptedKeyCipher(password, header[15:15+encryptedKeySaltLength], params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return header, aead, nil
+}
+
+// openPasswordHeader parses header of encrypted key layout with the given magic and version, checks KDF parameters
+// against import limits and returns the header and the cipher keyed with password; parse errors wrap base error
+func openPasswordHeader(magic []byte, version byte, blob, password []byte, base error, input string) ([]byte, cipher.AEAD, error) {
+	if len(blob) < encryptedKeyHeaderLength {
+		return nil, nil, newParseError(base, input, len(blob), "header is truncated")
+	}
+	if !bytes.Equal(blob[:4], magic) {
+		return nil, nil, newParseError(base, input, 0, "unknown magic")
+	}
+	if blob[4] != version {
+		return nil, nil, newParseError(base, input, 4, fmt.Sprintf("unsupported version %d", blob[4]))
+	}
+
+	var params PasswordKDFParams
+	switch blob[5] {
+	case encryptedKeyArgon2id:
+		params = PasswordKDFParams{
+			Algorithm: "argon2id",
+			Time:      binary.BigEndian.Uint32(blob[6:10]),
+			Memory:    binary.BigEndian.Uint32(blob[10:14]),
+			Threads:   blob[14],
+		}
+	case encryptedKeyScrypt:
+		params = PasswordKDFParams{Algorithm: "scrypt", P: int(blob[14])}
+		// Limits are checked before conversion, so huge values do not overflow int
+		if n, r := binary.BigEndian.Uint32(blob[6:10]), binary.BigEndian.Uint32(blob[10:14]); n <= maxScryptN && r <= maxScryptMemory {
+			params.N, params.R = int(n), int(r)
+		}
+	default:
+		return nil, nil, newParseError(ErrUnsupportedKDF, input, 5, fmt.Sprintf("unknown KDF ID %d", blob[5]))
+	}
+	if err := validatePasswordKDF(params); err != nil {
+		return nil, nil, newParseError(base, input, 6, err.Error())
+	}
+
+	header := blob[:encryptedKeyHeaderLength]
+	aead, err := encryptedKeyCipher(password, header[15:15+encryptedKeySaltLength], params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return header, aead, nil
+}
+
+func encryptedKeyCipher(password, salt []byte, params PasswordKDFParams) (cipher.AEAD, error) {
+	key, err := derivePasswordKey(password, salt, params, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(key)
+
+	return chacha20poly1305.NewX(key)
+}
@@ -0,0 +1,138 @@
+package eciesgo
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Small parameters keep tests fast, never use them for real keys
+var testingArgon2Params = Argon2Params{Time: 1, Memory: 64, Threads: 1}
+
+func TestPrivateKey_Export(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	blob, err := privkey.Export([]byte("password"), testingArgon2Params)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	imported, err := ImportEncryptedKey(blob, []byte("password"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, privkey.Equals(imported))
+
+	// Salt and nonce are random
+	blob2, err := privkey.Export([]byte("password"), testingArgon2Params)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotEqual(t, blob, blob2)
+
+	_, err = ImportEncryptedKey(blob, []byte("wrong"))
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), "%v", err)
+
+	// Header is authenticated
+	tampered := append([]byte(nil), blob...)
+	tampered[20] ^= 1
+	_, err = ImportEncryptedKey(tampered, []byte("password"))
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), "%v", err)
+
+	for _, b := range [][]byte{nil, blob[:encryptedKeyHeaderLength-1], append([]byte("XXXX"), blob[4:]...)} {
+		_, err = ImportEncryptedKey(b, []byte("password"))
+		assert.True(t, errors.Is(err, ErrInvalidPrivateKey), "%v", err)
+	}
+
+	// Parameters exceeding limits are rejected before key derivation
+	huge := append([]byte(nil), blob...)
+	huge[10] = 0xff
+	_, err = ImportEncryptedKey(huge, []byte("password"))
+	assert.True(t, errors.Is(err, ErrInvalidPrivateKey), "%v", err)
+
+	for _, params := range []Argon2Params{{}, {Time: 1, Memory: 64}, {Time: 1, Memory: 1, Threads: 1}} {
+		_, err = privkey.Export([]byte("password"), params)
+		assert.Error(t, err)
+	}
+}
+
+func TestPrivateKey_ExportWithKDF(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	blob, err := privkey.ExportWithKDF([]byte("password"), PasswordKDFParams{Algorithm: "scrypt", N: 1 << 10, R: 8, P: 1})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, byte(encryptedKeyScrypt), blob[5])
+
+	imported, err := ImportEncryptedKey(blob, []byte("password"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, privkey.Equals(imported))
+
+	_, err = ImportEncryptedKey(blob, []byte("wrong"))
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), "%v", err)
+
+	// N exceeding the limit is rejected before key derivation
+	huge := append([]byte(nil), blob...)
+	huge[6] = 0xff
+	_, err = ImportEncryptedKey(huge, []byte("password"))
+	assert.True(t, errors.Is(err, ErrInvalidPrivateKey), "%v", err)
+
+	unknown := append([]byte(nil), blob...)
+	unknown[5] = 0xff
+	_, err = ImportEncryptedKey(unknown, []byte("password"))
+	assert.True(t, errors.Is(err, ErrUnsupportedKDF), "%v", err)
+
+	for _, params := range []PasswordKDFParams{
+		{Algorithm: "scrypt", N: 1000, R: 8, P: 1},
+		{Algorithm: "scrypt", N: 1 << 21, R: 8, P: 1},
+		{Algorithm: "scrypt", N: 1 << 10, R: 8, P: 0},
+		{Algorithm: "pbkdf2"},
+	} {
+		_, err = privkey.ExportWithKDF([]byte("password"), params)
+		assert.Error(t, err)
+	}
+}
+
+func TestPrivateKey_ExportCurves(t *testing.T) {
+	skipUnapproved(t)
+
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384()} {
+		privkey, err := GenerateKeyCurve(curve)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		blob, err := privkey.ExportWithKDF([]byte("password"), PasswordKDFParams{Algorithm: "scrypt", N: 1 << 10, R: 8, P: 1})
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		imported, err := ImportEncryptedKey(blob, []byte("password"))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.True(t, privkey.Equals(imported))
+		assert.True(t, privkey.PublicKey.Equals(imported.PublicKey))
+
+		// Curve ID is authenticated
+		tampered := append([]byte(nil), blob...)
+		tampered[encryptedKeyHeaderLength] = 0x01
+		_, err = ImportEncryptedKey(tampered, []byte("password"))
+		assert.True(t, errors.Is(err, ErrAuthenticationFailed), "%v", err)
+	}
+}
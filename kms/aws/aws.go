@@ -0,0 +1,109 @@
+// Package aws implements eciesgo.Decapsulator on top of AWS KMS DeriveSharedSecret operation,
+// so ciphertexts can be decrypted without exporting the private key from KMS.
+//
+// AWS KMS performs key agreement only with keys of KEY_AGREEMENT usage, which are limited to NIST curves
+// and SM2, while ECC_SECG_P256K1 keys are sign-only. So the adapter works with ECC_NIST_P256, ECC_NIST_P384
+// and ECC_NIST_P521 keys: ciphertexts must be produced for P-256, P-384 or P-521 public keys and decrypted
+// with eciesgo.DecryptWithConf and the matching config curve; secp256k1 ephemeral keys are rejected.
+// GCP Cloud KMS and Azure Key Vault do not offer ECDH for EC keys, so there are no adapters for them.
+package aws
+
+import (
+	"context"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+
+	eciesgo "github.com/ecies/go/v2"
+)
+
+// SharedSecretDeriver is the subset of KMS client used by Decapsulator;
+// wrap kms.Client.DeriveSharedSecret from aws-sdk-go-v2 (KeyAgreementAlgorithm "ECDH") to implement it
+type SharedSecretDeriver interface {
+	DeriveSharedSecret(ctx context.Context, keyID string, publicKey []byte) ([]byte, error)
+}
+
+// Decapsulator computes shared points with the private key held by KMS
+type Decapsulator struct {
+	Client SharedSecretDeriver
+	KeyID  string
+}
+
+// NewDecapsulator returns Decapsulator for the KMS key with the given ID or ARN
+func NewDecapsulator(client SharedSecretDeriver, keyID string) *Decapsulator {
+	return &Decapsulator{Client: client, KeyID: keyID}
+}
+
+// SharedPoint implements eciesgo.Decapsulator; KMS returns X coordinate only, so Y is always nil
+func (d *Decapsulator) SharedPoint(pub *eciesgo.PublicKey) (x, y *big.Int, err error) {
+	return d.SharedPointContext(context.Background(), pub)
+}
+
+// SharedPointContext is SharedPoint which passes ctx to KMS client
+func (d *Decapsulator) SharedPointContext(ctx context.Context, pub *eciesgo.PublicKey) (x, y *big.Int, err error) {
+	der, err := marshalPublicKey(pub)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	secret, err := d.Client.DeriveSharedSecret(ctx, d.KeyID, der)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot derive shared secret with KMS: %w", err)
+	}
+
+	if len(secret) != len(pub.Params().P.Bytes()) {
+		return nil, nil, fmt.Errorf("invalid length of shared secret: %d", len(secret))
+	}
+
+	return new(big.Int).SetBytes(secret), nil, nil
+}
+
+var (
+	oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidNamedCurveP256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	oidNamedCurveP384 = asn1.ObjectIdentifier{1, 3, 132, 0, 34}
+	oidNamedCurveP521 = asn1.ObjectIdentifier{1, 3, 132, 0, 35}
+)
+
+// curveOID returns named curve OID of the curves KMS supports for key agreement
+func curveOID(curve elliptic.Curve) (asn1.ObjectIdentifier, error) {
+	switch curve {
+	case elliptic.P256():
+		return oidNamedCurveP256, nil
+	case elliptic.P384():
+		return oidNamedCurveP384, nil
+	case elliptic.P521():
+		return oidNamedCurveP521, nil
+	default:
+		return nil, fmt.Errorf("%w: KMS key agreement supports only P-256, P-384 and P-521 keys", eciesgo.ErrUnsupportedCurve)
+	}
+}
+
+type subjectPublicKeyInfo struct {
+	Algorithm struct {
+		Algorithm asn1.ObjectIdentifier
+		Curve     asn1.ObjectIdentifier
+	}
+	PublicKey asn1.BitString
+}
+
+// marshalPublicKey encodes public key as DER SubjectPublicKeyInfo, which KMS accepts
+func marshalPublicKey(pub *eciesgo.PublicKey) ([]byte, error) {
+	if pub == nil || pub.Curve == nil {
+		return nil, fmt.Errorf("%w: public key is empty", eciesgo.ErrInvalidPublicKey)
+	}
+	oid, err := curveOID(pub.Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	var spki subjectPublicKeyInfo
+	spki.Algorithm.Algorithm = oidPublicKeyECDSA
+	spki.Algorithm.Curve = oid
+
+	b := pub.Bytes(false)
+	spki.PublicKey = asn1.BitString{Bytes: b, BitLength: 8 * len(b)}
+
+	return asn1.Marshal(spki)
+}
@@ -0,0 +1,103 @@
+package aws
+
+import (
+	"context"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"testing"
+
+	eciesgo "github.com/ecies/go/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// testingKMS emulates KMS DeriveSharedSecret with a local key
+type testingKMS struct {
+	keys map[string]*eciesgo.PrivateKey
+}
+
+func (k *testingKMS) DeriveSharedSecret(_ context.Context, keyID string, publicKey []byte) ([]byte, error) {
+	priv, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("key not found: %s", keyID)
+	}
+
+	var spki subjectPublicKeyInfo
+	if _, err := asn1.Unmarshal(publicKey, &spki); err != nil {
+		return nil, err
+	}
+	oid, err := curveOID(priv.Curve)
+	if err != nil {
+		return nil, err
+	}
+	if !spki.Algorithm.Curve.Equal(oid) {
+		return nil, fmt.Errorf("key agreement curve mismatch: %v", spki.Algorithm.Curve)
+	}
+	pub, err := eciesgo.NewPublicKeyFromBytesCurve(priv.Curve, spki.PublicKey.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	ss, err := priv.ECDH(pub)
+	if err != nil {
+		return nil, err
+	}
+	return ss[1:], nil
+}
+
+func TestDecapsulator(t *testing.T) {
+	type testCurve struct {
+		name  string
+		curve elliptic.Curve
+	}
+	curves := []testCurve{{"P-256", elliptic.P256()}, {"P-384", elliptic.P384()}}
+	if !eciesgo.FIPSMode() {
+		curves = append(curves, testCurve{"P-521", elliptic.P521()})
+	}
+
+	for _, c := range curves {
+		priv, err := eciesgo.GenerateKeyCurve(c.curve)
+		if !assert.NoError(t, err) {
+			return
+		}
+		d := NewDecapsulator(&testingKMS{keys: map[string]*eciesgo.PrivateKey{"alias/ecies": priv}}, "alias/ecies")
+		config := eciesgo.DefaultConfig().WithCurve(c.name)
+
+		for i := 0; i < 4; i++ {
+			ciphertext, err := eciesgo.Encrypt(priv.PublicKey, []byte("helloworld"))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			plaintext, err := eciesgo.DecryptWithConf(d, ciphertext, config)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, "helloworld", string(plaintext))
+		}
+
+		ciphertext, err := eciesgo.Encrypt(priv.PublicKey, []byte("helloworld"))
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, err = eciesgo.DecryptWithConf(NewDecapsulator(d.Client, "alias/unknown"), ciphertext, config)
+		assert.Error(t, err)
+	}
+
+	// KMS cannot agree keys on secp256k1, which is not approved in FIPS mode anyway
+	if eciesgo.FIPSMode() {
+		return
+	}
+	priv, err := eciesgo.GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	ciphertext, err := eciesgo.Encrypt(priv.PublicKey, []byte("helloworld"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	d := NewDecapsulator(&testingKMS{keys: map[string]*eciesgo.PrivateKey{"alias/ecies": priv}}, "alias/ecies")
+	_, err = eciesgo.DecryptWith(d, ciphertext)
+	assert.True(t, errors.Is(err, eciesgo.ErrUnsupportedCurve), err)
+}
@@ -0,0 +1,95 @@
+package eciesgo
+
+import (
+	"crypto/elliptic"
+	"fmt"
+)
+
+// Plaintext limits of AEAD ciphers, Seal panics above them: 2^32-2 blocks of AES-GCM counter
+// and 2^32-1 blocks of ChaCha20 counter (RFC 8439)
+const (
+	maxGCMPlaintext      = (1<<32 - 2) * 16
+	maxChaCha20Plaintext = (1<<32 - 1) * 64
+)
+
+// WithMaxMessageSize returns copy of config limiting message size, e.g. for services decrypting user-supplied blobs:
+// Encrypt rejects plaintexts longer than n bytes and Decrypt rejects ciphertexts longer than CiphertextLength of
+// n bytes before any computation, both with ErrMessageTooLarge; n <= 0 removes the limit.
+// Multi-recipient, hybrid, session, re-encryptable and symmetric encryption check the limit as well,
+// their decryption checks the encrypted payload length before key agreement.
+// Plaintexts above the cipher limit (about 64 GB for AES-GCM) are rejected regardless of it.
+// Streams are chunked, so the limit does not apply to them
+func (c Config) WithMaxMessageSize(n int) Config {
+	if n < 0 {
+		n = 0
+	}
+
+	c.maxMessageSize = n
+	return c
+}
+
+// checkPlaintextSize checks plaintext length against the config and cipher limits
+func checkPlaintextSize(config Config, n int) error {
+	if config.maxMessageSize > 0 && n > config.maxMessageSize {
+		return fmt.Errorf("%w: %d bytes, limit is %d", ErrMessageTooLarge, n, config.maxMessageSize)
+	}
+
+	var limit uint64
+	switch {
+	case config.hpke != nil:
+		return nil
+	case config.symmetricAlgorithm == "aes-128-gcm", config.symmetricAlgorithm == "aes-192-gcm",
+		config.symmetricAlgorithm == "aes-256-gcm":
+		limit = maxGCMPlaintext
+	case config.symmetricAlgorithm == "chacha20poly1305", config.symmetricAlgorithm == "xchacha20":
+		limit = maxChaCha20Plaintext
+	default:
+		return nil
+	}
+	if uint64(n) > limit {
+		return fmt.Errorf("%w: %d bytes, %s limit is %d", ErrMessageTooLarge, n, config.symmetricAlgorithm, limit)
+	}
+
+	return nil
+}
+
+// checkSymmCiphertextSize checks length of symmetric ciphertext (nonce, tag and encrypted message)
+// against the config limit
+func checkSymmCiphertextSize(config Config, n int) error {
+	if config.maxMessageSize <= 0 {
+		return nil
+	}
+
+	limit, err := symmCiphertextLength(config, config.maxMessageSize)
+	if err != nil {
+		return err
+	}
+	if n > limit {
+		return fmt.Errorf("%w: ciphertext of %d bytes, limit is %d", ErrMessageTooLarge, n, limit)
+	}
+
+	return nil
+}
+
+// checkCiphertextSize checks ciphertext length against the config limit; ephemeral key size is of the receiver
+// curve unless config curve is set
+func checkCiphertextSize(config Config, curve elliptic.Curve, n int) error {
+	if config.maxMessageSize <= 0 {
+		return nil
+	}
+
+	if config.curve == "" && curve != nil {
+		if name, err := CurveName(curve); err == nil {
+			config.curve = name
+		}
+	}
+	limit, err := CiphertextLength(config, config.maxMessageSize)
+	if err != nil {
+		return err
+	}
+	if n > limit {
+		return fmt.Errorf("%w: ciphertext of %d bytes, limit is %d", ErrMessageTooLarge, n, limit)
+	}
+
+	return nil
+}
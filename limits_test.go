@@ -0,0 +1,153 @@
+package eciesgo
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_WithMaxMessageSize(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	config := DEFAULT_CONFIG.WithMaxMessageSize(16)
+
+	ct, err := EncryptConf(privkey.PublicKey, make([]byte, 16), config)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = EncryptConf(privkey.PublicKey, make([]byte, 17), config)
+	assert.True(t, errors.Is(err, ErrMessageTooLarge), err)
+	_, err = EncryptWithEphemeralConf(privkey, privkey.PublicKey, make([]byte, 17), config)
+	assert.True(t, errors.Is(err, ErrMessageTooLarge), err)
+
+	pt, err := DecryptConf(privkey, ct, config)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, pt, 16)
+
+	large, err := EncryptConf(privkey.PublicKey, make([]byte, 17), DEFAULT_CONFIG)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = DecryptConf(privkey, large, config)
+	assert.True(t, errors.Is(err, ErrMessageTooLarge), err)
+	_, err = DecryptInPlaceConf(privkey, large, config)
+	assert.True(t, errors.Is(err, ErrMessageTooLarge), err)
+	_, err = DecryptWithConf(DecapsulatorFunc(privkey.SharedPoint), large, config)
+	assert.True(t, errors.Is(err, ErrMessageTooLarge), err)
+
+	// Limit of ciphertext accounts for the receiver curve point size
+	p384, err := GenerateKeyCurve(elliptic.P384())
+	if !assert.NoError(t, err) {
+		return
+	}
+	ct, err = EncryptConf(p384.PublicKey, make([]byte, 16), config)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = DecryptConf(p384, ct, config)
+	assert.NoError(t, err)
+
+	// Limit is removed
+	_, err = EncryptConf(privkey.PublicKey, make([]byte, 17), config.WithMaxMessageSize(0))
+	assert.NoError(t, err)
+}
+
+func TestConfig_WithMaxMessageSizeEntryPoints(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	hybrid, err := GenerateHybridKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	key := make([]byte, 32)
+	config := DEFAULT_CONFIG.WithMaxMessageSize(16)
+
+	for name, c := range map[string]struct {
+		encrypt func(msg []byte, config Config) ([]byte, error)
+		decrypt func(ct []byte, config Config) ([]byte, error)
+	}{
+		"multi": {
+			func(msg []byte, config Config) ([]byte, error) {
+				return EncryptMultiConf([]*PublicKey{privkey.PublicKey}, msg, config)
+			},
+			func(ct []byte, config Config) ([]byte, error) { return DecryptMultiConf(privkey, ct, config) },
+		},
+		"hybrid": {
+			func(msg []byte, config Config) ([]byte, error) {
+				return EncryptHybridConf(hybrid.Public(), msg, config)
+			},
+			func(ct []byte, config Config) ([]byte, error) { return DecryptHybridConf(hybrid, ct, config) },
+		},
+		"hybrid envelope": {
+			func(msg []byte, config Config) ([]byte, error) {
+				return EncryptHybridConf(hybrid.Public(), msg, config.WithEnvelope())
+			},
+			func(ct []byte, config Config) ([]byte, error) {
+				return DecryptHybridConf(hybrid, ct, config.WithEnvelope())
+			},
+		},
+		"session": {
+			func(msg []byte, config Config) ([]byte, error) {
+				s, err := NewSessionConf(privkey.PublicKey, config)
+				if err != nil {
+					return nil, err
+				}
+				return s.Encrypt(msg)
+			},
+			func(ct []byte, config Config) ([]byte, error) { return DecryptConf(privkey, ct, config) },
+		},
+		"reencryptable": {
+			func(msg []byte, config Config) ([]byte, error) {
+				return EncryptReencryptableConf(privkey.PublicKey, msg, config)
+			},
+			func(ct []byte, config Config) ([]byte, error) { return DecryptReencryptableConf(privkey, ct, config) },
+		},
+		"symmetric": {
+			func(msg []byte, config Config) ([]byte, error) { return EncryptSymm(key, msg, config) },
+			func(ct []byte, config Config) ([]byte, error) { return DecryptSymm(key, ct, config) },
+		},
+	} {
+		ct, err := c.encrypt(make([]byte, 16), config)
+		if !assert.NoError(t, err, name) {
+			return
+		}
+		pt, err := c.decrypt(ct, config)
+		if !assert.NoError(t, err, name) {
+			return
+		}
+		assert.Len(t, pt, 16, name)
+
+		_, err = c.encrypt(make([]byte, 17), config)
+		assert.True(t, errors.Is(err, ErrMessageTooLarge), "%s: %v", name, err)
+
+		large, err := c.encrypt(make([]byte, 17), DEFAULT_CONFIG)
+		if !assert.NoError(t, err, name) {
+			return
+		}
+		_, err = c.decrypt(large, config)
+		assert.True(t, errors.Is(err, ErrMessageTooLarge), "%s: %v", name, err)
+	}
+}
+
+func TestCheckPlaintextSize(t *testing.T) {
+	assert.NoError(t, checkPlaintextSize(DEFAULT_CONFIG, maxGCMPlaintext))
+	err := checkPlaintextSize(DEFAULT_CONFIG, maxGCMPlaintext+1)
+	assert.True(t, errors.Is(err, ErrMessageTooLarge), err)
+
+	config := NewConfig("xchacha20", 0)
+	assert.NoError(t, checkPlaintextSize(config, maxGCMPlaintext+1))
+	err = checkPlaintextSize(config, maxChaCha20Plaintext+1)
+	assert.True(t, errors.Is(err, ErrMessageTooLarge), err)
+}
@@ -0,0 +1,282 @@
+package eciesgo
+
+import (
+	"crypto/elliptic"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// MarshalText encodes public key as hex of uncompressed raw bytes, implements encoding.TextMarshaler;
+// keys of other curves than secp256k1 are prefixed with registered curve name and colon, e.g. "P-256:04..."
+func (k *PublicKey) MarshalText() ([]byte, error) {
+	return marshalPublicKeyText(k, false)
+}
+
+// UnmarshalText decodes public key from hex of compressed or uncompressed raw bytes,
+// optionally prefixed with curve name, implements encoding.TextUnmarshaler
+func (k *PublicKey) UnmarshalText(text []byte) error {
+	pub, err := unmarshalPublicKeyText(text)
+	if err != nil {
+		return err
+	}
+
+	*k = *pub
+	return nil
+}
+
+// MarshalJSON encodes public key as JSON string with hex of uncompressed raw bytes
+func (k *PublicKey) MarshalJSON() ([]byte, error) {
+	return marshalJSONText(k)
+}
+
+// UnmarshalJSON decodes public key from JSON string with hex of compressed or uncompressed raw bytes
+func (k *PublicKey) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONText(b, k.UnmarshalText)
+}
+
+// CompressedPublicKey wraps public key to marshal it in compressed form;
+// unmarshaling accepts both compressed and uncompressed forms
+type CompressedPublicKey struct {
+	*PublicKey
+}
+
+// MarshalText encodes public key as hex of compressed raw bytes
+func (k CompressedPublicKey) MarshalText() ([]byte, error) {
+	if k.PublicKey == nil {
+		return nil, fmt.Errorf("public key is empty")
+	}
+
+	return marshalPublicKeyText(k.PublicKey, true)
+}
+
+// UnmarshalText decodes public key from hex of compressed or uncompressed raw bytes
+func (k *CompressedPublicKey) UnmarshalText(text []byte) error {
+	pub, err := unmarshalPublicKeyText(text)
+	if err != nil {
+		return err
+	}
+
+	k.PublicKey = pub
+	return nil
+}
+
+// MarshalJSON encodes public key as JSON string with hex of compressed raw bytes
+func (k CompressedPublicKey) MarshalJSON() ([]byte, error) {
+	return marshalJSONText(k)
+}
+
+// UnmarshalJSON decodes public key from JSON string with hex of compressed or uncompressed raw bytes
+func (k *CompressedPublicKey) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONText(b, k.UnmarshalText)
+}
+
+// MarshalText encodes private key as hex of raw bytes, prefixed with curve name as public key text,
+// implements encoding.TextMarshaler;
+// PrivateKey defines its own marshaling methods, so the nested public key ones are not promoted
+func (k *PrivateKey) MarshalText() ([]byte, error) {
+	if k.IsZero() || k.PublicKey == nil {
+		return nil, fmt.Errorf("%w: private key is empty", ErrInvalidPrivateKey)
+	}
+
+	prefix, err := keyTextPrefix(k.Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(prefix + k.Hex()), nil
+}
+
+// UnmarshalText decodes private key from hex of raw bytes, optionally prefixed with curve name,
+// implements encoding.TextUnmarshaler
+func (k *PrivateKey) UnmarshalText(text []byte) error {
+	curve, b, err := parseKeyText(text)
+	if err != nil {
+		return err
+	}
+
+	priv, err := NewPrivateKeyFromBytesCurve(curve, b)
+	if err != nil {
+		return err
+	}
+
+	*k = *priv
+	return nil
+}
+
+// MarshalJSON encodes private key as JSON string with hex of raw bytes
+func (k *PrivateKey) MarshalJSON() ([]byte, error) {
+	return marshalJSONText(k)
+}
+
+// UnmarshalJSON decodes private key from JSON string with hex of raw bytes
+func (k *PrivateKey) UnmarshalJSON(b []byte) error {
+	return unmarshalJSONText(b, k.UnmarshalText)
+}
+
+// keyTextPrefix returns text key prefix of the curve, which is empty for secp256k1
+func keyTextPrefix(curve elliptic.Curve) (string, error) {
+	if curve == nil || sameCurve(curve, getCurve()) {
+		return "", nil
+	}
+
+	name, err := CurveName(curve)
+	if err != nil {
+		return "", err
+	}
+
+	return name + ":", nil
+}
+
+// parseKeyText returns curve of text key (secp256k1 if it is not prefixed) and decoded key bytes
+func parseKeyText(text []byte) (elliptic.Curve, []byte, error) {
+	curve, s := getCurve(), string(text)
+	if i := strings.IndexByte(s, ':'); i >= 0 {
+		c, err := CurveByName(s[:i])
+		if err != nil {
+			return nil, nil, err
+		}
+		curve, s = c, s[i+1:]
+	}
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot decode hex string: %w", err)
+	}
+
+	return curve, b, nil
+}
+
+func marshalPublicKeyText(k *PublicKey, compressed bool) ([]byte, error) {
+	if k.IsZero() {
+		return nil, fmt.Errorf("%w: public key is empty", ErrInvalidPublicKey)
+	}
+
+	prefix, err := keyTextPrefix(k.Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(prefix + k.Hex(compressed)), nil
+}
+
+func unmarshalPublicKeyText(text []byte) (*PublicKey, error) {
+	curve, b, err := parseKeyText(text)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPublicKeyFromBytesCurve(curve, b)
+}
+
+type textMarshaler interface {
+	MarshalText() ([]byte, error)
+}
+
+func marshalJSONText(m textMarshaler) ([]byte, error) {
+	text, err := m.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(string(text))
+}
+
+func unmarshalJSONText(b []byte, unmarshalText func([]byte) error) error {
+	// null is no-op by convention
+	if string(b) == "null" {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("cannot decode JSON string: %w", err)
+	}
+
+	return unmarshalText([]byte(s))
+}
+
+// Binary layout version; layout is version byte, curve ID (see RegisterCurveID) and compressed point
+// or fixed width scalar
+const keyBinaryVersion = 1
+
+// MarshalBinary encodes public key in stable versioned binary layout, implements encoding.BinaryMarshaler;
+// keys of curves without ID are not supported
+func (k *PublicKey) MarshalBinary() ([]byte, error) {
+	if k.IsZero() {
+		return nil, fmt.Errorf("%w: public key is empty", ErrInvalidPublicKey)
+	}
+
+	id, err := curveID(k.Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{keyBinaryVersion, id}, k.Bytes(true)...), nil
+}
+
+// UnmarshalBinary decodes public key encoded with MarshalBinary, implements encoding.BinaryUnmarshaler
+func (k *PublicKey) UnmarshalBinary(data []byte) error {
+	curve, b, err := parseKeyBinary(data)
+	if err != nil {
+		return err
+	}
+
+	pub, err := NewPublicKeyFromBytesCurve(curve, b)
+	if err != nil {
+		return err
+	}
+
+	*k = *pub
+	return nil
+}
+
+// MarshalBinary encodes private key in stable versioned binary layout, implements encoding.BinaryMarshaler;
+// keys of curves without ID are not supported
+func (k *PrivateKey) MarshalBinary() ([]byte, error) {
+	if k.IsZero() || k.PublicKey == nil {
+		return nil, fmt.Errorf("%w: private key is empty", ErrInvalidPrivateKey)
+	}
+
+	id, err := curveID(k.Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{keyBinaryVersion, id}, k.Bytes()...), nil
+}
+
+// UnmarshalBinary decodes private key encoded with MarshalBinary, implements encoding.BinaryUnmarshaler
+func (k *PrivateKey) UnmarshalBinary(data []byte) error {
+	curve, b, err := parseKeyBinary(data)
+	if err != nil {
+		return err
+	}
+
+	priv, err := NewPrivateKeyFromBytesCurve(curve, b)
+	if err != nil {
+		return err
+	}
+
+	*k = *priv
+	return nil
+}
+
+// parseKeyBinary checks version of binary encoded key and returns its curve and raw key bytes
+func parseKeyBinary(data []byte) (elliptic.Curve, []byte, error) {
+	if len(data) < 2 {
+		return nil, nil, fmt.Errorf("invalid length of binary key")
+	}
+
+	if data[0] != keyBinaryVersion {
+		return nil, nil, fmt.Errorf("unsupported binary key version: %d", data[0])
+	}
+
+	_, curve, err := curveByID(data[1])
+	if err != nil {
+		return nil, nil, fmt.Errorf("unsupported binary key curve: %w", err)
+	}
+
+	return curve, data[2:], nil
+}
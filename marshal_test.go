@@ -0,0 +1,223 @@
+package eciesgo
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	privkey, err := NewPrivateKeyFromHex(testingReceiverPrivkeyHex)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	type keys struct {
+		Private    *PrivateKey         `json:"private"`
+		Public     *PublicKey          `json:"public"`
+		Compressed CompressedPublicKey `json:"compressed"`
+	}
+
+	b, err := json.Marshal(keys{
+		Private:    privkey,
+		Public:     privkey.PublicKey,
+		Compressed: CompressedPublicKey{privkey.PublicKey},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.JSONEq(t, `{
+		"private": "`+testingReceiverPrivkeyHex+`",
+		"public": "`+privkey.PublicKey.Hex(false)+`",
+		"compressed": "`+privkey.PublicKey.Hex(true)+`"
+	}`, string(b))
+
+	var decoded keys
+	if !assert.NoError(t, json.Unmarshal(b, &decoded)) {
+		return
+	}
+	assert.True(t, privkey.Equals(decoded.Private))
+	assert.True(t, privkey.PublicKey.Equals(decoded.Private.PublicKey))
+	assert.True(t, privkey.PublicKey.Equals(decoded.Public))
+	assert.True(t, privkey.PublicKey.Equals(decoded.Compressed.PublicKey))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"public": "04ff"}`), &decoded))
+	assert.Error(t, json.Unmarshal([]byte(`{"public": ""}`), &decoded))
+	assert.Error(t, json.Unmarshal([]byte(`{"private": 1}`), &decoded))
+}
+
+func TestMarshalText(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	text, err := privkey.PublicKey.MarshalText()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var pub PublicKey
+	if !assert.NoError(t, pub.UnmarshalText(text)) {
+		return
+	}
+	assert.True(t, privkey.PublicKey.Equals(&pub))
+
+	text, err = privkey.MarshalText()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, privkey.Hex(), string(text))
+}
+
+func TestMarshalBinary(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	b, err := privkey.MarshalBinary()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 34, len(b))
+
+	var priv PrivateKey
+	if !assert.NoError(t, priv.UnmarshalBinary(b)) {
+		return
+	}
+	assert.True(t, privkey.Equals(&priv))
+
+	b, err = privkey.PublicKey.MarshalBinary()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 35, len(b))
+
+	var pub PublicKey
+	if !assert.NoError(t, pub.UnmarshalBinary(b)) {
+		return
+	}
+	assert.True(t, privkey.PublicKey.Equals(&pub))
+
+	// Gob uses BinaryMarshaler
+	var buf bytes.Buffer
+	if !assert.NoError(t, gob.NewEncoder(&buf).Encode(privkey)) {
+		return
+	}
+	var decoded PrivateKey
+	if !assert.NoError(t, gob.NewDecoder(&buf).Decode(&decoded)) {
+		return
+	}
+	assert.True(t, privkey.Equals(&decoded))
+
+	// Private key is not accepted as public key and vice versa
+	assert.Error(t, pub.UnmarshalBinary(append([]byte{1, 1}, privkey.Bytes()...)))
+	assert.Error(t, priv.UnmarshalBinary(append([]byte{1, 1}, privkey.PublicKey.Bytes(true)...)))
+	assert.Error(t, pub.UnmarshalBinary(append([]byte{2, 1}, privkey.PublicKey.Bytes(true)...)))
+	assert.Error(t, pub.UnmarshalBinary(append([]byte{1, 9}, privkey.PublicKey.Bytes(true)...)))
+}
+
+func TestMarshalCurves(t *testing.T) {
+	skipUnapproved(t)
+
+	for _, name := range []string{"secp256k1", "P-256", "P-384", "P-521", "brainpoolP256r1", "brainpoolP384r1"} {
+		curve, err := CurveByName(name)
+		if !assert.NoError(t, err) {
+			return
+		}
+		privkey, err := GenerateKeyCurve(curve)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		b, err := privkey.MarshalBinary()
+		if !assert.NoError(t, err) {
+			return
+		}
+		var priv PrivateKey
+		if !assert.NoError(t, priv.UnmarshalBinary(b)) {
+			return
+		}
+		assert.True(t, privkey.Equals(&priv), name)
+		assert.True(t, privkey.PublicKey.Equals(priv.PublicKey), name)
+
+		b, err = privkey.PublicKey.MarshalBinary()
+		if !assert.NoError(t, err) {
+			return
+		}
+		var pub PublicKey
+		if !assert.NoError(t, pub.UnmarshalBinary(b)) {
+			return
+		}
+		assert.True(t, privkey.PublicKey.Equals(&pub), name)
+
+		text, err := privkey.MarshalText()
+		if !assert.NoError(t, err) {
+			return
+		}
+		var privText PrivateKey
+		if !assert.NoError(t, privText.UnmarshalText(text)) {
+			return
+		}
+		assert.True(t, privkey.PublicKey.Equals(privText.PublicKey), name)
+
+		b, err = json.Marshal(CompressedPublicKey{privkey.PublicKey})
+		if !assert.NoError(t, err) {
+			return
+		}
+		var compressed CompressedPublicKey
+		if !assert.NoError(t, json.Unmarshal(b, &compressed)) {
+			return
+		}
+		assert.True(t, privkey.PublicKey.Equals(compressed.PublicKey), name)
+	}
+
+	// secp256k1 text has no prefix, so it stays compatible
+	privkey, err := NewPrivateKeyFromHex(testingReceiverPrivkeyHex)
+	if !assert.NoError(t, err) {
+		return
+	}
+	text, err := privkey.MarshalText()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingReceiverPrivkeyHex, string(text))
+
+	p256, err := GenerateKeyCurve(elliptic.P256())
+	if !assert.NoError(t, err) {
+		return
+	}
+	text, err = p256.PublicKey.MarshalText()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "P-256:"+p256.PublicKey.Hex(false), string(text))
+
+	var pub PublicKey
+	assert.Error(t, pub.UnmarshalText([]byte("P-999:"+p256.PublicKey.Hex(false))))
+
+	// Keys of curves without ID have no binary form
+	twisted := BrainpoolP256r1().(*rcurve).twisted
+	if _, err := CurveByName("brainpoolP256t1"); err != nil {
+		if !assert.NoError(t, RegisterCurve("brainpoolP256t1", twisted)) {
+			return
+		}
+	}
+	privkey, err = GenerateKeyCurve(twisted)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = privkey.MarshalBinary()
+	assert.Error(t, err)
+	_, err = privkey.PublicKey.MarshalBinary()
+	assert.Error(t, err)
+}
@@ -0,0 +1,266 @@
+// Package eciesprom collects Prometheus metrics of ECIES operations: it implements eciesgo.Observer
+// and serves collected counters and histograms in Prometheus text exposition format.
+//
+//	m := eciesprom.New()
+//	eciesgo.SetDefaultConfig(eciesgo.DefaultConfig().WithObserver(m))
+//	http.Handle("/metrics/ecies", m)
+//
+// The package does not depend on the Prometheus client library, so the handler is scraped as a separate target
+// (or path); metric names are prefixed with "ecies_":
+//
+//	ecies_operations_total{operation,curve,cipher}                 counter
+//	ecies_operation_failures_total{operation,curve,cipher,error}   counter, error is ErrorClass of the failure
+//	ecies_operation_duration_seconds{operation}                    histogram
+//	ecies_payload_bytes{operation}                                 histogram of input size
+package eciesprom
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	eciesgo "github.com/ecies/go/v2"
+)
+
+// DurationBuckets are upper bounds of duration histogram buckets in seconds
+var DurationBuckets = []float64{.00001, .000025, .00005, .0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1}
+
+// SizeBuckets are upper bounds of payload size histogram buckets in bytes
+var SizeBuckets = []float64{64, 256, 1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20, 4 << 20, 16 << 20, 64 << 20}
+
+// errorClasses maps sentinel errors to values of "error" label, the first matching one is used
+var errorClasses = []struct {
+	err   error
+	class string
+}{
+	{eciesgo.ErrAuthenticationFailed, "authentication"},
+	{eciesgo.ErrCiphertextTooShort, "malformed_ciphertext"},
+	{eciesgo.ErrInvalidEnvelope, "invalid_envelope"},
+	{eciesgo.ErrInvalidPublicKey, "invalid_public_key"},
+	{eciesgo.ErrInvalidPrivateKey, "invalid_private_key"},
+	{eciesgo.ErrUnsupportedCipher, "unsupported_cipher"},
+	{eciesgo.ErrUnsupportedKDF, "unsupported_kdf"},
+	{eciesgo.ErrUnsupportedCurve, "unsupported_curve"},
+	{eciesgo.ErrNotApproved, "not_approved"},
+	{eciesgo.ErrMessageTooLarge, "message_too_large"},
+	{eciesgo.ErrUntrustedKey, "untrusted_key"},
+	{eciesgo.ErrKeyExpired, "key_expired"},
+}
+
+// ErrorClass returns "error" label value of the failure: name of the eciesgo sentinel error it wraps,
+// or "other"; error messages are not used as labels to keep cardinality bounded
+func ErrorClass(err error) string {
+	for _, c := range errorClasses {
+		if errors.Is(err, c.err) {
+			return c.class
+		}
+	}
+
+	return "other"
+}
+
+// Metrics collects metrics of observed operations, it is safe for concurrent use
+type Metrics struct {
+	mu       sync.Mutex
+	total    map[labels]uint64
+	failures map[labels]uint64
+	duration map[string]*histogram
+	size     map[string]*histogram
+}
+
+// labels is a set of metric label values; unused labels are empty
+type labels struct {
+	operation, curve, cipher, error string
+}
+
+// New creates empty metrics
+func New() *Metrics {
+	return &Metrics{
+		total:    make(map[labels]uint64),
+		failures: make(map[labels]uint64),
+		duration: make(map[string]*histogram),
+		size:     make(map[string]*histogram),
+	}
+}
+
+// Observe records the operation, it implements eciesgo.Observer
+func (m *Metrics) Observe(op eciesgo.Operation) {
+	l := labels{operation: op.Type, curve: op.Curve, cipher: op.Cipher}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.total[l]++
+	if op.Err != nil {
+		l.error = ErrorClass(op.Err)
+		m.failures[l]++
+	}
+
+	observe(m.duration, op.Type, DurationBuckets, op.Duration.Seconds())
+	observe(m.size, op.Type, SizeBuckets, float64(op.Size))
+}
+
+// ServeHTTP writes metrics in Prometheus text exposition format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// WriteTo writes metrics in Prometheus text exposition format, series are sorted by labels;
+// metrics are copied under the lock and written after it is released, so slow writers do not block Observe
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	s := m.snapshot()
+
+	cw := &countingWriter{w: w}
+	writeCounter(cw, "ecies_operations_total", "Encryption and decryption operations.", s.total)
+	writeCounter(cw, "ecies_operation_failures_total", "Failed operations by error class.", s.failures)
+	writeHistogram(cw, "ecies_operation_duration_seconds", "Operation duration in seconds.", s.duration)
+	writeHistogram(cw, "ecies_payload_bytes", "Operation input size in bytes.", s.size)
+
+	return cw.n, cw.err
+}
+
+// snapshot returns a copy of metrics which is not modified by following observations
+func (m *Metrics) snapshot() *Metrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := &Metrics{
+		total:    make(map[labels]uint64, len(m.total)),
+		failures: make(map[labels]uint64, len(m.failures)),
+		duration: make(map[string]*histogram, len(m.duration)),
+		size:     make(map[string]*histogram, len(m.size)),
+	}
+	for l, v := range m.total {
+		s.total[l] = v
+	}
+	for l, v := range m.failures {
+		s.failures[l] = v
+	}
+	for op, h := range m.duration {
+		s.duration[op] = h.copy()
+	}
+	for op, h := range m.size {
+		s.size[op] = h.copy()
+	}
+
+	return s
+}
+
+// histogram holds counts of values in each bucket, they are accumulated when written
+type histogram struct {
+	bounds []float64
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func (h *histogram) copy() *histogram {
+	c := *h
+	c.counts = append([]uint64(nil), h.counts...)
+	return &c
+}
+
+func observe(hs map[string]*histogram, operation string, bounds []float64, v float64) {
+	h, ok := hs[operation]
+	if !ok {
+		h = &histogram{bounds: bounds, counts: make([]uint64, len(bounds))}
+		hs[operation] = h
+	}
+
+	if i := sort.SearchFloat64s(h.bounds, v); i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.sum += v
+	h.count++
+}
+
+func writeCounter(w io.Writer, name, help string, series map[labels]uint64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+
+	keys := make([]labels, 0, len(series))
+	for l := range series {
+		keys = append(keys, l)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	for _, l := range keys {
+		fmt.Fprintf(w, "%s{%s} %d\n", name, l, series[l])
+	}
+}
+
+func writeHistogram(w io.Writer, name, help string, series map[string]*histogram) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+
+	operations := make([]string, 0, len(series))
+	for op := range series {
+		operations = append(operations, op)
+	}
+	sort.Strings(operations)
+
+	for _, op := range operations {
+		h := series[op]
+		l := labels{operation: op}.String()
+
+		var cumulative uint64
+		for i, bound := range h.bounds {
+			cumulative += h.counts[i]
+			fmt.Fprintf(w, "%s_bucket{%s,le=\"%s\"} %d\n", name, l, formatFloat(bound), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, l, h.count)
+		fmt.Fprintf(w, "%s_sum{%s} %s\n", name, l, formatFloat(h.sum))
+		fmt.Fprintf(w, "%s_count{%s} %d\n", name, l, h.count)
+	}
+}
+
+// String formats non-empty labels in alphabetical order
+func (l labels) String() string {
+	var parts []string
+	for _, p := range []struct{ name, value string }{
+		{"cipher", l.cipher},
+		{"curve", l.curve},
+		{"error", l.error},
+		{"operation", l.operation},
+	} {
+		if p.name == "operation" || p.value != "" {
+			parts = append(parts, p.name+"=\""+escapeLabel(p.value)+"\"")
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// countingWriter counts written bytes and keeps the first error
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	w.err = err
+	return n, err
+}
@@ -0,0 +1,125 @@
+package eciesprom
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	eciesgo "github.com/ecies/go/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	if eciesgo.FIPSMode() {
+		t.Skip("secp256k1 is not approved in FIPS mode")
+	}
+
+	m := New()
+	config := eciesgo.DEFAULT_CONFIG.WithObserver(m)
+
+	privkey, err := eciesgo.GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	ct, err := eciesgo.EncryptConf(privkey.PublicKey, []byte("helloworld"), config)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = eciesgo.DecryptConf(privkey, ct, config)
+	assert.NoError(t, err)
+	ct[len(ct)-1] ^= 1
+	_, err = eciesgo.DecryptConf(privkey, ct, config)
+	assert.Error(t, err)
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	out := rec.Body.String()
+
+	for _, line := range []string{
+		"# TYPE ecies_operations_total counter",
+		`ecies_operations_total{cipher="aes-256-gcm",curve="secp256k1",operation="decrypt"} 2`,
+		`ecies_operations_total{cipher="aes-256-gcm",curve="secp256k1",operation="encrypt"} 1`,
+		`ecies_operation_failures_total{cipher="aes-256-gcm",curve="secp256k1",error="authentication",operation="decrypt"} 1`,
+		"# TYPE ecies_operation_duration_seconds histogram",
+		`ecies_operation_duration_seconds_count{operation="decrypt"} 2`,
+		`ecies_payload_bytes_bucket{operation="encrypt",le="64"} 1`,
+		`ecies_payload_bytes_bucket{operation="decrypt",le="+Inf"} 2`,
+		`ecies_payload_bytes_sum{operation="encrypt"} 10`,
+		fmt.Sprintf(`ecies_payload_bytes_sum{operation="decrypt"} %d`, 2*len(ct)),
+	} {
+		assert.Contains(t, out, line+"\n")
+	}
+}
+
+func TestMetricsHistogram(t *testing.T) {
+	m := New()
+	for _, d := range []time.Duration{time.Microsecond, 3 * time.Millisecond, 2 * time.Second} {
+		m.Observe(eciesgo.Operation{Type: eciesgo.OperationEncrypt, Duration: d, Size: 100})
+	}
+
+	var b strings.Builder
+	n, err := m.WriteTo(&b)
+	if !assert.NoError(t, err) {
+		return
+	}
+	out := b.String()
+	assert.Equal(t, int64(len(out)), n)
+
+	// Buckets are cumulative, values above the largest bound are counted in +Inf only
+	assert.Contains(t, out, `ecies_operation_duration_seconds_bucket{operation="encrypt",le="1e-05"} 1`+"\n")
+	assert.Contains(t, out, `ecies_operation_duration_seconds_bucket{operation="encrypt",le="0.0025"} 1`+"\n")
+	assert.Contains(t, out, `ecies_operation_duration_seconds_bucket{operation="encrypt",le="0.005"} 2`+"\n")
+	assert.Contains(t, out, `ecies_operation_duration_seconds_bucket{operation="encrypt",le="1"} 2`+"\n")
+	assert.Contains(t, out, `ecies_operation_duration_seconds_bucket{operation="encrypt",le="+Inf"} 3`+"\n")
+	assert.Contains(t, out, `ecies_payload_bytes_bucket{operation="encrypt",le="256"} 3`+"\n")
+
+	// Operations without curve and cipher have no such labels
+	assert.Contains(t, out, `ecies_operations_total{operation="encrypt"} 3`+"\n")
+}
+
+func TestErrorClass(t *testing.T) {
+	assert.Equal(t, "authentication", ErrorClass(fmt.Errorf("%w: tag mismatch", eciesgo.ErrAuthenticationFailed)))
+	assert.Equal(t, "invalid_public_key", ErrorClass(eciesgo.ErrInvalidPublicKey))
+	assert.Equal(t, "other", ErrorClass(fmt.Errorf("unexpected")))
+}
+
+// testingObservingWriter observes an operation on every write, as operations running during a slow scrape do
+type testingObservingWriter struct {
+	strings.Builder
+	m *Metrics
+}
+
+func (w *testingObservingWriter) Write(p []byte) (int, error) {
+	w.m.Observe(eciesgo.Operation{Type: eciesgo.OperationDecrypt, Size: 1})
+	return w.Builder.Write(p)
+}
+
+func TestMetricsWriteToSnapshot(t *testing.T) {
+	m := New()
+	m.Observe(eciesgo.Operation{Type: eciesgo.OperationDecrypt, Size: 1})
+
+	// Lock is not held while writing, so observations do not wait for the writer
+	w := &testingObservingWriter{m: m}
+	done := make(chan struct{})
+	go func() {
+		m.WriteTo(w)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("WriteTo blocks Observe")
+	}
+
+	// Written metrics are the ones observed before WriteTo was called
+	out := w.String()
+	assert.Contains(t, out, `ecies_operations_total{operation="decrypt"} 1`+"\n")
+	assert.Contains(t, out, `ecies_payload_bytes_count{operation="decrypt"} 1`+"\n")
+
+	var b strings.Builder
+	m.WriteTo(&b)
+	assert.NotContains(t, b.String(), `ecies_operations_total{operation="decrypt"} 1`+"\n")
+}
@@ -0,0 +1,206 @@
+// Package eciesgrpc provides gRPC codec and interceptors which encrypt messages with ECIES.
+//
+// The package does not depend on gRPC: Codec implements google.golang.org/grpc/encoding.Codec interface
+// structurally, and interceptors take gRPC invokers, handlers and metadata functions with the same signatures,
+// so they are adapted to grpc.UnaryClientInterceptor, grpc.UnaryServerInterceptor and stream interceptors
+// in a few lines (see README).
+//
+// Codec decrypts incoming messages with its own private key and encrypts outgoing messages to the key
+// they are sealed to by the interceptors, so a single server codec serves any number of clients.
+// Client interceptor seals requests to the server key returned by its resolver and sends the client
+// public key in PublicKeyHeader metadata; server interceptor resolves the caller key, e.g. from that metadata
+// with MetadataKey, and seals responses to it. The interceptors must be the innermost ones, as other
+// interceptors would see sealed messages.
+package eciesgrpc
+
+import (
+	"context"
+	"fmt"
+
+	eciesgo "github.com/ecies/go/v2"
+)
+
+// PublicKeyHeader is binary metadata key carrying compressed client public key which responses are encrypted to
+const PublicKeyHeader = "ecies-public-key-bin"
+
+// InnerCodec is the codec messages are marshaled with before encryption, e.g. gRPC proto codec;
+// it has the same methods as google.golang.org/grpc/encoding.Codec
+type InnerCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+	Name() string
+}
+
+// Sealed is an outgoing message with the peer public key it is encrypted to by Codec
+type Sealed struct {
+	Message   interface{}
+	PublicKey *eciesgo.PublicKey
+}
+
+// Codec encrypts messages marshaled by the inner codec
+type Codec struct {
+	Inner InnerCodec
+	// PublicKey is the peer public key which outgoing messages not wrapped in Sealed are encrypted to;
+	// such messages are rejected if nil
+	PublicKey *eciesgo.PublicKey
+	// PrivateKey decrypts incoming messages
+	PrivateKey *eciesgo.PrivateKey
+	Config     eciesgo.Config
+}
+
+// NewCodec returns Codec with the default config, which encrypts only messages sealed by interceptors
+func NewCodec(inner InnerCodec, privkey *eciesgo.PrivateKey) *Codec {
+	return &Codec{Inner: inner, PrivateKey: privkey, Config: eciesgo.DefaultConfig()}
+}
+
+// Marshal marshals v with the inner codec and encrypts it to the key v is sealed to,
+// or to the codec public key if v is not Sealed
+func (c *Codec) Marshal(v interface{}) ([]byte, error) {
+	pub := c.PublicKey
+	if s, ok := v.(*Sealed); ok {
+		v, pub = s.Message, s.PublicKey
+	}
+	if pub == nil {
+		return nil, fmt.Errorf("cannot encrypt message: %w: peer public key is unknown", eciesgo.ErrInvalidPublicKey)
+	}
+
+	data, err := c.Inner.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	ct, err := eciesgo.EncryptConf(pub, data, c.Config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encrypt message: %w", err)
+	}
+
+	return ct, nil
+}
+
+// Unmarshal decrypts data with the private key and unmarshals it with the inner codec
+func (c *Codec) Unmarshal(data []byte, v interface{}) error {
+	pt, err := eciesgo.DecryptConf(c.PrivateKey, data, c.Config)
+	if err != nil {
+		return fmt.Errorf("cannot decrypt message: %w", err)
+	}
+
+	return c.Inner.Unmarshal(pt, v)
+}
+
+// Name returns content subtype of the codec, inner codec name with "-ecies" suffix
+func (c *Codec) Name() string {
+	return c.Inner.Name() + "-ecies"
+}
+
+// KeyFunc resolves public key of the peer of a call to the full method name
+type KeyFunc func(ctx context.Context, method string) (*eciesgo.PublicKey, error)
+
+// StaticKey returns KeyFunc resolving every peer to the same key
+func StaticKey(pub *eciesgo.PublicKey) KeyFunc {
+	return func(context.Context, string) (*eciesgo.PublicKey, error) {
+		return pub, nil
+	}
+}
+
+// ResolverKey returns KeyFunc resolving peer key by id with the resolver, e.g. fingerprint with TrustStore
+func ResolverKey(r eciesgo.KeyResolver, id string) KeyFunc {
+	return func(ctx context.Context, _ string) (*eciesgo.PublicKey, error) {
+		return r.ResolveKey(ctx, id)
+	}
+}
+
+// MetadataKey returns KeyFunc resolving caller key from PublicKeyHeader incoming metadata read with
+// get, which is metadata.ValueFromIncomingContext; if trust is not nil, the key must be pinned in it
+func MetadataKey(get func(ctx context.Context, key string) []string, trust *eciesgo.TrustStore) KeyFunc {
+	return func(ctx context.Context, _ string) (*eciesgo.PublicKey, error) {
+		values := get(ctx, PublicKeyHeader)
+		if len(values) != 1 {
+			return nil, fmt.Errorf("%w: expected single %s metadata value, got %d",
+				eciesgo.ErrInvalidPublicKey, PublicKeyHeader, len(values))
+		}
+
+		pub, err := eciesgo.NewPublicKeyFromBytes([]byte(values[0]))
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse client public key: %w", err)
+		}
+
+		if trust != nil {
+			if _, err := trust.Verify(pub); err != nil {
+				return nil, err
+			}
+		}
+
+		return pub, nil
+	}
+}
+
+// ClientInterceptor seals requests to the server key and sends the client key which responses are encrypted to
+type ClientInterceptor struct {
+	// PublicKey is sent in PublicKeyHeader metadata, client codec must have the matching private key
+	PublicKey *eciesgo.PublicKey
+	// ServerKey resolves the server key requests are encrypted to
+	ServerKey KeyFunc
+	// AppendToOutgoingContext is metadata.AppendToOutgoingContext
+	AppendToOutgoingContext func(ctx context.Context, kv ...string) context.Context
+}
+
+// Stream resolves the server key and returns context with client key metadata to start the call with
+// and function sealing messages sent on the call
+func (c *ClientInterceptor) Stream(ctx context.Context, method string) (context.Context, func(m interface{}) interface{}, error) {
+	pub, err := c.ServerKey(ctx, method)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot resolve server public key: %w", err)
+	}
+
+	ctx = c.AppendToOutgoingContext(ctx, PublicKeyHeader, string(c.PublicKey.Bytes(true)))
+
+	return ctx, func(m interface{}) interface{} {
+		return &Sealed{Message: m, PublicKey: pub}
+	}, nil
+}
+
+// Unary seals req and calls invoke, which calls gRPC invoker with the other arguments of the interceptor
+func (c *ClientInterceptor) Unary(ctx context.Context, method string, req interface{},
+	invoke func(ctx context.Context, req interface{}) error) error {
+	ctx, seal, err := c.Stream(ctx, method)
+	if err != nil {
+		return err
+	}
+
+	return invoke(ctx, seal(req))
+}
+
+// ServerInterceptor seals responses to the caller key
+type ServerInterceptor struct {
+	// ClientKey resolves the caller key responses are encrypted to, e.g. MetadataKey
+	ClientKey KeyFunc
+}
+
+// Stream resolves the caller key and returns function sealing messages sent on the call
+func (s *ServerInterceptor) Stream(ctx context.Context, method string) (func(m interface{}) interface{}, error) {
+	pub, err := s.ClientKey(ctx, method)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve client public key: %w", err)
+	}
+
+	return func(m interface{}) interface{} {
+		return &Sealed{Message: m, PublicKey: pub}
+	}, nil
+}
+
+// Unary calls gRPC handler and seals its response; the caller key is resolved before the handler is called,
+// so requests of unknown clients are not handled
+func (s *ServerInterceptor) Unary(ctx context.Context, method string, req interface{},
+	handler func(ctx context.Context, req interface{}) (interface{}, error)) (interface{}, error) {
+	seal, err := s.Stream(ctx, method)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return seal(resp), nil
+}
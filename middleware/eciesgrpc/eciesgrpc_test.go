@@ -0,0 +1,235 @@
+package eciesgrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	eciesgo "github.com/ecies/go/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type testingJSONCodec struct{}
+
+func (testingJSONCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (testingJSONCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (testingJSONCodec) Name() string                               { return "json" }
+
+type testingMetadataKey struct{}
+
+// testingAppendMetadata and testingMetadata mimic gRPC metadata, outgoing context is passed to the server as is
+func testingAppendMetadata(ctx context.Context, kv ...string) context.Context {
+	md := map[string][]string{}
+	if prev, ok := ctx.Value(testingMetadataKey{}).(map[string][]string); ok {
+		for k, v := range prev {
+			md[k] = v
+		}
+	}
+	for i := 0; i+1 < len(kv); i += 2 {
+		md[kv[i]] = append(md[kv[i]], kv[i+1])
+	}
+	return context.WithValue(ctx, testingMetadataKey{}, md)
+}
+
+func testingMetadata(ctx context.Context, key string) []string {
+	md, _ := ctx.Value(testingMetadataKey{}).(map[string][]string)
+	return md[key]
+}
+
+type testingMessage struct{ Text string }
+
+func TestCodec(t *testing.T) {
+	if eciesgo.FIPSMode() {
+		t.Skip("secp256k1 is not approved in FIPS mode")
+	}
+
+	serverKey, err := eciesgo.GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	clientKey, err := eciesgo.GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	client := NewCodec(testingJSONCodec{}, clientKey)
+	server := NewCodec(testingJSONCodec{}, serverKey)
+	assert.Equal(t, "json-ecies", client.Name())
+
+	// Messages not sealed by interceptors are rejected without the default peer key
+	_, err = client.Marshal(testingMessage{"hello"})
+	assert.True(t, errors.Is(err, eciesgo.ErrInvalidPublicKey), err)
+
+	client.PublicKey = serverKey.PublicKey
+	data, err := client.Marshal(testingMessage{"hello"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotContains(t, string(data), "hello")
+
+	var received testingMessage
+	if !assert.NoError(t, server.Unmarshal(data, &received)) {
+		return
+	}
+	assert.Equal(t, "hello", received.Text)
+
+	// Client can't decrypt messages encrypted to the server
+	assert.Error(t, client.Unmarshal(data, &received))
+
+	data, err = server.Marshal(&Sealed{Message: testingMessage{"reply"}, PublicKey: clientKey.PublicKey})
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.NoError(t, client.Unmarshal(data, &received)) {
+		return
+	}
+	assert.Equal(t, "reply", received.Text)
+}
+
+func TestInterceptors(t *testing.T) {
+	if eciesgo.FIPSMode() {
+		t.Skip("secp256k1 is not approved in FIPS mode")
+	}
+
+	serverKey, err := eciesgo.GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	serverCodec := NewCodec(testingJSONCodec{}, serverKey)
+	trust := eciesgo.NewTrustStore()
+	server := &ServerInterceptor{ClientKey: MetadataKey(testingMetadata, trust)}
+
+	// handler echoes request text prefixed with method
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return testingMessage{"/echo " + req.(*testingMessage).Text}, nil
+	}
+
+	// call marshals request with client codec, handles it like gRPC server does and unmarshals the response
+	call := func(clientKey *eciesgo.PrivateKey, text string) (string, error) {
+		client := &ClientInterceptor{
+			PublicKey:               clientKey.PublicKey,
+			ServerKey:               ResolverKey(trust, serverKey.PublicKey.Fingerprint()),
+			AppendToOutgoingContext: testingAppendMetadata,
+		}
+		clientCodec := NewCodec(testingJSONCodec{}, clientKey)
+
+		var reply testingMessage
+		err := client.Unary(context.Background(), "/echo", testingMessage{text}, func(ctx context.Context, req interface{}) error {
+			data, err := clientCodec.Marshal(req)
+			if err != nil {
+				return err
+			}
+
+			var in testingMessage
+			if err := serverCodec.Unmarshal(data, &in); err != nil {
+				return err
+			}
+			resp, err := server.Unary(ctx, "/echo", &in, handler)
+			if err != nil {
+				return err
+			}
+
+			if data, err = serverCodec.Marshal(resp); err != nil {
+				return err
+			}
+			return clientCodec.Unmarshal(data, &reply)
+		})
+
+		return reply.Text, err
+	}
+
+	alice, err := eciesgo.GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	bob, err := eciesgo.GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Server key is not resolved yet
+	_, err = call(alice, "hello")
+	assert.True(t, errors.Is(err, eciesgo.ErrUntrustedKey), err)
+
+	if !assert.NoError(t, trust.Add("server", serverKey.PublicKey)) {
+		return
+	}
+	_, err = call(alice, "hello")
+	assert.True(t, errors.Is(err, eciesgo.ErrUntrustedKey), err)
+
+	// Single server codec replies to every pinned client with its own key
+	if !assert.NoError(t, trust.Add("alice", alice.PublicKey)) {
+		return
+	}
+	if !assert.NoError(t, trust.Add("bob", bob.PublicKey)) {
+		return
+	}
+	for _, key := range []*eciesgo.PrivateKey{alice, bob} {
+		reply, err := call(key, "hello")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "/echo hello", reply)
+		}
+	}
+
+	// Requests without client key are not handled
+	_, err = server.Unary(context.Background(), "/echo", &testingMessage{}, handler)
+	assert.True(t, errors.Is(err, eciesgo.ErrInvalidPublicKey), err)
+}
+
+func TestStreamInterceptors(t *testing.T) {
+	if eciesgo.FIPSMode() {
+		t.Skip("secp256k1 is not approved in FIPS mode")
+	}
+
+	serverKey, err := eciesgo.GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	clientKey, err := eciesgo.GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	client := &ClientInterceptor{
+		PublicKey:               clientKey.PublicKey,
+		ServerKey:               StaticKey(serverKey.PublicKey),
+		AppendToOutgoingContext: testingAppendMetadata,
+	}
+	server := &ServerInterceptor{ClientKey: MetadataKey(testingMetadata, nil)}
+	clientCodec, serverCodec := NewCodec(testingJSONCodec{}, clientKey), NewCodec(testingJSONCodec{}, serverKey)
+
+	ctx, sealRequest, err := client.Stream(context.Background(), "/stream")
+	if !assert.NoError(t, err) {
+		return
+	}
+	sealResponse, err := server.Stream(ctx, "/stream")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, text := range []string{"first", "second"} {
+		data, err := clientCodec.Marshal(sealRequest(testingMessage{text}))
+		if !assert.NoError(t, err) {
+			return
+		}
+		var in, out testingMessage
+		if !assert.NoError(t, serverCodec.Unmarshal(data, &in)) {
+			return
+		}
+		assert.Equal(t, text, in.Text)
+
+		data, err = serverCodec.Marshal(sealResponse(testingMessage{"re: " + in.Text}))
+		if !assert.NoError(t, err) {
+			return
+		}
+		if !assert.NoError(t, clientCodec.Unmarshal(data, &out)) {
+			return
+		}
+		assert.Equal(t, "re: "+text, out.Text)
+	}
+
+	// Malformed client key
+	ctx = testingAppendMetadata(context.Background(), PublicKeyHeader, "garbage")
+	_, err = server.Stream(ctx, "/stream")
+	assert.True(t, errors.Is(err, eciesgo.ErrInvalidPublicKey), err)
+}
@@ -0,0 +1,213 @@
+// Package ecieshttp provides http.RoundTripper and http.Handler wrappers which transparently encrypt
+// request and response bodies with ECIES.
+//
+// Client encrypts request body to the server public key and marks it with "Content-Encoding: ecies";
+// if the client has a private key, it sends the public key in Ecies-Public-Key header
+// and the server encrypts response body to it. Headers, URL and status are not encrypted.
+// Server does not authenticate clients by the key, combine it with TLS and usual authentication.
+package ecieshttp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	eciesgo "github.com/ecies/go/v2"
+)
+
+const (
+	// ContentEncoding marks encrypted bodies
+	ContentEncoding = "ecies"
+	// PublicKeyHeader carries hex encoded compressed client public key which response is encrypted to
+	PublicKeyHeader = "Ecies-Public-Key"
+	// DefaultMaxBodySize limits encrypted request body size read by Handler with zero MaxBodySize
+	DefaultMaxBodySize = 10 << 20
+)
+
+// Transport encrypts request bodies and decrypts response bodies
+type Transport struct {
+	// Base is the underlying transport, http.DefaultTransport is used if nil
+	Base http.RoundTripper
+	// PublicKey is the server public key which request bodies are encrypted to
+	PublicKey *eciesgo.PublicKey
+	// PrivateKey decrypts response bodies; responses are not encrypted if nil
+	PrivateKey *eciesgo.PrivateKey
+	Config     eciesgo.Config
+}
+
+// NewTransport returns Transport with the default config
+func NewTransport(base http.RoundTripper, serverPubkey *eciesgo.PublicKey, clientPrivkey *eciesgo.PrivateKey) *Transport {
+	return &Transport{Base: base, PublicKey: serverPubkey, PrivateKey: clientPrivkey, Config: eciesgo.DefaultConfig()}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("cannot read request body: %w", err)
+		}
+
+		ct, err := eciesgo.EncryptConf(t.PublicKey, body, t.Config)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encrypt request body: %w", err)
+		}
+
+		setBody(req.Header, ct)
+		req.Body, req.ContentLength = ioutil.NopCloser(bytes.NewReader(ct)), int64(len(ct))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(ct)), nil
+		}
+	}
+
+	if t.PrivateKey != nil {
+		req.Header.Set(PublicKeyHeader, t.PrivateKey.PublicKey.Hex(true))
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil || resp.Header.Get("Content-Encoding") != ContentEncoding {
+		return resp, err
+	}
+
+	ct, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read response body: %w", err)
+	}
+
+	if t.PrivateKey == nil {
+		return nil, fmt.Errorf("response is encrypted, but transport has no private key")
+	}
+	body, err := eciesgo.DecryptConf(t.PrivateKey, ct, t.Config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt response body: %w", err)
+	}
+
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	resp.Body, resp.ContentLength = ioutil.NopCloser(bytes.NewReader(body)), int64(len(body))
+
+	return resp, nil
+}
+
+// Handler decrypts request bodies and encrypts response bodies to the client key if it is passed;
+// requests with plaintext bodies are rejected with 415 status
+type Handler struct {
+	Next       http.Handler
+	PrivateKey *eciesgo.PrivateKey
+	Config     eciesgo.Config
+	// MaxBodySize limits encrypted request body size, DefaultMaxBodySize is used if zero;
+	// negative value disables the limit, so whole request body is buffered in memory
+	MaxBodySize int64
+}
+
+// NewHandler returns Handler with the default config
+func NewHandler(next http.Handler, serverPrivkey *eciesgo.PrivateKey) *Handler {
+	return &Handler{Next: next, PrivateKey: serverPrivkey, Config: eciesgo.DefaultConfig()}
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Header.Get("Content-Encoding") == ContentEncoding:
+		src, limit := r.Body, h.MaxBodySize
+		if limit == 0 {
+			limit = DefaultMaxBodySize
+		}
+		if limit > 0 {
+			src = http.MaxBytesReader(w, src, limit)
+		}
+
+		ct, err := ioutil.ReadAll(src)
+		if err != nil {
+			http.Error(w, "cannot read request body", http.StatusBadRequest)
+			return
+		}
+
+		body, err := eciesgo.DecryptConf(h.PrivateKey, ct, h.Config)
+		if err != nil {
+			http.Error(w, "cannot decrypt request body", http.StatusBadRequest)
+			return
+		}
+
+		r.Header.Del("Content-Encoding")
+		r.Body, r.ContentLength = ioutil.NopCloser(bytes.NewReader(body)), int64(len(body))
+	case r.ContentLength != 0:
+		http.Error(w, "request body must be encrypted", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	header := r.Header.Get(PublicKeyHeader)
+	if header == "" {
+		h.Next.ServeHTTP(w, r)
+		return
+	}
+
+	pub, err := eciesgo.NewPublicKeyFromHex(header)
+	if err != nil {
+		http.Error(w, "invalid client public key", http.StatusBadRequest)
+		return
+	}
+
+	rec := &responseRecorder{header: make(http.Header), status: http.StatusOK}
+	h.Next.ServeHTTP(rec, r)
+
+	for k, v := range rec.header {
+		w.Header()[k] = v
+	}
+
+	if rec.body.Len() > 0 {
+		ct, err := eciesgo.EncryptConf(pub, rec.body.Bytes(), h.Config)
+		if err != nil {
+			http.Error(w, "cannot encrypt response body", http.StatusInternalServerError)
+			return
+		}
+
+		setBody(w.Header(), ct)
+		w.WriteHeader(rec.status)
+		w.Write(ct)
+		return
+	}
+
+	w.WriteHeader(rec.status)
+}
+
+// setBody marks header as describing encrypted body
+func setBody(header http.Header, ct []byte) {
+	header.Set("Content-Encoding", ContentEncoding)
+	header.Set("Content-Length", strconv.Itoa(len(ct)))
+}
+
+// responseRecorder buffers response, so its body can be encrypted at once
+type responseRecorder struct {
+	header      http.Header
+	body        bytes.Buffer
+	status      int
+	wroteHeader bool
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(b)
+}
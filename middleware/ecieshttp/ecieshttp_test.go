@@ -0,0 +1,137 @@
+package ecieshttp
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	eciesgo "github.com/ecies/go/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// testingEcho replies with request body prefixed with method
+var testingEcho = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(r.Method + " " + string(body)))
+})
+
+func TestTransportAndHandler(t *testing.T) {
+	if eciesgo.FIPSMode() {
+		t.Skip("secp256k1 is not approved in FIPS mode")
+	}
+
+	serverKey, err := eciesgo.GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	clientKey, err := eciesgo.GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Body seen on the wire must be encrypted in both directions
+	var wireRequest string
+	inspect := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		wireRequest = string(body)
+		r.Body = ioutil.NopCloser(strings.NewReader(wireRequest))
+		NewHandler(testingEcho, serverKey).ServeHTTP(w, r)
+	})
+
+	server := httptest.NewServer(inspect)
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(nil, serverKey.PublicKey, clientKey)}
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("hello"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "POST hello", string(body))
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "text/plain", resp.Header.Get("Content-Type"))
+	assert.Empty(t, resp.Header.Get("Content-Encoding"))
+	assert.NotContains(t, wireRequest, "hello")
+
+	// Without client key response is not encrypted
+	client = &http.Client{Transport: NewTransport(nil, serverKey.PublicKey, nil)}
+	resp, err = client.Get(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	body, _ = ioutil.ReadAll(resp.Body)
+	assert.Equal(t, "GET ", string(body))
+}
+
+func TestHandlerRejects(t *testing.T) {
+	if eciesgo.FIPSMode() {
+		t.Skip("secp256k1 is not approved in FIPS mode")
+	}
+
+	serverKey, err := eciesgo.GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	handler := NewHandler(testingEcho, serverKey)
+
+	for _, tc := range []struct {
+		header http.Header
+		body   string
+		status int
+	}{
+		{http.Header{}, "plaintext", http.StatusUnsupportedMediaType},
+		{http.Header{"Content-Encoding": {ContentEncoding}}, "garbage", http.StatusBadRequest},
+		{http.Header{PublicKeyHeader: {"00"}}, "", http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+		req.Header = tc.header
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, tc.status, rec.Code)
+	}
+}
+
+func TestHandlerMaxBodySize(t *testing.T) {
+	if eciesgo.FIPSMode() {
+		t.Skip("secp256k1 is not approved in FIPS mode")
+	}
+
+	serverKey, err := eciesgo.GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	handler := NewHandler(testingEcho, serverKey)
+
+	// Bodies over the limit are not read, bodies within it reach decryption
+	for _, tc := range []struct {
+		limit int64
+		size  int
+		error string
+	}{
+		{0, DefaultMaxBodySize + 1, "cannot read request body"},
+		{0, DefaultMaxBodySize, "cannot decrypt request body"},
+		{16, 17, "cannot read request body"},
+		{16, 16, "cannot decrypt request body"},
+		{-1, DefaultMaxBodySize + 1, "cannot decrypt request body"},
+	} {
+		handler.MaxBodySize = tc.limit
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat("a", tc.size)))
+		req.Header.Set("Content-Encoding", ContentEncoding)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, tc.error, strings.TrimSpace(rec.Body.String()))
+	}
+}
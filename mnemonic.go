@@ -0,0 +1,137 @@
+package eciesgo
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// BIP-39 constants: every word encodes 11 bits, seed is derived with 2048 iterations of PBKDF2-HMAC-SHA512
+const (
+	mnemonicWordBits   = 11
+	mnemonicIterations = 2048
+)
+
+var (
+	bip39Words   = strings.Split(bip39English, "\n")
+	bip39Indices = func() map[string]int {
+		m := make(map[string]int, len(bip39Words))
+		for i, w := range bip39Words {
+			m[w] = i
+		}
+		return m
+	}()
+)
+
+// NewMnemonic generates BIP-39 mnemonic phrase with given entropy size in bits: 128, 160, 192, 224 or 256
+// (12 to 24 words)
+func NewMnemonic(bits int) (string, error) {
+	if err := validateEntropyBits(bits); err != nil {
+		return "", err
+	}
+
+	entropy := make([]byte, bits/8)
+	if err := randomBytes(entropy); err != nil {
+		return "", fmt.Errorf("cannot read random bytes for entropy: %w", err)
+	}
+	defer zeroBytes(entropy)
+
+	return NewMnemonicFromEntropy(entropy)
+}
+
+// NewMnemonicFromEntropy encodes entropy as BIP-39 mnemonic phrase
+func NewMnemonicFromEntropy(entropy []byte) (string, error) {
+	bits := len(entropy) * 8
+	if err := validateEntropyBits(bits); err != nil {
+		return "", err
+	}
+
+	// Entropy is followed by first bits/32 bits of its SHA-256 checksum
+	checksumBits := bits / 32
+	checksum := sha256.Sum256(entropy)
+
+	v := new(big.Int).SetBytes(entropy)
+	v.Lsh(v, uint(checksumBits))
+	v.Or(v, big.NewInt(int64(checksum[0]>>(8-checksumBits))))
+
+	words := make([]string, (bits+checksumBits)/mnemonicWordBits)
+	mask := big.NewInt(1<<mnemonicWordBits - 1)
+	for i := len(words) - 1; i >= 0; i-- {
+		words[i] = bip39Words[new(big.Int).And(v, mask).Int64()]
+		v.Rsh(v, mnemonicWordBits)
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy decodes BIP-39 mnemonic phrase and verifies its checksum
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+
+	bits := len(words) * mnemonicWordBits
+	checksumBits := bits / 33
+	entropyBits := bits - checksumBits
+	if len(words)%3 != 0 || validateEntropyBits(entropyBits) != nil {
+		return nil, fmt.Errorf("invalid mnemonic length: %d words", len(words))
+	}
+
+	v := new(big.Int)
+	for i, w := range words {
+		idx, ok := bip39Indices[w]
+		if !ok {
+			return nil, fmt.Errorf("unknown mnemonic word %d: %q", i+1, w)
+		}
+		v.Lsh(v, mnemonicWordBits)
+		v.Or(v, big.NewInt(int64(idx)))
+	}
+
+	checksum := byte(new(big.Int).And(v, big.NewInt(1<<uint(checksumBits)-1)).Int64())
+	entropy := zeroPad(v.Rsh(v, uint(checksumBits)).Bytes(), entropyBits/8)
+
+	expected := sha256.Sum256(entropy)
+	if expected[0]>>(8-checksumBits) != checksum {
+		return nil, fmt.Errorf("invalid mnemonic checksum")
+	}
+
+	return entropy, nil
+}
+
+// ValidateMnemonic checks that mnemonic phrase consists of BIP-39 English words and has valid checksum
+func ValidateMnemonic(mnemonic string) error {
+	_, err := MnemonicToEntropy(mnemonic)
+	return err
+}
+
+// MnemonicToSeed validates mnemonic phrase and derives 64 bytes BIP-39 seed with optional passphrase;
+// passphrase is used as is, non-ASCII passphrases must be NFKD normalized by the caller to match other implementations
+func MnemonicToSeed(mnemonic, passphrase string) ([]byte, error) {
+	if err := ValidateMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+
+	normalized := strings.Join(strings.Fields(mnemonic), " ")
+	return pbkdf2.Key([]byte(normalized), []byte("mnemonic"+passphrase), mnemonicIterations, 64, sha512.New), nil
+}
+
+// NewMasterKeyFromMnemonic derives master extended private key from BIP-39 mnemonic phrase and passphrase
+func NewMasterKeyFromMnemonic(mnemonic, passphrase string) (*ExtendedKey, error) {
+	seed, err := MnemonicToSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(seed)
+
+	return NewMasterKey(seed)
+}
+
+func validateEntropyBits(bits int) error {
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return fmt.Errorf("invalid entropy size: %d bits", bits)
+	}
+
+	return nil
+}
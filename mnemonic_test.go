@@ -0,0 +1,114 @@
+package eciesgo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Trezor BIP-39 test vectors, passphrase "TREZOR"
+var testingMnemonicVectors = []struct {
+	entropy, mnemonic, seed string
+}{
+	{
+		"00000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+	},
+	{
+		"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+		"legal winner thank year wave sausage worth useful legal winner thank yellow",
+		"2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+	},
+	{
+		"ffffffffffffffffffffffffffffffff",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+		"ac27495480225222079d7be181583751e86f571027b0497b5b5d11218e0a8a13332572917f0f8e5a589620c6f15b11c61dee327651a14c34e18231052e48c069",
+	},
+	{
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art",
+		"bda85446c68413707090a52022edd26a1c9462295029f2e60cd7c4f2bbd3097170af7a4d73245cafa9c3cca8d561a7c3de6f5d4a10be8ed2a5e608d68f92fcc8",
+	},
+}
+
+func TestBIP39Wordlist(t *testing.T) {
+	sum := sha256.Sum256([]byte(bip39English + "\n"))
+	assert.Equal(t, "2f5eed53a4727b4bf8880d8f3f199efc90e58503646d9ff8eff3a2ed3b24dbda", hex.EncodeToString(sum[:]))
+	assert.Len(t, bip39Indices, 2048)
+}
+
+func TestMnemonicVectors(t *testing.T) {
+	for _, v := range testingMnemonicVectors {
+		entropy, _ := hex.DecodeString(v.entropy)
+
+		mnemonic, err := NewMnemonicFromEntropy(entropy)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, v.mnemonic, mnemonic)
+
+		decoded, err := MnemonicToEntropy(v.mnemonic)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, entropy, decoded)
+
+		seed, err := MnemonicToSeed(v.mnemonic, "TREZOR")
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, v.seed, hex.EncodeToString(seed))
+	}
+}
+
+func TestNewMnemonic(t *testing.T) {
+	for _, bits := range []int{128, 160, 192, 224, 256} {
+		mnemonic, err := NewMnemonic(bits)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, strings.Fields(mnemonic), bits/32*3)
+		assert.NoError(t, ValidateMnemonic(mnemonic))
+	}
+
+	for _, bits := range []int{0, 96, 129, 288} {
+		_, err := NewMnemonic(bits)
+		assert.Error(t, err)
+	}
+
+	for _, mnemonic := range []string{
+		"",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abaft",
+	} {
+		assert.Error(t, ValidateMnemonic(mnemonic), mnemonic)
+	}
+}
+
+func TestNewMasterKeyFromMnemonic(t *testing.T) {
+	v := testingMnemonicVectors[0]
+
+	master, err := NewMasterKeyFromMnemonic(v.mnemonic, "TREZOR")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	seed, _ := hex.DecodeString(v.seed)
+	expected, err := NewMasterKey(seed)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, expected.String(), master.String())
+
+	// Passphrase changes the seed
+	other, err := NewMasterKeyFromMnemonic(v.mnemonic, "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotEqual(t, master.String(), other.String())
+}
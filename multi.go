@@ -0,0 +1,196 @@
+package eciesgo
+
+import (
+	"crypto/elliptic"
+	"encoding/binary"
+	"fmt"
+)
+
+// EncryptMultiConf encrypts a passed message once under a random content key and wraps the key for every receiver;
+// output consists of 2 bytes big endian receivers count, wrapped content keys (each one is ECIES ciphertext)
+// and symmetrically encrypted payload. Wrapped keys have fixed length, so receivers must be on the same curve
+func EncryptMultiConf(pubkeys []*PublicKey, msg []byte, config Config) ([]byte, error) {
+	if len(pubkeys) == 0 || len(pubkeys) > 0xffff {
+		return nil, fmt.Errorf("invalid number of receivers: %d", len(pubkeys))
+	}
+	if err := checkPlaintextSize(config, len(msg)); err != nil {
+		return nil, err
+	}
+
+	curve := keyCurve(pubkeys[0])
+	for _, pub := range pubkeys[1:] {
+		if c := keyCurve(pub); c != nil && curve != nil && !sameCurve(c, curve) {
+			return nil, fmt.Errorf("%w: receivers are on different curves", ErrInvalidPublicKey)
+		}
+	}
+
+	// Content key length matches the symmetric cipher key size
+	keySize, err := symmKeySize(config)
+	if err != nil {
+		return nil, err
+	}
+
+	cek := make([]byte, keySize)
+	if err := randomBytesConf(config, cek); err != nil {
+		return nil, fmt.Errorf("cannot read random bytes for content key: %w", err)
+	}
+
+	ct, config, err := appendEnvelope(nil, curve, config)
+	if err != nil {
+		return nil, err
+	}
+
+	ct = append(ct, 0, 0)
+	binary.BigEndian.PutUint16(ct[len(ct)-2:], uint16(len(pubkeys)))
+
+	for _, pub := range pubkeys {
+		if ct, err = EncryptAppendConf(ct, pub, cek, contentKeyConfig(config)); err != nil {
+			return nil, err
+		}
+	}
+
+	payload, err := EncryptSymm(cek, msg, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(ct, payload...), nil
+}
+
+func EncryptMulti(pubkeys []*PublicKey, msg []byte) ([]byte, error) {
+	return EncryptMultiConf(pubkeys, msg, DefaultConfig())
+}
+
+// DecryptMultiConf decrypts a message produced by EncryptMulti with one of the receivers private keys
+func DecryptMultiConf(privkey *PrivateKey, msg []byte, config Config) ([]byte, error) {
+	msg, config, err := openEnvelope(msg, config)
+	if err != nil {
+		return nil, err
+	}
+
+	cek, payload, err := unwrapContentKey(privkey, msg, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecryptSymm(cek, payload, config)
+}
+
+func DecryptMulti(privkey *PrivateKey, msg []byte) ([]byte, error) {
+	return DecryptMultiConf(privkey, msg, DefaultConfig())
+}
+
+// splitMulti splits multi-recipient message into wrapped content keys with ephemeral keys on the curve and payload
+func splitMulti(msg []byte, curve elliptic.Curve, config Config) (wrapped [][]byte, payload []byte, err error) {
+	if len(msg) < 2 {
+		return nil, nil, ErrCiphertextTooShort
+	}
+
+	count := int(binary.BigEndian.Uint16(msg))
+	if count == 0 {
+		return nil, nil, fmt.Errorf("%w: invalid number of receivers %d", ErrCiphertextTooShort, count)
+	}
+
+	keySize, err := symmKeySize(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wrappedLength, err := symmCiphertextLength(config, keySize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	size := 1 + 2*((curve.Params().BitSize+7)/8) + wrappedLength
+	if len(msg) <= 2+count*size {
+		return nil, nil, ErrCiphertextTooShort
+	}
+
+	msg = msg[2:]
+	wrapped = make([][]byte, count)
+	for i := range wrapped {
+		wrapped[i] = msg[i*size : (i+1)*size]
+	}
+
+	return wrapped, msg[count*size:], nil
+}
+
+// unwrapContentKey finds the content key wrapped for the passed private key
+func unwrapContentKey(privkey *PrivateKey, msg []byte, config Config) (cek []byte, payload []byte, err error) {
+	curve, err := ephemeralCurve(privkey, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wrapped, payload, err := splitMulti(msg, curve, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkSymmCiphertextSize(config, len(payload)); err != nil {
+		return nil, nil, err
+	}
+
+	for _, w := range wrapped {
+		if cek, err := DecryptConf(privkey, w, contentKeyConfig(config)); err == nil {
+			return cek, payload, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("%w: no content key is wrapped for the private key", ErrAuthenticationFailed)
+}
+
+// RewrapForRecipientConf replaces content key wrapped for the old private key with one wrapped for the new public key,
+// so recipient key can be rotated without re-encrypting the payload; other recipients and the payload are kept as is.
+// The new key must be on the curve of the old one, as wrapped keys have fixed length
+func RewrapForRecipientConf(oldPrivkey *PrivateKey, newPubkey *PublicKey, msg []byte, config Config) ([]byte, error) {
+	body, config, err := openEnvelope(msg, config)
+	if err != nil {
+		return nil, err
+	}
+	header := msg[:len(msg)-len(body)]
+
+	curve, err := ephemeralCurve(oldPrivkey, config)
+	if err != nil {
+		return nil, err
+	}
+	if c := keyCurve(newPubkey); c != nil && !sameCurve(c, curve) {
+		return nil, fmt.Errorf("%w: new key is on another curve", ErrInvalidPublicKey)
+	}
+
+	wrapped, _, err := splitMulti(body, curve, config)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, w := range wrapped {
+		cek, err := DecryptConf(oldPrivkey, w, contentKeyConfig(config))
+		if err != nil {
+			continue
+		}
+		defer zeroBytes(cek)
+
+		rewrapped, err := EncryptConf(newPubkey, cek, contentKeyConfig(config))
+		if err != nil {
+			return nil, err
+		}
+
+		// Wrapped keys have fixed length, so the new one takes place of the old one
+		offset := len(header) + 2 + i*len(w)
+		ret := append([]byte(nil), msg...)
+		copy(ret[offset:offset+len(w)], rewrapped)
+
+		return ret, nil
+	}
+
+	return nil, fmt.Errorf("%w: no content key is wrapped for the private key", ErrAuthenticationFailed)
+}
+
+func RewrapForRecipient(oldPrivkey *PrivateKey, newPubkey *PublicKey, msg []byte) ([]byte, error) {
+	return RewrapForRecipientConf(oldPrivkey, newPubkey, msg, DefaultConfig())
+}
+
+// contentKeyConfig returns config wrapping content keys, which are not limited by WithMaxMessageSize
+func contentKeyConfig(config Config) Config {
+	config.maxMessageSize = 0
+	return config
+}
@@ -0,0 +1,192 @@
+package eciesgo
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptMultiAndDecryptMulti(t *testing.T) {
+	skipUnapproved(t)
+
+	var privkeys []*PrivateKey
+	var pubkeys []*PublicKey
+	for i := 0; i < 3; i++ {
+		privkey, err := GenerateKey()
+		if !assert.NoError(t, err) {
+			return
+		}
+		privkeys = append(privkeys, privkey)
+		pubkeys = append(pubkeys, privkey.PublicKey)
+	}
+
+	for _, conf := range []Config{DEFAULT_CONFIG, NewConfig("xchacha20", 0)} {
+		ciphertext, err := EncryptMultiConf(pubkeys, []byte(testingJsonMessage), conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		for _, privkey := range privkeys {
+			plaintext, err := DecryptMultiConf(privkey, ciphertext, conf)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, testingJsonMessage, string(plaintext))
+		}
+	}
+
+	outsider, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	ciphertext, err := EncryptMulti(pubkeys, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = DecryptMulti(outsider, ciphertext)
+	assert.Error(t, err)
+
+	_, err = DecryptMulti(privkeys[0], ciphertext[:100])
+	assert.Error(t, err)
+
+	_, err = EncryptMulti(nil, []byte(testingMessage))
+	assert.Error(t, err)
+}
+
+func TestEncryptMultiEnvelope(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ct, err := EncryptMultiConf([]*PublicKey{privkey.PublicKey}, []byte(testingMessage), NewConfig("xchacha20", 24).WithEnvelope())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	pt, err := DecryptMultiConf(privkey, ct, DEFAULT_CONFIG.WithEnvelope())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(pt))
+}
+
+func TestRewrapForRecipient(t *testing.T) {
+	skipUnapproved(t)
+
+	var privkeys []*PrivateKey
+	var pubkeys []*PublicKey
+	for i := 0; i < 3; i++ {
+		privkey, err := GenerateKey()
+		if !assert.NoError(t, err) {
+			return
+		}
+		privkeys = append(privkeys, privkey)
+		pubkeys = append(pubkeys, privkey.PublicKey)
+	}
+
+	rotated, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, conf := range []Config{DEFAULT_CONFIG, NewConfig("xchacha20", 0).WithEnvelope()} {
+		ciphertext, err := EncryptMultiConf(pubkeys, []byte(testingJsonMessage), conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		rewrapped, err := RewrapForRecipientConf(privkeys[1], rotated.PublicKey, ciphertext, conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, rewrapped, len(ciphertext))
+
+		for _, privkey := range []*PrivateKey{privkeys[0], privkeys[2], rotated} {
+			plaintext, err := DecryptMultiConf(privkey, rewrapped, conf)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, testingJsonMessage, string(plaintext))
+		}
+
+		_, err = DecryptMultiConf(privkeys[1], rewrapped, conf)
+		assert.Error(t, err)
+
+		// Payload is not re-encrypted
+		assert.Equal(t, ciphertext[len(ciphertext)-len(testingJsonMessage):], rewrapped[len(rewrapped)-len(testingJsonMessage):])
+
+		_, err = RewrapForRecipientConf(privkeys[1], rotated.PublicKey, rewrapped, conf)
+		assert.Error(t, err)
+	}
+}
+
+func TestEncryptMultiCurves(t *testing.T) {
+	skipUnapproved(t)
+
+	for _, curve := range []elliptic.Curve{elliptic.P384(), elliptic.P521()} {
+		var privkeys []*PrivateKey
+		var pubkeys []*PublicKey
+		for i := 0; i < 3; i++ {
+			privkey, err := GenerateKeyCurve(curve)
+			if !assert.NoError(t, err) {
+				return
+			}
+			privkeys = append(privkeys, privkey)
+			pubkeys = append(pubkeys, privkey.PublicKey)
+		}
+
+		rotated, err := GenerateKeyCurve(curve)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		for _, conf := range []Config{DEFAULT_CONFIG, DEFAULT_CONFIG.WithEnvelope()} {
+			ciphertext, err := EncryptMultiConf(pubkeys, []byte(testingJsonMessage), conf)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			for _, privkey := range privkeys {
+				plaintext, err := DecryptMultiConf(privkey, ciphertext, conf)
+				if !assert.NoError(t, err, curve.Params().Name) {
+					return
+				}
+				assert.Equal(t, testingJsonMessage, string(plaintext))
+			}
+
+			rewrapped, err := RewrapForRecipientConf(privkeys[1], rotated.PublicKey, ciphertext, conf)
+			if !assert.NoError(t, err) {
+				return
+			}
+			plaintext, err := DecryptMultiConf(rotated, rewrapped, conf)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, testingJsonMessage, string(plaintext))
+		}
+	}
+
+	// Wrapped keys of receivers on different curves would have different lengths
+	p256, err := GenerateKeyCurve(elliptic.P256())
+	if !assert.NoError(t, err) {
+		return
+	}
+	p384, err := GenerateKeyCurve(elliptic.P384())
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = EncryptMulti([]*PublicKey{p256.PublicKey, p384.PublicKey}, []byte(testingMessage))
+	assert.True(t, errors.Is(err, ErrInvalidPublicKey), err)
+
+	ciphertext, err := EncryptMulti([]*PublicKey{p256.PublicKey}, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = RewrapForRecipient(p256, p384.PublicKey, ciphertext)
+	assert.True(t, errors.Is(err, ErrInvalidPublicKey), err)
+}
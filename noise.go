@@ -0,0 +1,463 @@
+package eciesgo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Noise protocol framework (https://noiseprotocol.org/noise.html) handshakes over secp256k1 keys with
+// ChaChaPoly and SHA256. DH function follows Lightning BOLT 8: SHA-256 of the compressed shared point,
+// public keys are sent compressed (33 bytes), so Noise_XK is compatible with Lightning transport handshake
+
+// NoisePattern is a Noise handshake pattern; all supported patterns pre-share the responder static key
+type NoisePattern struct {
+	name     string
+	messages [][]string
+}
+
+// Supported handshake patterns
+var (
+	// NoiseX is one-way pattern: single message from initiator, which static key is sent encrypted
+	NoiseX = &NoisePattern{name: "X", messages: [][]string{{"e", "es", "s", "ss"}}}
+	// NoiseIK is interactive pattern with mutual authentication in one round trip,
+	// initiator static key is sent in the first message
+	NoiseIK = &NoisePattern{name: "IK", messages: [][]string{{"e", "es", "s", "ss"}, {"e", "ee", "se"}}}
+	// NoiseXK is interactive pattern with initiator static key sent in the third message, it hides initiator identity
+	// from active attackers; it is the pattern of Lightning transport
+	NoiseXK = &NoisePattern{name: "XK", messages: [][]string{{"e", "es"}, {"e", "ee"}, {"s", "se"}}}
+)
+
+// Name returns Noise protocol name of the pattern, e.g. "Noise_IK_secp256k1_ChaChaPoly_SHA256"
+func (p *NoisePattern) Name() string {
+	return "Noise_" + p.name + "_secp256k1_ChaChaPoly_SHA256"
+}
+
+// Lengths of Noise values
+const (
+	noiseKeyLength    = 33
+	noiseMaxMessage   = 65535
+	noiseTagLength    = chacha20poly1305.Overhead
+	noiseMaxNonce     = math.MaxUint64
+	noiseHashLength   = sha256.Size
+	noiseCipherKeyLen = chacha20poly1305.KeySize
+)
+
+// CipherState encrypts transport messages of one direction after handshake, it is not safe for concurrent use
+type CipherState struct {
+	k      [noiseCipherKeyLen]byte
+	hasKey bool
+	n      uint64
+}
+
+func (c *CipherState) initializeKey(k []byte) {
+	copy(c.k[:], k)
+	c.hasKey = true
+	c.n = 0
+}
+
+func (c *CipherState) nonce() []byte {
+	var nonce [chacha20poly1305.NonceSize]byte
+	binary.LittleEndian.PutUint64(nonce[4:], c.n)
+	return nonce[:]
+}
+
+// Encrypt appends encrypted plaintext with tag to out, ad is authenticated associated data
+func (c *CipherState) Encrypt(out, ad, plaintext []byte) ([]byte, error) {
+	if !c.hasKey {
+		return append(out, plaintext...), nil
+	}
+	if c.n == noiseMaxNonce {
+		return nil, fmt.Errorf("noise nonce is exhausted")
+	}
+
+	aead, err := chacha20poly1305.New(c.k[:])
+	if err != nil {
+		return nil, fmt.Errorf("cannot create ChaCha20-Poly1305: %w", err)
+	}
+	out = aead.Seal(out, c.nonce(), plaintext, ad)
+	c.n++
+
+	return out, nil
+}
+
+// Decrypt appends decrypted ciphertext to out, ad is authenticated associated data; nonce is advanced only
+// on success, so forged messages do not desynchronize the session
+func (c *CipherState) Decrypt(out, ad, ciphertext []byte) ([]byte, error) {
+	if !c.hasKey {
+		return append(out, ciphertext...), nil
+	}
+	if c.n == noiseMaxNonce {
+		return nil, fmt.Errorf("noise nonce is exhausted")
+	}
+
+	aead, err := chacha20poly1305.New(c.k[:])
+	if err != nil {
+		return nil, fmt.Errorf("cannot create ChaCha20-Poly1305: %w", err)
+	}
+	out, err = aead.Open(out, c.nonce(), ciphertext, ad)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAuthenticationFailed, err)
+	}
+	c.n++
+
+	return out, nil
+}
+
+// Rekey replaces the key with REKEY function of Noise specification (section 11.3), nonce is kept;
+// both sides must rekey at the same message
+func (c *CipherState) Rekey() {
+	aead, _ := chacha20poly1305.New(c.k[:])
+
+	var nonce [chacha20poly1305.NonceSize]byte
+	binary.LittleEndian.PutUint64(nonce[4:], noiseMaxNonce)
+	var zeros [noiseCipherKeyLen]byte
+	copy(c.k[:], aead.Seal(nil, nonce[:], zeros[:], nil))
+}
+
+// symmetricState holds chaining key and handshake hash
+type symmetricState struct {
+	cs CipherState
+	ck [noiseHashLength]byte
+	h  [noiseHashLength]byte
+}
+
+func (s *symmetricState) initialize(protocolName string) {
+	if len(protocolName) <= noiseHashLength {
+		copy(s.h[:], protocolName)
+	} else {
+		s.h = sha256.Sum256([]byte(protocolName))
+	}
+	s.ck = s.h
+}
+
+func (s *symmetricState) mixKey(ikm []byte) {
+	ck, k := noiseHKDF(s.ck[:], ikm)
+	copy(s.ck[:], ck)
+	s.cs.initializeKey(k)
+}
+
+func (s *symmetricState) mixHash(data []byte) {
+	h := sha256.New()
+	h.Write(s.h[:])
+	h.Write(data)
+	h.Sum(s.h[:0])
+}
+
+func (s *symmetricState) encryptAndHash(out, plaintext []byte) ([]byte, error) {
+	l := len(out)
+	out, err := s.cs.Encrypt(out, s.h[:], plaintext)
+	if err != nil {
+		return nil, err
+	}
+	s.mixHash(out[l:])
+
+	return out, nil
+}
+
+func (s *symmetricState) decryptAndHash(out, ciphertext []byte) ([]byte, error) {
+	out, err := s.cs.Decrypt(out, s.h[:], ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	s.mixHash(ciphertext)
+
+	return out, nil
+}
+
+func (s *symmetricState) split() (*CipherState, *CipherState) {
+	k1, k2 := noiseHKDF(s.ck[:], nil)
+
+	c1, c2 := &CipherState{}, &CipherState{}
+	c1.initializeKey(k1)
+	c2.initializeKey(k2)
+
+	return c1, c2
+}
+
+// noiseHKDF returns two outputs of Noise HKDF (section 4.3)
+func noiseHKDF(ck, ikm []byte) ([]byte, []byte) {
+	mac := hmac.New(sha256.New, ck)
+	mac.Write(ikm)
+	tempKey := mac.Sum(nil)
+
+	mac = hmac.New(sha256.New, tempKey)
+	mac.Write([]byte{0x01})
+	out1 := mac.Sum(nil)
+
+	mac = hmac.New(sha256.New, tempKey)
+	mac.Write(out1)
+	mac.Write([]byte{0x02})
+	out2 := mac.Sum(nil)
+
+	return out1, out2
+}
+
+// noiseDH computes BOLT 8 DH: SHA-256 of compressed shared point
+func noiseDH(priv *PrivateKey, pub *PublicKey) ([]byte, error) {
+	ss, err := priv.ECDH(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.Sum256(ss)
+	zeroBytes(ss)
+	return h[:], nil
+}
+
+// NoiseConfig configures handshake of one side
+type NoiseConfig struct {
+	// Pattern is the handshake pattern
+	Pattern *NoisePattern
+	// Initiator is set for the side sending the first message
+	Initiator bool
+	// Prologue is data both sides must agree on, e.g. protocol version, it is authenticated by the handshake
+	Prologue []byte
+	// StaticKey is the local static key
+	StaticKey *PrivateKey
+	// RemoteStatic is the responder static key, required for initiator
+	RemoteStatic *PublicKey
+
+	// ephemeral replaces generated ephemeral key in tests
+	ephemeral *PrivateKey
+}
+
+// HandshakeState runs Noise handshake; messages are written and read in turns defined by the pattern,
+// the last one returns cipher states of both directions. It is not safe for concurrent use
+type HandshakeState struct {
+	ss        symmetricState
+	pattern   *NoisePattern
+	initiator bool
+
+	s  *PrivateKey
+	e  *PrivateKey
+	rs *PublicKey
+	re *PublicKey
+
+	message   int
+	ephemeral *PrivateKey
+}
+
+// NewHandshakeState starts handshake of one side
+func NewHandshakeState(config NoiseConfig) (*HandshakeState, error) {
+	if config.Pattern == nil {
+		return nil, fmt.Errorf("noise pattern is empty")
+	}
+	if config.StaticKey == nil || config.StaticKey.PublicKey == nil || config.StaticKey.D == nil {
+		return nil, fmt.Errorf("%w: noise static key is empty", ErrInvalidPrivateKey)
+	}
+	if err := checkNoiseKey(config.StaticKey.PublicKey); err != nil {
+		return nil, err
+	}
+	if config.Initiator {
+		if err := checkNoiseKey(config.RemoteStatic); err != nil {
+			return nil, fmt.Errorf("noise remote static key: %w", err)
+		}
+	}
+
+	hs := &HandshakeState{
+		pattern:   config.Pattern,
+		initiator: config.Initiator,
+		s:         config.StaticKey,
+		ephemeral: config.ephemeral,
+	}
+	hs.ss.initialize(config.Pattern.Name())
+	hs.ss.mixHash(config.Prologue)
+
+	// Pre-message "<- s" of all supported patterns
+	if config.Initiator {
+		hs.rs = config.RemoteStatic
+		hs.ss.mixHash(hs.rs.Bytes(true))
+	} else {
+		hs.ss.mixHash(hs.s.PublicKey.Bytes(true))
+	}
+
+	return hs, nil
+}
+
+// checkNoiseKey checks that key is secp256k1 key, as DH and key encoding are defined for it only
+func checkNoiseKey(pub *PublicKey) error {
+	if pub == nil || pub.X == nil || pub.Y == nil {
+		return fmt.Errorf("%w: public key is empty", ErrInvalidPublicKey)
+	}
+	if !sameCurve(pub.Curve, getCurve()) {
+		return fmt.Errorf("%w: noise handshake requires secp256k1 key", ErrInvalidPublicKey)
+	}
+
+	return nil
+}
+
+// RemoteStatic returns the remote static key, it is known to responder once the message with it is read
+func (hs *HandshakeState) RemoteStatic() *PublicKey {
+	return hs.rs
+}
+
+// HandshakeHash returns handshake hash, which uniquely identifies the session for channel binding;
+// it is final once the handshake completes
+func (hs *HandshakeState) HandshakeHash() []byte {
+	return append([]byte(nil), hs.ss.h[:]...)
+}
+
+// Complete reports whether all handshake messages are processed
+func (hs *HandshakeState) Complete() bool {
+	return hs.message >= len(hs.pattern.messages)
+}
+
+// WriteMessage appends next handshake message with payload to out; the last message returns cipher states
+// for initiator-to-responder and responder-to-initiator traffic
+func (hs *HandshakeState) WriteMessage(out, payload []byte) (_ []byte, c1, c2 *CipherState, err error) {
+	if hs.Complete() {
+		return nil, nil, nil, fmt.Errorf("noise handshake is complete")
+	}
+	if hs.initiator != (hs.message%2 == 0) {
+		return nil, nil, nil, fmt.Errorf("noise handshake expects message from the peer")
+	}
+
+	for _, token := range hs.pattern.messages[hs.message] {
+		switch token {
+		case "e":
+			if hs.e, err = hs.generateEphemeral(); err != nil {
+				return nil, nil, nil, err
+			}
+			pub := hs.e.PublicKey.Bytes(true)
+			hs.ss.mixHash(pub)
+			out = append(out, pub...)
+		case "s":
+			if out, err = hs.ss.encryptAndHash(out, hs.s.PublicKey.Bytes(true)); err != nil {
+				return nil, nil, nil, err
+			}
+		default:
+			if err := hs.mixDH(token); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+	}
+
+	if out, err = hs.ss.encryptAndHash(out, payload); err != nil {
+		return nil, nil, nil, err
+	}
+	if len(out) > noiseMaxMessage {
+		return nil, nil, nil, fmt.Errorf("noise message is too long: %d", len(out))
+	}
+
+	c1, c2 = hs.next()
+	return out, c1, c2, nil
+}
+
+// ReadMessage processes next handshake message from the peer and appends its payload to out; the last message
+// returns cipher states for initiator-to-responder and responder-to-initiator traffic. Failed message aborts
+// the handshake, as its state is already changed
+func (hs *HandshakeState) ReadMessage(out, message []byte) (_ []byte, c1, c2 *CipherState, err error) {
+	if hs.Complete() {
+		return nil, nil, nil, fmt.Errorf("noise handshake is complete")
+	}
+	if hs.initiator == (hs.message%2 == 0) {
+		return nil, nil, nil, fmt.Errorf("noise handshake expects message to the peer")
+	}
+	if len(message) > noiseMaxMessage {
+		return nil, nil, nil, fmt.Errorf("%w: message is too long: %d", ErrNoiseHandshake, len(message))
+	}
+
+	for _, token := range hs.pattern.messages[hs.message] {
+		switch token {
+		case "e":
+			if len(message) < noiseKeyLength {
+				return nil, nil, nil, fmt.Errorf("%w: ephemeral key is truncated", ErrNoiseHandshake)
+			}
+			if hs.re, err = NewPublicKeyFromBytes(message[:noiseKeyLength]); err != nil {
+				return nil, nil, nil, fmt.Errorf("%w: %v", ErrNoiseHandshake, err)
+			}
+			hs.ss.mixHash(message[:noiseKeyLength])
+			message = message[noiseKeyLength:]
+		case "s":
+			l := noiseKeyLength
+			if hs.ss.cs.hasKey {
+				l += noiseTagLength
+			}
+			if len(message) < l {
+				return nil, nil, nil, fmt.Errorf("%w: static key is truncated", ErrNoiseHandshake)
+			}
+			pub, err := hs.ss.decryptAndHash(nil, message[:l])
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if hs.rs, err = NewPublicKeyFromBytes(pub); err != nil {
+				return nil, nil, nil, fmt.Errorf("%w: %v", ErrNoiseHandshake, err)
+			}
+			message = message[l:]
+		default:
+			if err := hs.mixDH(token); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+	}
+
+	if hs.ss.cs.hasKey && len(message) < noiseTagLength {
+		return nil, nil, nil, fmt.Errorf("%w: payload is truncated", ErrNoiseHandshake)
+	}
+	if out, err = hs.ss.decryptAndHash(out, message); err != nil {
+		return nil, nil, nil, err
+	}
+
+	c1, c2 = hs.next()
+	return out, c1, c2, nil
+}
+
+// mixDH mixes DH of the token ("ee", "es", "se" or "ss") into the chaining key; the first letter is the key
+// of initiator, the second one is of responder
+func (hs *HandshakeState) mixDH(token string) error {
+	var priv *PrivateKey
+	var pub *PublicKey
+	switch {
+	case token == "ee":
+		priv, pub = hs.e, hs.re
+	case token == "ss":
+		priv, pub = hs.s, hs.rs
+	case token == "es" && hs.initiator:
+		priv, pub = hs.e, hs.rs
+	case token == "es":
+		priv, pub = hs.s, hs.re
+	case token == "se" && hs.initiator:
+		priv, pub = hs.s, hs.re
+	case token == "se":
+		priv, pub = hs.e, hs.rs
+	default:
+		return fmt.Errorf("unknown noise token %s", token)
+	}
+
+	dh, err := noiseDH(priv, pub)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNoiseHandshake, err)
+	}
+	hs.ss.mixKey(dh)
+	zeroBytes(dh)
+
+	return nil
+}
+
+func (hs *HandshakeState) generateEphemeral() (*PrivateKey, error) {
+	if hs.ephemeral != nil {
+		return hs.ephemeral, nil
+	}
+
+	return GenerateKey()
+}
+
+// next advances to the next message, returns cipher states after the last one
+func (hs *HandshakeState) next() (*CipherState, *CipherState) {
+	hs.message++
+	if !hs.Complete() {
+		return nil, nil
+	}
+
+	c1, c2 := hs.ss.split()
+	if hs.e != nil && hs.e != hs.ephemeral {
+		hs.e.Zeroize()
+	}
+
+	return c1, c2
+}
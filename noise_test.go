@@ -0,0 +1,271 @@
+package eciesgo
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func noiseTestKey(t *testing.T, b byte) *PrivateKey {
+	k, err := NewPrivateKeyFromBytes(bytes.Repeat([]byte{b}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestNoiseXK_BOLT8(t *testing.T) {
+	skipUnapproved(t)
+
+	// Lightning transport handshake test vectors (BOLT 8, appendix A), acts are prefixed with version byte 0
+	initiatorStatic, initiatorEphemeral := noiseTestKey(t, 0x11), noiseTestKey(t, 0x12)
+	responderStatic, responderEphemeral := noiseTestKey(t, 0x21), noiseTestKey(t, 0x22)
+	assert.Equal(t, "034f355bdcb7cc0af728ef3cceb9615d90684bb5b2ca5f859ab0f0b704075871aa", initiatorStatic.PublicKey.Hex(true))
+	assert.Equal(t, "028d7500dd4c12685d1f568b4c2b5048e8534b873319f3a8daa612b469132ec7f7", responderStatic.PublicKey.Hex(true))
+
+	initiator, err := NewHandshakeState(NoiseConfig{
+		Pattern:      NoiseXK,
+		Initiator:    true,
+		Prologue:     []byte("lightning"),
+		StaticKey:    initiatorStatic,
+		RemoteStatic: responderStatic.PublicKey,
+		ephemeral:    initiatorEphemeral,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	responder, err := NewHandshakeState(NoiseConfig{
+		Pattern:   NoiseXK,
+		Prologue:  []byte("lightning"),
+		StaticKey: responderStatic,
+		ephemeral: responderEphemeral,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	act1, _, _, err := initiator.WriteMessage([]byte{0}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "00036360e856310ce5d294e8be33fc807077dc56ac80d95d9cd4ddbd21325eff73f70df6086551151f58b8afe6c195782c6a",
+		hex.EncodeToString(act1))
+	_, _, _, err = responder.ReadMessage(nil, act1[1:])
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	act2, _, _, err := responder.WriteMessage([]byte{0}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "0002466d7fcae563e5cb09a0d1870bb580344804617879a14949cf22285f1bae3f276e2470b93aac583c9ef6eafca3f730ae",
+		hex.EncodeToString(act2))
+	_, _, _, err = initiator.ReadMessage(nil, act2[1:])
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	act3, sk, rk, err := initiator.WriteMessage([]byte{0}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "00b9e3a702e93e3a9948c2ed6e5fd7590a6e1c3a0344cfc9d5b57357049aa22355361aa02e55a8fc28fef5bd6d71ad0c38228dc68b1c466263b47fdf31e560e139ba",
+		hex.EncodeToString(act3))
+	assert.Equal(t, "969ab31b4d288cedf6218839b27a3e2140827047f2c0f01bf5c04435d43511a9", hex.EncodeToString(sk.k[:]))
+	assert.Equal(t, "bb9020b8965f4df047e07f955f3c4b88418984aadc5cdb35096b9ea8fa5c3442", hex.EncodeToString(rk.k[:]))
+
+	_, c1, c2, err := responder.ReadMessage(nil, act3[1:])
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, sk.k, c1.k)
+	assert.Equal(t, rk.k, c2.k)
+	assert.True(t, responder.RemoteStatic().Equals(initiatorStatic.PublicKey))
+	assert.Equal(t, initiator.HandshakeHash(), responder.HandshakeHash())
+}
+
+func TestNoiseIK(t *testing.T) {
+	skipUnapproved(t)
+
+	alice, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	bob, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	initiator, err := NewHandshakeState(NoiseConfig{Pattern: NoiseIK, Initiator: true, StaticKey: alice, RemoteStatic: bob.PublicKey})
+	if !assert.NoError(t, err) {
+		return
+	}
+	responder, err := NewHandshakeState(NoiseConfig{Pattern: NoiseIK, StaticKey: bob})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Writing out of turn fails
+	_, _, _, err = responder.WriteMessage(nil, nil)
+	assert.Error(t, err)
+
+	msg1, _, _, err := initiator.WriteMessage(nil, []byte("hello"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	payload, _, _, err := responder.ReadMessage(nil, msg1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "hello", string(payload))
+	assert.True(t, responder.RemoteStatic().Equals(alice.PublicKey))
+
+	msg2, r1, r2, err := responder.WriteMessage(nil, []byte("welcome"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	payload, i1, i2, err := initiator.ReadMessage(nil, msg2)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "welcome", string(payload))
+	assert.True(t, initiator.Complete() && responder.Complete())
+	assert.Equal(t, initiator.HandshakeHash(), responder.HandshakeHash())
+
+	// Transport messages in both directions
+	ct, err := i1.Encrypt(nil, nil, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+	pt, err := r1.Decrypt(nil, nil, ct)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(pt))
+
+	ct, err = r2.Encrypt(nil, []byte("ad"), []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = i2.Decrypt(nil, nil, ct)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+	pt, err = i2.Decrypt(nil, []byte("ad"), ct)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(pt))
+
+	// Replayed message fails as nonce advanced
+	_, err = i2.Decrypt(nil, []byte("ad"), ct)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+
+	// Both sides rekey at the same message
+	i1.Rekey()
+	r1.Rekey()
+	ct, err = i1.Encrypt(nil, nil, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = r1.Decrypt(nil, nil, ct)
+	assert.NoError(t, err)
+
+	_, _, _, err = initiator.WriteMessage(nil, nil)
+	assert.Error(t, err)
+}
+
+func TestNoiseX(t *testing.T) {
+	skipUnapproved(t)
+
+	alice, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	bob, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	mallory, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	initiator, err := NewHandshakeState(NoiseConfig{Pattern: NoiseX, Initiator: true, Prologue: []byte("v1"), StaticKey: alice, RemoteStatic: bob.PublicKey})
+	if !assert.NoError(t, err) {
+		return
+	}
+	msg, c1, _, err := initiator.WriteMessage(nil, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotNil(t, c1)
+
+	responder, err := NewHandshakeState(NoiseConfig{Pattern: NoiseX, Prologue: []byte("v1"), StaticKey: bob})
+	if !assert.NoError(t, err) {
+		return
+	}
+	payload, r1, _, err := responder.ReadMessage(nil, msg)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(payload))
+	assert.True(t, responder.RemoteStatic().Equals(alice.PublicKey))
+	assert.Equal(t, c1.k, r1.k)
+
+	// Other responder key, prologue or tampered message fail
+	for _, config := range []NoiseConfig{
+		{Pattern: NoiseX, Prologue: []byte("v1"), StaticKey: mallory},
+		{Pattern: NoiseX, Prologue: []byte("v2"), StaticKey: bob},
+	} {
+		hs, err := NewHandshakeState(config)
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, _, _, err = hs.ReadMessage(nil, msg)
+		assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+	}
+
+	tampered := append([]byte(nil), msg...)
+	tampered[len(tampered)-1] ^= 1
+	hs, err := NewHandshakeState(NoiseConfig{Pattern: NoiseX, Prologue: []byte("v1"), StaticKey: bob})
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, _, _, err = hs.ReadMessage(nil, tampered)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+
+	hs, err = NewHandshakeState(NoiseConfig{Pattern: NoiseX, StaticKey: bob})
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, _, _, err = hs.ReadMessage(nil, msg[:20])
+	assert.True(t, errors.Is(err, ErrNoiseHandshake), err)
+}
+
+func TestNewHandshakeStateInvalid(t *testing.T) {
+	skipUnapproved(t)
+
+	alice, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	p256, err := GenerateKeyCurve(elliptic.P256())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, config := range []NoiseConfig{
+		{StaticKey: alice},
+		{Pattern: NoiseIK},
+		{Pattern: NoiseIK, StaticKey: p256},
+		{Pattern: NoiseIK, Initiator: true, StaticKey: alice},
+		{Pattern: NoiseIK, Initiator: true, StaticKey: alice, RemoteStatic: p256.PublicKey},
+	} {
+		_, err := NewHandshakeState(config)
+		assert.Error(t, err)
+	}
+}
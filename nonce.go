@@ -0,0 +1,99 @@
+package eciesgo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sync/atomic"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// NonceSource generates nonces for single-shot symmetric encryption (Encrypt, EncryptSymm);
+// streams and sessions use their own nonce schemes
+type NonceSource interface {
+	// Nonce fills nonce for a message encrypted with key
+	Nonce(key, nonce []byte) error
+}
+
+// WithNonceSource returns copy of config with nonce source set, random nonces (see WithRand) are used by default
+func (c Config) WithNonceSource(source NonceSource) Config {
+	c.nonceSource = source
+	return c
+}
+
+// RandomNonceSource reads nonces from crypto/rand or the reader set with SetRandReader
+var RandomNonceSource NonceSource = randomNonceSource{}
+
+type randomNonceSource struct{}
+
+func (randomNonceSource) Nonce(_, nonce []byte) error {
+	if err := randomBytes(nonce); err != nil {
+		return fmt.Errorf("cannot read random bytes for nonce: %w", err)
+	}
+
+	return nil
+}
+
+// DeterministicNonceSource derives nonce from the key with HKDF-SHA256;
+// it is safe only if every key encrypts a single message, which holds for ECIES ciphertexts
+// (each one has fresh ephemeral key), but not for EncryptSymm with a reused key
+var DeterministicNonceSource NonceSource = deterministicNonceSource{}
+
+type deterministicNonceSource struct{}
+
+func (deterministicNonceSource) Nonce(key, nonce []byte) error {
+	if _, err := io.ReadFull(hkdf.New(sha256.New, key, nil, []byte("ecies/nonce")), nonce); err != nil {
+		return fmt.Errorf("cannot read nonce from HKDF reader: %w", err)
+	}
+
+	return nil
+}
+
+// CounterNonceSource produces nonces consisting of a key commitment prefix (HMAC-SHA256 of the key)
+// and 8 bytes big endian message counter, so nonces never repeat under the same key regardless of birthday bound;
+// one source must be shared by all senders using the same key, it is safe for concurrent use
+type CounterNonceSource struct {
+	counter uint64
+}
+
+// NewCounterNonceSource returns counter nonce source starting from zero
+func NewCounterNonceSource() *CounterNonceSource {
+	return &CounterNonceSource{}
+}
+
+func (s *CounterNonceSource) Nonce(key, nonce []byte) error {
+	if len(nonce) < 12 {
+		return fmt.Errorf("nonce is too short for counter nonce source: %d", len(nonce))
+	}
+
+	counter := atomic.AddUint64(&s.counter, 1) - 1
+	if counter == math.MaxUint64 {
+		return fmt.Errorf("nonce counter is exhausted")
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("ecies/nonce-prefix"))
+	prefixLength := copy(nonce[:len(nonce)-8], mac.Sum(nil))
+	for i := prefixLength; i < len(nonce)-8; i++ {
+		nonce[i] = 0
+	}
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], counter)
+
+	return nil
+}
+
+// generateNonce fills nonce with the config nonce source
+func generateNonce(conf Config, key, nonce []byte) error {
+	if conf.nonceSource == nil {
+		if err := randomBytesConf(conf, nonce); err != nil {
+			return fmt.Errorf("cannot read random bytes for nonce: %w", err)
+		}
+		return nil
+	}
+
+	return conf.nonceSource.Nonce(key, nonce)
+}
@@ -0,0 +1,75 @@
+package eciesgo
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNonceSources(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, source := range []NonceSource{RandomNonceSource, DeterministicNonceSource, NewCounterNonceSource()} {
+		for _, conf := range []Config{DEFAULT_CONFIG, NewConfig("aes-256-gcm", 12), NewConfig("xchacha20", 0)} {
+			conf = conf.WithNonceSource(source)
+
+			ciphertext, err := EncryptConf(privkey.PublicKey, []byte(testingMessage), conf)
+			if !assert.NoError(t, err) {
+				return
+			}
+			plaintext, err := DecryptConf(privkey, ciphertext, conf)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, testingMessage, string(plaintext))
+		}
+	}
+}
+
+func TestCounterNonceSource(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	source := NewCounterNonceSource()
+
+	n1, n2 := make([]byte, 12), make([]byte, 12)
+	if !assert.NoError(t, source.Nonce(key, n1)) || !assert.NoError(t, source.Nonce(key, n2)) {
+		return
+	}
+	assert.Equal(t, n1[:4], n2[:4])
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 0}, n1[4:])
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 1}, n2[4:])
+
+	// Prefix commits to the key
+	n3 := make([]byte, 12)
+	if !assert.NoError(t, source.Nonce(bytes.Repeat([]byte{2}, 32), n3)) {
+		return
+	}
+	assert.NotEqual(t, n1[:4], n3[:4])
+
+	assert.Error(t, source.Nonce(key, make([]byte, 8)))
+}
+
+func TestDeterministicNonceSource(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+
+	n1, n2 := make([]byte, 24), make([]byte, 24)
+	if !assert.NoError(t, DeterministicNonceSource.Nonce(key, n1)) || !assert.NoError(t, DeterministicNonceSource.Nonce(key, n2)) {
+		return
+	}
+	assert.Equal(t, n1, n2)
+
+	ct1, err := EncryptSymm(key, []byte(testingMessage), DEFAULT_CONFIG.WithNonceSource(DeterministicNonceSource))
+	if !assert.NoError(t, err) {
+		return
+	}
+	ct2, err := EncryptSymm(key, []byte(testingMessage), DEFAULT_CONFIG.WithNonceSource(DeterministicNonceSource))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, ct1, ct2)
+}
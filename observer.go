@@ -0,0 +1,101 @@
+package eciesgo
+
+import (
+	"crypto/elliptic"
+	"time"
+)
+
+// Operation types reported to Observer
+const (
+	OperationEncrypt = "encrypt"
+	OperationDecrypt = "decrypt"
+	// OperationGenerateKey is reported by GenerateKeyContext
+	OperationGenerateKey = "generate_key"
+)
+
+// Operation describes finished encryption, decryption or key generation; it holds no keys, secrets or message content
+type Operation struct {
+	// Type is OperationEncrypt, OperationDecrypt or OperationGenerateKey
+	Type string
+	// Curve is the registered name of the key curve (see CurveName), empty if it is not registered
+	Curve string
+	// Cipher is the configured symmetric algorithm, "hpke" in HPKE mode; empty for key generation
+	Cipher string
+	// Size is the input length: plaintext for encryption, ciphertext for decryption, 0 for key generation
+	Size int
+	// Duration is the time the operation took
+	Duration time.Duration
+	// Err is the returned error, nil on success
+	Err error
+}
+
+// Observer receives reports of Encrypt and Decrypt family calls (including Conf, Append, InPlace and
+// WithEphemeral variants), Session.Encrypt and GenerateKeyContext made with config set with WithObserver;
+// set it on the default
+// config with SetDefaultConfig to observe calls without config argument. Observe is called synchronously
+// and concurrently, so it must be fast and safe for concurrent use
+type Observer interface {
+	Observe(op Operation)
+}
+
+// ObserverFunc is an adapter to allow the use of ordinary functions as Observer
+type ObserverFunc func(op Operation)
+
+// Observe calls f(op)
+func (f ObserverFunc) Observe(op Operation) {
+	f(op)
+}
+
+// WithObserver returns copy of config reporting operations to the observer, nil disables reporting
+func (c Config) WithObserver(o Observer) Config {
+	c.observer = o
+	return c
+}
+
+// observe reports finished operation to the config observer; it is deferred with the operation start time
+// and pointer to the returned error
+func (c Config) observe(op string, curve elliptic.Curve, size int, start time.Time, err *error) {
+	if c.observer == nil {
+		return
+	}
+
+	var name string
+	if curve != nil {
+		name, _ = CurveName(curve)
+	}
+
+	cipher := c.symmetricAlgorithm
+	if c.hpke != nil {
+		cipher = "hpke"
+	}
+	if op == OperationGenerateKey {
+		cipher = ""
+	}
+
+	c.observer.Observe(Operation{
+		Type:     op,
+		Curve:    name,
+		Cipher:   cipher,
+		Size:     size,
+		Duration: time.Since(start),
+		Err:      *err,
+	})
+}
+
+// keyCurve returns curve of the public key, nil for empty key
+func keyCurve(k *PublicKey) elliptic.Curve {
+	if k == nil {
+		return nil
+	}
+
+	return k.Curve
+}
+
+// privateKeyCurve returns curve of the private key, nil for empty key
+func privateKeyCurve(k *PrivateKey) elliptic.Curve {
+	if k == nil {
+		return nil
+	}
+
+	return keyCurve(k.PublicKey)
+}
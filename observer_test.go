@@ -0,0 +1,104 @@
+package eciesgo
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_WithObserver(t *testing.T) {
+	skipUnapproved(t)
+
+	var (
+		mu  sync.Mutex
+		ops []Operation
+	)
+	config := DEFAULT_CONFIG.WithObserver(ObserverFunc(func(op Operation) {
+		mu.Lock()
+		defer mu.Unlock()
+		ops = append(ops, op)
+	}))
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ct, err := EncryptConf(privkey.PublicKey, []byte(testingMessage), config)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = DecryptConf(privkey, ct, config)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ct[len(ct)-1] ^= 1
+	_, err = DecryptConf(privkey, ct, config)
+	assert.Error(t, err)
+	_, err = DecryptWithConf(DecapsulatorFunc(privkey.SharedPoint), ct[:10], config)
+	assert.Error(t, err)
+
+	p256, err := GenerateKeyCurve(elliptic.P256())
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = EncryptConf(p256.PublicKey, nil, config.WithCurve("P-256"))
+	assert.NoError(t, err)
+
+	if !assert.Len(t, ops, 5) {
+		return
+	}
+
+	assert.Equal(t, OperationEncrypt, ops[0].Type)
+	assert.Equal(t, "secp256k1", ops[0].Curve)
+	assert.Equal(t, "aes-256-gcm", ops[0].Cipher)
+	assert.Equal(t, len(testingMessage), ops[0].Size)
+	assert.NoError(t, ops[0].Err)
+
+	assert.Equal(t, OperationDecrypt, ops[1].Type)
+	assert.Equal(t, len(ct), ops[1].Size)
+	assert.NoError(t, ops[1].Err)
+
+	assert.True(t, errors.Is(ops[2].Err, ErrAuthenticationFailed), ops[2].Err)
+	assert.True(t, errors.Is(ops[3].Err, ErrCiphertextTooShort), ops[3].Err)
+	assert.Equal(t, "P-256", ops[4].Curve)
+
+	// Without observer nothing is reported
+	_, err = Encrypt(privkey.PublicKey, []byte(testingMessage))
+	assert.NoError(t, err)
+	assert.Len(t, ops, 5)
+}
+
+func TestSession_Observer(t *testing.T) {
+	var ops []Operation
+	config := DEFAULT_CONFIG.WithObserver(ObserverFunc(func(op Operation) {
+		ops = append(ops, op)
+	})).WithMaxMessageSize(len(testingMessage))
+
+	privkey, err := GenerateKeyCurve(elliptic.P256())
+	if !assert.NoError(t, err) {
+		return
+	}
+	s, err := NewSessionConf(privkey.PublicKey, config)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = s.Encrypt([]byte(testingMessage))
+	assert.NoError(t, err)
+	_, err = s.Encrypt([]byte(testingMessage + "!"))
+	assert.True(t, errors.Is(err, ErrMessageTooLarge), err)
+
+	if !assert.Len(t, ops, 2) {
+		return
+	}
+	assert.Equal(t, OperationEncrypt, ops[0].Type)
+	assert.Equal(t, "P-256", ops[0].Curve)
+	assert.Equal(t, "aes-256-gcm", ops[0].Cipher)
+	assert.Equal(t, len(testingMessage), ops[0].Size)
+	assert.NoError(t, ops[0].Err)
+	assert.True(t, errors.Is(ops[1].Err, ErrMessageTooLarge), ops[1].Err)
+}
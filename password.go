@@ -0,0 +1,55 @@
+package eciesgo
+
+import (
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Password-encrypted message has encrypted key layout (see PrivateKey.Export) with its own magic,
+// sealed message follows the header
+var passwordMessageMagic = []byte("ECIP")
+
+// EncryptWithPassword encrypts a passed message with a key derived from password with Argon2id
+// (DEFAULT_ARGON2ID_PARAMS); KDF parameters, salt and nonce are stored in the ciphertext
+func EncryptWithPassword(password, msg []byte) ([]byte, error) {
+	return EncryptWithPasswordConf(password, msg, DefaultConfig())
+}
+
+// EncryptWithPasswordConf encrypts a passed message with a key derived from password with memory-hard KDF
+// of the passed config (see Config.WithPasswordKDF), Argon2id is used if config KDF is not memory-hard;
+// message is sealed with XChaCha20-Poly1305 regardless of the config cipher
+func EncryptWithPasswordConf(password, msg []byte, config Config) ([]byte, error) {
+	if config.hpke != nil || config.envelope {
+		return nil, fmt.Errorf("password-based encryption is not supported in HPKE and envelope modes")
+	}
+
+	params := DEFAULT_ARGON2ID_PARAMS
+	if config.kdfFunction == "scrypt" || config.kdfFunction == "argon2id" {
+		params = passwordKDFParams(config)
+	}
+
+	header, aead, err := newPasswordHeader(passwordMessageMagic, encryptedKeyVersion, password, params, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(header, header[encryptedKeyHeaderLength-chacha20poly1305.NonceSizeX:], msg, header), nil
+}
+
+// DecryptWithPassword decrypts a message encrypted with EncryptWithPassword or EncryptWithPasswordConf;
+// KDF parameters are read from the ciphertext and checked against the same limits as encrypted keys
+func DecryptWithPassword(password, msg []byte) ([]byte, error) {
+	header, aead, err := openPasswordHeader(passwordMessageMagic, encryptedKeyVersion, msg, password, ErrCiphertextTooShort, "password-encrypted message")
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := header[encryptedKeyHeaderLength-chacha20poly1305.NonceSizeX:]
+	plaintext, err := aead.Open(nil, nonce, msg[encryptedKeyHeaderLength:], header)
+	if err != nil {
+		return nil, fmt.Errorf("%w: wrong password or corrupted message", ErrAuthenticationFailed)
+	}
+
+	return plaintext, nil
+}
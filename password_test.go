@@ -0,0 +1,100 @@
+package eciesgo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptWithPassword(t *testing.T) {
+	skipUnapproved(t)
+
+	password := []byte("correct horse battery staple")
+
+	for _, conf := range []Config{
+		DEFAULT_CONFIG.WithPasswordKDF(PasswordKDFParams{Algorithm: "argon2id", Time: 1, Memory: 64, Threads: 1}),
+		DEFAULT_CONFIG.WithPasswordKDF(PasswordKDFParams{Algorithm: "scrypt", N: 1 << 10, R: 8, P: 1}),
+	} {
+		ciphertext, err := EncryptWithPasswordConf(password, []byte(testingMessage), conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, ciphertext, encryptedKeyHeaderLength+len(testingMessage)+16)
+
+		// KDF parameters are read from the ciphertext
+		plaintext, err := DecryptWithPassword(password, ciphertext)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(plaintext))
+
+		_, err = DecryptWithPassword([]byte("wrong"), ciphertext)
+		assert.True(t, errors.Is(err, ErrAuthenticationFailed), "%v", err)
+
+		tampered := append([]byte(nil), ciphertext...)
+		tampered[20] ^= 1
+		_, err = DecryptWithPassword(password, tampered)
+		assert.True(t, errors.Is(err, ErrAuthenticationFailed), "%v", err)
+	}
+
+	// Encrypted keys are not accepted as messages
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	blob, err := privkey.Export(password, testingArgon2Params)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = DecryptWithPassword(password, blob)
+	assert.True(t, errors.Is(err, ErrCiphertextTooShort), "%v", err)
+	_, err = DecryptWithPassword(password, blob[:10])
+	assert.True(t, errors.Is(err, ErrCiphertextTooShort), "%v", err)
+
+	_, err = EncryptWithPasswordConf(password, []byte(testingMessage), DEFAULT_CONFIG.WithEnvelope())
+	assert.Error(t, err)
+}
+
+func TestPasswordKDF(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	conf := NewConfig("xchacha20", 0).
+		WithPasswordKDF(PasswordKDFParams{Algorithm: "argon2id", Time: 1, Memory: 64, Threads: 1}).
+		WithKDFSalt([]byte("salt"))
+
+	ciphertext, err := EncryptConf(privkey.PublicKey, []byte(testingMessage), conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	plaintext, err := DecryptConf(privkey, ciphertext, conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(plaintext))
+
+	// Keys from low-entropy secrets
+	keys, err := DeriveKeysConf([]byte("1234"), conf, 32, 32)
+	if !assert.NoError(t, err) {
+		return
+	}
+	expected, err := derivePasswordKey([]byte("1234"), []byte("salt"), *conf.passwordKDF, 64)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, expected, append(keys[0], keys[1]...))
+
+	// WithKDF alone uses default parameters
+	assert.Equal(t, DEFAULT_SCRYPT_PARAMS, passwordKDFParams(DEFAULT_CONFIG.WithKDF("scrypt")))
+	assert.Equal(t, DEFAULT_ARGON2ID_PARAMS, passwordKDFParams(DEFAULT_CONFIG.WithKDF("argon2id")))
+
+	// Salt is required
+	_, err = EncryptConf(privkey.PublicKey, []byte(testingMessage), conf.WithKDFSalt(nil))
+	assert.Error(t, err)
+	_, err = EncryptConf(privkey.PublicKey, []byte(testingMessage), conf.WithEnvelope())
+	assert.True(t, errors.Is(err, ErrUnsupportedKDF), "%v", err)
+}
@@ -0,0 +1,75 @@
+package eciesgo
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Add returns validated sum of two public keys (points);
+// note that it shadows Add method of the nested elliptic.Curve, use k.Curve.Add for raw coordinates
+func (k *PublicKey) Add(other *PublicKey) (*PublicKey, error) {
+	if err := k.validatePoint(other); err != nil {
+		return nil, err
+	}
+
+	x, y := k.Curve.Add(k.X, k.Y, other.X, other.Y)
+	return k.newPoint(x, y)
+}
+
+// ScalarMult returns validated product of the public key (point) and big endian scalar;
+// scalar must be in [1, n-1] range. Multiplication runs in constant time without CGO.
+// Note that it shadows ScalarMult method of the nested elliptic.Curve, use k.Curve.ScalarMult for raw coordinates
+func (k *PublicKey) ScalarMult(scalar []byte) (*PublicKey, error) {
+	if err := k.validatePoint(k); err != nil {
+		return nil, err
+	}
+
+	n := k.Curve.Params().N
+	if s := new(big.Int).SetBytes(scalar); s.Sign() == 0 || s.Cmp(n) >= 0 {
+		return nil, fmt.Errorf("scalar is out of range")
+	}
+
+	x, y := scalarMult(k.Curve, k.X, k.Y, zeroPad(trimLeadingZeros(scalar), len(n.Bytes())))
+	return k.newPoint(x, y)
+}
+
+// Negate returns public key (point) with negated Y coordinate
+func (k *PublicKey) Negate() *PublicKey {
+	y := new(big.Int).Sub(k.Curve.Params().P, k.Y)
+	y.Mod(y, k.Curve.Params().P)
+
+	return &PublicKey{
+		Curve: k.Curve,
+		X:     new(big.Int).Set(k.X),
+		Y:     y,
+	}
+}
+
+// validatePoint checks that both the key and other are valid public keys
+func (k *PublicKey) validatePoint(other *PublicKey) error {
+	if err := k.Validate(); err != nil {
+		return err
+	}
+
+	return other.Validate()
+}
+
+// newPoint wraps operation result into PublicKey, point at infinity is not a valid public key
+func (k *PublicKey) newPoint(x, y *big.Int) (*PublicKey, error) {
+	if x == nil || y == nil || (x.Sign() == 0 && y.Sign() == 0) {
+		return nil, fmt.Errorf("result is point at infinity")
+	}
+
+	return &PublicKey{
+		Curve: k.Curve,
+		X:     x,
+		Y:     y,
+	}, nil
+}
+
+func trimLeadingZeros(b []byte) []byte {
+	for len(b) > 0 && b[0] == 0 {
+		b = b[1:]
+	}
+	return b
+}
@@ -0,0 +1,64 @@
+package eciesgo
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicKeyArithmetic(t *testing.T) {
+	skipUnapproved(t)
+
+	a, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	b, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// (a + b) G = aG + bG
+	n := getCurve().Params().N
+	sum, err := NewPrivateKeyFromBytesReduced(new(big.Int).Add(a.D, b.D).Bytes())
+	if !assert.NoError(t, err) {
+		return
+	}
+	p, err := a.PublicKey.Add(b.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, sum.PublicKey.Equals(p))
+
+	// b (aG) = (a b) G
+	product, err := NewPrivateKeyFromBytesReduced(new(big.Int).Mul(a.D, b.D).Bytes())
+	if !assert.NoError(t, err) {
+		return
+	}
+	p, err = a.PublicKey.ScalarMult(b.Bytes())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, product.PublicKey.Equals(p))
+
+	// -aG = (n - a) G
+	neg, err := NewPrivateKeyFromBytes(zeroPad(new(big.Int).Sub(n, a.D).Bytes(), 32))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, neg.PublicKey.Equals(a.PublicKey.Negate()))
+
+	// aG + (-aG) is infinity
+	_, err = a.PublicKey.Add(a.PublicKey.Negate())
+	assert.Error(t, err)
+
+	_, err = a.PublicKey.ScalarMult(n.Bytes())
+	assert.Error(t, err)
+	_, err = a.PublicKey.ScalarMult(nil)
+	assert.Error(t, err)
+
+	invalid := &PublicKey{Curve: getCurve(), X: big.NewInt(1), Y: big.NewInt(1)}
+	_, err = a.PublicKey.Add(invalid)
+	assert.Error(t, err)
+}
@@ -0,0 +1,295 @@
+package eciesgo
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+)
+
+// Proxy re-encryption ciphertexts start with a capsule, followed by nonce, tag and ciphertext of symmetric encryption.
+// Original capsule: 0x01, E (33 bytes), V (33 bytes), s (32 bytes), where E = rG, V = uG, s = u + r H(E, V);
+// re-encrypted capsule: 0x02, E' = rk E (33 bytes), V' = rk V (33 bytes) and delegation public key X (33 bytes).
+// Symmetric key is KDF((r + u) PK) for the delegator public key PK
+const (
+	preOriginal    = 0x01
+	preReencrypted = 0x02
+
+	preCapsuleLength            = 1 + 33 + 33 + 32
+	preReencryptedCapsuleLength = 1 + 33 + 33 + 33
+)
+
+// ReencryptionKey allows a proxy to transform ciphertexts encrypted with EncryptReencryptable to the delegator
+// into ciphertexts decryptable by the delegatee; the proxy learns neither plaintexts nor private keys,
+// but the proxy colluding with the delegatee can recover the delegator private key
+type ReencryptionKey struct {
+	RK *big.Int
+	X  *PublicKey
+}
+
+// EncryptReencryptable encrypts a message with a delegator public key so that it can later be re-encrypted
+// for a delegatee with Reencrypt; the delegator decrypts it with DecryptReencryptable
+func EncryptReencryptable(pubkey *PublicKey, msg []byte) ([]byte, error) {
+	return EncryptReencryptableConf(pubkey, msg, DefaultConfig())
+}
+
+// EncryptReencryptableConf encrypts a re-encryptable message with the passed config
+func EncryptReencryptableConf(pubkey *PublicKey, msg []byte, config Config) ([]byte, error) {
+	if config.hpke != nil || config.envelope {
+		return nil, fmt.Errorf("proxy re-encryption does not support HPKE and envelope modes")
+	}
+	if err := pubkey.Validate(); err != nil {
+		return nil, err
+	}
+	if err := checkPlaintextSize(config, len(msg)); err != nil {
+		return nil, err
+	}
+	if err := checkTrusted(config, pubkey); err != nil {
+		return nil, err
+	}
+
+	r, err := generateKey(config.random())
+	if err != nil {
+		return nil, err
+	}
+	defer r.Zeroize()
+	u, err := generateKey(config.random())
+	if err != nil {
+		return nil, err
+	}
+	defer u.Zeroize()
+
+	n := pubkey.Curve.Params().N
+	s := new(big.Int).Mul(r.D, preCapsuleHash(r.PublicKey, u.PublicKey, n))
+	s.Add(s, u.D)
+	s.Mod(s, n)
+
+	// Shared point is PK (r + u)
+	ru := new(big.Int).Add(r.D, u.D)
+	ru.Mod(ru, n)
+	defer zeroBigInt(ru)
+	if ru.Sign() == 0 {
+		return nil, fmt.Errorf("capsule scalar is zero")
+	}
+	kx, ky := scalarMult(pubkey.Curve, pubkey.X, pubkey.Y, zeroPad(ru.Bytes(), len(n.Bytes())))
+
+	key, err := preKey(pubkey.Curve.Params().P, kx, ky, config)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(key)
+
+	ct, err := EncryptSymm(key, msg, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.Join([][]byte{{preOriginal}, r.PublicKey.Bytes(true), u.PublicKey.Bytes(true), zeroPad(s.Bytes(), len(n.Bytes())), ct}, nil), nil
+}
+
+// GenerateReencryptionKey generates re-encryption key from delegator private key to delegatee public key
+func GenerateReencryptionKey(delegator *PrivateKey, delegatee *PublicKey) (*ReencryptionKey, error) {
+	if err := delegator.Validate(); err != nil {
+		return nil, err
+	}
+
+	x, err := GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	defer x.Zeroize()
+
+	d, err := preDelegationHash(x, delegatee, x.PublicKey, delegatee)
+	if err != nil {
+		return nil, err
+	}
+
+	n := delegator.Curve.Params().N
+	rk, err := secretInverse(delegator.Curve, d)
+	if err != nil {
+		return nil, err
+	}
+	rk.Mul(rk, delegator.D)
+	rk.Mod(rk, n)
+
+	return &ReencryptionKey{RK: rk, X: x.PublicKey}, nil
+}
+
+// Reencrypt transforms ciphertext produced by EncryptReencryptable to the delegator into ciphertext
+// for the delegatee; capsule is verified, so the proxy doesn't transform malformed ciphertexts
+func Reencrypt(rk *ReencryptionKey, msg []byte) ([]byte, error) {
+	if rk == nil || rk.RK == nil || rk.RK.Sign() == 0 {
+		return nil, fmt.Errorf("re-encryption key is empty")
+	}
+	if err := rk.X.Validate(); err != nil {
+		return nil, err
+	}
+
+	e, v, err := parseCapsule(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	curve := e.Curve
+	l := len(curve.Params().N.Bytes())
+	ex, ey := scalarMult(curve, e.X, e.Y, zeroPad(rk.RK.Bytes(), l))
+	vx, vy := scalarMult(curve, v.X, v.Y, zeroPad(rk.RK.Bytes(), l))
+	e2, err := e.newPoint(ex, ey)
+	if err != nil {
+		return nil, err
+	}
+	v2, err := v.newPoint(vx, vy)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.Join([][]byte{{preReencrypted}, e2.Bytes(true), v2.Bytes(true), rk.X.Bytes(true), msg[preCapsuleLength:]}, nil), nil
+}
+
+// DecryptReencryptable decrypts both original ciphertexts with the delegator private key
+// and re-encrypted ones with the delegatee private key
+func DecryptReencryptable(privkey *PrivateKey, msg []byte) ([]byte, error) {
+	return DecryptReencryptableConf(privkey, msg, DefaultConfig())
+}
+
+// DecryptReencryptableConf decrypts a re-encryptable message with the passed config
+func DecryptReencryptableConf(privkey *PrivateKey, msg []byte, config Config) ([]byte, error) {
+	if err := privkey.Validate(); err != nil {
+		return nil, err
+	}
+	if len(msg) == 0 {
+		return nil, newParseError(ErrCiphertextTooShort, "capsule", 0, "message is empty")
+	}
+
+	// Size is checked before capsule verification and key agreement
+	capsuleLength := preCapsuleLength
+	if msg[0] == preReencrypted {
+		capsuleLength = preReencryptedCapsuleLength
+	}
+	if err := checkSymmCiphertextSize(config, len(msg)-capsuleLength); err != nil {
+		return nil, err
+	}
+
+	curve := privkey.Curve
+	l := len(curve.Params().N.Bytes())
+
+	var (
+		e, v   *PublicKey
+		scalar *big.Int
+		body   []byte
+		err    error
+	)
+
+	switch msg[0] {
+	case preOriginal:
+		if e, v, err = parseCapsule(msg); err != nil {
+			return nil, err
+		}
+		scalar, body = new(big.Int).Set(privkey.D), msg[preCapsuleLength:]
+	case preReencrypted:
+		if len(msg) < preReencryptedCapsuleLength {
+			return nil, newParseError(ErrCiphertextTooShort, "capsule", len(msg), "capsule is truncated")
+		}
+
+		points := make([]*PublicKey, 3)
+		for i := range points {
+			if points[i], err = NewPublicKeyFromBytes(msg[1+33*i : 1+33*(i+1)]); err != nil {
+				return nil, err
+			}
+		}
+		e, v = points[0], points[1]
+
+		if scalar, err = preDelegationHash(privkey, points[2], points[2], privkey.PublicKey); err != nil {
+			return nil, err
+		}
+		body = msg[preReencryptedCapsuleLength:]
+	default:
+		return nil, newParseError(ErrInvalidCapsule, "capsule", 0, fmt.Sprintf("unknown capsule type %d", msg[0]))
+	}
+	defer zeroBigInt(scalar)
+
+	// Shared point is scalar (E + V)
+	sx, sy := curve.Add(e.X, e.Y, v.X, v.Y)
+	if _, err := e.newPoint(sx, sy); err != nil {
+		return nil, err
+	}
+	kx, ky := scalarMult(curve, sx, sy, zeroPad(scalar.Bytes(), l))
+
+	key, err := preKey(curve.Params().P, kx, ky, config)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(key)
+
+	return DecryptSymm(key, body, config)
+}
+
+// parseCapsule parses original capsule and verifies that sG = V + H(E, V) E
+func parseCapsule(msg []byte) (e, v *PublicKey, err error) {
+	if len(msg) < preCapsuleLength {
+		return nil, nil, newParseError(ErrCiphertextTooShort, "capsule", len(msg), "capsule is truncated")
+	}
+	if msg[0] != preOriginal {
+		return nil, nil, newParseError(ErrInvalidCapsule, "capsule", 0, "capsule is already re-encrypted")
+	}
+
+	if e, err = NewPublicKeyFromBytes(msg[1:34]); err != nil {
+		return nil, nil, err
+	}
+	if v, err = NewPublicKeyFromBytes(msg[34:67]); err != nil {
+		return nil, nil, err
+	}
+
+	curve := e.Curve
+	n := curve.Params().N
+	s := new(big.Int).SetBytes(msg[67:preCapsuleLength])
+	if s.Cmp(n) >= 0 {
+		return nil, nil, newParseError(ErrInvalidCapsule, "capsule", 67, "scalar is out of range")
+	}
+
+	lx, ly := curve.ScalarBaseMult(msg[67:preCapsuleLength])
+	hx, hy := curve.ScalarMult(e.X, e.Y, zeroPad(preCapsuleHash(e, v, n).Bytes(), len(n.Bytes())))
+	rx, ry := curve.Add(v.X, v.Y, hx, hy)
+	if lx.Cmp(rx) != 0 || ly.Cmp(ry) != 0 {
+		return nil, nil, fmt.Errorf("%w: capsule check failed", ErrInvalidCapsule)
+	}
+
+	return e, v, nil
+}
+
+// preCapsuleHash hashes capsule points to scalar
+func preCapsuleHash(e, v *PublicKey, n *big.Int) *big.Int {
+	h := new(big.Int).SetBytes(taggedHash("ECIES/PRE/capsule", e.Bytes(true), v.Bytes(true)))
+	return h.Mod(h, n)
+}
+
+// preDelegationHash hashes delegation public key X, delegatee public key and their shared point to non-zero scalar;
+// the shared point is computed by priv and peer: either delegation private key and delegatee, or delegatee and X
+func preDelegationHash(priv *PrivateKey, peer, x, delegatee *PublicKey) (*big.Int, error) {
+	sx, sy, err := priv.sharedPoint(peer)
+	if err != nil {
+		return nil, err
+	}
+	shared := &PublicKey{Curve: priv.Curve, X: sx, Y: sy}
+
+	n := priv.Curve.Params().N
+	d := new(big.Int).SetBytes(taggedHash("ECIES/PRE/delegation", x.Bytes(true), delegatee.Bytes(true), shared.Bytes(true)))
+	d.Mod(d, n)
+	if d.Sign() == 0 {
+		return nil, fmt.Errorf("delegation scalar is zero")
+	}
+
+	return d, nil
+}
+
+// preKey derives symmetric key from the shared point
+func preKey(p, x, y *big.Int, config Config) ([]byte, error) {
+	if x == nil || y == nil || (x.Sign() == 0 && y.Sign() == 0) {
+		return nil, fmt.Errorf("%w: shared point is at infinity", ErrInvalidPublicKey)
+	}
+
+	l := len(p.Bytes())
+	secret := bytes.Join([][]byte{{0x04}, zeroPad(x.Bytes(), l), zeroPad(y.Bytes(), l)}, nil)
+	defer zeroBytes(secret)
+
+	return kdf(secret, config)
+}
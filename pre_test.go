@@ -0,0 +1,107 @@
+package eciesgo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxyReencryption(t *testing.T) {
+	skipUnapproved(t)
+
+	alice, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	bob, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	carol, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, conf := range []Config{DEFAULT_CONFIG, NewConfig("xchacha20", 0)} {
+		ct, err := EncryptReencryptableConf(alice.PublicKey, []byte(testingMessage), conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		pt, err := DecryptReencryptableConf(alice, ct, conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(pt))
+
+		_, err = DecryptReencryptableConf(bob, ct, conf)
+		assert.True(t, errors.Is(err, ErrAuthenticationFailed), "%v", err)
+
+		rk, err := GenerateReencryptionKey(alice, bob.PublicKey)
+		if !assert.NoError(t, err) {
+			return
+		}
+		reencrypted, err := Reencrypt(rk, ct)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		pt, err = DecryptReencryptableConf(bob, reencrypted, conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(pt))
+
+		for _, k := range []*PrivateKey{alice, carol} {
+			_, err = DecryptReencryptableConf(k, reencrypted, conf)
+			assert.True(t, errors.Is(err, ErrAuthenticationFailed), "%v", err)
+		}
+
+		// Re-encrypted ciphertext can't be re-encrypted again
+		_, err = Reencrypt(rk, reencrypted)
+		assert.True(t, errors.Is(err, ErrInvalidCapsule), "%v", err)
+	}
+}
+
+func TestReencryptInvalidCapsule(t *testing.T) {
+	skipUnapproved(t)
+
+	alice, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	bob, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ct, err := EncryptReencryptable(alice.PublicKey, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+	rk, err := GenerateReencryptionKey(alice, bob.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Capsule scalar is verified
+	tampered := append([]byte(nil), ct...)
+	tampered[preCapsuleLength-1] ^= 1
+	_, err = Reencrypt(rk, tampered)
+	assert.True(t, errors.Is(err, ErrInvalidCapsule), "%v", err)
+	_, err = DecryptReencryptable(alice, tampered)
+	assert.True(t, errors.Is(err, ErrInvalidCapsule), "%v", err)
+
+	for _, msg := range [][]byte{nil, ct[:preCapsuleLength-1], append([]byte{0x03}, ct[1:]...)} {
+		_, err = Reencrypt(rk, msg)
+		assert.Error(t, err)
+		_, err = DecryptReencryptable(alice, msg)
+		assert.Error(t, err)
+	}
+
+	_, err = Reencrypt(nil, ct)
+	assert.Error(t, err)
+	_, err = EncryptReencryptableConf(alice.PublicKey, []byte(testingMessage), DEFAULT_CONFIG.WithEnvelope())
+	assert.Error(t, err)
+}
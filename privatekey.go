@@ -2,11 +2,12 @@ package eciesgo
 
 import (
 	"bytes"
+	"crypto"
 	"crypto/elliptic"
-	"crypto/rand"
 	"crypto/subtle"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"math/big"
 )
 
@@ -18,21 +19,54 @@ type PrivateKey struct {
 
 // GenerateKey generates secp256k1 key pair
 func GenerateKey() (*PrivateKey, error) {
-	curve := getCurve()
+	if err := checkFIPSCurve(getCurve()); err != nil {
+		return nil, err
+	}
 
-	p, x, y, err := elliptic.GenerateKey(curve, rand.Reader)
-	if err != nil {
-		return nil, fmt.Errorf("cannot generate key pair: %w", err)
+	return generateKey(randReader)
+}
+
+// GenerateKeyCurve generates key pair on the passed curve, e.g. elliptic.P256() for FIPS mode;
+// Encrypt and Decrypt support NIST curve keys, while other features are secp256k1 only
+func GenerateKeyCurve(curve elliptic.Curve) (*PrivateKey, error) {
+	if curve == nil {
+		return nil, fmt.Errorf("curve is empty")
+	}
+	if err := checkFIPSCurve(curve); err != nil {
+		return nil, err
 	}
 
-	return &PrivateKey{
-		PublicKey: &PublicKey{
-			Curve: curve,
-			X:     x,
-			Y:     y,
-		},
-		D: new(big.Int).SetBytes(p),
-	}, nil
+	return generateKeyCurve(curve, randReader)
+}
+
+// generateKey generates secp256k1 key pair reading randomness from the passed reader
+func generateKey(rand io.Reader) (*PrivateKey, error) {
+	return generateKeyCurve(getCurve(), rand)
+}
+
+// generateKeyCurve generates key pair on the curve reading randomness from the passed reader;
+// scalar is sampled as elliptic.GenerateKey does, so the same randomness gives the same key,
+// but public key is computed with constant time scalarBaseMult
+func generateKeyCurve(curve elliptic.Curve, rand io.Reader) (*PrivateKey, error) {
+	n := curve.Params().N
+	bitSize := n.BitLen()
+	p := make([]byte, (bitSize+7)/8)
+	defer zeroBytes(p)
+
+	for {
+		if _, err := io.ReadFull(rand, p); err != nil {
+			return nil, fmt.Errorf("cannot generate key pair: %w", err)
+		}
+		if excess := uint(len(p)*8 - bitSize); excess > 0 {
+			p[0] &= byte(0xff >> excess)
+		}
+		p[1] ^= 0x42
+
+		d := new(big.Int).SetBytes(p)
+		if d.Sign() > 0 && d.Cmp(n) < 0 {
+			return newPrivateKey(curve, d), nil
+		}
+	}
 }
 
 // NewPrivateKeyFromHex decodes hex form of private key raw bytes, computes public key and returns PrivateKey instance
@@ -42,13 +76,53 @@ func NewPrivateKeyFromHex(s string) (*PrivateKey, error) {
 		return nil, fmt.Errorf("cannot decode hex string: %w", err)
 	}
 
-	return NewPrivateKeyFromBytes(b), nil
+	return NewPrivateKeyFromBytes(b)
+}
+
+// NewPrivateKeyFromBytes decodes private key raw bytes, computes public key and returns PrivateKey instance;
+// raw bytes must be exactly 32 bytes long and encode scalar in [1, n-1] range
+func NewPrivateKeyFromBytes(priv []byte) (*PrivateKey, error) {
+	return NewPrivateKeyFromBytesCurve(getCurve(), priv)
+}
+
+// NewPrivateKeyFromBytesCurve decodes private key raw bytes of the passed curve (e.g. elliptic.P384()),
+// which must be exactly as long as the curve order
+func NewPrivateKeyFromBytesCurve(curve elliptic.Curve, priv []byte) (*PrivateKey, error) {
+	if curve == nil {
+		return nil, fmt.Errorf("%w: curve is empty", ErrInvalidPrivateKey)
+	}
+
+	if l := len(curve.Params().N.Bytes()); len(priv) != l {
+		return nil, newParseError(ErrInvalidPrivateKey, "private key", len(priv), fmt.Sprintf("length is %d, expected %d", len(priv), l))
+	}
+
+	d := new(big.Int).SetBytes(priv)
+	if d.Sign() == 0 || d.Cmp(curve.Params().N) >= 0 {
+		return nil, newParseError(ErrInvalidPrivateKey, "private key", 0, "scalar is out of range")
+	}
+
+	return newPrivateKey(curve, d), nil
 }
 
-// NewPrivateKeyFromBytes decodes private key raw bytes, computes public key and returns PrivateKey instance
-func NewPrivateKeyFromBytes(priv []byte) *PrivateKey {
+// NewPrivateKeyFromBytesReduced decodes private key raw bytes of any length and reduces them modulo curve order;
+// returns error only if the reduced scalar is zero
+func NewPrivateKeyFromBytesReduced(priv []byte) (*PrivateKey, error) {
 	curve := getCurve()
-	x, y := curve.ScalarBaseMult(priv)
+
+	d := new(big.Int).SetBytes(priv)
+	d.Mod(d, curve.Params().N)
+	if d.Sign() == 0 {
+		return nil, fmt.Errorf("%w: scalar is zero modulo curve order", ErrInvalidPrivateKey)
+	}
+
+	return newPrivateKey(curve, d), nil
+}
+
+// newPrivateKey computes public key for the scalar and returns PrivateKey instance
+func newPrivateKey(curve elliptic.Curve, d *big.Int) *PrivateKey {
+	b := zeroPad(d.Bytes(), len(curve.Params().N.Bytes()))
+	defer zeroBytes(b)
+	x, y := scalarBaseMult(curve, b)
 
 	return &PrivateKey{
 		PublicKey: &PublicKey{
@@ -56,13 +130,13 @@ func NewPrivateKeyFromBytes(priv []byte) *PrivateKey {
 			X:     x,
 			Y:     y,
 		},
-		D: new(big.Int).SetBytes(priv),
+		D: d,
 	}
 }
 
-// Bytes returns private key raw bytes
+// Bytes returns private key raw bytes, zero padded to the curve order length
 func (k *PrivateKey) Bytes() []byte {
-	return k.D.Bytes()
+	return zeroPad(k.D.Bytes(), len(k.Curve.Params().N.Bytes()))
 }
 
 // Hex returns private key bytes in hex form
@@ -71,43 +145,54 @@ func (k *PrivateKey) Hex() string {
 }
 
 // Encapsulate encapsulates key by using Key Encapsulation Mechanism and returns symmetric key;
-// can be safely used as encryption key
+// can be safely used as encryption key. The key is KDF(sender public key || 0x04 || Sx || Sy),
+// where S is the shared point, so it matches PublicKey.Decapsulate called on the receiver side
 func (k *PrivateKey) Encapsulate(pub *PublicKey) ([]byte, error) {
-	if pub == nil {
-		return nil, fmt.Errorf("public key is empty")
-	}
+	return k.encapsulate(pub, DefaultConfig())
+}
 
-	if !k.Curve.IsOnCurve(pub.X, pub.Y) {
-		return nil, fmt.Errorf("invalid public key")
+// EncapsulateConf encapsulates key with KDF and symmetric key size taken from the passed config
+func (k *PrivateKey) EncapsulateConf(pub *PublicKey, config Config) ([]byte, error) {
+	return k.encapsulate(pub, config)
+}
+
+func (k *PrivateKey) encapsulate(pub *PublicKey, conf Config) ([]byte, error) {
+	sx, sy, err := k.sharedPoint(pub)
+	if err != nil {
+		return nil, err
 	}
 
 	var secret bytes.Buffer
-	secret.Write(k.PublicKey.Bytes(false))
-
-	sx, sy := pub.Curve.ScalarMult(pub.X, pub.Y, k.D.Bytes())
+	if !conf.kdfSharedPointOnly {
+		secret.Write(k.PublicKey.Bytes(false))
+	}
 	secret.Write([]byte{0x04})
 
 	// Sometimes shared secret coordinates are less than 32 bytes; Big Endian
 	l := len(pub.Curve.Params().P.Bytes())
 	secret.Write(zeroPad(sx.Bytes(), l))
 	secret.Write(zeroPad(sy.Bytes(), l))
+	secret.Write(conf.kdfSuffix)
 
-	return kdf(secret.Bytes())
+	if conf.wipeSecrets {
+		defer func() {
+			zeroBytes(secret.Bytes())
+			zeroBigInt(sx)
+			zeroBigInt(sy)
+		}()
+	}
+
+	return kdf(secret.Bytes(), conf)
 }
 
 // ECDH derives shared secret;
 // Must not be used as encryption key, it increases chances to perform successful key restoration attack
 func (k *PrivateKey) ECDH(pub *PublicKey) ([]byte, error) {
-	if pub == nil {
-		return nil, fmt.Errorf("public key is empty")
-	}
-
-	if !k.Curve.IsOnCurve(pub.X, pub.Y) {
-		return nil, fmt.Errorf("invalid public key")
-	}
-
 	// Shared secret generation
-	sx, sy := pub.Curve.ScalarMult(pub.X, pub.Y, k.D.Bytes())
+	sx, sy, err := k.sharedPoint(pub)
+	if err != nil {
+		return nil, err
+	}
 
 	var ss []byte
 	if sy.Bit(0) != 0 { // If odd
@@ -125,7 +210,116 @@ func (k *PrivateKey) ECDH(pub *PublicKey) ([]byte, error) {
 	return append(ss, sx.Bytes()...), nil
 }
 
-// Equals compares two private keys with constant time (to resist timing attacks)
+// ECDHX derives SEC 1 (section 3.3.1) and IEEE 1363 ECSVDP-DH shared secret: X coordinate of the shared point,
+// left-padded to the field size, for interoperability with stacks which feed raw X into their KDF;
+// like ECDH, it must not be used as encryption key directly
+func (k *PrivateKey) ECDHX(pub *PublicKey) ([]byte, error) {
+	sx, sy, err := k.sharedPoint(pub)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBigInt(sy)
+
+	ss, _ := fixedBytes(sx, len(pub.Curve.Params().P.Bytes()))
+	zeroBigInt(sx)
+	return ss, nil
+}
+
+// ECDHCofactor derives SEC 1 (section 3.3.2) and IEEE 1363 ECSVDP-DHC cofactor shared secret:
+// X coordinate of h·d·Q. secp256k1 and NIST curves have cofactor h = 1 and public key validation already
+// rejects points outside the prime order group, so the result equals ECDHX; it is provided for protocols
+// which specify cofactor Diffie-Hellman
+func (k *PrivateKey) ECDHCofactor(pub *PublicKey) ([]byte, error) {
+	return k.ECDHX(pub)
+}
+
+// ECDHPoint returns the whole shared point as public key, which composes with Add, ScalarMult and Bytes
+// for protocols hashing or reusing the point; SharedPoint returns its raw coordinates.
+// Like ECDH, it must not be used as encryption key directly
+func (k *PrivateKey) ECDHPoint(pub *PublicKey) (*PublicKey, error) {
+	sx, sy, err := k.sharedPoint(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	return pub.newPoint(sx, sy)
+}
+
+// Validate checks that the private scalar is in [1, n-1] range and the nested public key is valid
+func (k *PrivateKey) Validate() error {
+	if k == nil || k.D == nil {
+		return fmt.Errorf("%w: private key is empty", ErrInvalidPrivateKey)
+	}
+	if err := k.PublicKey.Validate(); err != nil {
+		return err
+	}
+	if k.D.Sign() <= 0 || k.D.Cmp(k.Curve.Params().N) >= 0 {
+		return fmt.Errorf("%w: scalar is out of range", ErrInvalidPrivateKey)
+	}
+
+	return nil
+}
+
+// sharedPoint validates both keys and multiplies public key by private key scalar
+func (k *PrivateKey) sharedPoint(pub *PublicKey) (sx, sy *big.Int, err error) {
+	if err := k.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if err := pub.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if !sameCurve(k.Curve, pub.Curve) {
+		return nil, nil, fmt.Errorf("%w: curve does not match private key curve", ErrInvalidPublicKey)
+	}
+
+	// Fixed width scalar encoding does not leak the number of leading zero bytes
+	d := k.Bytes()
+	defer zeroBytes(d)
+
+	sx, sy = scalarMult(k.Curve, pub.X, pub.Y, d)
+	if sx == nil || sy == nil || (sx.Sign() == 0 && sy.Sign() == 0) {
+		return nil, nil, fmt.Errorf("%w: shared point is at infinity", ErrInvalidPublicKey)
+	}
+
+	return sx, sy, nil
+}
+
+// Public returns copy of the public key (*PublicKey), so PrivateKey implements crypto.Signer;
+// mutating the returned key does not affect the private key
+func (k *PrivateKey) Public() crypto.PublicKey {
+	return &PublicKey{
+		Curve: k.Curve,
+		X:     new(big.Int).Set(k.X),
+		Y:     new(big.Int).Set(k.Y),
+	}
+}
+
+// Equals compares two private keys with constant time (to resist timing attacks);
+// scalars are compared in fixed width encoding, so leading zeros do not change comparison time.
+// Keys on different curves are never equal
 func (k *PrivateKey) Equals(priv *PrivateKey) bool {
-	return subtle.ConstantTimeCompare(k.D.Bytes(), priv.D.Bytes()) == 1
+	if k == nil || priv == nil || k.D == nil || priv.D == nil {
+		return false
+	}
+
+	curve, other := getCurve(), getCurve()
+	if k.PublicKey != nil && k.Curve != nil {
+		curve = k.Curve
+	}
+	if priv.PublicKey != nil && priv.Curve != nil {
+		other = priv.Curve
+	}
+	if !sameCurve(curve, other) {
+		return false
+	}
+
+	l := len(curve.Params().N.Bytes())
+	a, okA := fixedBytes(k.D, l)
+	b, okB := fixedBytes(priv.D, l)
+	return okA && okB && subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// IsZero reports whether the private key is empty or wiped with Zeroize
+func (k *PrivateKey) IsZero() bool {
+	return k == nil || k.D == nil || k.D.Sign() == 0
 }
@@ -1,8 +1,11 @@
 package eciesgo
 
 import (
+	"crypto"
+	"crypto/elliptic"
 	"crypto/subtle"
 	"github.com/stretchr/testify/assert"
+	"math/big"
 	"testing"
 )
 
@@ -19,6 +22,8 @@ func TestNewPrivateKeyFromHex(t *testing.T) {
 }
 
 func TestPrivateKey_Hex(t *testing.T) {
+	skipUnapproved(t)
+
 	privkey, err := GenerateKey()
 	if !assert.NoError(t, err) {
 		return
@@ -28,15 +33,60 @@ func TestPrivateKey_Hex(t *testing.T) {
 }
 
 func TestPrivateKey_Equals(t *testing.T) {
+	skipUnapproved(t)
+
 	privkey, err := GenerateKey()
 	if !assert.NoError(t, err) {
 		return
 	}
 
 	assert.True(t, privkey.Equals(privkey))
+
+	other, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.False(t, privkey.Equals(other))
+
+	// Scalars with leading zero bytes and keys without public part are compared in fixed width
+	small := &PrivateKey{D: big.NewInt(1)}
+	assert.True(t, small.Equals(&PrivateKey{D: big.NewInt(1)}))
+	assert.False(t, small.Equals(privkey))
+	assert.False(t, (&PrivateKey{D: new(big.Int).Lsh(big.NewInt(1), 300)}).Equals(small))
+	assert.False(t, (*PrivateKey)(nil).Equals(privkey))
+
+	// Keys with the same scalar on different curves are not equal
+	k1, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	p256, err := NewPrivateKeyFromBytesCurve(elliptic.P256(), testingReceiverPrivkey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.False(t, k1.Equals(p256))
+	assert.False(t, p256.Equals(k1))
+	assert.False(t, small.Equals(p256))
+}
+
+func TestPrivateKey_IsZero(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.False(t, privkey.IsZero())
+	privkey.Zeroize()
+	assert.True(t, privkey.IsZero())
+	assert.True(t, (*PrivateKey)(nil).IsZero())
+	assert.True(t, (&PrivateKey{}).IsZero())
 }
 
 func TestPrivateKey_UnsafeECDH(t *testing.T) {
+	skipUnapproved(t)
+
 	privkey1, err := NewPrivateKeyFromHex(privkeyBase)
 	if !assert.NoError(t, err) {
 		return
@@ -57,3 +107,206 @@ func TestPrivateKey_UnsafeECDH(t *testing.T) {
 		assert.Equal(t, subtle.ConstantTimeCompare(ss1, ss2), 1)
 	}
 }
+
+func TestPrivateKey_ECDHX(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey1, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	privkey2, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ss, err := privkey1.ECDH(privkey2.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	x1, err := privkey1.ECDHX(privkey2.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	x2, err := privkey2.ECDHX(privkey1.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, x1, 32)
+	assert.Equal(t, ss[1:], x1)
+	assert.Equal(t, x1, x2)
+
+	// Cofactor of secp256k1 is 1
+	c, err := privkey1.ECDHCofactor(privkey2.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, x1, c)
+
+	_, err = privkey1.ECDHX(&PublicKey{})
+	assert.Error(t, err)
+}
+
+func TestPrivateKey_ECDHPoint(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey1, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	privkey2, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	p1, err := privkey1.ECDHPoint(privkey2.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	p2, err := privkey2.ECDHPoint(privkey1.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, p1.Equals(p2))
+	assert.NoError(t, p1.Validate())
+
+	// Compressed point encoding is ECDH output
+	ss, err := privkey1.ECDH(privkey2.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, ss, p1.Bytes(true))
+
+	// Shared point is the peer public key multiplied by the private scalar
+	expected, err := privkey2.PublicKey.ScalarMult(privkey1.Bytes())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, expected.Equals(p1))
+
+	_, err = privkey1.ECDHPoint(&PublicKey{})
+	assert.Error(t, err)
+}
+
+func TestNewPrivateKeyFromBytesValidation(t *testing.T) {
+	n := getCurve().Params().N
+
+	for _, b := range [][]byte{
+		nil,
+		make([]byte, 32),
+		n.Bytes(),
+		new(big.Int).Add(n, big.NewInt(1)).Bytes(),
+		testingReceiverPrivkey[1:],
+		append([]byte{0}, testingReceiverPrivkey...),
+	} {
+		_, err := NewPrivateKeyFromBytes(b)
+		assert.Error(t, err)
+	}
+
+	_, err := NewPrivateKeyFromHex("00")
+	assert.Error(t, err)
+}
+
+func TestNewPrivateKeyFromBytesReduced(t *testing.T) {
+	n := getCurve().Params().N
+
+	_, err := NewPrivateKeyFromBytesReduced(n.Bytes())
+	assert.Error(t, err)
+
+	k1, err := NewPrivateKeyFromBytesReduced(new(big.Int).Add(n, big.NewInt(5)).Bytes())
+	if !assert.NoError(t, err) {
+		return
+	}
+	k2, err := NewPrivateKeyFromBytesReduced([]byte{5})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, k1.Equals(k2))
+
+	k3, err := NewPrivateKeyFromBytes(k2.Bytes())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, k2.PublicKey.Equals(k3.PublicKey))
+}
+
+func TestPrivateKey_Public(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var signer crypto.Signer = privkey
+	pub, ok := signer.Public().(*PublicKey)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.True(t, pub.Equals(privkey.PublicKey))
+
+	// Returned key is a copy
+	pub.X.SetInt64(1)
+	assert.False(t, pub.Equals(privkey.PublicKey))
+	assert.True(t, privkey.Curve.IsOnCurve(privkey.X, privkey.Y))
+}
+
+func TestPrivateKey_EncapsulateConf(t *testing.T) {
+	skipUnapproved(t)
+
+	sender, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	receiver, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	conf := NewConfig("aes-128-gcm", 16).WithKDFInfo([]byte("protocol"))
+	sk1, err := sender.EncapsulateConf(receiver.PublicKey, conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	sk2, err := sender.PublicKey.DecapsulateConf(receiver, conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, sk1, sk2)
+	assert.Len(t, sk1, 16)
+
+	sk3, err := sender.Encapsulate(receiver.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotEqual(t, sk1, sk3)
+}
+
+func TestPrivateKey_Validate(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, privkey.Validate())
+
+	n := privkey.Curve.Params().N
+	for _, d := range []*big.Int{nil, new(big.Int), new(big.Int).Set(n), new(big.Int).Neg(privkey.D)} {
+		invalid := &PrivateKey{PublicKey: privkey.PublicKey, D: d}
+		assert.Error(t, invalid.Validate())
+
+		_, err := invalid.Encapsulate(privkey.PublicKey)
+		assert.Error(t, err)
+		_, err = privkey.PublicKey.Decapsulate(invalid)
+		assert.Error(t, err)
+	}
+
+	// Public key on another curve is rejected
+	x, y := elliptic.P256().ScalarBaseMult([]byte{1})
+	other := &PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+	_, err = privkey.Encapsulate(other)
+	assert.Error(t, err)
+	_, err = other.Decapsulate(privkey)
+	assert.Error(t, err)
+}
@@ -0,0 +1,292 @@
+package eciesgo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Field numbers of proto/ecies/v1/ciphertext.proto messages
+const (
+	protoCiphertextEphemeral = 1
+	protoCiphertextSuite     = 2
+	protoCiphertextNonce     = 3
+	protoCiphertextTag       = 4
+	protoCiphertextPayload   = 5
+	protoCiphertextAADHash   = 6
+
+	protoSuiteCurve         = 1
+	protoSuiteCipher        = 2
+	protoSuiteKDF           = 3
+	protoSuiteKDFHash       = 4
+	protoSuiteNonceLength   = 5
+	protoSuiteKeyCommitment = 6
+	protoSuiteSharedPoint   = 7
+	protoSuiteKeySeparation = 8
+)
+
+// Protobuf wire types used by the messages
+const (
+	protoVarint = 0
+	protoBytes  = 2
+)
+
+// CipherSuite describes algorithms of a ciphertext, see ecies.v1.CipherSuite message
+type CipherSuite struct {
+	Curve          string
+	Cipher         string
+	KDF            string
+	KDFHash        string
+	NonceLength    uint32
+	KeyCommitment  bool
+	SharedPointKDF bool
+	KeySeparation  bool
+}
+
+// CiphertextMessage is a ciphertext split into parts, see ecies.v1.Ciphertext message in proto/ecies/v1/ciphertext.proto
+type CiphertextMessage struct {
+	EphemeralPublicKey []byte
+	Suite              CipherSuite
+	Nonce              []byte
+	Tag                []byte
+	Payload            []byte
+	AADHash            []byte
+}
+
+// NewCiphertextMessage splits a ciphertext produced by EncryptConf with the passed config;
+// for enveloped ciphertexts the suite is taken from the envelope header
+func NewCiphertextMessage(msg []byte, config Config) (*CiphertextMessage, error) {
+	msg, config, err := openEnvelope(msg, config)
+	if err != nil {
+		return nil, err
+	}
+	if config.hpke != nil {
+		return nil, fmt.Errorf("HPKE ciphertexts have no structured form")
+	}
+
+	nonceSize, tagSize, err := symmSizes(config)
+	if err != nil {
+		return nil, err
+	}
+	if len(msg) <= 65+nonceSize+tagSize {
+		return nil, newParseError(ErrCiphertextTooShort, "ciphertext", len(msg), "ciphertext is truncated")
+	}
+
+	kdf, kdfHash := config.kdfFunction, config.kdfHash
+	if kdf == "" {
+		kdf = "hkdf"
+	}
+	if kdfHash == "" {
+		kdfHash = "sha256"
+	}
+
+	return &CiphertextMessage{
+		EphemeralPublicKey: msg[:65],
+		Suite: CipherSuite{
+			Curve:          "secp256k1",
+			Cipher:         config.symmetricAlgorithm,
+			KDF:            kdf,
+			KDFHash:        kdfHash,
+			NonceLength:    uint32(nonceSize),
+			KeyCommitment:  config.keyCommitment,
+			SharedPointKDF: config.kdfSharedPointOnly,
+			KeySeparation:  config.kdfKeySeparation,
+		},
+		Nonce:   msg[65 : 65+nonceSize],
+		Tag:     msg[65+nonceSize : 65+nonceSize+tagSize],
+		Payload: msg[65+nonceSize+tagSize:],
+	}, nil
+}
+
+// Ciphertext joins message parts into a ciphertext, which can be decrypted with DecryptConf and Config
+func (m *CiphertextMessage) Ciphertext() []byte {
+	return bytes.Join([][]byte{m.EphemeralPublicKey, m.Nonce, m.Tag, m.Payload}, nil)
+}
+
+// Config returns config described by the message suite; KDF salt and info are not part of the suite
+func (m *CiphertextMessage) Config() (Config, error) {
+	if m.Suite.Curve != "" && m.Suite.Curve != "secp256k1" {
+		return Config{}, fmt.Errorf("unsupported curve: %s", m.Suite.Curve)
+	}
+
+	config := NewConfig(m.Suite.Cipher, int(m.Suite.NonceLength)).WithKDF(m.Suite.KDF).WithKDFHash(m.Suite.KDFHash)
+	if m.Suite.KeyCommitment {
+		config = config.WithKeyCommitment()
+	}
+	if m.Suite.SharedPointKDF {
+		config = config.WithSharedPointKDF()
+	}
+	if m.Suite.KeySeparation {
+		config = config.WithKeySeparation()
+	}
+
+	return config, nil
+}
+
+// MarshalProto encodes the message in protobuf wire format
+func (m *CiphertextMessage) MarshalProto() []byte {
+	var suite []byte
+	suite = appendProtoBytes(suite, protoSuiteCurve, []byte(m.Suite.Curve))
+	suite = appendProtoBytes(suite, protoSuiteCipher, []byte(m.Suite.Cipher))
+	suite = appendProtoBytes(suite, protoSuiteKDF, []byte(m.Suite.KDF))
+	suite = appendProtoBytes(suite, protoSuiteKDFHash, []byte(m.Suite.KDFHash))
+	suite = appendProtoVarint(suite, protoSuiteNonceLength, uint64(m.Suite.NonceLength))
+	if m.Suite.KeyCommitment {
+		suite = appendProtoVarint(suite, protoSuiteKeyCommitment, 1)
+	}
+	if m.Suite.SharedPointKDF {
+		suite = appendProtoVarint(suite, protoSuiteSharedPoint, 1)
+	}
+	if m.Suite.KeySeparation {
+		suite = appendProtoVarint(suite, protoSuiteKeySeparation, 1)
+	}
+
+	var b []byte
+	b = appendProtoBytes(b, protoCiphertextEphemeral, m.EphemeralPublicKey)
+	b = appendProtoBytes(b, protoCiphertextSuite, suite)
+	b = appendProtoBytes(b, protoCiphertextNonce, m.Nonce)
+	b = appendProtoBytes(b, protoCiphertextTag, m.Tag)
+	b = appendProtoBytes(b, protoCiphertextPayload, m.Payload)
+	b = appendProtoBytes(b, protoCiphertextAADHash, m.AADHash)
+
+	return b
+}
+
+// UnmarshalCiphertextMessage decodes message in protobuf wire format, unknown fields are skipped
+func UnmarshalCiphertextMessage(b []byte) (*CiphertextMessage, error) {
+	m := &CiphertextMessage{}
+
+	err := readProtoFields(b, func(field int, v uint64, data []byte) error {
+		switch field {
+		case protoCiphertextEphemeral:
+			m.EphemeralPublicKey = data
+		case protoCiphertextNonce:
+			m.Nonce = data
+		case protoCiphertextTag:
+			m.Tag = data
+		case protoCiphertextPayload:
+			m.Payload = data
+		case protoCiphertextAADHash:
+			m.AADHash = data
+		case protoCiphertextSuite:
+			return readProtoFields(data, func(field int, v uint64, data []byte) error {
+				switch field {
+				case protoSuiteCurve:
+					m.Suite.Curve = string(data)
+				case protoSuiteCipher:
+					m.Suite.Cipher = string(data)
+				case protoSuiteKDF:
+					m.Suite.KDF = string(data)
+				case protoSuiteKDFHash:
+					m.Suite.KDFHash = string(data)
+				case protoSuiteNonceLength:
+					m.Suite.NonceLength = uint32(v)
+				case protoSuiteKeyCommitment:
+					m.Suite.KeyCommitment = v != 0
+				case protoSuiteSharedPoint:
+					m.Suite.SharedPointKDF = v != 0
+				case protoSuiteKeySeparation:
+					m.Suite.KeySeparation = v != 0
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// symmSizes returns nonce and tag sizes of the symmetric cipher described by config
+func symmSizes(config Config) (nonceSize, tagSize int, err error) {
+	keySize, err := symmKeySize(config)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	aead, err := generateSymmCipher(make([]byte, keySize), config)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return aead.NonceSize(), aead.Overhead(), nil
+}
+
+func appendProtoVarint(b []byte, field int, v uint64) []byte {
+	b = appendUvarint(b, uint64(field)<<3|protoVarint)
+	return appendUvarint(b, v)
+}
+
+// appendProtoBytes appends length-delimited field, empty values are omitted as proto3 does
+func appendProtoBytes(b []byte, field int, data []byte) []byte {
+	if len(data) == 0 {
+		return b
+	}
+
+	b = appendUvarint(b, uint64(field)<<3|protoBytes)
+	b = appendUvarint(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+// readProtoFields calls f for every varint and length-delimited field of the message,
+// fixed-size fields are skipped, groups are rejected
+func readProtoFields(b []byte, f func(field int, v uint64, data []byte) error) error {
+	for offset := 0; offset < len(b); {
+		key, n := binary.Uvarint(b[offset:])
+		if n <= 0 {
+			return newParseError(ErrCiphertextTooShort, "protobuf message", offset, "invalid field key")
+		}
+		offset += n
+
+		field, wireType := int(key>>3), key&7
+		if field == 0 {
+			return newParseError(ErrCiphertextTooShort, "protobuf message", offset-n, "invalid field number")
+		}
+
+		var (
+			v    uint64
+			data []byte
+		)
+		switch wireType {
+		case protoVarint:
+			if v, n = binary.Uvarint(b[offset:]); n <= 0 {
+				return newParseError(ErrCiphertextTooShort, "protobuf message", offset, "invalid varint")
+			}
+			offset += n
+		case protoBytes:
+			l, n := binary.Uvarint(b[offset:])
+			if n <= 0 || l > uint64(len(b)-offset-n) {
+				return newParseError(ErrCiphertextTooShort, "protobuf message", offset, "invalid length")
+			}
+			offset += n
+			data = b[offset : offset+int(l)]
+			offset += int(l)
+		case 1, 5:
+			size := 8
+			if wireType == 5 {
+				size = 4
+			}
+			if len(b)-offset < size {
+				return newParseError(ErrCiphertextTooShort, "protobuf message", offset, "fixed field is truncated")
+			}
+			offset += size
+			continue
+		default:
+			return newParseError(ErrCiphertextTooShort, "protobuf message", offset-n, fmt.Sprintf("unsupported wire type %d", wireType))
+		}
+
+		if err := f(field, v, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
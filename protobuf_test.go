@@ -0,0 +1,88 @@
+package eciesgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCiphertextMessage(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, conf := range []Config{
+		DEFAULT_CONFIG,
+		NewConfig("xchacha20", 0).WithKDFHash("sha512"),
+		NewConfig("aes-256-gcm", 12).WithKDF("x963").WithKeyCommitment().WithEnvelope(),
+		DEFAULT_CONFIG.WithSharedPointKDF(),
+		DEFAULT_CONFIG.WithKeySeparation().WithKeyCommitment(),
+	} {
+		ct, err := EncryptConf(privkey.PublicKey, []byte(testingMessage), conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		m, err := NewCiphertextMessage(ct, conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, m.EphemeralPublicKey, 65)
+		assert.Len(t, m.Payload, len(testingMessage))
+
+		decoded, err := UnmarshalCiphertextMessage(m.MarshalProto())
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, m, decoded)
+
+		decodedConf, err := decoded.Config()
+		if !assert.NoError(t, err) {
+			return
+		}
+		pt, err := DecryptConf(privkey, decoded.Ciphertext(), decodedConf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(pt))
+	}
+}
+
+func TestUnmarshalCiphertextMessage(t *testing.T) {
+	m := &CiphertextMessage{
+		EphemeralPublicKey: []byte{4, 1, 2},
+		Suite:              CipherSuite{Curve: "secp256k1", Cipher: "aes-256-gcm", NonceLength: 16},
+		Payload:            []byte("payload"),
+	}
+	b := m.MarshalProto()
+
+	// Unknown fields of all wire types are skipped
+	unknown := append([]byte(nil), b...)
+	unknown = append(unknown, 7<<3|0, 0x96, 0x01)
+	unknown = append(unknown, 8<<3|1, 1, 2, 3, 4, 5, 6, 7, 8)
+	unknown = append(unknown, 9<<3|2, 2, 'h', 'i')
+	unknown = append(unknown, 10<<3|5, 1, 2, 3, 4)
+	decoded, err := UnmarshalCiphertextMessage(unknown)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, m, decoded)
+
+	for _, invalid := range [][]byte{
+		{0x80},
+		{0<<3 | 2, 0},
+		{1<<3 | 2, 5, 1},
+		{1<<3 | 3},
+		{8<<3 | 1, 1, 2},
+		b[:len(b)-1],
+	} {
+		_, err := UnmarshalCiphertextMessage(invalid)
+		assert.Error(t, err, "%x", invalid)
+	}
+
+	_, err = (&CiphertextMessage{Suite: CipherSuite{Curve: "P-256"}}).Config()
+	assert.Error(t, err)
+}
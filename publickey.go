@@ -7,6 +7,8 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 )
 
 // PublicKey instance with nested elliptic.Curve interface (secp256k1 instance in our case)
@@ -25,68 +27,93 @@ func NewPublicKeyFromHex(s string) (*PublicKey, error) {
 	return NewPublicKeyFromBytes(b)
 }
 
+// PublicKeyParseMode selects public key encodings accepted by NewPublicKeyFromBytesMode
+type PublicKeyParseMode int
+
+const (
+	// ParseStrict accepts SEC 1 compressed (0x02, 0x03) and uncompressed (0x04) encodings only
+	ParseStrict PublicKeyParseMode = iota
+	// ParseLenient additionally accepts hybrid encodings (0x06, 0x07; prefix must match Y parity)
+	// and 64 bytes of X and Y without prefix, which some legacy implementations produce;
+	// coordinates must be canonical and on curve in both modes
+	ParseLenient
+)
+
 // NewPublicKeyFromBytes decodes public key raw bytes and returns PublicKey instance;
-// Supports both compressed and uncompressed public keys
+// Supports both compressed and uncompressed public keys.
+// Length is checked before anything else, coordinates must be canonical (less than field prime) and on curve
 func NewPublicKeyFromBytes(b []byte) (*PublicKey, error) {
+	return NewPublicKeyFromBytesMode(b, ParseStrict)
+}
+
+// NewPublicKeyFromBytesMode decodes public key raw bytes accepting encodings allowed by the mode
+func NewPublicKeyFromBytesMode(b []byte, mode PublicKeyParseMode) (*PublicKey, error) {
 	curve := getCurve()
+	byteLen := (curve.Params().BitSize + 7) / 8
 
-	switch b[0] {
-	case 0x02, 0x03:
-		if len(b) != 33 {
-			return nil, fmt.Errorf("cannot parse public key")
+	if mode == ParseLenient {
+		switch {
+		case len(b) == 2*byteLen:
+			pub, err := parsePublicKey(curve, byteLen, append([]byte{0x04}, b...))
+			if pe, ok := err.(*ParseError); ok {
+				// Offset in the input without prefix
+				pe.Offset--
+			}
+			return pub, err
+		case len(b) == 1+2*byteLen && (b[0] == 0x06 || b[0] == 0x07):
+			pub, err := parsePublicKey(curve, byteLen, append([]byte{0x04}, b[1:]...))
+			if err != nil {
+				return nil, err
+			}
+			if pub.Y.Bit(0) != uint(b[0]&1) {
+				return nil, newParseError(ErrInvalidPublicKey, "public key", 0, "hybrid prefix does not match Y parity")
+			}
+			return pub, nil
 		}
+	} else if len(b) > 0 && (b[0] == 0x06 || b[0] == 0x07) {
+		return nil, newParseError(ErrInvalidPublicKey, "public key", 0, "hybrid encoding is not allowed in strict mode")
+	}
 
-		x := new(big.Int).SetBytes(b[1:])
-		var ybit uint
-		switch b[0] {
-		case 0x02:
-			ybit = 0
-		case 0x03:
-			ybit = 1
-		}
+	return parsePublicKey(curve, byteLen, b)
+}
 
-		if x.Cmp(curve.Params().P) >= 0 {
-			return nil, fmt.Errorf("cannot parse public key")
-		}
+// NewPublicKeyFromBytesCurve decodes compressed or uncompressed public key raw bytes of the passed curve
+// (e.g. elliptic.P384()), coordinates are as long as the curve field elements
+func NewPublicKeyFromBytesCurve(curve elliptic.Curve, b []byte) (*PublicKey, error) {
+	if curve == nil {
+		return nil, fmt.Errorf("%w: curve is empty", ErrInvalidPublicKey)
+	}
 
-		// y^2 = x^3 + b
-		// y   = sqrt(x^3 + b)
-		var y, x3b big.Int
-		x3b.Mul(x, x)
-		x3b.Mul(&x3b, x)
-		x3b.Add(&x3b, curve.Params().B)
-		x3b.Mod(&x3b, curve.Params().P)
-		if z := y.ModSqrt(&x3b, curve.Params().P); z == nil {
-			return nil, fmt.Errorf("cannot parse public key")
-		}
+	return parsePublicKey(curve, (curve.Params().BitSize+7)/8, b)
+}
 
-		if y.Bit(0) != ybit {
-			y.Sub(curve.Params().P, &y)
-		}
-		if y.Bit(0) != ybit {
-			return nil, fmt.Errorf("incorrectly encoded X and Y bit")
+func parsePublicKey(curve elliptic.Curve, byteLen int, b []byte) (*PublicKey, error) {
+	switch {
+	case len(b) == 1+byteLen && (b[0] == 0x02 || b[0] == 0x03):
+		x := new(big.Int).SetBytes(b[1:])
+		y, err := decompressY(curve, x, b[0] == 0x03)
+		if err != nil {
+			return nil, newParseError(ErrInvalidPublicKey, "public key", 1, err.Error())
 		}
 
 		return &PublicKey{
 			Curve: curve,
 			X:     x,
-			Y:     &y,
+			Y:     y,
 		}, nil
-	case 0x04:
-		if len(b) != 65 {
-			return nil, fmt.Errorf("cannot parse public key")
-		}
-
-		x := new(big.Int).SetBytes(b[1:33])
-		y := new(big.Int).SetBytes(b[33:])
+	case len(b) == 1+2*byteLen && b[0] == 0x04:
+		x := new(big.Int).SetBytes(b[1 : 1+byteLen])
+		y := new(big.Int).SetBytes(b[1+byteLen:])
 
-		if x.Cmp(curve.Params().P) >= 0 || y.Cmp(curve.Params().P) >= 0 {
-			return nil, fmt.Errorf("cannot parse public key")
+		if x.Cmp(curve.Params().P) >= 0 {
+			return nil, newParseError(ErrInvalidPublicKey, "public key", 1, "X coordinate is out of range")
+		}
+		if y.Cmp(curve.Params().P) >= 0 {
+			return nil, newParseError(ErrInvalidPublicKey, "public key", 1+byteLen, "Y coordinate is out of range")
 		}
 
-		x3 := new(big.Int).Sqrt(x).Mul(x, x)
-		if t := new(big.Int).Sqrt(y).Sub(y, x3.Add(x3, curve.Params().B)); t.IsInt64() && t.Int64() == 0 {
-			return nil, fmt.Errorf("cannot parse public key")
+		if !curve.IsOnCurve(x, y) {
+			return nil, newParseError(ErrInvalidPublicKey, "public key", 1, "point is not on curve")
 		}
 
 		return &PublicKey{
@@ -94,16 +121,21 @@ func NewPublicKeyFromBytes(b []byte) (*PublicKey, error) {
 			X:     x,
 			Y:     y,
 		}, nil
+	case len(b) == 0:
+		return nil, newParseError(ErrInvalidPublicKey, "public key", 0, "input is empty")
+	case b[0] != 0x02 && b[0] != 0x03 && b[0] != 0x04:
+		return nil, newParseError(ErrInvalidPublicKey, "public key", 0, fmt.Sprintf("unknown prefix %#x", b[0]))
 	default:
-		return nil, fmt.Errorf("cannot parse public key")
+		return nil, newParseError(ErrInvalidPublicKey, "public key", len(b), fmt.Sprintf("invalid length %d for prefix %#x", len(b), b[0]))
 	}
 }
 
 // Bytes returns public key raw bytes;
 // Could be optionally compressed by dropping Y part
 func (k *PublicKey) Bytes(compressed bool) []byte {
+	l := (k.Curve.Params().BitSize + 7) / 8
 	x := k.X.Bytes()
-	x = zeroPad(x, 32)
+	x = zeroPad(x, l)
 
 	if compressed {
 		// If odd
@@ -116,7 +148,7 @@ func (k *PublicKey) Bytes(compressed bool) []byte {
 	}
 
 	y := k.Y.Bytes()
-	y = zeroPad(y, 32)
+	y = zeroPad(y, l)
 
 	return bytes.Join([][]byte{{0x04}, x, y}, nil)
 }
@@ -127,33 +159,196 @@ func (k *PublicKey) Hex(compressed bool) string {
 }
 
 // Decapsulate decapsulates key by using Key Encapsulation Mechanism and returns symmetric key;
-// can be safely used as encryption key
+// can be safely used as encryption key. It is the receiver side of PrivateKey.Encapsulate:
+// k is the sender (ephemeral) public key and priv is the receiver private key, both keys are validated
 func (k *PublicKey) Decapsulate(priv *PrivateKey) ([]byte, error) {
+	return k.decapsulate(priv, DefaultConfig())
+}
+
+// DecapsulateConf decapsulates key with KDF and symmetric key size taken from the passed config
+func (k *PublicKey) DecapsulateConf(priv *PrivateKey, config Config) ([]byte, error) {
+	return k.decapsulate(priv, config)
+}
+
+func (k *PublicKey) decapsulate(priv *PrivateKey, conf Config) ([]byte, error) {
+	if priv == nil {
+		return nil, fmt.Errorf("%w: private key is empty", ErrInvalidPrivateKey)
+	}
+
+	keys, err := k.decapsulateWith(priv, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return keys[0], nil
+}
+
+// Validate checks that the public key is a point of the curve prime order subgroup:
+// coordinates are canonical, point is on curve and is not the point at infinity;
+// secp256k1 and NIST curves have cofactor 1, so every such point has order n
+func (k *PublicKey) Validate() error {
+	if k == nil || k.Curve == nil || k.X == nil || k.Y == nil {
+		return fmt.Errorf("%w: public key is empty", ErrInvalidPublicKey)
+	}
+	if err := checkFIPSCurve(k.Curve); err != nil {
+		return err
+	}
+
+	p := k.Curve.Params().P
+	if k.X.Sign() < 0 || k.Y.Sign() < 0 || k.X.Cmp(p) >= 0 || k.Y.Cmp(p) >= 0 {
+		return fmt.Errorf("%w: coordinate is out of range", ErrInvalidPublicKey)
+	}
+	if k.X.Sign() == 0 && k.Y.Sign() == 0 {
+		return fmt.Errorf("%w: point is at infinity", ErrInvalidPublicKey)
+	}
 	if !k.Curve.IsOnCurve(k.X, k.Y) {
-		return nil, fmt.Errorf("invalid public key")
+		return fmt.Errorf("%w: point is not on curve", ErrInvalidPublicKey)
 	}
 
-	if priv == nil {
-		return nil, fmt.Errorf("private key is empty")
+	return nil
+}
+
+// decapsulateWith computes shared point with the decapsulator and derives symmetric key;
+// if decapsulator returns X coordinate only, keys for both possible Y coordinates are returned
+func (k *PublicKey) decapsulateWith(d Decapsulator, conf Config) ([][]byte, error) {
+	if err := k.Validate(); err != nil {
+		return nil, err
+	}
+
+	sx, sy, err := d.SharedPoint(k)
+	if err != nil {
+		return nil, err
 	}
 
-	var secret bytes.Buffer
-	secret.Write(k.Bytes(false))
+	candidates := []*big.Int{sy}
+	if sy == nil {
+		y, err := yFromX(k.Curve, sx)
+		if err != nil {
+			return nil, fmt.Errorf("invalid shared point: %w", err)
+		}
+		candidates = []*big.Int{y, new(big.Int).Sub(k.Curve.Params().P, y)}
+	}
 
-	sx, sy := priv.Curve.ScalarMult(k.X, k.Y, priv.D.Bytes())
-	secret.Write([]byte{0x04})
+	if conf.wipeSecrets {
+		defer func() {
+			zeroBigInt(sx)
+			for _, y := range candidates {
+				zeroBigInt(y)
+			}
+		}()
+	}
 
 	// Sometimes shared secret coordinates are less than 32 bytes; Big Endian
-	l := len(priv.Curve.Params().P.Bytes())
-	secret.Write(zeroPad(sx.Bytes(), l))
-	secret.Write(zeroPad(sy.Bytes(), l))
+	l := len(k.Curve.Params().P.Bytes())
+
+	keys := make([][]byte, 0, len(candidates))
+	for _, y := range candidates {
+		var secret bytes.Buffer
+		if !conf.kdfSharedPointOnly {
+			secret.Write(k.Bytes(false))
+		}
+		secret.Write([]byte{0x04})
+		secret.Write(zeroPad(sx.Bytes(), l))
+		secret.Write(zeroPad(y.Bytes(), l))
+		secret.Write(conf.kdfSuffix)
+
+		ss, err := kdf(secret.Bytes(), conf)
+		if conf.wipeSecrets {
+			zeroBytes(secret.Bytes())
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, ss)
+	}
 
-	return kdf(secret.Bytes())
+	return keys, nil
 }
 
-// Equals compares two public keys with constant time (to resist timing attacks)
+// yFromX computes even Y coordinate of the point with the given X coordinate
+func yFromX(curve elliptic.Curve, x *big.Int) (*big.Int, error) {
+	return decompressY(curve, x, false)
+}
+
+// decompressY computes Y coordinate with the given parity of the point with the given X coordinate;
+// secp256k1 square root is computed in constant time field arithmetic, other curves (a = -3) use math/big,
+// Brainpool curves compute it on their twisted curve.
+// Result is checked to satisfy the curve equation independently of the square root computation
+func decompressY(curve elliptic.Curve, x *big.Int, odd bool) (*big.Int, error) {
+	params := curve.Params()
+	if x.Sign() < 0 || x.Cmp(params.P) >= 0 {
+		return nil, fmt.Errorf("x coordinate is out of range")
+	}
+
+	var y *big.Int
+	if rc, ok := curve.(*rcurve); ok {
+		// Brainpool curves, a != -3
+		var err error
+		if y, err = rc.decompressY(x, odd); err != nil {
+			return nil, err
+		}
+	} else if sameCurve(curve, getCurve()) {
+		// y = sqrt(x^3 + 7)
+		var fx, fy secp256k1.FieldVal
+		fx.SetByteSlice(x.Bytes())
+		if !secp256k1.DecompressY(&fx, odd, &fy) {
+			return nil, fmt.Errorf("point is not on curve")
+		}
+		y = new(big.Int).SetBytes(fy.Normalize().Bytes()[:])
+	} else {
+		// y = sqrt(x^3 - 3x + b)
+		rhs := new(big.Int).Mul(x, x)
+		rhs.Mul(rhs, x)
+		rhs.Sub(rhs, new(big.Int).Lsh(x, 1))
+		rhs.Sub(rhs, x)
+		rhs.Add(rhs, params.B)
+		rhs.Mod(rhs, params.P)
+
+		y = new(big.Int)
+		if y.ModSqrt(rhs, params.P) == nil {
+			return nil, fmt.Errorf("point is not on curve")
+		}
+		if (y.Bit(0) == 1) != odd {
+			y.Sub(params.P, y)
+		}
+	}
+
+	if !curve.IsOnCurve(x, y) {
+		return nil, fmt.Errorf("point is not on curve")
+	}
+
+	return y, nil
+}
+
+// Equals compares two public keys with constant time (to resist timing attacks);
+// coordinates are compared in fixed width encoding, so leading zeros do not change comparison time.
+// Keys on different curves and empty keys are never equal
 func (k *PublicKey) Equals(pub *PublicKey) bool {
-	eqX := subtle.ConstantTimeCompare(k.X.Bytes(), pub.X.Bytes()) == 1
-	eqY := subtle.ConstantTimeCompare(k.Y.Bytes(), pub.Y.Bytes()) == 1
-	return eqX && eqY
+	if k.IsZero() || pub.IsZero() || !sameCurve(k.Curve, pub.Curve) {
+		return false
+	}
+
+	a, okA := k.fixedBytes()
+	b, okB := pub.fixedBytes()
+	return okA && okB && subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// Cmp compares compressed encodings of two public keys lexicographically and returns -1, 0 or 1;
+// it gives keys deterministic order (e.g. for sorting receivers), but unlike Equals is not constant time
+func (k *PublicKey) Cmp(pub *PublicKey) int {
+	return bytes.Compare(k.Bytes(true), pub.Bytes(true))
+}
+
+// IsZero reports whether the public key is empty or the point at infinity
+func (k *PublicKey) IsZero() bool {
+	return k == nil || k.Curve == nil || k.X == nil || k.Y == nil || (k.X.Sign() == 0 && k.Y.Sign() == 0)
+}
+
+// fixedBytes returns X and Y coordinates left-padded to the curve field size
+func (k *PublicKey) fixedBytes() ([]byte, bool) {
+	l := (k.Curve.Params().BitSize + 7) / 8
+
+	x, okX := fixedBytes(k.X, l)
+	y, okY := fixedBytes(k.Y, l)
+	return append(x, y...), okX && okY
 }
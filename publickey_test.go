@@ -1,7 +1,12 @@
 package eciesgo
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
 	"github.com/stretchr/testify/assert"
+	"math/big"
 	"testing"
 )
 
@@ -11,10 +16,304 @@ func TestNewPublicKeyFromHex(t *testing.T) {
 }
 
 func TestPublicKey_Equals(t *testing.T) {
+	skipUnapproved(t)
+
 	privkey, err := GenerateKey()
 	if !assert.NoError(t, err) {
 		return
 	}
 
 	assert.True(t, privkey.PublicKey.Equals(privkey.PublicKey))
+
+	other, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.False(t, privkey.PublicKey.Equals(other.PublicKey))
+
+	// Copy with the same coordinates is equal, while the same coordinates on another curve are not
+	cp := &PublicKey{Curve: privkey.Curve, X: new(big.Int).Set(privkey.X), Y: new(big.Int).Set(privkey.Y)}
+	assert.True(t, privkey.PublicKey.Equals(cp))
+	assert.False(t, privkey.PublicKey.Equals(&PublicKey{Curve: elliptic.P256(), X: cp.X, Y: cp.Y}))
+
+	// Short and oversized coordinates do not panic
+	small := &PublicKey{Curve: privkey.Curve, X: big.NewInt(1), Y: big.NewInt(2)}
+	assert.True(t, small.Equals(&PublicKey{Curve: privkey.Curve, X: big.NewInt(1), Y: big.NewInt(2)}))
+	huge := &PublicKey{Curve: privkey.Curve, X: new(big.Int).Lsh(big.NewInt(1), 300), Y: big.NewInt(2)}
+	assert.False(t, huge.Equals(huge))
+
+	var empty *PublicKey
+	assert.False(t, empty.Equals(privkey.PublicKey))
+	assert.False(t, privkey.PublicKey.Equals(&PublicKey{}))
+}
+
+func TestPublicKey_Cmp(t *testing.T) {
+	skipUnapproved(t)
+
+	k1, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	k2, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, 0, k1.PublicKey.Cmp(k1.PublicKey))
+	assert.Equal(t, -k1.PublicKey.Cmp(k2.PublicKey), k2.PublicKey.Cmp(k1.PublicKey))
+	assert.NotEqual(t, 0, k1.PublicKey.Cmp(k2.PublicKey))
+}
+
+func TestPublicKey_IsZero(t *testing.T) {
+	skipUnapproved(t)
+
+	k, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.False(t, k.PublicKey.IsZero())
+	assert.True(t, (*PublicKey)(nil).IsZero())
+	assert.True(t, (&PublicKey{}).IsZero())
+	assert.True(t, (&PublicKey{Curve: k.Curve, X: new(big.Int), Y: new(big.Int)}).IsZero())
+}
+
+func TestNewPublicKeyFromBytesValidation(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	uncompressed, compressed := privkey.PublicKey.Bytes(false), privkey.PublicKey.Bytes(true)
+
+	offCurve := append([]byte(nil), uncompressed...)
+	offCurve[64] ^= 1
+
+	for _, b := range [][]byte{
+		nil,
+		{0x04},
+		uncompressed[:64],
+		append(uncompressed, 0),
+		compressed[:32],
+		append([]byte{0x06}, uncompressed[1:]...),
+		append([]byte{0x04}, compressed[1:]...),
+		offCurve,
+		make([]byte, 65),
+	} {
+		_, err := NewPublicKeyFromBytes(b)
+		assert.Error(t, err)
+	}
+
+	for _, b := range [][]byte{uncompressed, compressed} {
+		pub, err := NewPublicKeyFromBytes(b)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.True(t, pub.Equals(privkey.PublicKey))
+	}
+}
+
+func TestNewPublicKeyFromBytesMode(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	uncompressed := privkey.PublicKey.Bytes(false)
+
+	hybrid := append([]byte{0x06 | byte(privkey.PublicKey.Y.Bit(0))}, uncompressed[1:]...)
+	wrongParity := append([]byte{hybrid[0] ^ 1}, uncompressed[1:]...)
+	raw := uncompressed[1:]
+
+	for _, b := range [][]byte{uncompressed, privkey.PublicKey.Bytes(true), hybrid, raw} {
+		pub, err := NewPublicKeyFromBytesMode(b, ParseLenient)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.True(t, pub.Equals(privkey.PublicKey))
+	}
+
+	for _, b := range [][]byte{hybrid, raw, wrongParity} {
+		_, err := NewPublicKeyFromBytesMode(b, ParseStrict)
+		assert.True(t, errors.Is(err, ErrInvalidPublicKey))
+	}
+
+	// Lenient mode does not relax coordinate checks
+	offCurve := append([]byte(nil), raw...)
+	offCurve[63] ^= 1
+	for _, b := range [][]byte{wrongParity, offCurve, append([]byte{0x06}, offCurve...)} {
+		_, err := NewPublicKeyFromBytesMode(b, ParseLenient)
+		assert.True(t, errors.Is(err, ErrInvalidPublicKey))
+	}
+
+	_, err = NewPublicKeyFromBytesMode(offCurve, ParseLenient)
+	var pe *ParseError
+	if assert.True(t, errors.As(err, &pe)) {
+		assert.Equal(t, 0, pe.Offset)
+	}
+}
+
+func TestNewPublicKeyFromBytesCompressed(t *testing.T) {
+	skipUnapproved(t)
+
+	// Both parities decompress to the original key
+	for i := 0; i < 32; i++ {
+		privkey, err := GenerateKey()
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		pub, err := NewPublicKeyFromBytes(privkey.PublicKey.Bytes(true))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.True(t, pub.Equals(privkey.PublicKey))
+	}
+
+	curve := getCurve()
+	p := curve.Params().P
+
+	// X coordinates without a point (x^3 + 7 is not a quadratic residue) are rejected with both prefixes
+	var residues, nonResidues int
+	for x := int64(0); x < 32; x++ {
+		rhs := new(big.Int).Exp(big.NewInt(x), big.NewInt(3), nil)
+		rhs.Add(rhs, big.NewInt(7))
+
+		for _, prefix := range []byte{0x02, 0x03} {
+			b := append([]byte{prefix}, zeroPad(big.NewInt(x).Bytes(), 32)...)
+			pub, err := NewPublicKeyFromBytes(b)
+			if big.Jacobi(rhs, p) == -1 {
+				assert.True(t, errors.Is(err, ErrInvalidPublicKey))
+				nonResidues++
+				continue
+			}
+
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.True(t, curve.IsOnCurve(pub.X, pub.Y))
+			assert.Equal(t, uint(prefix&1), pub.Y.Bit(0))
+			residues++
+		}
+	}
+	assert.NotZero(t, residues)
+	assert.NotZero(t, nonResidues)
+
+	// Non-canonical X coordinates are rejected
+	for _, x := range []*big.Int{
+		p,
+		new(big.Int).Add(p, big.NewInt(1)),
+		new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1)),
+	} {
+		_, err := NewPublicKeyFromBytes(append([]byte{0x02}, x.Bytes()...))
+		assert.True(t, errors.Is(err, ErrInvalidPublicKey))
+	}
+}
+
+func TestDecompressYNIST(t *testing.T) {
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		k, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		y, err := decompressY(curve, k.X, k.Y.Bit(0) == 1)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, k.Y, y)
+
+		x, yy := elliptic.UnmarshalCompressed(curve, elliptic.MarshalCompressed(curve, k.X, k.Y))
+		assert.Equal(t, x, k.X)
+		assert.Equal(t, yy, y)
+
+		_, err = decompressY(curve, curve.Params().P, false)
+		assert.Error(t, err)
+	}
+}
+
+func TestNewKeyFromBytesCurve(t *testing.T) {
+	skipUnapproved(t)
+
+	for _, curve := range []elliptic.Curve{elliptic.P384(), elliptic.P521()} {
+		k, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if !assert.NoError(t, err) {
+			return
+		}
+		l := (curve.Params().BitSize + 7) / 8
+
+		privkey, err := NewPrivateKeyFromBytesCurve(curve, zeroPad(k.D.Bytes(), l))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, k.X, privkey.X)
+		assert.Equal(t, k.Y, privkey.Y)
+		_, err = NewPrivateKeyFromBytesCurve(curve, privkey.Bytes()[1:])
+		assert.Error(t, err)
+
+		for _, b := range [][]byte{elliptic.Marshal(curve, k.X, k.Y), elliptic.MarshalCompressed(curve, k.X, k.Y)} {
+			pub, err := NewPublicKeyFromBytesCurve(curve, b)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.True(t, pub.Equals(privkey.PublicKey))
+			assert.Equal(t, b, pub.Bytes(len(b) == 1+l))
+		}
+
+		// Shared secret and ciphertext are sized by the curve
+		peer, err := GenerateKeyCurve(curve)
+		if !assert.NoError(t, err) {
+			return
+		}
+		ss, err := privkey.ECDH(peer.PublicKey)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, ss, 1+l)
+
+		ct, err := Encrypt(privkey.PublicKey, []byte(testingMessage))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, byte(0x04), ct[0])
+		assert.Len(t, ct, 1+2*l+16+16+len(testingMessage))
+		pt, err := Decrypt(privkey, ct)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(pt))
+	}
+}
+
+func TestPublicKey_Validate(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, privkey.PublicKey.Validate())
+
+	curve := getCurve()
+	p := curve.Params().P
+
+	for _, pub := range []*PublicKey{
+		nil,
+		{Curve: curve},
+		{X: privkey.X, Y: privkey.Y},
+		{Curve: curve, X: new(big.Int), Y: new(big.Int)},
+		{Curve: curve, X: privkey.X, Y: new(big.Int).Add(privkey.Y, big.NewInt(1))},
+		{Curve: curve, X: privkey.X, Y: new(big.Int).Add(privkey.Y, p)},
+		{Curve: curve, X: new(big.Int).Neg(privkey.X), Y: privkey.Y},
+	} {
+		err := pub.Validate()
+		assert.True(t, errors.Is(err, ErrInvalidPublicKey), "%v", err)
+
+		_, err = Encrypt(pub, []byte("message"))
+		assert.Error(t, err)
+		_, err = privkey.Encapsulate(pub)
+		assert.Error(t, err)
+	}
 }
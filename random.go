@@ -0,0 +1,49 @@
+package eciesgo
+
+import (
+	"crypto/rand"
+	"io"
+)
+
+// Source of randomness for keys, nonces and content keys
+var randReader io.Reader = rand.Reader
+
+// SetRandReader replaces crypto/rand as the source of randomness, e.g. with hardware RNG on TinyGo targets
+// which lack crypto/rand support; nil restores crypto/rand.
+// It must be called before the package is used, it is not safe for concurrent use
+func SetRandReader(r io.Reader) {
+	if r == nil {
+		r = rand.Reader
+	}
+
+	randReader = r
+}
+
+// WithRand returns copy of config with source of randomness for ephemeral keys, nonces and content keys set,
+// so encryption becomes deterministic for test vectors; nil (default) uses crypto/rand or the reader set with SetRandReader.
+// Never use predictable reader outside of tests: reused ephemeral key and nonce break confidentiality
+func (c Config) WithRand(r io.Reader) Config {
+	c.rand = r
+	return c
+}
+
+// random returns source of randomness set with WithRand or package-wide one
+func (c Config) random() io.Reader {
+	if c.rand != nil {
+		return c.rand
+	}
+
+	return randReader
+}
+
+// randomBytes fills b with random bytes
+func randomBytes(b []byte) error {
+	_, err := io.ReadFull(randReader, b)
+	return err
+}
+
+// randomBytesConf fills b with random bytes read from config source of randomness
+func randomBytesConf(config Config, b []byte) error {
+	_, err := io.ReadFull(config.random(), b)
+	return err
+}
@@ -0,0 +1,106 @@
+package eciesgo
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetRandReader(t *testing.T) {
+	skipUnapproved(t)
+
+	defer SetRandReader(nil)
+
+	SetRandReader(bytes.NewReader(bytes.Repeat([]byte{1}, 1024)))
+	k1, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	SetRandReader(bytes.NewReader(bytes.Repeat([]byte{1}, 1024)))
+	k2, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, k1.Equals(k2))
+
+	// Exhausted source fails instead of producing weak keys
+	SetRandReader(bytes.NewReader(nil))
+	_, err = GenerateKey()
+	assert.Error(t, err)
+
+	SetRandReader(nil)
+	k3, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.False(t, k1.Equals(k3))
+}
+
+func TestConfig_WithRand(t *testing.T) {
+	skipUnapproved(t)
+
+	k, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	msg := []byte("deterministic")
+
+	encrypt := []struct {
+		name    string
+		encrypt func(config Config) ([]byte, error)
+	}{
+		{"single-shot", func(config Config) ([]byte, error) {
+			return EncryptConf(k.PublicKey, msg, config)
+		}},
+		{"multi", func(config Config) ([]byte, error) {
+			return EncryptMultiConf([]*PublicKey{k.PublicKey, k.PublicKey}, msg, config)
+		}},
+		{"stream", func(config Config) ([]byte, error) {
+			var buf bytes.Buffer
+			w, err := NewEncryptWriterConf(&buf, k.PublicKey, config)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := w.Write(msg); err != nil {
+				return nil, err
+			}
+			err = w.Close()
+			return buf.Bytes(), err
+		}},
+		{"kem", func(config Config) ([]byte, error) {
+			ephemeral, key, err := EncapsulateConf(k.PublicKey, config)
+			return append(ephemeral, key...), err
+		}},
+		{"hpke", func(config Config) ([]byte, error) {
+			return EncryptConf(k.PublicKey, msg, config.WithHPKE(HPKESuite{KDF: HPKEKDFHKDFSHA256, AEAD: HPKEAEADAES128GCM}))
+		}},
+		{"signed", func(config Config) ([]byte, error) {
+			return EncryptSignedConf(k, k.PublicKey, msg, config)
+		}},
+	}
+
+	for _, tc := range encrypt {
+		ct1, err := tc.encrypt(DEFAULT_CONFIG.WithRand(bytes.NewReader(bytes.Repeat([]byte{1}, 1024))))
+		if !assert.NoError(t, err, tc.name) {
+			continue
+		}
+		ct2, err := tc.encrypt(DEFAULT_CONFIG.WithRand(bytes.NewReader(bytes.Repeat([]byte{1}, 1024))))
+		if !assert.NoError(t, err, tc.name) {
+			continue
+		}
+		assert.Equal(t, ct1, ct2, tc.name)
+
+		// Package-wide source is used by default
+		ct3, err := tc.encrypt(DEFAULT_CONFIG)
+		if !assert.NoError(t, err, tc.name) {
+			continue
+		}
+		assert.NotEqual(t, ct1, ct3, tc.name)
+	}
+
+	// Exhausted source fails
+	_, err = EncryptConf(k.PublicKey, msg, DEFAULT_CONFIG.WithRand(bytes.NewReader(nil)))
+	assert.Error(t, err)
+}
@@ -0,0 +1,460 @@
+package eciesgo
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Double Ratchet (https://signal.org/docs/specifications/doubleratchet/) with header encryption over secp256k1:
+// every message is encrypted with a fresh key of a symmetric chain, and chains are replaced by a DH ratchet step
+// whenever the peer replies with a new ratchet key, which gives forward secrecy and post-compromise security.
+// Headers (ratchet key and message numbers) are encrypted, so messages are unlinkable to sessions by observers
+
+// Ratchet parameters
+const (
+	// RatchetMaxSkip is the maximal number of message keys skipped in a single chain, e.g. for lost
+	// or reordered messages; it bounds work an attacker causes with a forged message number
+	RatchetMaxSkip = 1000
+	// ratchetMaxSkipped bounds total number of stored skipped message keys, the oldest ones are dropped
+	ratchetMaxSkipped = 2 * RatchetMaxSkip
+
+	ratchetKeyLength    = 32
+	ratchetHeaderLength = 33 + 4 + 4
+	// ratchetEncryptedHeaderLength is length of encrypted header: XChaCha20-Poly1305 nonce, header and tag
+	ratchetEncryptedHeaderLength = chacha20poly1305.NonceSizeX + ratchetHeaderLength + chacha20poly1305.Overhead
+)
+
+// skippedKey identifies stored message key by header key and message number
+type skippedKey struct {
+	hk [ratchetKeyLength]byte
+	n  uint32
+}
+
+// Ratchet is one side of Double Ratchet session; both sides start from the same shared secret, e.g. agreed with
+// Noise handshake or X3DH, and the responder ratchet key known to initiator. It is safe for concurrent use
+type Ratchet struct {
+	mu sync.Mutex
+
+	dhs *PrivateKey
+	dhr *PublicKey
+	// ownsDHs is unset while dhs is the caller key of responder, which is not wiped
+	ownsDHs bool
+
+	rk, cks, ckr     []byte
+	hks, hkr         []byte
+	nhks, nhkr       []byte
+	ns, nr, pn       uint32
+	skipped          map[skippedKey][]byte
+	skippedOrder     []skippedKey
+	ephemeralFactory func() (*PrivateKey, error)
+}
+
+// NewRatchetInitiator starts session of the side sending the first message to the responder ratchet key
+func NewRatchetInitiator(sharedSecret []byte, remote *PublicKey) (*Ratchet, error) {
+	if err := checkNoiseKey(remote); err != nil {
+		return nil, fmt.Errorf("ratchet remote key: %w", err)
+	}
+	sk, hka, nhkb, err := ratchetInit(sharedSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Ratchet{
+		dhr:              remote,
+		hks:              hka,
+		nhkr:             nhkb,
+		skipped:          make(map[skippedKey][]byte),
+		ephemeralFactory: GenerateKey,
+	}
+	if r.dhs, err = r.ephemeralFactory(); err != nil {
+		return nil, err
+	}
+	r.ownsDHs = true
+
+	dh, err := r.dh()
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(dh)
+	if r.rk, r.cks, r.nhks, err = ratchetKDFRoot(sk, dh); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// NewRatchetResponder starts session of the side receiving the first message with its ratchet key pair,
+// which public key initiator uses; the key is not wiped by the session
+func NewRatchetResponder(sharedSecret []byte, keyPair *PrivateKey) (*Ratchet, error) {
+	if keyPair == nil || keyPair.D == nil {
+		return nil, fmt.Errorf("%w: ratchet key is empty", ErrInvalidPrivateKey)
+	}
+	if err := checkNoiseKey(keyPair.PublicKey); err != nil {
+		return nil, fmt.Errorf("ratchet key: %w", err)
+	}
+	sk, hka, nhkb, err := ratchetInit(sharedSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Ratchet{
+		dhs:              keyPair,
+		rk:               sk,
+		nhks:             nhkb,
+		nhkr:             hka,
+		skipped:          make(map[skippedKey][]byte),
+		ephemeralFactory: GenerateKey,
+	}, nil
+}
+
+// ratchetInit derives root key and initial header keys from the shared secret
+func ratchetInit(sharedSecret []byte) (sk, hka, nhkb []byte, err error) {
+	if len(sharedSecret) < 16 {
+		return nil, nil, nil, fmt.Errorf("ratchet shared secret is too short: %d", len(sharedSecret))
+	}
+
+	out := make([]byte, 3*ratchetKeyLength)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, sharedSecret, nil, []byte("ecies/ratchet-init")), out); err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot read ratchet keys from HKDF reader: %w", err)
+	}
+
+	return out[:32], out[32:64], out[64:], nil
+}
+
+// ratchetKDFRoot derives new root key, chain key and next header key from root key and DH output
+func ratchetKDFRoot(rk, dh []byte) (newRK, ck, nhk []byte, err error) {
+	out := make([]byte, 3*ratchetKeyLength)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, dh, rk, []byte("ecies/ratchet-root")), out); err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot read ratchet keys from HKDF reader: %w", err)
+	}
+
+	return out[:32], out[32:64], out[64:], nil
+}
+
+// ratchetKDFChain derives next chain key and message key
+func ratchetKDFChain(ck []byte) (newCK, mk []byte) {
+	mac := hmac.New(sha256.New, ck)
+	mac.Write([]byte{0x01})
+	mk = mac.Sum(nil)
+
+	mac = hmac.New(sha256.New, ck)
+	mac.Write([]byte{0x02})
+	newCK = mac.Sum(nil)
+
+	return newCK, mk
+}
+
+// dh computes DH of the current ratchet keys: compressed shared point
+func (r *Ratchet) dh() ([]byte, error) {
+	return r.dhs.ECDH(r.dhr)
+}
+
+// Encrypt encrypts the message with the next message key, ad is authenticated associated data;
+// output is encrypted header followed by ciphertext
+func (r *Ratchet) Encrypt(plaintext, ad []byte) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cks == nil {
+		return nil, fmt.Errorf("ratchet responder cannot send before receiving a message")
+	}
+
+	var mk []byte
+	r.cks, mk = ratchetKDFChain(r.cks)
+	defer zeroBytes(mk)
+
+	header := make([]byte, 0, ratchetHeaderLength)
+	header = append(header, r.dhs.PublicKey.Bytes(true)...)
+	header = append(header, 0, 0, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(header[33:], r.pn)
+	binary.BigEndian.PutUint32(header[37:], r.ns)
+
+	out, err := ratchetSealHeader(r.hks, header)
+	if err != nil {
+		return nil, err
+	}
+	r.ns++
+
+	return ratchetSeal(out, mk, plaintext, ad)
+}
+
+// Decrypt decrypts message produced by the peer Encrypt, ad is authenticated associated data; messages
+// may be lost or reordered up to RatchetMaxSkip per chain. Session state is unchanged if decryption fails
+func (r *Ratchet) Decrypt(msg, ad []byte) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(msg) < ratchetEncryptedHeaderLength+chacha20poly1305.Overhead {
+		return nil, newParseError(ErrCiphertextTooShort, "ratchet message", len(msg), "header or tag is truncated")
+	}
+	encHeader := msg[:ratchetEncryptedHeaderLength]
+
+	if pt, ok, err := r.trySkipped(msg, ad); ok || err != nil {
+		return pt, err
+	}
+
+	// State is changed on a copy, which replaces it only if the message is authentic
+	s := r.clone()
+
+	header, next, err := s.decryptHeader(encHeader)
+	if err != nil {
+		return nil, err
+	}
+	dhr, err := NewPublicKeyFromBytes(header[:33])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAuthenticationFailed, err)
+	}
+	pn, n := binary.BigEndian.Uint32(header[33:]), binary.BigEndian.Uint32(header[37:])
+
+	if next {
+		if err := s.skip(pn); err != nil {
+			return nil, err
+		}
+		if err := s.ratchetStep(dhr); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.skip(n); err != nil {
+		return nil, err
+	}
+
+	var mk []byte
+	s.ckr, mk = ratchetKDFChain(s.ckr)
+	defer zeroBytes(mk)
+	s.nr++
+
+	pt, err := ratchetOpen(mk, msg, ad)
+	if err != nil {
+		return nil, err
+	}
+
+	r.replace(s)
+	return pt, nil
+}
+
+// trySkipped decrypts message with a stored skipped key, ok is false if no key matches
+func (r *Ratchet) trySkipped(msg, ad []byte) (pt []byte, ok bool, err error) {
+	for _, id := range r.skippedOrder {
+		header, err := ratchetOpenHeader(id.hk[:], msg[:ratchetEncryptedHeaderLength])
+		if err != nil || binary.BigEndian.Uint32(header[37:]) != id.n {
+			continue
+		}
+
+		pt, err := ratchetOpen(r.skipped[id], msg, ad)
+		if err != nil {
+			return nil, false, err
+		}
+		r.dropSkipped(id)
+
+		return pt, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// decryptHeader opens header with the current receiving header key, or with the next one; next is set
+// in the latter case, which starts DH ratchet step
+func (r *Ratchet) decryptHeader(encHeader []byte) (header []byte, next bool, err error) {
+	if r.hkr != nil {
+		if header, err := ratchetOpenHeader(r.hkr, encHeader); err == nil {
+			return header, false, nil
+		}
+	}
+	if header, err := ratchetOpenHeader(r.nhkr, encHeader); err == nil {
+		return header, true, nil
+	}
+
+	return nil, false, fmt.Errorf("%w: cannot decrypt ratchet header", ErrAuthenticationFailed)
+}
+
+// skip stores message keys of the receiving chain up to message number until
+func (r *Ratchet) skip(until uint32) error {
+	if until < r.nr {
+		return fmt.Errorf("%w: ratchet message number %d is already received", ErrAuthenticationFailed, until)
+	}
+	if until-r.nr > RatchetMaxSkip {
+		return fmt.Errorf("%w: ratchet skips %d messages", ErrAuthenticationFailed, until-r.nr)
+	}
+	if r.ckr == nil {
+		return nil
+	}
+
+	for r.nr < until {
+		var mk []byte
+		r.ckr, mk = ratchetKDFChain(r.ckr)
+
+		var id skippedKey
+		copy(id.hk[:], r.hkr)
+		id.n = r.nr
+		r.skipped[id] = mk
+		r.skippedOrder = append(r.skippedOrder, id)
+		if len(r.skippedOrder) > ratchetMaxSkipped {
+			r.dropSkipped(r.skippedOrder[0])
+		}
+		r.nr++
+	}
+
+	return nil
+}
+
+func (r *Ratchet) dropSkipped(id skippedKey) {
+	zeroBytes(r.skipped[id])
+	delete(r.skipped, id)
+	for i := range r.skippedOrder {
+		if r.skippedOrder[i] == id {
+			r.skippedOrder = append(r.skippedOrder[:i:i], r.skippedOrder[i+1:]...)
+			break
+		}
+	}
+}
+
+// ratchetStep performs DH ratchet step with the new peer ratchet key
+func (r *Ratchet) ratchetStep(dhr *PublicKey) error {
+	r.pn, r.ns, r.nr = r.ns, 0, 0
+	r.hks, r.hkr = r.nhks, r.nhkr
+	r.dhr = dhr
+
+	dh, err := r.dh()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrAuthenticationFailed, err)
+	}
+	r.rk, r.ckr, r.nhkr, err = ratchetKDFRoot(r.rk, dh)
+	zeroBytes(dh)
+	if err != nil {
+		return err
+	}
+
+	dhs, err := r.ephemeralFactory()
+	if err != nil {
+		return err
+	}
+	r.dhs, r.ownsDHs = dhs, true
+
+	if dh, err = r.dh(); err != nil {
+		return err
+	}
+	r.rk, r.cks, r.nhks, err = ratchetKDFRoot(r.rk, dh)
+	zeroBytes(dh)
+	return err
+}
+
+// clone copies state for Decrypt; keys are replaced, not modified in place, so slices are shared
+func (r *Ratchet) clone() *Ratchet {
+	s := &Ratchet{
+		dhs: r.dhs, dhr: r.dhr, ownsDHs: r.ownsDHs,
+		rk: r.rk, cks: r.cks, ckr: r.ckr,
+		hks: r.hks, hkr: r.hkr, nhks: r.nhks, nhkr: r.nhkr,
+		ns: r.ns, nr: r.nr, pn: r.pn,
+		skipped:          make(map[skippedKey][]byte, len(r.skipped)),
+		skippedOrder:     append([]skippedKey(nil), r.skippedOrder...),
+		ephemeralFactory: r.ephemeralFactory,
+	}
+	for id, mk := range r.skipped {
+		s.skipped[id] = append([]byte(nil), mk...)
+	}
+
+	return s
+}
+
+// replace moves state of s into r, wiping replaced ratchet key
+func (r *Ratchet) replace(s *Ratchet) {
+	if r.ownsDHs && r.dhs != s.dhs {
+		r.dhs.Zeroize()
+	}
+	for id := range r.skipped {
+		zeroBytes(r.skipped[id])
+	}
+
+	r.dhs, r.dhr, r.ownsDHs = s.dhs, s.dhr, s.ownsDHs
+	r.rk, r.cks, r.ckr = s.rk, s.cks, s.ckr
+	r.hks, r.hkr, r.nhks, r.nhkr = s.hks, s.hkr, s.nhks, s.nhkr
+	r.ns, r.nr, r.pn = s.ns, s.nr, s.pn
+	r.skipped, r.skippedOrder = s.skipped, s.skippedOrder
+}
+
+// ratchetSealHeader appends random nonce and encrypted header to a new buffer
+func ratchetSealHeader(hk, header []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(hk)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create XChaCha20: %w", err)
+	}
+
+	out := make([]byte, chacha20poly1305.NonceSizeX, ratchetEncryptedHeaderLength)
+	if err := randomBytes(out); err != nil {
+		return nil, fmt.Errorf("cannot read random bytes for nonce: %w", err)
+	}
+
+	return aead.Seal(out, out, header, nil), nil
+}
+
+func ratchetOpenHeader(hk, encHeader []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(hk)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create XChaCha20: %w", err)
+	}
+
+	nonce := encHeader[:chacha20poly1305.NonceSizeX]
+	return aead.Open(nil, nonce, encHeader[chacha20poly1305.NonceSizeX:], nil)
+}
+
+// ratchetMessageCipher derives ChaCha20-Poly1305 key and nonce from message key
+func ratchetMessageCipher(mk []byte) (cipher.AEAD, []byte, error) {
+	out := make([]byte, chacha20poly1305.KeySize+chacha20poly1305.NonceSize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, mk, nil, []byte("ecies/ratchet-message")), out); err != nil {
+		return nil, nil, fmt.Errorf("cannot read message key from HKDF reader: %w", err)
+	}
+	defer zeroBytes(out[:chacha20poly1305.KeySize])
+
+	c, err := chacha20poly1305.New(out[:chacha20poly1305.KeySize])
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot create ChaCha20-Poly1305: %w", err)
+	}
+
+	return c, out[chacha20poly1305.KeySize:], nil
+}
+
+// ratchetSeal appends ciphertext to the encrypted header, both header and ad are authenticated
+func ratchetSeal(encHeader, mk, plaintext, ad []byte) ([]byte, error) {
+	aead, nonce, err := ratchetMessageCipher(mk)
+	if err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(encHeader, nonce, plaintext, ratchetAD(ad, encHeader)), nil
+}
+
+func ratchetOpen(mk, msg, ad []byte) ([]byte, error) {
+	aead, nonce, err := ratchetMessageCipher(mk)
+	if err != nil {
+		return nil, err
+	}
+
+	encHeader := msg[:ratchetEncryptedHeaderLength]
+	pt, err := aead.Open(nil, nonce, msg[ratchetEncryptedHeaderLength:], ratchetAD(ad, encHeader))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAuthenticationFailed, err)
+	}
+
+	return pt, nil
+}
+
+// ratchetAD encodes associated data and encrypted header unambiguously
+func ratchetAD(ad, encHeader []byte) []byte {
+	var buf bytes.Buffer
+	var l [8]byte
+	binary.BigEndian.PutUint64(l[:], uint64(len(ad)))
+	buf.Write(l[:])
+	buf.Write(ad)
+	buf.Write(encHeader)
+
+	return buf.Bytes()
+}
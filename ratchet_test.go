@@ -0,0 +1,175 @@
+package eciesgo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRatchets(t *testing.T) (alice, bob *Ratchet) {
+	bobKey, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("shared secret agreed by handshake")
+
+	if alice, err = NewRatchetInitiator(secret, bobKey.PublicKey); err != nil {
+		t.Fatal(err)
+	}
+	if bob, err = NewRatchetResponder(secret, bobKey); err != nil {
+		t.Fatal(err)
+	}
+	return alice, bob
+}
+
+func TestRatchet(t *testing.T) {
+	skipUnapproved(t)
+
+	alice, bob := newTestRatchets(t)
+
+	// Responder sends only after the first message
+	_, err := bob.Encrypt([]byte("hello"), nil)
+	assert.Error(t, err)
+
+	ad := []byte("channel")
+	send := func(from, to *Ratchet, msg string) {
+		ct, err := from.Encrypt([]byte(msg), ad)
+		if !assert.NoError(t, err) {
+			return
+		}
+		pt, err := to.Decrypt(ct, ad)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, msg, string(pt))
+	}
+
+	send(alice, bob, "a1")
+	send(alice, bob, "a2")
+	send(bob, alice, "b1")
+	send(alice, bob, "a3")
+	send(bob, alice, "b2")
+	send(bob, alice, "b3")
+
+	// Ratchet key changes on every DH ratchet step, old message keys are gone
+	ct, err := alice.Encrypt([]byte("a4"), ad)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = bob.Decrypt(ct, ad)
+	assert.NoError(t, err)
+	_, err = bob.Decrypt(ct, ad)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+
+	// Other associated data
+	ct, err = alice.Encrypt([]byte("a5"), ad)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = bob.Decrypt(ct, []byte("other"))
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+	pt, err := bob.Decrypt(ct, ad)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "a5", string(pt))
+}
+
+func TestRatchet_OutOfOrder(t *testing.T) {
+	skipUnapproved(t)
+
+	alice, bob := newTestRatchets(t)
+
+	var cts [][]byte
+	for i := 0; i < 5; i++ {
+		ct, err := alice.Encrypt([]byte(fmt.Sprintf("a%d", i)), nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		cts = append(cts, ct)
+	}
+
+	// Messages of the previous chain arrive after DH ratchet step
+	for _, i := range []int{3, 0} {
+		pt, err := bob.Decrypt(cts[i], nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, fmt.Sprintf("a%d", i), string(pt))
+	}
+	reply, err := bob.Encrypt([]byte("b0"), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = alice.Decrypt(reply, nil)
+	assert.NoError(t, err)
+	next, err := alice.Encrypt([]byte("a5"), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = bob.Decrypt(next, nil)
+	assert.NoError(t, err)
+
+	for _, i := range []int{4, 1, 2} {
+		pt, err := bob.Decrypt(cts[i], nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, fmt.Sprintf("a%d", i), string(pt))
+	}
+	assert.Len(t, bob.skipped, 0)
+}
+
+func TestRatchet_Invalid(t *testing.T) {
+	skipUnapproved(t)
+
+	alice, bob := newTestRatchets(t)
+
+	ct, err := alice.Encrypt([]byte("hello"), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Failed decryption leaves state unchanged
+	tampered := append([]byte(nil), ct...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = bob.Decrypt(tampered, nil)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+	tampered = append([]byte(nil), ct...)
+	tampered[30] ^= 1
+	_, err = bob.Decrypt(tampered, nil)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+	_, err = bob.Decrypt(ct[:50], nil)
+	assert.True(t, errors.Is(err, ErrCiphertextTooShort), err)
+
+	pt, err := bob.Decrypt(ct, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "hello", string(pt))
+
+	// Message of another session
+	eve, _ := newTestRatchets(t)
+	ct, err = eve.Encrypt([]byte("hello"), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = bob.Decrypt(ct, nil)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+
+	// Too many skipped messages
+	for i := 0; i <= RatchetMaxSkip+1; i++ {
+		if ct, err = alice.Encrypt(nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = bob.Decrypt(ct, nil)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+
+	_, err = NewRatchetInitiator([]byte("short"), bob.dhs.PublicKey)
+	assert.Error(t, err)
+	_, err = NewRatchetResponder([]byte("shared secret agreed by handshake"), nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,62 @@
+package eciesgo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// KeyResolver finds recipient public keys by identifier, e.g. in a local store, directory service or DNS;
+// resolution policy (caching, trust, transport) is up to the implementation. TrustStore is a KeyResolver
+// of pinned keys by fingerprint
+type KeyResolver interface {
+	ResolveKey(ctx context.Context, id string) (*PublicKey, error)
+}
+
+// KeyResolverFunc is an adapter to allow the use of ordinary functions as KeyResolver
+type KeyResolverFunc func(ctx context.Context, id string) (*PublicKey, error)
+
+// ResolveKey calls f(ctx, id)
+func (f KeyResolverFunc) ResolveKey(ctx context.Context, id string) (*PublicKey, error) {
+	return f(ctx, id)
+}
+
+// WithKeyResolver returns copy of config resolving recipients of EncryptTo with the resolver
+func (c Config) WithKeyResolver(r KeyResolver) Config {
+	c.keyResolver = r
+	return c
+}
+
+// ResolveKey returns key pinned with the fingerprint, it implements KeyResolver
+func (s *TrustStore) ResolveKey(_ context.Context, fingerprint string) (*PublicKey, error) {
+	pub, _, err := s.Lookup(fingerprint)
+	return pub, err
+}
+
+// EncryptTo encrypts a passed message to the recipient with the key fingerprint (see PublicKey.Fingerprint),
+// resolving the key with the resolver of the default config
+func EncryptTo(fingerprint string, msg []byte) ([]byte, error) {
+	return EncryptToContext(context.Background(), fingerprint, msg, DefaultConfig())
+}
+
+// EncryptToContext encrypts a passed message to the recipient with the key fingerprint and the passed config,
+// resolving the key with the config resolver (see WithKeyResolver); resolved key must have the fingerprint,
+// so the resolver is not trusted to return the right key
+func EncryptToContext(ctx context.Context, fingerprint string, msg []byte, config Config) ([]byte, error) {
+	if config.keyResolver == nil {
+		return nil, fmt.Errorf("key resolver is not set")
+	}
+
+	pub, err := config.keyResolver.ResolveKey(ctx, fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve key %s: %w", fingerprint, err)
+	}
+	if pub == nil || pub.X == nil || pub.Y == nil {
+		return nil, fmt.Errorf("%w: resolved key %s is empty", ErrInvalidPublicKey, fingerprint)
+	}
+	if got := pub.Fingerprint(); got != strings.ToLower(fingerprint) {
+		return nil, fmt.Errorf("%w: resolved key has fingerprint %s, expected %s", ErrUntrustedKey, got, fingerprint)
+	}
+
+	return EncryptContext(ctx, pub, msg, config)
+}
@@ -0,0 +1,175 @@
+// Package eciesdns discovers recipient public keys published in DNS for email-address-like identifiers,
+// in the manner of OPENPGPKEY records (RFC 7929): key of user@example.com is published as TXT record
+//
+//	<hex SHA-256 of "user" truncated to 28 bytes>._ecieskey.example.com. IN TXT "v=ecies1; k=<hex key>"
+//
+// with optional "crv=<registered curve name>" for keys of curves other than secp256k1 (see eciesgo.CurveByName).
+// Resolver implements eciesgo.KeyResolver.
+//
+// DNS answers are trusted only as far as the path to the resolver is: the default lookup uses net.Resolver,
+// which does not report DNSSEC validation, so set Lookuper to a validating client (or a local validating
+// resolver checked for the AD bit) and RequireDNSSEC to reject unauthenticated answers
+package eciesdns
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	eciesgo "github.com/ecies/go/v2"
+)
+
+// DefaultLabel is the label under the domain which holds key records
+const DefaultLabel = "_ecieskey"
+
+// ErrNotAuthenticated is returned when DNSSEC is required, but the answer was not validated
+var ErrNotAuthenticated = errors.New("DNS answer is not authenticated")
+
+// Lookuper looks up TXT records; authenticated reports whether the answer was DNSSEC validated
+type Lookuper interface {
+	LookupTXT(ctx context.Context, name string) (records []string, authenticated bool, err error)
+}
+
+// LookuperFunc is an adapter to allow the use of ordinary functions as Lookuper
+type LookuperFunc func(ctx context.Context, name string) ([]string, bool, error)
+
+// LookupTXT calls f(ctx, name)
+func (f LookuperFunc) LookupTXT(ctx context.Context, name string) ([]string, bool, error) {
+	return f(ctx, name)
+}
+
+// netLookuper looks up with net.DefaultResolver, answers are never authenticated
+type netLookuper struct{}
+
+func (netLookuper) LookupTXT(ctx context.Context, name string) ([]string, bool, error) {
+	records, err := net.DefaultResolver.LookupTXT(ctx, name)
+	return records, false, err
+}
+
+// Resolver resolves keys of email-address-like identifiers from DNS
+type Resolver struct {
+	// Lookuper looks up records, nil uses net.DefaultResolver
+	Lookuper Lookuper
+	// RequireDNSSEC rejects answers which are not DNSSEC validated with ErrNotAuthenticated
+	RequireDNSSEC bool
+	// Label is the label of key records, empty uses DefaultLabel
+	Label string
+}
+
+// ResolveKey returns key published for the identifier, it implements eciesgo.KeyResolver;
+// several valid keys for one identifier are an error, as the right one cannot be chosen
+func (r *Resolver) ResolveKey(ctx context.Context, id string) (*eciesgo.PublicKey, error) {
+	name, err := RecordName(id, r.Label)
+	if err != nil {
+		return nil, err
+	}
+
+	lookuper := r.Lookuper
+	if lookuper == nil {
+		lookuper = netLookuper{}
+	}
+	records, authenticated, err := lookuper.LookupTXT(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot look up %s: %w", name, err)
+	}
+	if r.RequireDNSSEC && !authenticated {
+		return nil, fmt.Errorf("%w: %s", ErrNotAuthenticated, name)
+	}
+
+	var found *eciesgo.PublicKey
+	for _, record := range records {
+		pub, ok, err := ParseRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("record %s: %w", name, err)
+		}
+		if !ok {
+			continue
+		}
+		if found != nil && !found.Equals(pub) {
+			return nil, fmt.Errorf("%s has several keys", name)
+		}
+		found = pub
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%s has no key records", name)
+	}
+
+	return found, nil
+}
+
+// RecordName returns name of the key record of user@domain identifier; local part is hashed as is,
+// without case folding, as in RFC 7929. Empty label uses DefaultLabel
+func RecordName(id, label string) (string, error) {
+	at := strings.LastIndex(id, "@")
+	if at <= 0 || at == len(id)-1 {
+		return "", fmt.Errorf("identifier %q is not of user@domain form", id)
+	}
+	if label == "" {
+		label = DefaultLabel
+	}
+
+	h := sha256.Sum256([]byte(id[:at]))
+	return hex.EncodeToString(h[:28]) + "." + label + "." + strings.TrimSuffix(id[at+1:], ".") + ".", nil
+}
+
+// Record returns TXT record content publishing the key, the key is compressed
+func Record(pub *eciesgo.PublicKey) (string, error) {
+	name, err := eciesgo.CurveName(pub.Curve)
+	if err != nil {
+		return "", err
+	}
+
+	record := "v=ecies1; k=" + pub.Hex(true)
+	if name != "secp256k1" {
+		record += "; crv=" + name
+	}
+
+	return record, nil
+}
+
+// ParseRecord parses TXT record content; ok is false for records of other versions or applications,
+// which share the name
+func ParseRecord(record string) (pub *eciesgo.PublicKey, ok bool, err error) {
+	if !strings.HasPrefix(strings.TrimSpace(record), "v=ecies1") {
+		return nil, false, nil
+	}
+
+	fields := make(map[string]string)
+	for _, part := range strings.Split(record, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, false, fmt.Errorf("malformed field %q", part)
+		}
+		fields[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	if fields["v"] != "ecies1" {
+		return nil, false, nil
+	}
+
+	b, err := hex.DecodeString(fields["k"])
+	if err != nil || len(b) == 0 {
+		return nil, false, fmt.Errorf("%w: malformed key", eciesgo.ErrInvalidPublicKey)
+	}
+
+	crv := fields["crv"]
+	if crv == "" {
+		crv = "secp256k1"
+	}
+	curve, err := eciesgo.CurveByName(crv)
+	if err != nil {
+		return nil, false, err
+	}
+	if pub, err = eciesgo.NewPublicKeyFromBytesCurve(curve, b); err != nil {
+		return nil, false, err
+	}
+
+	return pub, true, nil
+}
@@ -0,0 +1,122 @@
+package eciesdns
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	eciesgo "github.com/ecies/go/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// testZone serves records by name, answers are authenticated if the zone is signed
+type testZone struct {
+	records map[string][]string
+	signed  bool
+}
+
+func (z testZone) LookupTXT(_ context.Context, name string) ([]string, bool, error) {
+	records, ok := z.records[name]
+	if !ok {
+		return nil, false, errors.New("no such host")
+	}
+	return records, z.signed, nil
+}
+
+func TestRecordName(t *testing.T) {
+	// Example of RFC 7929, section 3 (hugh@example.com)
+	name, err := RecordName("hugh@example.com", "_openpgpkey")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "c93f1e400f26708f98cb19d936620da35eec8f72e57f9eec01c1afd6._openpgpkey.example.com.", name)
+
+	name, err = RecordName("hugh@example.com", "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "c93f1e400f26708f98cb19d936620da35eec8f72e57f9eec01c1afd6._ecieskey.example.com.", name)
+
+	for _, id := range []string{"example.com", "@example.com", "hugh@"} {
+		_, err := RecordName(id, "")
+		assert.Error(t, err, id)
+	}
+}
+
+func TestResolver(t *testing.T) {
+	if eciesgo.FIPSMode() {
+		t.Skip("secp256k1 is not approved in FIPS mode")
+	}
+
+	alice, err := eciesgo.GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	bob, err := eciesgo.GenerateKeyCurve(eciesgo.BrainpoolP256r1())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	aliceRecord, err := Record(alice.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	bobRecord, err := Record(bob.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, bobRecord, "crv=brainpoolP256r1")
+
+	aliceName, _ := RecordName("alice@example.com", "")
+	bobName, _ := RecordName("bob@example.com", "")
+	malloryName, _ := RecordName("mallory@example.com", "")
+	zone := testZone{records: map[string][]string{
+		aliceName:   {"v=spf1 -all", aliceRecord},
+		bobName:     {bobRecord},
+		malloryName: {aliceRecord, bobRecord},
+	}}
+
+	r := &Resolver{Lookuper: zone}
+	pub, err := r.ResolveKey(context.Background(), "alice@example.com")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, pub.Equals(alice.PublicKey))
+	pub, err = r.ResolveKey(context.Background(), "bob@example.com")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, pub.Equals(bob.PublicKey))
+
+	_, err = r.ResolveKey(context.Background(), "mallory@example.com")
+	assert.Error(t, err)
+	_, err = r.ResolveKey(context.Background(), "eve@example.com")
+	assert.Error(t, err)
+
+	// Unsigned zone is rejected when DNSSEC is required
+	r.RequireDNSSEC = true
+	_, err = r.ResolveKey(context.Background(), "alice@example.com")
+	assert.True(t, errors.Is(err, ErrNotAuthenticated), err)
+	zone.signed = true
+	r.Lookuper = zone
+	_, err = r.ResolveKey(context.Background(), "alice@example.com")
+	assert.NoError(t, err)
+
+}
+
+func TestParseRecord(t *testing.T) {
+	for _, record := range []string{"v=spf1 include:example.com", "site verification", "v=ecies10; k=02aa"} {
+		_, ok, err := ParseRecord(record)
+		assert.NoError(t, err, record)
+		assert.False(t, ok, record)
+	}
+
+	_, _, err := ParseRecord("v=ecies1; k=zz")
+	assert.True(t, errors.Is(err, eciesgo.ErrInvalidPublicKey), err)
+	_, _, err = ParseRecord("v=ecies1; k=02aa")
+	assert.True(t, errors.Is(err, eciesgo.ErrInvalidPublicKey), err)
+	_, _, err = ParseRecord("v=ecies1; k=02aa; crv=curve25519")
+	assert.True(t, errors.Is(err, eciesgo.ErrUnsupportedCurve), err)
+	_, _, err = ParseRecord("v=ecies1; broken")
+	assert.Error(t, err)
+}
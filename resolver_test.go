@@ -0,0 +1,58 @@
+package eciesgo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptTo(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	other, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	store := NewTrustStore()
+	if !assert.NoError(t, store.Add("alice", privkey.PublicKey)) {
+		return
+	}
+	config := DEFAULT_CONFIG.WithKeyResolver(store)
+
+	ct, err := EncryptToContext(context.Background(), strings.ToUpper(privkey.Fingerprint()), []byte(testingMessage), config)
+	if !assert.NoError(t, err) {
+		return
+	}
+	pt, err := Decrypt(privkey, ct)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(pt))
+
+	_, err = EncryptToContext(context.Background(), other.Fingerprint(), []byte(testingMessage), config)
+	assert.True(t, errors.Is(err, ErrUntrustedKey), err)
+
+	// Resolved key must match the fingerprint
+	lying := KeyResolverFunc(func(ctx context.Context, id string) (*PublicKey, error) {
+		return other.PublicKey, nil
+	})
+	_, err = EncryptToContext(context.Background(), privkey.Fingerprint(), []byte(testingMessage), config.WithKeyResolver(lying))
+	assert.True(t, errors.Is(err, ErrUntrustedKey), err)
+
+	// Default config has no resolver
+	_, err = EncryptTo(privkey.Fingerprint(), []byte(testingMessage))
+	assert.Error(t, err)
+
+	SetDefaultConfig(config)
+	defer SetDefaultConfig(DEFAULT_CONFIG)
+	_, err = EncryptTo(privkey.Fingerprint(), []byte(testingMessage))
+	assert.NoError(t, err)
+}
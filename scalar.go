@@ -0,0 +1,238 @@
+package eciesgo
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// Scalar is secp256k1 scalar (integer modulo the group order n) with fixed 32 bytes encoding;
+// unlike big.Int, arithmetic and comparison run in constant time and do not depend on the value length.
+// PrivateKey.D stays big.Int for compatibility, so secret arithmetic of signing, HD and stealth key derivation
+// converts secp256k1 keys to Scalar and Point instead
+type Scalar struct {
+	s secp256k1.ModNScalar
+}
+
+// NewScalarFromBytes decodes 32 bytes big endian scalar, values not less than n are rejected
+func NewScalarFromBytes(b []byte) (*Scalar, error) {
+	if len(b) != 32 {
+		return nil, fmt.Errorf("invalid scalar length %d", len(b))
+	}
+
+	var s Scalar
+	if s.s.SetByteSlice(b) {
+		s.Zeroize()
+		return nil, fmt.Errorf("scalar is out of range")
+	}
+
+	return &s, nil
+}
+
+// newScalarReduced returns v mod n for v of at most 32 bytes, e.g. hash or nonce
+func newScalarReduced(v *big.Int) *Scalar {
+	b := zeroPad(v.Bytes(), 32)
+	defer zeroBytes(b)
+
+	return newScalarReducedBytes(b)
+}
+
+// newScalarReducedBytes returns 32 bytes big endian b mod n, so secret hashes are reduced without big.Int
+func newScalarReducedBytes(b []byte) *Scalar {
+	var s Scalar
+	s.s.SetByteSlice(b)
+	return &s
+}
+
+// Bytes returns 32 bytes big endian encoding of the scalar
+func (s *Scalar) Bytes() []byte {
+	b := s.s.Bytes()
+	return b[:]
+}
+
+// Add returns s + other mod n
+func (s *Scalar) Add(other *Scalar) *Scalar {
+	var r Scalar
+	r.s.Add2(&s.s, &other.s)
+	return &r
+}
+
+// Mul returns s * other mod n
+func (s *Scalar) Mul(other *Scalar) *Scalar {
+	var r Scalar
+	r.s.Mul2(&s.s, &other.s)
+	return &r
+}
+
+// inverse returns s^-1 mod n computed as s^(n-2); the exponent is public, so unlike big.Int.ModInverse
+// the running time does not depend on s. Zero scalar gives zero
+func (s *Scalar) inverse() *Scalar {
+	e := new(big.Int).Sub(getCurve().Params().N, big.NewInt(2))
+
+	var r Scalar
+	r.s.SetInt(1)
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		r.s.Square()
+		if e.Bit(i) == 1 {
+			r.s.Mul(&s.s)
+		}
+	}
+
+	return &r
+}
+
+// big returns the scalar as big.Int, e.g. for PrivateKey.D
+func (s *Scalar) big() *big.Int {
+	b := s.s.Bytes()
+	defer zeroBytes(b[:])
+
+	return new(big.Int).SetBytes(b[:])
+}
+
+// Negate returns -s mod n
+func (s *Scalar) Negate() *Scalar {
+	var r Scalar
+	r.s.NegateVal(&s.s)
+	return &r
+}
+
+// Equal compares two scalars in constant time
+func (s *Scalar) Equal(other *Scalar) bool {
+	return s.s.Equals(&other.s)
+}
+
+// IsZero reports whether the scalar is zero, which is not a valid private key
+func (s *Scalar) IsZero() bool {
+	return s.s.IsZero()
+}
+
+// Zeroize overwrites the scalar with zero
+func (s *Scalar) Zeroize() {
+	s.s.Zero()
+}
+
+// Point is secp256k1 point other than the point at infinity with fixed width encodings;
+// every Point is validated on construction, so operations do not have to check curve membership
+type Point struct {
+	x, y secp256k1.FieldVal
+}
+
+// NewPointFromBytes decodes SEC 1 compressed (33 bytes) or uncompressed (65 bytes) point
+func NewPointFromBytes(b []byte) (*Point, error) {
+	pub, err := secp256k1.ParsePubKey(b)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+
+	var j secp256k1.JacobianPoint
+	pub.AsJacobian(&j)
+
+	return &Point{x: j.X, y: j.Y}, nil
+}
+
+// ScalarBaseMult returns s * G, zero scalar is rejected
+func ScalarBaseMult(s *Scalar) (*Point, error) {
+	return newPointFromBig(scalarBaseMult(getCurve(), s.Bytes()))
+}
+
+// Bytes returns SEC 1 encoding of the point: 33 bytes compressed or 65 bytes uncompressed
+func (p *Point) Bytes(compressed bool) []byte {
+	if compressed {
+		b := make([]byte, 33)
+		b[0] = 0x02
+		if p.y.IsOdd() {
+			b[0] = 0x03
+		}
+		p.x.PutBytesUnchecked(b[1:])
+		return b
+	}
+
+	b := make([]byte, 65)
+	b[0] = 0x04
+	p.x.PutBytesUnchecked(b[1:33])
+	p.y.PutBytesUnchecked(b[33:])
+	return b
+}
+
+// Add returns p + other; points are public, so variable time addition is used
+func (p *Point) Add(other *Point) (*Point, error) {
+	var a, b, r secp256k1.JacobianPoint
+	a.X, a.Y = p.x, p.y
+	a.Z.SetInt(1)
+	b.X, b.Y = other.x, other.y
+	b.Z.SetInt(1)
+
+	secp256k1.AddNonConst(&a, &b, &r)
+	if r.Z.IsZero() {
+		return nil, fmt.Errorf("result is point at infinity")
+	}
+	r.ToAffine()
+
+	return &Point{x: r.X, y: r.Y}, nil
+}
+
+// ScalarMult returns s * p; multiplication runs in constant time (see scalarMult)
+func (p *Point) ScalarMult(s *Scalar) (*Point, error) {
+	x, y := p.big()
+	return newPointFromBig(scalarMult(getCurve(), x, y, s.Bytes()))
+}
+
+// Equal compares two points in constant time
+func (p *Point) Equal(other *Point) bool {
+	return subtle.ConstantTimeCompare(p.Bytes(false), other.Bytes(false)) == 1
+}
+
+// PublicKey returns the point as public key
+func (p *Point) PublicKey() *PublicKey {
+	x, y := p.big()
+	return &PublicKey{Curve: getCurve(), X: x, Y: y}
+}
+
+func (p *Point) big() (x, y *big.Int) {
+	b := p.Bytes(false)
+	return new(big.Int).SetBytes(b[1:33]), new(big.Int).SetBytes(b[33:])
+}
+
+// newPointFromBig wraps scalarMult result into Point, point at infinity (of zero scalar) is rejected
+func newPointFromBig(x, y *big.Int) (*Point, error) {
+	if x == nil || y == nil || (x.Sign() == 0 && y.Sign() == 0) {
+		return nil, fmt.Errorf("result is point at infinity")
+	}
+
+	var p Point
+	p.x.SetByteSlice(zeroPad(x.Bytes(), 32))
+	p.y.SetByteSlice(zeroPad(y.Bytes(), 32))
+	p.x.Normalize()
+	p.y.Normalize()
+
+	return &p, nil
+}
+
+// Scalar returns private scalar as Scalar; only secp256k1 keys are supported
+func (k *PrivateKey) Scalar() (*Scalar, error) {
+	if err := k.Validate(); err != nil {
+		return nil, err
+	}
+	if !sameCurve(k.Curve, getCurve()) {
+		return nil, fmt.Errorf("%w: scalar requires secp256k1 key", ErrInvalidPrivateKey)
+	}
+
+	b := k.Bytes()
+	defer zeroBytes(b)
+
+	return NewScalarFromBytes(b)
+}
+
+// Point returns validated public key as Point; only secp256k1 keys are supported
+func (k *PublicKey) Point() (*Point, error) {
+	if err := k.Validate(); err != nil {
+		return nil, err
+	}
+	if !sameCurve(k.Curve, getCurve()) {
+		return nil, fmt.Errorf("%w: point requires secp256k1 key", ErrInvalidPublicKey)
+	}
+
+	return NewPointFromBytes(k.Bytes(false))
+}
@@ -0,0 +1,148 @@
+package eciesgo
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScalar(t *testing.T) {
+	_, err := NewScalarFromBytes(make([]byte, 31))
+	assert.Error(t, err)
+	_, err = NewScalarFromBytes(getCurve().Params().N.Bytes())
+	assert.Error(t, err)
+
+	one := make([]byte, 32)
+	one[31] = 1
+	s1, err := NewScalarFromBytes(one)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.False(t, s1.IsZero())
+	assert.True(t, s1.Add(s1.Negate()).IsZero())
+	assert.True(t, s1.Mul(s1).Equal(s1))
+
+	two := make([]byte, 32)
+	two[31] = 2
+	s2, err := NewScalarFromBytes(two)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, two, s1.Add(s1).Bytes())
+	assert.False(t, s1.Equal(s2))
+
+	assert.True(t, s2.Mul(s2.inverse()).Equal(s1))
+	assert.True(t, s1.inverse().Equal(s1))
+
+	s2.Zeroize()
+	assert.True(t, s2.IsZero())
+}
+
+func TestPoint(t *testing.T) {
+	skipUnapproved(t)
+
+	k1, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	k2, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	s1, err := k1.Scalar()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, k1.Bytes(), s1.Bytes())
+
+	p1, err := k1.PublicKey.Point()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, k1.PublicKey.Bytes(true), p1.Bytes(true))
+	assert.Equal(t, k1.PublicKey.Bytes(false), p1.Bytes(false))
+	assert.True(t, p1.PublicKey().Equals(k1.PublicKey))
+
+	// Public key is scalar multiple of generator
+	g, err := ScalarBaseMult(s1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, g.Equal(p1))
+	_, err = ScalarBaseMult(s1.Add(s1.Negate()))
+	assert.Error(t, err)
+
+	// Shared point matches ECDHPoint
+	p2, err := k2.PublicKey.Point()
+	if !assert.NoError(t, err) {
+		return
+	}
+	shared, err := p2.ScalarMult(s1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	expected, err := k1.ECDHPoint(k2.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, bytes.Equal(expected.Bytes(false), shared.Bytes(false)))
+
+	// Addition matches PublicKey.Add
+	sum, err := p1.Add(p2)
+	if !assert.NoError(t, err) {
+		return
+	}
+	expected, err = k1.PublicKey.Add(k2.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, expected.Bytes(true), sum.Bytes(true))
+	neg, err := k1.PublicKey.Negate().Point()
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = p1.Add(neg)
+	assert.Error(t, err)
+
+	// Decoding validates curve membership
+	decoded, err := NewPointFromBytes(p1.Bytes(true))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, decoded.Equal(p1))
+	invalid := p1.Bytes(false)
+	invalid[64] ^= 1
+	_, err = NewPointFromBytes(invalid)
+	assert.True(t, errors.Is(err, ErrInvalidPublicKey), err)
+}
+
+func TestSignSScalar(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	n := privkey.Curve.Params().N
+
+	// Scalar arithmetic of secp256k1 keys matches big.Int one, hash and nonce are reduced modulo n
+	for _, values := range [][3]*big.Int{
+		{big.NewInt(7), big.NewInt(11), big.NewInt(13)},
+		{new(big.Int).Sub(n, big.NewInt(1)), new(big.Int).Add(n, big.NewInt(5)), new(big.Int).Sub(n, big.NewInt(2))},
+	} {
+		nonce, r, e := values[0], values[1], values[2]
+		s, err := privkey.signS(nonce, r, e)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		expected := new(big.Int).Mul(r, privkey.D)
+		expected.Add(expected, e)
+		expected.Mul(expected, new(big.Int).ModInverse(nonce, n))
+		assert.Equal(t, expected.Mod(expected, n), s)
+	}
+}
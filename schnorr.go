@@ -0,0 +1,158 @@
+package eciesgo
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+// NewPublicKeyFromXOnly decodes 32 bytes x-only public key (BIP-340), Y coordinate is chosen to be even
+func NewPublicKeyFromXOnly(b []byte) (*PublicKey, error) {
+	if len(b) != 32 {
+		return nil, fmt.Errorf("invalid length of x-only public key")
+	}
+
+	curve := getCurve()
+	x := new(big.Int).SetBytes(b)
+	y, err := yFromX(curve, x)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse x-only public key: %w", err)
+	}
+
+	return &PublicKey{
+		Curve: curve,
+		X:     x,
+		Y:     y,
+	}, nil
+}
+
+// XOnlyBytes returns 32 bytes x-only public key encoding (BIP-340)
+func (k *PublicKey) XOnlyBytes() []byte {
+	return zeroPad(k.X.Bytes(), 32)
+}
+
+// SignSchnorr signs message with BIP-340 Schnorr signature scheme and returns 64 bytes signature;
+// auxRand is 32 bytes of auxiliary randomness, it is read from crypto/rand (or SetRandReader source) if nil.
+// Only secp256k1 keys are supported, secret arithmetic is done with constant time Scalar
+func (k *PrivateKey) SignSchnorr(msg, auxRand []byte) ([]byte, error) {
+	if auxRand == nil {
+		auxRand = make([]byte, 32)
+		if err := randomBytes(auxRand); err != nil {
+			return nil, fmt.Errorf("cannot read random bytes for auxiliary randomness: %w", err)
+		}
+	}
+	if len(auxRand) != 32 {
+		return nil, fmt.Errorf("invalid length of auxiliary randomness")
+	}
+
+	// Scalar validates the key and rejects other curves than secp256k1
+	d, err := k.Scalar()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { d.Zeroize() }()
+
+	p, err := k.PublicKey.Point()
+	if err != nil {
+		return nil, err
+	}
+	pb := p.Bytes(true)
+	px := pb[1:]
+
+	// Private key is negated if its public key has odd Y, so the x-only public key corresponds to it
+	if pb[0] == 0x03 {
+		neg := d.Negate()
+		d.Zeroize()
+		d = neg
+	}
+
+	db := d.Bytes()
+	defer zeroBytes(db)
+	t := taggedHash("BIP0340/aux", auxRand)
+	defer zeroBytes(t)
+	for i, b := range db {
+		t[i] ^= b
+	}
+
+	nonce := taggedHash("BIP0340/nonce", t, px, msg)
+	defer zeroBytes(nonce)
+	kk := newScalarReducedBytes(nonce)
+	defer func() { kk.Zeroize() }()
+	if kk.IsZero() {
+		return nil, fmt.Errorf("nonce is zero")
+	}
+
+	rp, err := ScalarBaseMult(kk)
+	if err != nil {
+		return nil, err
+	}
+	rb := rp.Bytes(true)
+	if rb[0] == 0x03 {
+		neg := kk.Negate()
+		kk.Zeroize()
+		kk = neg
+	}
+	r := rb[1:]
+
+	e := newScalarReducedBytes(taggedHash("BIP0340/challenge", r, px, msg))
+
+	// s = k + e d mod n
+	sig := append(append([]byte(nil), r...), e.Mul(d).Add(kk).Bytes()...)
+
+	// Verify the signature to protect against fault attacks
+	if !k.PublicKey.VerifySchnorr(msg, sig) {
+		return nil, fmt.Errorf("cannot verify produced signature")
+	}
+
+	return sig, nil
+}
+
+// VerifySchnorr verifies BIP-340 Schnorr signature of message; only X coordinate of the public key is used
+func (k *PublicKey) VerifySchnorr(msg, sig []byte) bool {
+	if len(sig) != 64 {
+		return false
+	}
+
+	pub, err := NewPublicKeyFromXOnly(k.XOnlyBytes())
+	if err != nil {
+		return false
+	}
+
+	curve := pub.Curve
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	if r.Cmp(curve.Params().P) >= 0 || s.Cmp(curve.Params().N) >= 0 {
+		return false
+	}
+
+	e := new(big.Int).SetBytes(taggedHash("BIP0340/challenge", sig[:32], pub.XOnlyBytes(), msg))
+	e.Mod(e, curve.Params().N)
+
+	// R = s G - e P
+	sx, sy := curve.ScalarBaseMult(zeroPad(s.Bytes(), 32))
+	ex, ey := curve.ScalarMult(pub.X, pub.Y, zeroPad(e.Bytes(), 32))
+	if ey.Sign() != 0 {
+		ey.Sub(curve.Params().P, ey)
+	}
+	rx, ry := curve.Add(sx, sy, ex, ey)
+
+	if (rx.Sign() == 0 && ry.Sign() == 0) || ry.Bit(0) != 0 {
+		return false
+	}
+
+	return rx.Cmp(r) == 0
+}
+
+// taggedHash computes BIP-340 tagged hash SHA256(SHA256(tag) || SHA256(tag) || data)
+func taggedHash(tag string, data ...[]byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for _, d := range data {
+		h.Write(d)
+	}
+
+	return h.Sum(nil)
+}
@@ -0,0 +1,152 @@
+package eciesgo
+
+import (
+	"crypto/elliptic"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// BIP-340 test vectors
+var testingSchnorrVectors = []struct {
+	secretKey, publicKey, auxRand, message, signature string
+	valid                                             bool
+}{
+	{
+		"0000000000000000000000000000000000000000000000000000000000000003",
+		"F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9",
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		"E907831F80848D1069A5371B402410364BDF1C5F8307B0084C55F1CE2DCA821525F66A4A85EA8B71E482A74F382D2CE5EBEEE8FDB2172F477DF4900D310536C0",
+		true,
+	},
+	{
+		"B7E151628AED2A6ABF7158809CF4F3C762E7160F38B4DA56A784D9045190CFEF",
+		"DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+		"0000000000000000000000000000000000000000000000000000000000000001",
+		"243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+		"6896BD60EEAE296DB48A229FF71DFE071BDE413E6D43F917DC8DCF8C78DE33418906D11AC976ABCCB20B091292BFF4EA897EFCB639EA871CFA95F6DE339E4B0A",
+		true,
+	},
+	{
+		"C90FDAA22168C234C4C6628B80DC1CD129024E088A67CC74020BBEA63B14E5C9",
+		"DD308AFEC5777E13121FA72B9CC1B7CC0139715309B086C960E18FD969774EB8",
+		"C87AA53824B4D7AE2EB035A2B5BBBCCC080E76CDC6D1692C4B0B62D798E6D906",
+		"7E2D58D8B3BCDF1ABADEC7829054F90DDA9805AAB56C77333024B9D0A508B75C",
+		"5831AAEED7B44BB74E5EAB94BA9D4294C49BCF2A60728D8B4C200F50DD313C1BAB745879A5AD954A72C45A91C3A51D3C7ADEA98D82F8481E0E1E03674A6F3FB7",
+		true,
+	},
+	{
+		"0B432B2677937381AEF05BB02A66ECD012773062CF3FA2549E44F58ED2401710",
+		"25D1DFF95105F5253C4022F628A996AD3A0D95FBF21D468A1B33F8C160D8F517",
+		"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF",
+		"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF",
+		"7EB0509757E246F19449885651611CB965ECC1A187DD51B64FDA1EDC9637D5EC97582B9CB13DB3933705B32BA982AF5AF25FD78881EBB32771FC5922EFC66EA3",
+		true,
+	},
+	{
+		"",
+		"D69C3509BB99E412E68B0FE8544E72837DFA30746D8BE2AA65975F29D22DC7B9",
+		"",
+		"4DF3C3F68FCC83B27E9D42C90431A72499F17875C81A599B566C9889B9696703",
+		"00000000000000000000003B78CE563F89A0ED9414F5AA28AD0D96D6795F9C6376AFB1548AF603B3EB45C9F8207DEE1060CB71C04E80F593060B07D28308D7F4",
+		true,
+	},
+	{
+		"",
+		"DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+		"",
+		"243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+		"FFF97BD5755EEEA420453A14355235D382F6472F8568A18B2F057A14602975563CC27944640AC607CD107AE10923D9EF7A73C643E166BE5EBEAFA34B1AC553E2",
+		false,
+	},
+	{
+		"",
+		"DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+		"",
+		"243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+		"0000000000000000000000000000000000000000000000000000000000000000123DDA8328AF9C23A94C1FEECFD123BA4FB73476F0D594DCB65C6425BD186051",
+		false,
+	},
+	{
+		"",
+		"DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+		"",
+		"243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+		"4A298DACAE57395A15D0795DDBFD1DCB564DA82B0F269BC70A74F8220429BA1D69E89B4C5564D00349106B8497785DD7D1D713A8AE82B32FA79D5F7FC407D39B",
+		false,
+	},
+}
+
+func TestSchnorrVectors(t *testing.T) {
+	for _, v := range testingSchnorrVectors {
+		pubBytes, _ := hex.DecodeString(v.publicKey)
+		msg, _ := hex.DecodeString(v.message)
+		sig, _ := hex.DecodeString(v.signature)
+
+		pub, err := NewPublicKeyFromXOnly(pubBytes)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, v.valid, pub.VerifySchnorr(msg, sig), v.signature)
+
+		// Signing validates secp256k1 key, which is not approved in FIPS mode
+		if v.secretKey == "" || FIPSMode() {
+			continue
+		}
+
+		priv, err := NewPrivateKeyFromHex(v.secretKey)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, pubBytes, priv.PublicKey.XOnlyBytes())
+
+		auxRand, _ := hex.DecodeString(v.auxRand)
+		produced, err := priv.SignSchnorr(msg, auxRand)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, sig, produced)
+	}
+}
+
+func TestSignSchnorr(t *testing.T) {
+	skipUnapproved(t)
+
+	priv, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	sig, err := priv.SignSchnorr([]byte(testingMessage), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, priv.PublicKey.VerifySchnorr([]byte(testingMessage), sig))
+	assert.False(t, priv.PublicKey.VerifySchnorr([]byte(testingJsonMessage), sig))
+
+	// Public key with odd Y verifies the same way as its x-only form
+	xOnly, err := NewPublicKeyFromXOnly(priv.PublicKey.XOnlyBytes())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, xOnly.VerifySchnorr([]byte(testingMessage), sig))
+
+	_, err = NewPublicKeyFromXOnly(make([]byte, 31))
+	assert.Error(t, err)
+
+	// BIP-340 is defined for secp256k1 only, invalid keys are rejected instead of producing signatures
+	p256, err := GenerateKeyCurve(elliptic.P256())
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = p256.SignSchnorr([]byte(testingMessage), nil)
+	assert.True(t, errors.Is(err, ErrInvalidPrivateKey), err)
+	for _, invalid := range []*PrivateKey{nil, {}, {PublicKey: priv.PublicKey, D: new(big.Int)}} {
+		_, err = invalid.SignSchnorr([]byte(testingMessage), nil)
+		assert.True(t, errors.Is(err, ErrInvalidPrivateKey), err)
+	}
+}
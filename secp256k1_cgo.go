@@ -1,10 +1,11 @@
-//go:build cgo && !ecies_test_race
-// +build cgo,!ecies_test_race
+//go:build cgo && !ecies_test_race && !tinygo
+// +build cgo,!ecies_test_race,!tinygo
 
 package eciesgo
 
 import (
 	"crypto/elliptic"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/crypto/secp256k1"
 )
@@ -12,3 +13,15 @@ import (
 func getCurve() elliptic.Curve {
 	return secp256k1.S256()
 }
+
+// scalarMult multiplies point by scalar;
+// secp256k1 scalar multiplication is done by libsecp256k1 in constant time
+func scalarMult(curve elliptic.Curve, x, y *big.Int, k []byte) (*big.Int, *big.Int) {
+	return curve.ScalarMult(x, y, k)
+}
+
+// scalarBaseMult multiplies base point by secret scalar, e.g. private key or signature nonce;
+// libsecp256k1 multiplies the base point in constant time as well
+func scalarBaseMult(curve elliptic.Curve, k []byte) (*big.Int, *big.Int) {
+	return curve.ScalarBaseMult(k)
+}
@@ -1,10 +1,13 @@
-//go:build !cgo || ecies_test_race
-// +build !cgo ecies_test_race
+//go:build !cgo || ecies_test_race || tinygo
+// +build !cgo ecies_test_race tinygo
 
 package eciesgo
 
 import (
 	"crypto/elliptic"
+	"crypto/subtle"
+	"encoding/binary"
+	"math/big"
 
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 )
@@ -12,3 +15,181 @@ import (
 func getCurve() elliptic.Curve {
 	return secp256k1.S256()
 }
+
+// scalarMult multiplies point by scalar;
+// secp256k1 library implements only variable time multiplication, so constant time one is used for it
+func scalarMult(curve elliptic.Curve, x, y *big.Int, k []byte) (*big.Int, *big.Int) {
+	if curve != getCurve() || len(k) > 32 {
+		return curve.ScalarMult(x, y, k)
+	}
+
+	return ctScalarMult(x, y, k)
+}
+
+// scalarBaseMult multiplies base point by secret scalar, e.g. private key or signature nonce;
+// secp256k1 library base point multiplication is variable time as well, so it goes through ctScalarMult
+func scalarBaseMult(curve elliptic.Curve, k []byte) (*big.Int, *big.Int) {
+	if curve != getCurve() || len(k) > 32 {
+		return curve.ScalarBaseMult(k)
+	}
+
+	params := curve.Params()
+	return ctScalarMult(params.Gx, params.Gy, k)
+}
+
+// ctPoint is secp256k1 point in projective coordinates, (0:1:0) is the point at infinity
+type ctPoint struct {
+	x, y, z secp256k1.FieldVal
+}
+
+// ctScalarMult computes k * (x, y) with fixed 4-bit window method over complete formulas
+// (Renes, Costello, Batina, 2015); neither branches nor memory access depend on the scalar value
+func ctScalarMult(x, y *big.Int, k []byte) (*big.Int, *big.Int) {
+	var p ctPoint
+	p.x.SetByteSlice(zeroPad(x.Bytes(), 32))
+	p.y.SetByteSlice(zeroPad(y.Bytes(), 32))
+	p.z.SetInt(1)
+
+	// Table of 0P, 1P, ..., 15P
+	var table [16][12]uint64
+	var q ctPoint
+	q.y.SetInt(1)
+	for i := range table {
+		q.pack(&table[i])
+		q.add(&q, &p)
+	}
+
+	var acc, sel ctPoint
+	acc.y.SetInt(1)
+
+	scalar := zeroPad(k, 32)
+	for i := 0; i < 64; i++ {
+		for j := 0; j < 4; j++ {
+			acc.double(&acc)
+		}
+
+		w := scalar[i/2] >> (4 * uint(1-i%2)) & 0x0f
+		sel.lookup(&table, w)
+		acc.add(&acc, &sel)
+	}
+
+	// Point at infinity
+	if acc.z.IsZero() {
+		return new(big.Int), new(big.Int)
+	}
+
+	var zinv secp256k1.FieldVal
+	zinv.Set(&acc.z).Inverse()
+	acc.x.Mul(&zinv).Normalize()
+	acc.y.Mul(&zinv).Normalize()
+
+	return new(big.Int).SetBytes(acc.x.Bytes()[:]), new(big.Int).SetBytes(acc.y.Bytes()[:])
+}
+
+// pack stores normalized coordinates in the table entry
+func (p *ctPoint) pack(entry *[12]uint64) {
+	var b [96]byte
+	p.x.Normalize().PutBytesUnchecked(b[0:32])
+	p.y.Normalize().PutBytesUnchecked(b[32:64])
+	p.z.Normalize().PutBytesUnchecked(b[64:96])
+	for i := range entry {
+		entry[i] = binary.BigEndian.Uint64(b[8*i:])
+	}
+}
+
+// lookup sets p to table entry w scanning the whole table
+func (p *ctPoint) lookup(table *[16][12]uint64, w byte) {
+	var words [12]uint64
+	for i := range table {
+		mask := -uint64(subtle.ConstantTimeByteEq(byte(i), w))
+		for j := range words {
+			words[j] |= table[i][j] & mask
+		}
+	}
+
+	var b [96]byte
+	for i := range words {
+		binary.BigEndian.PutUint64(b[8*i:], words[i])
+	}
+	p.x.SetByteSlice(b[0:32])
+	p.y.SetByteSlice(b[32:64])
+	p.z.SetByteSlice(b[64:96])
+}
+
+// double sets p = 2a, complete formula for a = 0 curves (algorithm 9 of RCB paper); a and p can alias.
+// Field elements magnitudes are tracked manually to avoid normalization, output coordinates have magnitude 2
+func (p *ctPoint) double(a *ctPoint) {
+	var t0, t1, t2, x3, y3, z3, n secp256k1.FieldVal
+
+	t0.SquareVal(&a.y)
+	z3.Set(&t0).MulInt(8)
+	t1.Mul2(&a.y, &a.z)
+	t2.SquareVal(&a.z).MulInt(21).Normalize()
+	x3.Mul2(&t2, &z3)
+	y3.Add2(&t0, &t2)
+	z3.Mul(&t1)
+	t2.MulInt(3)
+	n.NegateVal(&t2, 3)
+	t0.Add(&n)
+	y3.Mul(&t0)
+	y3.Add(&x3)
+	t1.Mul2(&a.x, &a.y)
+	x3.Mul2(&t0, &t1).MulInt(2)
+
+	p.x.Set(&x3)
+	p.y.Set(&y3)
+	p.z.Set(&z3)
+}
+
+// add sets p = a + b, complete formula for a = 0 curves (algorithm 7 of RCB paper); a, b and p can alias
+func (p *ctPoint) add(a, b *ctPoint) {
+	var t0, t1, t2, t3, t4, x3, y3, z3 secp256k1.FieldVal
+
+	t0.Mul2(&a.x, &b.x)
+	t1.Mul2(&a.y, &b.y)
+	t2.Mul2(&a.z, &b.z)
+	fieldAdd(&t3, &a.x, &a.y)
+	fieldAdd(&t4, &b.x, &b.y)
+	t3.Mul(&t4)
+	fieldAdd(&t4, &t0, &t1)
+	fieldSub(&t3, &t3, &t4)
+	fieldAdd(&t4, &a.y, &a.z)
+	fieldAdd(&x3, &b.y, &b.z)
+	t4.Mul(&x3)
+	fieldAdd(&x3, &t1, &t2)
+	fieldSub(&t4, &t4, &x3)
+	fieldAdd(&x3, &a.x, &a.z)
+	fieldAdd(&y3, &b.x, &b.z)
+	x3.Mul(&y3)
+	fieldAdd(&y3, &t0, &t2)
+	fieldSub(&y3, &x3, &y3)
+	fieldAdd(&x3, &t0, &t0)
+	fieldAdd(&t0, &x3, &t0)
+	t2.MulInt(21).Normalize()
+	fieldAdd(&z3, &t1, &t2)
+	fieldSub(&t1, &t1, &t2)
+	y3.MulInt(21).Normalize()
+	x3.Mul2(&t4, &y3)
+	t2.Mul2(&t3, &t1)
+	fieldSub(&x3, &t2, &x3)
+	y3.Mul(&t0)
+	t1.Mul(&z3)
+	fieldAdd(&y3, &t1, &y3)
+	t0.Mul(&t3)
+	z3.Mul(&t4)
+	fieldAdd(&z3, &z3, &t0)
+
+	p.x.Set(&x3)
+	p.y.Set(&y3)
+	p.z.Set(&z3)
+}
+
+func fieldAdd(r, a, b *secp256k1.FieldVal) {
+	r.Add2(a, b).Normalize()
+}
+
+func fieldSub(r, a, b *secp256k1.FieldVal) {
+	var nb secp256k1.FieldVal
+	nb.NegateVal(b, 1)
+	r.Add2(a, &nb).Normalize()
+}
@@ -0,0 +1,55 @@
+//go:build !cgo || ecies_test_race || tinygo
+// +build !cgo ecies_test_race tinygo
+
+package eciesgo
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCtScalarMult(t *testing.T) {
+	curve := getCurve()
+	n := curve.Params().N
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	scalars := [][]byte{
+		{1},
+		{2},
+		new(big.Int).Sub(n, big.NewInt(1)).Bytes(),
+	}
+	for i := 0; i < 16; i++ {
+		k, err := rand.Int(rand.Reader, n)
+		if !assert.NoError(t, err) {
+			return
+		}
+		scalars = append(scalars, k.Bytes())
+	}
+
+	for _, k := range scalars {
+		ex, ey := curve.ScalarMult(privkey.X, privkey.Y, k)
+		x, y := ctScalarMult(privkey.X, privkey.Y, k)
+		assert.Equal(t, 0, ex.Cmp(x))
+		assert.Equal(t, 0, ey.Cmp(y))
+	}
+
+	// Multiplication by group order gives point at infinity
+	x, y := ctScalarMult(privkey.X, privkey.Y, n.Bytes())
+	assert.Equal(t, 0, x.Sign())
+	assert.Equal(t, 0, y.Sign())
+
+	// Base point multiplication goes through the constant time path as well
+	for _, k := range scalars {
+		ex, ey := curve.ScalarBaseMult(k)
+		x, y := scalarBaseMult(curve, k)
+		assert.Equal(t, 0, ex.Cmp(x))
+		assert.Equal(t, 0, ey.Cmp(y))
+	}
+}
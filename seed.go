@@ -0,0 +1,108 @@
+package eciesgo
+
+import (
+	"crypto/elliptic"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math/big"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Minimal length of seed accepted by NewPrivateKeyFromSeed
+const minSeedLength = 16
+
+// PasswordKDFParams are parameters of memory-hard key derivation from passwords;
+// N, R and P are used by "scrypt", Time, Memory (in KiB) and Threads are used by "argon2id"
+type PasswordKDFParams struct {
+	Algorithm string
+
+	N, R, P int
+
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+}
+
+// DEFAULT_SCRYPT_PARAMS are interactive-login scrypt parameters recommended by the scrypt paper
+var DEFAULT_SCRYPT_PARAMS = PasswordKDFParams{Algorithm: "scrypt", N: 1 << 15, R: 8, P: 1}
+
+// DEFAULT_ARGON2ID_PARAMS are Argon2id parameters recommended by RFC 9106 for memory-constrained environments
+var DEFAULT_ARGON2ID_PARAMS = PasswordKDFParams{Algorithm: "argon2id", Time: 3, Memory: 64 * 1024, Threads: 4}
+
+// NewPrivateKeyFromSeed deterministically derives private key from seed with HKDF-SHA256;
+// seed must be at least 16 bytes long and have enough entropy
+func NewPrivateKeyFromSeed(seed []byte) (*PrivateKey, error) {
+	if len(seed) < minSeedLength {
+		return nil, fmt.Errorf("seed must be at least %d bytes long", minSeedLength)
+	}
+
+	curve := getCurve()
+	b := make([]byte, scalarDerivationLength(curve))
+	kdf := hkdf.New(sha256.New, seed, nil, []byte("ecies private key"))
+	if _, err := io.ReadFull(kdf, b); err != nil {
+		return nil, fmt.Errorf("cannot read secret from HKDF reader: %w", err)
+	}
+
+	return newPrivateKeyFromUniformBytes(curve, b), nil
+}
+
+// NewPrivateKeyFromPassword deterministically derives private key from password and salt
+// with memory-hard key derivation function
+func NewPrivateKeyFromPassword(password, salt []byte, params PasswordKDFParams) (*PrivateKey, error) {
+	curve := getCurve()
+
+	b, err := derivePasswordKey(password, salt, params, scalarDerivationLength(curve))
+	if err != nil {
+		return nil, err
+	}
+
+	return newPrivateKeyFromUniformBytes(curve, b), nil
+}
+
+// derivePasswordKey derives key of the given length from password with memory-hard key derivation function
+func derivePasswordKey(password, salt []byte, params PasswordKDFParams, length int) ([]byte, error) {
+	if len(salt) == 0 {
+		return nil, fmt.Errorf("salt is empty")
+	}
+	if err := checkFIPS(params.Algorithm); err != nil {
+		return nil, err
+	}
+
+	switch params.Algorithm {
+	case "scrypt":
+		key, err := scrypt.Key(password, salt, params.N, params.R, params.P, length)
+		if err != nil {
+			return nil, fmt.Errorf("cannot derive key with scrypt: %w", err)
+		}
+		return key, nil
+	case "argon2id":
+		if params.Time == 0 || params.Memory == 0 || params.Threads == 0 {
+			return nil, fmt.Errorf("invalid Argon2id parameters")
+		}
+		return argon2.IDKey(password, salt, params.Time, params.Memory, params.Threads, uint32(length)), nil
+	default:
+		return nil, fmt.Errorf("unknown password KDF: %s", params.Algorithm)
+	}
+}
+
+// scalarDerivationLength returns number of uniform random bytes required to derive unbiased scalar;
+// 64 extra bits make the bias of modular reduction negligible (FIPS 186-4, B.4.1)
+func scalarDerivationLength(curve elliptic.Curve) int {
+	return (curve.Params().N.BitLen() + 64 + 7) / 8
+}
+
+// newPrivateKeyFromUniformBytes maps uniform random bytes to scalar in [1, n-1] range and computes public key
+func newPrivateKeyFromUniformBytes(curve elliptic.Curve, b []byte) *PrivateKey {
+	n := curve.Params().N
+	nMinusOne := new(big.Int).Sub(n, big.NewInt(1))
+
+	d := new(big.Int).SetBytes(b)
+	d.Mod(d, nMinusOne)
+	d.Add(d, big.NewInt(1))
+
+	return newPrivateKey(curve, d)
+}
@@ -0,0 +1,69 @@
+package eciesgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPrivateKeyFromSeed(t *testing.T) {
+	seed := []byte("0123456789abcdef")
+
+	k1, err := NewPrivateKeyFromSeed(seed)
+	if !assert.NoError(t, err) {
+		return
+	}
+	k2, err := NewPrivateKeyFromSeed(seed)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, k1.Equals(k2))
+	assert.True(t, k1.IsOnCurve(k1.X, k1.Y))
+
+	k3, err := NewPrivateKeyFromSeed([]byte("0123456789abcdeF"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.False(t, k1.Equals(k3))
+
+	_, err = NewPrivateKeyFromSeed([]byte("short"))
+	assert.Error(t, err)
+}
+
+func TestNewPrivateKeyFromPassword(t *testing.T) {
+	skipUnapproved(t)
+
+	salt := []byte("salt")
+
+	for _, params := range []PasswordKDFParams{
+		{Algorithm: "scrypt", N: 1 << 10, R: 8, P: 1},
+		{Algorithm: "argon2id", Time: 1, Memory: 1024, Threads: 1},
+	} {
+		k1, err := NewPrivateKeyFromPassword([]byte("password"), salt, params)
+		if !assert.NoError(t, err) {
+			return
+		}
+		k2, err := NewPrivateKeyFromPassword([]byte("password"), salt, params)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.True(t, k1.Equals(k2))
+		assert.True(t, k1.IsOnCurve(k1.X, k1.Y))
+
+		// Derived key is usable for encryption
+		ciphertext, err := Encrypt(k1.PublicKey, []byte(testingMessage))
+		if !assert.NoError(t, err) {
+			return
+		}
+		plaintext, err := Decrypt(k2, ciphertext)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(plaintext))
+	}
+
+	_, err := NewPrivateKeyFromPassword([]byte("password"), nil, DEFAULT_SCRYPT_PARAMS)
+	assert.Error(t, err)
+	_, err = NewPrivateKeyFromPassword([]byte("password"), salt, PasswordKDFParams{Algorithm: "pbkdf2"})
+	assert.Error(t, err)
+}
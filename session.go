@@ -0,0 +1,111 @@
+package eciesgo
+
+import (
+	"crypto/cipher"
+	"crypto/elliptic"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// Session encrypts multiple messages to the same receiver reusing a single ephemeral key;
+// key encapsulation is performed only once, per-message nonces are derived from a random prefix and a counter.
+// Produced ciphertexts have the same layout as Encrypt output and can be opened with Decrypt
+type Session struct {
+	counter uint64 // first field to keep 64-bit alignment for atomic operations
+
+	header    []byte
+	ephemeral []byte
+	aead      cipher.AEAD
+	prefix    []byte
+	config    Config
+	curve     elliptic.Curve
+}
+
+// NewSession performs key encapsulation for the receiver public key and returns Session instance
+func NewSession(pubkey *PublicKey) (*Session, error) {
+	return NewSessionConf(pubkey, DefaultConfig())
+}
+
+// NewSessionConf performs key encapsulation for the receiver public key and returns Session instance
+// which encrypts messages with the passed config
+func NewSessionConf(pubkey *PublicKey, config Config) (*Session, error) {
+	if config.hpke != nil {
+		return nil, fmt.Errorf("session encryption is not supported in HPKE mode, use HPKEContext instead")
+	}
+	if err := checkTrusted(config, pubkey); err != nil {
+		return nil, err
+	}
+
+	header, config, err := appendEnvelope(nil, keyCurve(pubkey), config)
+	if err != nil {
+		return nil, err
+	}
+
+	ek, err := generateEphemeralKey(pubkey, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Derive shared secret
+	ss, err := ek.encapsulate(pubkey, config)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := generateSymmCipher(ss, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Nonce is random prefix followed by 8 bytes of big endian counter
+	if aead.NonceSize() < 12 {
+		return nil, fmt.Errorf("nonce is too short for session encryption")
+	}
+
+	prefix := make([]byte, aead.NonceSize()-8)
+	if err := randomBytesConf(config, prefix); err != nil {
+		return nil, fmt.Errorf("cannot read random bytes for nonce prefix: %w", err)
+	}
+
+	return &Session{
+		header:    header,
+		ephemeral: ek.PublicKey.Bytes(false),
+		aead:      aead,
+		prefix:    prefix,
+		config:    config,
+		curve:     keyCurve(pubkey),
+	}, nil
+}
+
+// Encrypt encrypts a passed message within the session, returns ciphertext or encryption error;
+// safe for concurrent use
+func (s *Session) Encrypt(msg []byte) (_ []byte, err error) {
+	defer s.config.observe(OperationEncrypt, s.curve, len(msg), time.Now(), &err)
+
+	if err := checkPlaintextSize(s.config, len(msg)); err != nil {
+		return nil, err
+	}
+
+	counter := atomic.AddUint64(&s.counter, 1)
+	if counter == math.MaxUint64 {
+		return nil, fmt.Errorf("session nonce counter is exhausted")
+	}
+
+	nonce := make([]byte, len(s.prefix)+8)
+	copy(nonce, s.prefix)
+	binary.BigEndian.PutUint64(nonce[len(s.prefix):], counter)
+
+	ct := make([]byte, 0, len(s.header)+len(s.ephemeral)+len(nonce)+s.aead.Overhead()+len(msg))
+	ct = append(ct, s.header...)
+	ct = append(ct, s.ephemeral...)
+
+	return append(ct, sealSymm(s.aead, nonce, msg, nil)...), nil
+}
+
+// EphemeralKey returns raw bytes of the session ephemeral public key
+func (s *Session) EphemeralKey() []byte {
+	return append([]byte(nil), s.ephemeral...)
+}
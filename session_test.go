@@ -0,0 +1,149 @@
+package eciesgo
+
+import (
+	"crypto/elliptic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSession_Encrypt(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	session, err := NewSession(privkey.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ct1, err := session.Encrypt([]byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+	ct2, err := session.Encrypt([]byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Same ephemeral key, different nonces
+	assert.Equal(t, session.EphemeralKey(), ct1[:65])
+	assert.Equal(t, ct1[:65], ct2[:65])
+	assert.NotEqual(t, ct1[65:81], ct2[65:81])
+
+	for _, ct := range [][]byte{ct1, ct2} {
+		plaintext, err := Decrypt(privkey, ct)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(plaintext))
+	}
+}
+
+func TestSession_EncryptConf(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, conf := range []Config{
+		{symmetricAlgorithm: "aes-256-gcm", symmetricNonceLength: 12},
+		{symmetricAlgorithm: "xchacha20"},
+		{symmetricAlgorithm: "chacha20poly1305"},
+	} {
+		session, err := NewSessionConf(privkey.PublicKey, conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		ciphertext, err := session.Encrypt([]byte(testingMessage))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		plaintext, err := DecryptConf(privkey, ciphertext, conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(plaintext))
+	}
+}
+
+func BenchmarkSession_Encrypt(b *testing.B) {
+	privkey, err := NewPrivateKeyFromBytes(testingReceiverPrivkey)
+	if err != nil {
+		b.Fatal(err)
+	}
+	msg := []byte(testingJsonMessage)
+
+	session, err := NewSession(privkey.PublicKey)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 0; i < b.N; i++ {
+		if _, err := session.Encrypt(msg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestSession_Envelope(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	session, err := NewSessionConf(privkey.PublicKey, DEFAULT_CONFIG.WithEnvelope())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ct, err := session.Encrypt([]byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	pt, err := DecryptConf(privkey, ct, DEFAULT_CONFIG.WithEnvelope())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(pt))
+}
+
+func TestSession_Curves(t *testing.T) {
+	curves := []elliptic.Curve{elliptic.P256(), elliptic.P384()}
+	if !FIPSMode() {
+		curves = append(curves, getCurve(), elliptic.P521())
+	}
+
+	// Ephemeral key is generated on the receiver curve
+	for _, curve := range curves {
+		privkey, err := GenerateKeyCurve(curve)
+		if !assert.NoError(t, err) {
+			return
+		}
+		session, err := NewSession(privkey.PublicKey)
+		if !assert.NoError(t, err) {
+			return
+		}
+		ct, err := session.Encrypt([]byte(testingMessage))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, session.EphemeralKey(), len(privkey.PublicKey.Bytes(false)))
+
+		plaintext, err := Decrypt(privkey, ct)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(plaintext))
+	}
+}
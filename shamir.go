@@ -0,0 +1,108 @@
+package eciesgo
+
+import "fmt"
+
+// SplitKey splits private key into shares using Shamir secret sharing over GF(256),
+// any threshold of them recover the key with CombineKey, fewer reveal nothing about it.
+// Every share is 1 byte X coordinate (1 to 255) followed by 32 bytes, one polynomial is used per key byte.
+// Shares do not identify the curve, so only secp256k1 keys are supported
+func SplitKey(privkey *PrivateKey, threshold, shares int) ([][]byte, error) {
+	if err := privkey.Validate(); err != nil {
+		return nil, err
+	}
+	if !sameCurve(privkey.Curve, getCurve()) {
+		return nil, fmt.Errorf("%w: %s keys cannot be split", ErrUnsupportedCurve, privkey.Curve.Params().Name)
+	}
+	if threshold < 2 || threshold > shares || shares > 255 {
+		return nil, fmt.Errorf("invalid threshold %d of %d shares", threshold, shares)
+	}
+
+	secret := privkey.Bytes()
+	defer zeroBytes(secret)
+
+	// Random coefficients of polynomials, constant terms are the secret bytes
+	coefficients := make([]byte, len(secret)*(threshold-1))
+	if err := randomBytes(coefficients); err != nil {
+		return nil, fmt.Errorf("cannot read random bytes for polynomial: %w", err)
+	}
+	defer zeroBytes(coefficients)
+
+	result := make([][]byte, shares)
+	for i := range result {
+		x := byte(i + 1)
+
+		share := make([]byte, 1+len(secret))
+		share[0] = x
+		for j, s := range secret {
+			// Horner's method from the highest coefficient
+			coef := coefficients[j*(threshold-1) : (j+1)*(threshold-1)]
+			var y byte
+			for k := len(coef) - 1; k >= 0; k-- {
+				y = gfMul(y, x) ^ coef[k]
+			}
+			share[1+j] = gfMul(y, x) ^ s
+		}
+		result[i] = share
+	}
+
+	return result, nil
+}
+
+// CombineKey recovers secp256k1 private key from at least threshold shares produced by SplitKey;
+// combining fewer or foreign shares gives a wrong key, which is detected only if it is out of range
+func CombineKey(shares [][]byte) (*PrivateKey, error) {
+	if len(shares) < 2 {
+		return nil, fmt.Errorf("at least 2 shares are required")
+	}
+
+	seen := make(map[byte]bool, len(shares))
+	for i, share := range shares {
+		if len(share) != len(shares[0]) || len(share) < 2 {
+			return nil, fmt.Errorf("invalid length of share %d", i)
+		}
+		if share[0] == 0 || seen[share[0]] {
+			return nil, fmt.Errorf("invalid or duplicate X coordinate of share %d", i)
+		}
+		seen[share[0]] = true
+	}
+
+	// Lagrange interpolation at zero, subtraction in GF(256) is XOR
+	secret := make([]byte, len(shares[0])-1)
+	defer zeroBytes(secret)
+	for i, si := range shares {
+		basis := byte(1)
+		for j, sj := range shares {
+			if i != j {
+				basis = gfMul(basis, gfMul(sj[0], gfInv(si[0]^sj[0])))
+			}
+		}
+		for k := range secret {
+			secret[k] ^= gfMul(basis, si[1+k])
+		}
+	}
+
+	return NewPrivateKeyFromBytes(secret)
+}
+
+// gfMul multiplies in GF(256) with AES polynomial x^8 + x^4 + x^3 + x + 1 without secret dependent branches
+func gfMul(a, b byte) byte {
+	var p byte
+	for i := 0; i < 8; i++ {
+		p ^= a & -(b & 1)
+		a = a<<1 ^ 0x1b&-(a>>7)
+		b >>= 1
+	}
+
+	return p
+}
+
+// gfInv returns multiplicative inverse in GF(256) as a^254, inverse of zero is zero
+func gfInv(a byte) byte {
+	b := a
+	for i := 0; i < 6; i++ {
+		b = gfMul(b, b)
+		b = gfMul(b, a)
+	}
+
+	return gfMul(b, b)
+}
@@ -0,0 +1,75 @@
+package eciesgo
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitCombineKey(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	shares, err := SplitKey(privkey, 3, 5)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, shares, 5)
+
+	for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+		var selected [][]byte
+		for _, i := range subset {
+			selected = append(selected, shares[i])
+		}
+
+		combined, err := CombineKey(selected)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.True(t, privkey.Equals(combined), "%v", subset)
+	}
+
+	// Fewer shares than threshold do not recover the key
+	combined, err := CombineKey(shares[:2])
+	if err == nil {
+		assert.False(t, privkey.Equals(combined))
+	}
+
+	for _, invalid := range [][][]byte{
+		nil,
+		shares[:1],
+		{shares[0], shares[0]},
+		{shares[0], shares[1][:20]},
+		{append([]byte{0}, shares[0][1:]...), shares[1]},
+	} {
+		_, err = CombineKey(invalid)
+		assert.Error(t, err)
+	}
+
+	for _, params := range [][2]int{{1, 5}, {6, 5}, {2, 256}} {
+		_, err = SplitKey(privkey, params[0], params[1])
+		assert.Error(t, err)
+	}
+
+	// Shares do not identify the curve, so other curves would be recombined as secp256k1 keys
+	p256, err := GenerateKeyCurve(elliptic.P256())
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = SplitKey(p256, 2, 3)
+	assert.True(t, errors.Is(err, ErrUnsupportedCurve), err)
+}
+
+func TestGFArithmetic(t *testing.T) {
+	assert.Equal(t, byte(0xc1), gfMul(0x57, 0x83))
+	assert.Equal(t, byte(0), gfInv(0))
+	for a := 1; a < 256; a++ {
+		assert.Equal(t, byte(1), gfMul(byte(a), gfInv(byte(a))), "%d", a)
+	}
+}
@@ -0,0 +1,382 @@
+package eciesgo
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/asn1"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// Sign signs digest with ECDSA and returns ASN.1 DER encoded signature; opts are ignored.
+// Nonce is derived deterministically with RFC 6979, 32 bytes read from rand are mixed in as additional data
+// (hedged signature, RFC 6979 section 3.6), so a weak rand cannot leak the private key; nil rand makes
+// signature fully deterministic. Signature S value is always normalized to the lower half of the curve order
+func (k *PrivateKey) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	r, s, _, err := k.sign(rand, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(ecdsaSignature{R: r, S: s})
+}
+
+// SignCompact signs digest with ECDSA and returns compact signature (fixed width r || s);
+// nonce is derived deterministically with RFC 6979
+func (k *PrivateKey) SignCompact(digest []byte) ([]byte, error) {
+	r, s, _, err := k.sign(nil, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	l := len(k.Curve.Params().N.Bytes())
+	sig := make([]byte, 0, 2*l)
+	sig = append(sig, zeroPad(r.Bytes(), l)...)
+
+	return append(sig, zeroPad(s.Bytes(), l)...), nil
+}
+
+// SignRecoverable signs digest with ECDSA and returns recoverable signature r || s || v (65 bytes for secp256k1),
+// where v is recovery ID in [0, 3] range (Ethereum format without 27 offset); see RecoverPublicKey.
+// Nonce is derived deterministically with RFC 6979
+func (k *PrivateKey) SignRecoverable(digest []byte) ([]byte, error) {
+	r, s, recid, err := k.sign(nil, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	l := len(k.Curve.Params().N.Bytes())
+	sig := make([]byte, 0, 2*l+1)
+	sig = append(sig, zeroPad(r.Bytes(), l)...)
+	sig = append(sig, zeroPad(s.Bytes(), l)...)
+
+	return append(sig, recid), nil
+}
+
+// RecoverPublicKey recovers signer public key from digest and recoverable signature r || s || v produced by
+// SignRecoverable or Ethereum signers; v is recovery ID in [0, 3] range or the same with 27 offset.
+// Signature is verified against the recovered key, so a key is returned only for valid signatures
+func RecoverPublicKey(digest, sig []byte) (*PublicKey, error) {
+	curve := getCurve()
+	n := curve.Params().N
+	l := len(n.Bytes())
+	if len(sig) != 2*l+1 {
+		return nil, fmt.Errorf("invalid recoverable signature length: %d", len(sig))
+	}
+
+	recid := sig[2*l]
+	if recid >= 27 {
+		recid -= 27
+	}
+	if recid > 3 {
+		return nil, fmt.Errorf("invalid recovery ID: %d", sig[2*l])
+	}
+
+	r := new(big.Int).SetBytes(sig[:l])
+	s := new(big.Int).SetBytes(sig[l : 2*l])
+	if r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(n) >= 0 || s.Cmp(n) >= 0 {
+		return nil, fmt.Errorf("signature values are out of range")
+	}
+
+	// R is the nonce point: x = r (+ n if recovery ID says x overflowed curve order), Y parity is the low bit
+	x := new(big.Int).Set(r)
+	if recid&2 != 0 {
+		x.Add(x, n)
+	}
+	y, err := decompressY(curve, x, recid&1 != 0)
+	if err != nil {
+		return nil, fmt.Errorf("cannot recover nonce point: %w", err)
+	}
+
+	// Q = r^-1 (s R - e G) = (s r^-1) R + (-e r^-1) G
+	rInv := new(big.Int).ModInverse(r, n)
+	u1 := new(big.Int).Mul(hashToInt(digest, n), rInv)
+	u1.Neg(u1).Mod(u1, n)
+	u2 := new(big.Int).Mul(s, rInv)
+	u2.Mod(u2, n)
+
+	qx, qy := curve.ScalarMult(x, y, u2.Bytes())
+	if u1.Sign() != 0 {
+		gx, gy := curve.ScalarBaseMult(u1.Bytes())
+		qx, qy = curve.Add(qx, qy, gx, gy)
+	}
+
+	pub := &PublicKey{Curve: curve, X: qx, Y: qy}
+	if err := pub.Validate(); err != nil {
+		return nil, err
+	}
+	if !pub.verify(digest, r, s) {
+		return nil, fmt.Errorf("recovered public key does not verify signature")
+	}
+
+	return pub, nil
+}
+
+// sign returns ECDSA signature and recovery ID: parity of the nonce point Y and whether its X overflowed curve order;
+// nonce is RFC 6979 one, hedged with 32 bytes from random if it is not nil
+func (k *PrivateKey) sign(random io.Reader, digest []byte) (r, s *big.Int, recid byte, err error) {
+	if err := k.Validate(); err != nil {
+		return nil, nil, 0, err
+	}
+
+	var extra []byte
+	if random != nil {
+		extra = make([]byte, 32)
+		if _, err := io.ReadFull(random, extra); err != nil {
+			return nil, nil, 0, fmt.Errorf("cannot read random bytes for nonce: %w", err)
+		}
+	}
+
+	n := k.Curve.Params().N
+	e := hashToInt(digest, n)
+	nonces := newRFC6979(k.D, digest, n, extra)
+	defer nonces.wipe()
+
+	for {
+		nonce := nonces.next()
+
+		// r = (kG).x mod n
+		rx, ry := scalarBaseMult(k.Curve, zeroPad(nonce.Bytes(), len(n.Bytes())))
+		recid = byte(ry.Bit(0))
+		if rx.Cmp(n) >= 0 {
+			recid |= 2
+		}
+		r = rx.Mod(rx, n)
+		if r.Sign() == 0 {
+			continue
+		}
+
+		if s, err = k.signS(nonce, r, e); err != nil {
+			return nil, nil, 0, err
+		}
+		if s.Sign() == 0 {
+			continue
+		}
+
+		// Low S normalization prevents signature malleability; it negates the nonce point, so Y parity flips
+		if s.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+			s.Sub(n, s)
+			recid ^= 1
+		}
+
+		return r, s, recid, nil
+	}
+}
+
+// signS returns s = k^-1 (e + r d) mod n of nonce k: secp256k1 keys are computed with constant time Scalar
+// arithmetic, other curves invert the nonce with secretInverse
+func (k *PrivateKey) signS(nonce, r, e *big.Int) (*big.Int, error) {
+	if !sameCurve(k.Curve, getCurve()) {
+		kInv, err := secretInverse(k.Curve, nonce)
+		if err != nil {
+			return nil, err
+		}
+
+		s := new(big.Int).Mul(r, k.D)
+		s.Add(s, e)
+		s.Mul(s, kInv)
+		return s.Mod(s, k.Curve.Params().N), nil
+	}
+
+	d, err := k.Scalar()
+	if err != nil {
+		return nil, err
+	}
+	defer d.Zeroize()
+	kn := newScalarReduced(nonce)
+	defer kn.Zeroize()
+
+	return newScalarReduced(r).Mul(d).Add(newScalarReduced(e)).Mul(kn.inverse()).big(), nil
+}
+
+// secretInverse returns k^-1 mod n of secret k, e.g. signature nonce, without timing depending on k:
+// secp256k1 scalars are inverted with constant time Scalar arithmetic, other curves invert k blinded
+// with random b as b (k b)^-1
+func secretInverse(curve elliptic.Curve, k *big.Int) (*big.Int, error) {
+	n := curve.Params().N
+	if sameCurve(curve, getCurve()) {
+		b := zeroPad(k.Bytes(), 32)
+		defer zeroBytes(b)
+		s, err := NewScalarFromBytes(b)
+		if err != nil {
+			return nil, err
+		}
+		defer s.Zeroize()
+
+		return new(big.Int).SetBytes(s.inverse().Bytes()), nil
+	}
+
+	b, err := randScalar(randReader, n)
+	if err != nil {
+		return nil, err
+	}
+	kb := new(big.Int).Mul(k, b)
+	kb.Mod(kb, n)
+	kb.ModInverse(kb, n)
+
+	return kb.Mul(kb, b).Mod(kb, n), nil
+}
+
+// Verify verifies ASN.1 DER encoded ECDSA signature of digest; invalid public keys verify nothing
+func (k *PublicKey) Verify(digest, sig []byte) bool {
+	if k.Validate() != nil {
+		return false
+	}
+
+	var es ecdsaSignature
+	rest, err := asn1.Unmarshal(sig, &es)
+	if err != nil || len(rest) != 0 || es.R == nil || es.S == nil {
+		return false
+	}
+
+	return k.verify(digest, es.R, es.S)
+}
+
+// VerifyCompact verifies compact (fixed width r || s) ECDSA signature of digest; invalid public keys verify nothing
+func (k *PublicKey) VerifyCompact(digest, sig []byte) bool {
+	if k.Validate() != nil {
+		return false
+	}
+
+	l := len(k.Curve.Params().N.Bytes())
+	if len(sig) != 2*l {
+		return false
+	}
+
+	r := new(big.Int).SetBytes(sig[:l])
+	s := new(big.Int).SetBytes(sig[l:])
+
+	return k.verify(digest, r, s)
+}
+
+func (k *PublicKey) verify(digest []byte, r, s *big.Int) bool {
+	if k.Validate() != nil {
+		return false
+	}
+
+	n := k.Curve.Params().N
+	if r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(n) >= 0 || s.Cmp(n) >= 0 {
+		return false
+	}
+
+	e := hashToInt(digest, n)
+	w := new(big.Int).ModInverse(s, n)
+
+	// u1 = e w mod n, u2 = r w mod n
+	u1 := new(big.Int).Mul(e, w)
+	u1.Mod(u1, n)
+	u2 := new(big.Int).Mul(r, w)
+	u2.Mod(u2, n)
+
+	x1, y1 := k.Curve.ScalarBaseMult(u1.Bytes())
+	x2, y2 := k.Curve.ScalarMult(k.X, k.Y, u2.Bytes())
+	x, y := k.Curve.Add(x1, y1, x2, y2)
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return false
+	}
+
+	x.Mod(x, n)
+	return x.Cmp(r) == 0
+}
+
+// hashToInt converts digest to integer, truncating it to the bit length of curve order (SEC 1, 4.1.3)
+func hashToInt(digest []byte, n *big.Int) *big.Int {
+	orderBits := n.BitLen()
+	orderBytes := (orderBits + 7) / 8
+	if len(digest) > orderBytes {
+		digest = digest[:orderBytes]
+	}
+
+	e := new(big.Int).SetBytes(digest)
+	if excess := len(digest)*8 - orderBits; excess > 0 {
+		e.Rsh(e, uint(excess))
+	}
+
+	return e
+}
+
+// rfc6979 generates deterministic ECDSA nonces with HMAC-SHA256 DRBG (RFC 6979, section 3.2)
+type rfc6979 struct {
+	n    *big.Int
+	k, v []byte
+	// first is true until the first nonce is returned, later candidates require K and V update
+	first bool
+}
+
+// newRFC6979 seeds the generator with private key, message digest and optional additional data (section 3.6)
+func newRFC6979(d *big.Int, digest []byte, n *big.Int, extra []byte) *rfc6979 {
+	l := len(n.Bytes())
+	x := zeroPad(d.Bytes(), l)
+	h := zeroPad(new(big.Int).Mod(hashToInt(digest, n), n).Bytes(), l)
+	defer zeroBytes(x)
+
+	g := &rfc6979{n: n, k: make([]byte, sha256.Size), v: bytes.Repeat([]byte{0x01}, sha256.Size), first: true}
+	g.k = g.mac(g.v, []byte{0x00}, x, h, extra)
+	g.v = g.mac(g.v)
+	g.k = g.mac(g.v, []byte{0x01}, x, h, extra)
+	g.v = g.mac(g.v)
+
+	return g
+}
+
+func (g *rfc6979) mac(data ...[]byte) []byte {
+	m := hmac.New(sha256.New, g.k)
+	for _, b := range data {
+		m.Write(b)
+	}
+	return m.Sum(nil)
+}
+
+// next returns the next nonce candidate in [1, n-1] range
+func (g *rfc6979) next() *big.Int {
+	l := len(g.n.Bytes())
+	for {
+		if !g.first {
+			g.k = g.mac(g.v, []byte{0x00})
+			g.v = g.mac(g.v)
+		}
+		g.first = false
+
+		var t []byte
+		for len(t) < l {
+			g.v = g.mac(g.v)
+			t = append(t, g.v...)
+		}
+
+		nonce := hashToInt(t, g.n)
+		if nonce.Sign() > 0 && nonce.Cmp(g.n) < 0 {
+			return nonce
+		}
+	}
+}
+
+func (g *rfc6979) wipe() {
+	zeroBytes(g.k)
+	zeroBytes(g.v)
+}
+
+// randScalar reads uniformly distributed scalar in [1, n-1] range from random
+func randScalar(random io.Reader, n *big.Int) (*big.Int, error) {
+	b := make([]byte, len(n.Bytes()))
+	mask := byte(0xff >> uint(len(b)*8-n.BitLen()))
+
+	for {
+		if _, err := io.ReadFull(random, b); err != nil {
+			return nil, fmt.Errorf("cannot read random bytes for scalar: %w", err)
+		}
+		b[0] &= mask
+
+		k := new(big.Int).SetBytes(b)
+		if k.Sign() > 0 && k.Cmp(n) < 0 {
+			return k, nil
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package eciesgo
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrivateKey_Sign(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := NewPrivateKeyFromHex(testingReceiverPrivkeyHex)
+	if !assert.NoError(t, err) {
+		return
+	}
+	digest := sha256.Sum256([]byte(testingMessage))
+
+	sig, err := privkey.Sign(nil, digest[:], nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, privkey.PublicKey.Verify(digest[:], sig))
+
+	// Cross-check with independent implementation
+	dsig, err := ecdsa.ParseDERSignature(sig)
+	if !assert.NoError(t, err) {
+		return
+	}
+	dpub, err := secp256k1.ParsePubKey(privkey.PublicKey.Bytes(false))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, dsig.Verify(digest[:], dpub))
+
+	// Tampered digest
+	digest[0] ^= 0xff
+	assert.False(t, privkey.PublicKey.Verify(digest[:], sig))
+}
+
+func TestPrivateKey_SignCompact(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	digest := sha256.Sum256([]byte(testingMessage))
+
+	sig, err := privkey.SignCompact(digest[:])
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, sig, 64)
+	assert.True(t, privkey.PublicKey.VerifyCompact(digest[:], sig))
+
+	other, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.False(t, other.PublicKey.VerifyCompact(digest[:], sig))
+	assert.False(t, privkey.PublicKey.VerifyCompact(digest[:], sig[1:]))
+}
+
+func TestPublicKey_VerifyInvalid(t *testing.T) {
+	digest := sha256.Sum256([]byte(testingMessage))
+	sig := make([]byte, 64)
+	sig[31], sig[63] = 1, 1
+	der := []byte{0x30, 0x06, 0x02, 0x01, 0x01, 0x02, 0x01, 0x01}
+
+	// Empty keys verify nothing instead of panicking
+	for _, k := range []*PublicKey{nil, {}, {Curve: elliptic.P256()}} {
+		assert.False(t, k.Verify(digest[:], der))
+		assert.False(t, k.VerifyCompact(digest[:], sig))
+	}
+}
+
+func TestRecoverPublicKey(t *testing.T) {
+	skipUnapproved(t)
+
+	for i := 0; i < 20; i++ {
+		privkey, err := GenerateKey()
+		if !assert.NoError(t, err) {
+			return
+		}
+		digest := sha256.Sum256([]byte{byte(i)})
+
+		sig, err := privkey.SignRecoverable(digest[:])
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, sig, 65)
+		assert.True(t, privkey.PublicKey.VerifyCompact(digest[:], sig[:64]))
+
+		recovered, err := RecoverPublicKey(digest[:], sig)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.True(t, privkey.PublicKey.Equals(recovered))
+
+		// Ethereum style recovery ID with 27 offset
+		recovered, err = RecoverPublicKey(digest[:], append(sig[:64:64], sig[64]+27))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.True(t, privkey.PublicKey.Equals(recovered))
+
+		// Cross-check with independent implementation, its compact format is header byte || r || s
+		dpub, _, err := ecdsa.RecoverCompact(append([]byte{27 + sig[64]}, sig[:64]...), digest[:])
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, privkey.PublicKey.Bytes(false), dpub.SerializeUncompressed())
+
+		// Wrong recovery ID or digest gives another key or fails
+		wrong, err := RecoverPublicKey(digest[:], append(sig[:64:64], sig[64]^1))
+		if err == nil {
+			assert.False(t, privkey.PublicKey.Equals(wrong))
+		}
+		digest[0] ^= 1
+		wrong, err = RecoverPublicKey(digest[:], sig)
+		if err == nil {
+			assert.False(t, privkey.PublicKey.Equals(wrong))
+		}
+	}
+
+	digest := sha256.Sum256([]byte(testingMessage))
+	for _, sig := range [][]byte{
+		nil,
+		make([]byte, 64),
+		make([]byte, 65),
+		append(bytes.Repeat([]byte{1}, 64), 4),
+		append(bytes.Repeat([]byte{0xff}, 64), 0),
+	} {
+		_, err := RecoverPublicKey(digest[:], sig)
+		assert.Error(t, err)
+	}
+}
+
+func TestSignRFC6979(t *testing.T) {
+	skipUnapproved(t)
+
+	hexInt := func(s string) *big.Int {
+		x, _ := new(big.Int).SetString(s, 16)
+		return x
+	}
+
+	for _, c := range []struct {
+		curve   elliptic.Curve
+		d       string
+		msg     string
+		k, r, s string
+	}{
+		// RFC 6979, A.2.5: P-256, SHA-256, message "sample"
+		{
+			curve: elliptic.P256(),
+			d:     "C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721",
+			msg:   "sample",
+			k:     "A6E3C57DD01ABE90086538398355DD4C3B17AA873382B0F24D6129493D8AAD60",
+			r:     "EFD48B2AACB6A8FD1140DD9CD45E81D69D2C877B56AAF991C34D0EA84EAF3716",
+			s:     "F7CB1C942D657C41D436C7A1B6E29F65F3E900DBB9AFF4064DC4AB2F843ACDA8",
+		},
+		// secp256k1, private key 1, message "Satoshi Nakamoto"
+		{
+			curve: getCurve(),
+			d:     "01",
+			msg:   "Satoshi Nakamoto",
+			k:     "8F8A276C19F4149656B280621E358CCE24F5F52542772691EE69063B74F15D15",
+			r:     "934B1EA10A4B3C1757E2B0C017D0B6143CE3C9A7E6A4A49860D7A6AB210EE3D8",
+			s:     "2442CE9D2B916064108014783E923EC36B49743E2FFA1C4496F01A512AAFD9E5",
+		},
+	} {
+		d := hexInt(c.d)
+		n := c.curve.Params().N
+		x, y := c.curve.ScalarBaseMult(d.Bytes())
+		privkey := &PrivateKey{PublicKey: &PublicKey{Curve: c.curve, X: x, Y: y}, D: d}
+		digest := sha256.Sum256([]byte(c.msg))
+
+		assert.Equal(t, hexInt(c.k), newRFC6979(d, digest[:], n, nil).next())
+
+		r, s, _, err := privkey.sign(nil, digest[:])
+		if !assert.NoError(t, err) {
+			return
+		}
+		// S is normalized to the lower half of the order
+		expectedS := hexInt(c.s)
+		if expectedS.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+			expectedS.Sub(n, expectedS)
+		}
+		assert.Equal(t, hexInt(c.r), r)
+		assert.Equal(t, expectedS, s)
+	}
+
+	// Deterministic signatures repeat, hedged ones do not, both verify
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	digest := sha256.Sum256([]byte(testingMessage))
+
+	sig1, err := privkey.SignCompact(digest[:])
+	if !assert.NoError(t, err) {
+		return
+	}
+	sig2, err := privkey.SignCompact(digest[:])
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, sig1, sig2)
+
+	hedged1, err := privkey.Sign(rand.Reader, digest[:], nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	hedged2, err := privkey.Sign(rand.Reader, digest[:], nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotEqual(t, hedged1, hedged2)
+	assert.True(t, privkey.PublicKey.Verify(digest[:], hedged1))
+	assert.True(t, privkey.PublicKey.Verify(digest[:], hedged2))
+
+	// Broken rand does not leak the key, signatures only become deterministic
+	zero1, err := privkey.Sign(bytes.NewReader(make([]byte, 32)), digest[:], nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	zero2, err := privkey.Sign(bytes.NewReader(make([]byte, 32)), digest[:], nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, zero1, zero2)
+	assert.NotEqual(t, zero1, hedged1)
+
+	_, err = privkey.Sign(bytes.NewReader(nil), digest[:], nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,117 @@
+package eciesgo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// Signed payload layout: version, 8 bytes big endian Unix timestamp in seconds, 16 bytes random nonce,
+// message and 64 bytes BIP-340 Schnorr signature; the whole payload is encrypted with ECIES
+const (
+	signedVersion     = 1
+	signedNonceLength = 16
+	signedHeaderSize  = 1 + 8 + signedNonceLength
+	signedSigSize     = 64
+)
+
+// SignedMessage is a message decrypted and verified by DecryptVerified together with replay protection metadata;
+// callers should reject messages with already seen (sender, Nonce) pairs within the accepted age window
+type SignedMessage struct {
+	Message   []byte
+	Timestamp time.Time
+	Nonce     []byte
+}
+
+// EncryptSigned signs a message with a sender private key and encrypts it with a receiver public key (sign-then-encrypt);
+// signature covers both public keys, timestamp and random nonce, so the signed message can't be re-encrypted
+// to another receiver or attributed to another sender
+func EncryptSigned(senderPrivkey *PrivateKey, pubkey *PublicKey, msg []byte) ([]byte, error) {
+	return EncryptSignedConf(senderPrivkey, pubkey, msg, DefaultConfig())
+}
+
+// EncryptSignedConf signs and encrypts a message with the passed config
+func EncryptSignedConf(senderPrivkey *PrivateKey, pubkey *PublicKey, msg []byte, config Config) ([]byte, error) {
+	return encryptSigned(senderPrivkey, pubkey, msg, config, time.Now())
+}
+
+func encryptSigned(senderPrivkey *PrivateKey, pubkey *PublicKey, msg []byte, config Config, now time.Time) ([]byte, error) {
+	if senderPrivkey == nil {
+		return nil, fmt.Errorf("%w: sender private key is empty", ErrInvalidPrivateKey)
+	}
+	if err := pubkey.Validate(); err != nil {
+		return nil, err
+	}
+
+	payload := make([]byte, signedHeaderSize, signedHeaderSize+len(msg)+signedSigSize)
+	payload[0] = signedVersion
+	binary.BigEndian.PutUint64(payload[1:9], uint64(now.Unix()))
+	if err := randomBytesConf(config, payload[9:signedHeaderSize]); err != nil {
+		return nil, fmt.Errorf("cannot read random bytes for nonce: %w", err)
+	}
+	payload = append(payload, msg...)
+
+	auxRand := make([]byte, 32)
+	if err := randomBytesConf(config, auxRand); err != nil {
+		return nil, fmt.Errorf("cannot read random bytes for auxiliary randomness: %w", err)
+	}
+
+	sig, err := senderPrivkey.SignSchnorr(signedDigest(senderPrivkey.PublicKey, pubkey, payload), auxRand)
+	if err != nil {
+		return nil, fmt.Errorf("cannot sign message: %w", err)
+	}
+
+	return EncryptConf(pubkey, append(payload, sig...), config)
+}
+
+// DecryptVerified decrypts a message produced by EncryptSigned with a receiver private key
+// and verifies its signature against the sender public key; message age is not checked
+func DecryptVerified(privkey *PrivateKey, senderPubkey *PublicKey, msg []byte) (*SignedMessage, error) {
+	return DecryptVerifiedConf(privkey, senderPubkey, msg, DefaultConfig(), 0)
+}
+
+// DecryptVerifiedConf decrypts and verifies a message with the passed config;
+// if maxAge is positive, messages older than maxAge or dated more than maxAge in the future are rejected
+func DecryptVerifiedConf(privkey *PrivateKey, senderPubkey *PublicKey, msg []byte, config Config, maxAge time.Duration) (*SignedMessage, error) {
+	return decryptVerified(privkey, senderPubkey, msg, config, maxAge, time.Now())
+}
+
+func decryptVerified(privkey *PrivateKey, senderPubkey *PublicKey, msg []byte, config Config, maxAge time.Duration, now time.Time) (*SignedMessage, error) {
+	if err := senderPubkey.Validate(); err != nil {
+		return nil, err
+	}
+
+	payload, err := DecryptConf(privkey, msg, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payload) < signedHeaderSize+signedSigSize {
+		return nil, newParseError(ErrCiphertextTooShort, "signed message", len(payload), "signature is truncated")
+	}
+	if payload[0] != signedVersion {
+		return nil, newParseError(ErrAuthenticationFailed, "signed message", 0, fmt.Sprintf("unsupported version %d", payload[0]))
+	}
+
+	signed, sig := payload[:len(payload)-signedSigSize], payload[len(payload)-signedSigSize:]
+	if !senderPubkey.VerifySchnorr(signedDigest(senderPubkey, privkey.PublicKey, signed), sig) {
+		return nil, fmt.Errorf("%w: invalid sender signature", ErrAuthenticationFailed)
+	}
+
+	timestamp := time.Unix(int64(binary.BigEndian.Uint64(signed[1:9])), 0)
+	if maxAge > 0 && (now.Sub(timestamp) > maxAge || timestamp.Sub(now) > maxAge) {
+		return nil, fmt.Errorf("%w: message timestamp %s is outside of accepted window", ErrAuthenticationFailed, timestamp.UTC().Format(time.RFC3339))
+	}
+
+	return &SignedMessage{
+		Message:   signed[signedHeaderSize:],
+		Timestamp: timestamp,
+		Nonce:     signed[9:signedHeaderSize],
+	}, nil
+}
+
+// signedDigest computes tagged hash of sender and receiver compressed public keys and signed payload
+func signedDigest(sender, receiver *PublicKey, payload []byte) []byte {
+	return taggedHash("ECIES/signed", bytes.Join([][]byte{sender.Bytes(true), receiver.Bytes(true)}, nil), payload)
+}
@@ -0,0 +1,100 @@
+package eciesgo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptSigned(t *testing.T) {
+	skipUnapproved(t)
+
+	sender, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	receiver, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	other, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ct, err := EncryptSigned(sender, receiver.PublicKey, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	signed, err := DecryptVerified(receiver, sender.PublicKey, ct)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(signed.Message))
+	assert.Len(t, signed.Nonce, signedNonceLength)
+	assert.WithinDuration(t, time.Now(), signed.Timestamp, time.Minute)
+
+	// Nonce is random
+	ct2, err := EncryptSigned(sender, receiver.PublicKey, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+	signed2, err := DecryptVerified(receiver, sender.PublicKey, ct2)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotEqual(t, signed.Nonce, signed2.Nonce)
+
+	// Claimed sender does not match
+	_, err = DecryptVerified(receiver, other.PublicKey, ct)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), "%v", err)
+
+	// Receiver can't forward the signed message to another receiver
+	payload, err := Decrypt(receiver, ct)
+	if !assert.NoError(t, err) {
+		return
+	}
+	forwarded, err := Encrypt(other.PublicKey, payload)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = DecryptVerified(other, sender.PublicKey, forwarded)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), "%v", err)
+
+	// Unsigned message is rejected
+	unsigned, err := Encrypt(receiver.PublicKey, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = DecryptVerified(receiver, sender.PublicKey, unsigned)
+	assert.Error(t, err)
+}
+
+func TestDecryptVerifiedMaxAge(t *testing.T) {
+	skipUnapproved(t)
+
+	sender, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	receiver, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	now := time.Now()
+	ct, err := encryptSigned(sender, receiver.PublicKey, []byte(testingMessage), DEFAULT_CONFIG, now.Add(-time.Hour))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = decryptVerified(receiver, sender.PublicKey, ct, DEFAULT_CONFIG, time.Minute, now)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), "%v", err)
+	_, err = decryptVerified(receiver, sender.PublicKey, ct, DEFAULT_CONFIG, 2*time.Hour, now)
+	assert.NoError(t, err)
+	_, err = decryptVerified(receiver, sender.PublicKey, ct, DEFAULT_CONFIG, time.Minute, now.Add(-2*time.Hour))
+	assert.Error(t, err)
+}
@@ -0,0 +1,95 @@
+package eciesgo
+
+import (
+	"fmt"
+)
+
+// CiphertextLength returns length of Encrypt output for a message of n bytes with the passed config;
+// sizes are of secp256k1 receivers unless another curve is set with WithCurve
+func CiphertextLength(config Config, n int) (int, error) {
+	length, err := ciphertextLength(config)
+	if err != nil {
+		return 0, err
+	}
+
+	return length(n), nil
+}
+
+// CiphertextOverhead returns number of bytes Encrypt adds to a message with the passed config:
+// envelope header, ephemeral public key, nonce, tag and key commitment;
+// AES-CBC pads messages to the block size, for it the maximal overhead (of a message filling whole blocks) is returned
+func CiphertextOverhead(config Config) (int, error) {
+	length, err := ciphertextLength(config)
+	if err != nil {
+		return 0, err
+	}
+
+	return length(0), nil
+}
+
+// MaxPlaintextLen returns maximal length of a message which ciphertext fits into mtu bytes with the passed config
+func MaxPlaintextLen(config Config, mtu int) (int, error) {
+	length, err := ciphertextLength(config)
+	if err != nil {
+		return 0, err
+	}
+	if length(0) > mtu {
+		return 0, fmt.Errorf("ciphertext of empty message does not fit into %d bytes", mtu)
+	}
+
+	// Ciphertext length does not decrease with message length, so the largest fitting one is found by binary search
+	lo, hi := 0, mtu
+	for lo < hi {
+		mid := lo + (hi-lo+1)/2
+		if length(mid) <= mtu {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+
+	return lo, nil
+}
+
+// ciphertextLength returns function computing Encrypt output length from message length
+func ciphertextLength(config Config) (func(n int) int, error) {
+	header, config, err := appendEnvelope(nil, nil, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ephemeral public key is always uncompressed
+	curve, err := configCurve(config)
+	if err != nil {
+		return nil, err
+	}
+	if curve == nil {
+		curve = getCurve()
+	}
+	ephemeralLength := 1 + 2*((curve.Params().BitSize+7)/8)
+
+	if config.hpke != nil {
+		switch config.hpke.AEAD {
+		case HPKEAEADAES128GCM, HPKEAEADAES256GCM, HPKEAEADChaCha20Poly1305:
+		default:
+			return nil, fmt.Errorf("%w: HPKE AEAD %#04x", ErrUnsupportedCipher, config.hpke.AEAD)
+		}
+
+		return func(n int) int {
+			return len(header) + ephemeralLength + n + 16
+		}, nil
+	}
+
+	keySize, err := symmKeySize(config)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := generateSymmCipher(make([]byte, keySize), config)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(n int) int {
+		return len(header) + ephemeralLength + aead.NonceSize() + sealedLength(aead, n)
+	}, nil
+}
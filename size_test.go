@@ -0,0 +1,72 @@
+package eciesgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCiphertextLength(t *testing.T) {
+	skipUnapproved(t)
+
+	k, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	overhead, err := CiphertextOverhead(DEFAULT_CONFIG)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 65+16+16, overhead)
+
+	for _, config := range []Config{
+		DEFAULT_CONFIG,
+		NewConfig("aes-256-gcm", 12),
+		NewConfig("xchacha20", 24),
+		NewConfig("chacha20poly1305", 12),
+		NewConfig("aes-256-cbc-hmac-sha256", 16),
+		DEFAULT_CONFIG.WithEnvelope(),
+		DEFAULT_CONFIG.WithKeyCommitment(),
+		DEFAULT_CONFIG.WithHPKE(HPKESuite{KDF: HPKEKDFHKDFSHA256, AEAD: HPKEAEADChaCha20Poly1305}),
+	} {
+		overhead, err := CiphertextOverhead(config)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		for _, n := range []int{0, 1, 15, 16, 17, 1000} {
+			ct, err := EncryptConf(k.PublicKey, make([]byte, n), config)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			length, err := CiphertextLength(config, n)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, len(ct), length)
+			assert.True(t, len(ct)-n <= overhead)
+		}
+
+		for _, mtu := range []int{overhead, overhead + 1, 1280, 1500} {
+			n, err := MaxPlaintextLen(config, mtu)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			length, _ := CiphertextLength(config, n)
+			assert.True(t, length <= mtu)
+			length, _ = CiphertextLength(config, n+1)
+			assert.True(t, length > mtu)
+		}
+
+		_, err = MaxPlaintextLen(config, overhead-1)
+		assert.Error(t, err)
+	}
+
+	_, err = CiphertextOverhead(NewConfig("unknown", 16))
+	assert.Error(t, err)
+	_, err = CiphertextOverhead(DEFAULT_CONFIG.WithHPKE(HPKESuite{KDF: HPKEKDFHKDFSHA256, AEAD: HPKEAEADExportOnly}))
+	assert.Error(t, err)
+}
@@ -0,0 +1,142 @@
+package eciesgo
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"sync/atomic"
+)
+
+// ColumnKeys holds keys of encrypted database columns (EncryptedString and EncryptedBytes): values are encrypted
+// with EncryptMulti to every recipient, so keys are rotated by adding a recipient before re-encrypting rows,
+// and decrypted with the private key, which is nil for write-only services
+type ColumnKeys struct {
+	Recipients []*PublicKey
+	PrivateKey *PrivateKey
+	Config     Config
+}
+
+// NewColumnKeys returns column keys with the default config
+func NewColumnKeys(privkey *PrivateKey, recipients ...*PublicKey) *ColumnKeys {
+	return &ColumnKeys{Recipients: recipients, PrivateKey: privkey, Config: DefaultConfig()}
+}
+
+var defaultColumnKeys atomic.Value
+
+// SetColumnKeys sets keys of encrypted column values without own keys, it is safe for concurrent use
+func SetColumnKeys(keys *ColumnKeys) {
+	defaultColumnKeys.Store(keys)
+}
+
+// columnKeys returns keys of the value, or the ones set with SetColumnKeys
+func columnKeys(keys *ColumnKeys) (*ColumnKeys, error) {
+	if keys != nil {
+		return keys, nil
+	}
+	if keys, ok := defaultColumnKeys.Load().(*ColumnKeys); ok && keys != nil {
+		return keys, nil
+	}
+
+	return nil, fmt.Errorf("column keys are not set")
+}
+
+func (k *ColumnKeys) encrypt(msg []byte) (driver.Value, error) {
+	if len(k.Recipients) == 0 {
+		return nil, fmt.Errorf("column keys have no recipients")
+	}
+
+	return EncryptMultiConf(k.Recipients, msg, k.Config)
+}
+
+func (k *ColumnKeys) decrypt(src interface{}) ([]byte, error) {
+	var ct []byte
+	switch src := src.(type) {
+	case []byte:
+		ct = src
+	case string:
+		ct = []byte(src)
+	default:
+		return nil, fmt.Errorf("cannot scan %T into encrypted column", src)
+	}
+
+	if k.PrivateKey == nil {
+		return nil, fmt.Errorf("%w: column keys have no private key", ErrInvalidPrivateKey)
+	}
+
+	return DecryptMultiConf(k.PrivateKey, ct, k.Config)
+}
+
+// EncryptedString is a string stored encrypted in a binary database column, it implements driver.Valuer
+// and sql.Scanner, e.g. for database/sql, GORM and sqlx models; NULL is scanned as empty string
+type EncryptedString struct {
+	String string
+	// Keys are keys of the value, nil uses ones set with SetColumnKeys
+	Keys *ColumnKeys
+}
+
+// Value encrypts the string
+func (s EncryptedString) Value() (driver.Value, error) {
+	keys, err := columnKeys(s.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return keys.encrypt([]byte(s.String))
+}
+
+// Scan decrypts column value
+func (s *EncryptedString) Scan(src interface{}) error {
+	if src == nil {
+		s.String = ""
+		return nil
+	}
+
+	keys, err := columnKeys(s.Keys)
+	if err != nil {
+		return err
+	}
+	pt, err := keys.decrypt(src)
+	if err != nil {
+		return err
+	}
+
+	s.String = string(pt)
+	return nil
+}
+
+// EncryptedBytes is a byte slice stored encrypted in a binary database column, see EncryptedString;
+// NULL is scanned as nil
+type EncryptedBytes struct {
+	Bytes []byte
+	// Keys are keys of the value, nil uses ones set with SetColumnKeys
+	Keys *ColumnKeys
+}
+
+// Value encrypts the bytes
+func (b EncryptedBytes) Value() (driver.Value, error) {
+	keys, err := columnKeys(b.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return keys.encrypt(b.Bytes)
+}
+
+// Scan decrypts column value
+func (b *EncryptedBytes) Scan(src interface{}) error {
+	if src == nil {
+		b.Bytes = nil
+		return nil
+	}
+
+	keys, err := columnKeys(b.Keys)
+	if err != nil {
+		return err
+	}
+	pt, err := keys.decrypt(src)
+	if err != nil {
+		return err
+	}
+
+	b.Bytes = pt
+	return nil
+}
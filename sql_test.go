@@ -0,0 +1,82 @@
+package eciesgo
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptedString(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	other, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	keys := NewColumnKeys(privkey, privkey.PublicKey, other.PublicKey)
+
+	v, err := EncryptedString{String: testingMessage, Keys: keys}.Value()
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, driver.IsValue(v))
+
+	// Every recipient decrypts the value
+	s := EncryptedString{Keys: keys}
+	if !assert.NoError(t, s.Scan(v)) {
+		return
+	}
+	assert.Equal(t, testingMessage, s.String)
+	s = EncryptedString{Keys: NewColumnKeys(other)}
+	if !assert.NoError(t, s.Scan(string(v.([]byte)))) {
+		return
+	}
+	assert.Equal(t, testingMessage, s.String)
+
+	assert.NoError(t, s.Scan(nil))
+	assert.Equal(t, "", s.String)
+
+	// Write-only keys do not decrypt
+	s = EncryptedString{Keys: NewColumnKeys(nil, privkey.PublicKey)}
+	err = s.Scan(v)
+	assert.True(t, errors.Is(err, ErrInvalidPrivateKey), err)
+	_, err = EncryptedString{Keys: NewColumnKeys(privkey)}.Value()
+	assert.Error(t, err)
+	assert.Error(t, s.Scan(42))
+}
+
+func TestEncryptedBytes(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	SetColumnKeys(NewColumnKeys(privkey, privkey.PublicKey))
+	defer SetColumnKeys(nil)
+
+	v, err := EncryptedBytes{Bytes: []byte(testingMessage)}.Value()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var b EncryptedBytes
+	if !assert.NoError(t, b.Scan(v)) {
+		return
+	}
+	assert.Equal(t, []byte(testingMessage), b.Bytes)
+
+	assert.NoError(t, b.Scan(nil))
+	assert.Nil(t, b.Bytes)
+
+	SetColumnKeys(nil)
+	_, err = EncryptedBytes{Bytes: []byte(testingMessage)}.Value()
+	assert.Error(t, err)
+}
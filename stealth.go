@@ -0,0 +1,99 @@
+package eciesgo
+
+import (
+	"fmt"
+)
+
+// NewStealthKey derives one-time public key for the receiver with scan and spend public keys (dual-key stealth address);
+// returns one-time public key to pay or encrypt to and ephemeral public key which has to be published along with it;
+// only secp256k1 keys are supported
+func NewStealthKey(scanPub, spendPub *PublicKey) (oneTime, ephemeral *PublicKey, err error) {
+	ek, err := GenerateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tweak, err := stealthTweak(ek, scanPub)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	oneTime, err = stealthPublicKey(spendPub, tweak)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return oneTime, ek.PublicKey, nil
+}
+
+// StealthPublicKey recovers one-time public key from published ephemeral key with scan private key only,
+// so scanning for incoming keys can be delegated without the ability to spend or decrypt
+func StealthPublicKey(scanPriv *PrivateKey, spendPub, ephemeral *PublicKey) (*PublicKey, error) {
+	tweak, err := stealthTweak(scanPriv, ephemeral)
+	if err != nil {
+		return nil, err
+	}
+
+	return stealthPublicKey(spendPub, tweak)
+}
+
+// StealthPrivateKey derives one-time private key matching the one-time public key produced by NewStealthKey
+func StealthPrivateKey(scanPriv, spendPriv *PrivateKey, ephemeral *PublicKey) (*PrivateKey, error) {
+	tweak, err := stealthTweak(scanPriv, ephemeral)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tweak.Zeroize()
+
+	spend, err := spendPriv.Scalar()
+	if err != nil {
+		return nil, err
+	}
+	d := spend.Add(tweak)
+	spend.Zeroize()
+	defer d.Zeroize()
+	if d.IsZero() {
+		return nil, fmt.Errorf("one-time private key is zero")
+	}
+
+	return newPrivateKey(getCurve(), d.big()), nil
+}
+
+// stealthTweak hashes shared point of the ECDH into a scalar
+func stealthTweak(priv *PrivateKey, pub *PublicKey) (*Scalar, error) {
+	sx, sy, err := priv.sharedPoint(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	shared := &PublicKey{Curve: priv.Curve, X: sx, Y: sy}
+	h := taggedHash("ecies/stealth", shared.Bytes(true))
+	defer zeroBytes(h)
+
+	var tweak Scalar
+	tweak.s.SetByteSlice(h)
+	if tweak.IsZero() {
+		return nil, fmt.Errorf("stealth tweak is zero")
+	}
+
+	return &tweak, nil
+}
+
+// stealthPublicKey computes spend + tweak G
+func stealthPublicKey(spendPub *PublicKey, tweak *Scalar) (*PublicKey, error) {
+	spend, err := spendPub.Point()
+	if err != nil {
+		return nil, err
+	}
+	tweakG, err := ScalarBaseMult(tweak)
+	if err != nil {
+		return nil, err
+	}
+	p, err := spend.Add(tweakG)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.PublicKey(), nil
+}
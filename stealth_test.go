@@ -0,0 +1,61 @@
+package eciesgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStealthKey(t *testing.T) {
+	skipUnapproved(t)
+
+	scan, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	spend, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	oneTime, ephemeral, err := NewStealthKey(scan.PublicKey, spend.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.False(t, oneTime.Equals(spend.PublicKey))
+
+	// Scanning with scan key only
+	scanned, err := StealthPublicKey(scan, spend.PublicKey, ephemeral)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, oneTime.Equals(scanned))
+
+	priv, err := StealthPrivateKey(scan, spend, ephemeral)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, oneTime.Equals(priv.PublicKey))
+
+	// Message encrypted to one-time key is decrypted with derived private key
+	ciphertext, err := Encrypt(oneTime, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+	plaintext, err := Decrypt(priv, ciphertext)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(plaintext))
+
+	// Other receiver does not match
+	other, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	scanned, err = StealthPublicKey(other, spend.PublicKey, ephemeral)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.False(t, oneTime.Equals(scanned))
+}
@@ -0,0 +1,503 @@
+package eciesgo
+
+import (
+	"context"
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+)
+
+// Maximal length of plaintext encrypted in a single stream chunk
+const streamChunkSize = 64 * 1024
+
+// Flags set in chunk length: every chunk is sequenced, the last one is final
+const (
+	streamFinalFlag    = 0x80000000
+	streamSequenceFlag = 0x40000000
+)
+
+// Stream format: uncompressed ephemeral public key on the receiver curve followed by chunks;
+// every chunk is 4 bytes big endian plaintext length with flags, nonce, tag and ciphertext of up to
+// streamChunkSize bytes. All chunks but the last one carry exactly streamChunkSize bytes of plaintext
+// and every chunk has its own nonce, so offset of any chunk is computable and NewDecryptReadSeeker decrypts
+// byte ranges without reading the whole stream.
+// As in STREAM construction, chunk index and final flag are authenticated as additional data (see streamChunkAAD)
+// and the last chunk is marked final, possibly empty, so truncated, reordered and duplicated chunks are detected.
+// Chunks without the sequence flag, written before chunks were sequenced, are rejected
+type encryptWriter struct {
+	ctx  context.Context
+	w    io.Writer
+	aead cipher.AEAD
+	rand io.Reader
+
+	header []byte
+	buf    []byte
+	seq    uint64
+	err    error
+}
+
+// NewEncryptWriter returns writer which encrypts data written to it with a receiver public key
+// and writes chunked ciphertext to w in constant memory; Close must be called to flush the last chunk
+func NewEncryptWriter(w io.Writer, pubkey *PublicKey) (io.WriteCloser, error) {
+	return NewEncryptWriterConf(w, pubkey, DefaultConfig())
+}
+
+// NewEncryptWriterConf returns writer which encrypts data written to it with a receiver public key and the passed config
+func NewEncryptWriterConf(w io.Writer, pubkey *PublicKey, config Config) (io.WriteCloser, error) {
+	return newEncryptWriter(w, pubkey, config)
+}
+
+func newEncryptWriter(w io.Writer, pubkey *PublicKey, config Config) (*encryptWriter, error) {
+	if config.hpke != nil {
+		return nil, fmt.Errorf("stream encryption is not supported in HPKE mode")
+	}
+	if err := checkTrusted(config, pubkey); err != nil {
+		return nil, err
+	}
+
+	header, config, err := appendEnvelope(nil, keyCurve(pubkey), config)
+	if err != nil {
+		return nil, err
+	}
+
+	ek, err := generateEphemeralKey(pubkey, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Derive shared secret
+	ss, err := ek.encapsulate(pubkey, config)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := generateSymmCipher(ss, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptWriter{
+		ctx:    context.Background(),
+		w:      w,
+		aead:   aead,
+		rand:   config.random(),
+		header: append(header, ek.PublicKey.Bytes(false)...),
+		buf:    make([]byte, 0, streamChunkSize),
+	}, nil
+}
+
+func (s *encryptWriter) Write(p []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+
+	var n int
+	for len(p) > 0 {
+		// Full buffer is flushed only when more data comes, as the last chunk must be marked final on Close
+		if len(s.buf) == cap(s.buf) {
+			if s.err = s.flush(false); s.err != nil {
+				return n, s.err
+			}
+		}
+
+		k := copy(s.buf[len(s.buf):cap(s.buf)], p)
+		s.buf, p, n = s.buf[:len(s.buf)+k], p[k:], n+k
+	}
+
+	return n, nil
+}
+
+// Close encrypts and writes buffered data as the final chunk; it does not close the underlying writer
+func (s *encryptWriter) Close() error {
+	if s.err != nil {
+		return s.err
+	}
+
+	if s.err = s.flush(true); s.err != nil {
+		return s.err
+	}
+
+	s.err = fmt.Errorf("encrypt writer is closed")
+	return nil
+}
+
+// flush writes stream header (once) and buffered plaintext as a single chunk
+func (s *encryptWriter) flush(final bool) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	if s.header != nil {
+		if _, err := s.w.Write(s.header); err != nil {
+			return err
+		}
+		s.header = nil
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(s.rand, nonce); err != nil {
+		return fmt.Errorf("cannot read random bytes for nonce: %w", err)
+	}
+
+	var length [4]byte
+	flags := uint32(streamSequenceFlag)
+	if final {
+		flags |= streamFinalFlag
+	}
+	binary.BigEndian.PutUint32(length[:], uint32(len(s.buf))|flags)
+	if _, err := s.w.Write(length[:]); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(sealSymm(s.aead, nonce, s.buf, streamChunkAAD(s.seq, final))); err != nil {
+		return err
+	}
+
+	s.buf = s.buf[:0]
+	s.seq++
+	return nil
+}
+
+// streamChunkAAD returns additional data of sequenced chunk: 8 bytes big endian chunk index and final flag byte
+func streamChunkAAD(seq uint64, final bool) []byte {
+	aad := make([]byte, 9)
+	binary.BigEndian.PutUint64(aad, seq)
+	if final {
+		aad[8] = 1
+	}
+	return aad
+}
+
+type decryptReader struct {
+	ctx  context.Context
+	r    io.Reader
+	aead cipher.AEAD
+
+	chunk []byte
+	buf   []byte
+	err   error
+
+	// seq is index of the next chunk, final is set after the final chunk is read
+	seq   uint64
+	final bool
+}
+
+// NewDecryptReader returns reader which decrypts chunked ciphertext produced by NewEncryptWriter from r
+// with a receiver private key
+func NewDecryptReader(r io.Reader, privkey *PrivateKey) (io.Reader, error) {
+	return NewDecryptReaderConf(r, privkey, DefaultConfig())
+}
+
+// NewDecryptReaderConf returns reader which decrypts chunked ciphertext from r with a receiver private key
+// and the passed config
+func NewDecryptReaderConf(r io.Reader, privkey *PrivateKey, config Config) (io.Reader, error) {
+	return newDecryptReader(r, privkey, config)
+}
+
+func newDecryptReader(r io.Reader, privkey *PrivateKey, config Config) (*decryptReader, error) {
+	if config.hpke != nil {
+		return nil, fmt.Errorf("stream encryption is not supported in HPKE mode")
+	}
+
+	if config.envelope {
+		header := make([]byte, envelopeHeaderLength)
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, fmt.Errorf("cannot read envelope: %w", err)
+		}
+
+		e, err := ParseEnvelope(header)
+		if err != nil {
+			return nil, err
+		}
+		config = e.apply(config)
+	}
+
+	curve, err := ephemeralCurve(privkey, config)
+	if err != nil {
+		return nil, err
+	}
+	l := (curve.Params().BitSize + 7) / 8
+	pub := make([]byte, 1+2*l)
+	if _, err := io.ReadFull(r, pub); err != nil {
+		return nil, fmt.Errorf("%w: cannot read ephemeral public key", ErrCiphertextTooShort)
+	}
+
+	// Ephemeral sender public key
+	ethPubkey := &PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(pub[1 : 1+l]),
+		Y:     new(big.Int).SetBytes(pub[1+l:]),
+	}
+
+	// Derive shared secret
+	ss, err := ethPubkey.decapsulate(privkey, config)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := generateSymmCipher(ss, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &decryptReader{
+		ctx:   context.Background(),
+		r:     r,
+		aead:  aead,
+		chunk: make([]byte, aead.NonceSize()+sealedLength(aead, streamChunkSize)),
+	}, nil
+}
+
+func (s *decryptReader) Read(p []byte) (int, error) {
+	for len(s.buf) == 0 {
+		if s.err != nil {
+			return 0, s.err
+		}
+		s.buf, s.err = s.readChunk()
+	}
+
+	n := copy(p, s.buf)
+	s.buf = s.buf[n:]
+	return n, nil
+}
+
+// readChunk reads and decrypts next chunk, returns io.EOF at the end of stream
+func (s *decryptReader) readChunk() ([]byte, error) {
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var length [4]byte
+	if _, err := io.ReadFull(s.r, length[:]); err != nil {
+		switch {
+		case err != io.EOF:
+			return nil, fmt.Errorf("%w: cannot read chunk length", ErrCiphertextTooShort)
+		case !s.final:
+			return nil, fmt.Errorf("%w: stream is truncated, final chunk is missing", ErrCiphertextTooShort)
+		}
+		return nil, io.EOF
+	}
+	if s.final {
+		return nil, fmt.Errorf("%w: data after final chunk", ErrAuthenticationFailed)
+	}
+
+	l := binary.BigEndian.Uint32(length[:])
+	sequenced, final := l&streamSequenceFlag != 0, l&streamFinalFlag != 0
+	l &^= streamSequenceFlag | streamFinalFlag
+
+	switch {
+	case !sequenced:
+		return nil, fmt.Errorf("%w: chunk is not sequenced", ErrAuthenticationFailed)
+	case (l == 0 && !final) || l > streamChunkSize:
+		return nil, fmt.Errorf("%w: invalid chunk length %d", ErrCiphertextTooShort, l)
+	}
+
+	chunk := s.chunk[:s.aead.NonceSize()+sealedLength(s.aead, int(l))]
+	if _, err := io.ReadFull(s.r, chunk); err != nil {
+		return nil, fmt.Errorf("%w: cannot read chunk", ErrCiphertextTooShort)
+	}
+
+	plaintext, err := openSymm(s.aead, chunk, streamChunkAAD(s.seq, final))
+	if err != nil {
+		return nil, err
+	}
+
+	s.seq++
+	s.final = final
+	return plaintext, nil
+}
+
+// decryptReadSeeker decrypts chunks of a seekable stream on demand, caching the last decrypted one
+type decryptReadSeeker struct {
+	dr *decryptReader
+	rs io.ReadSeeker
+
+	// start is offset of the first chunk in rs, chunkLength is length of a full chunk in rs
+	start       int64
+	chunkLength int64
+	size        int64
+	offset      int64
+
+	chunkIndex int64
+	chunk      []byte
+}
+
+// NewDecryptReadSeeker returns reader which decrypts chunked ciphertext produced by NewEncryptWriter from rs
+// with a receiver private key and supports seeking over plaintext, e.g. for serving HTTP range requests
+// with http.ServeContent; only chunks covering read ranges are read and decrypted
+func NewDecryptReadSeeker(rs io.ReadSeeker, privkey *PrivateKey) (io.ReadSeeker, error) {
+	return NewDecryptReadSeekerConf(rs, privkey, DefaultConfig())
+}
+
+// NewDecryptReadSeekerConf returns seekable reader which decrypts chunked ciphertext from rs
+// with a receiver private key and the passed config
+func NewDecryptReadSeekerConf(rs io.ReadSeeker, privkey *PrivateKey, config Config) (io.ReadSeeker, error) {
+	dr, err := newDecryptReader(rs, privkey, config)
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := rs.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	end, err := rs.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &decryptReadSeeker{
+		dr:          dr,
+		rs:          rs,
+		start:       start,
+		chunkLength: int64(4 + dr.aead.NonceSize() + sealedLength(dr.aead, streamChunkSize)),
+		chunkIndex:  -1,
+	}
+
+	// Plaintext size is known from the number of chunks and the length of the last one;
+	// the first chunk is checked as well, so corrupted streams are rejected before seeking
+	if end <= start {
+		return nil, fmt.Errorf("%w: stream has no chunks", ErrCiphertextTooShort)
+	}
+	last := (end - start - 1) / s.chunkLength
+	if _, err := s.readChunk(0, last == 0); err != nil {
+		return nil, err
+	}
+	chunk, err := s.readChunk(last, true)
+	if err != nil {
+		return nil, err
+	}
+	if s.start+last*s.chunkLength+int64(4+dr.aead.NonceSize()+sealedLength(dr.aead, len(chunk))) != end {
+		return nil, fmt.Errorf("%w: invalid length of the last chunk", ErrCiphertextTooShort)
+	}
+	s.size = last*streamChunkSize + int64(len(chunk))
+
+	return s, nil
+}
+
+func (s *decryptReadSeeker) Read(p []byte) (int, error) {
+	if s.offset >= s.size {
+		return 0, io.EOF
+	}
+
+	i := s.offset / streamChunkSize
+	chunk, err := s.readChunk(i, i == (s.size-1)/streamChunkSize)
+	if err != nil {
+		return 0, err
+	}
+
+	n := copy(p, chunk[s.offset%streamChunkSize:])
+	s.offset += int64(n)
+	return n, nil
+}
+
+func (s *decryptReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += s.offset
+	case io.SeekEnd:
+		offset += s.size
+	default:
+		return 0, fmt.Errorf("invalid whence: %d", whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative position: %d", offset)
+	}
+
+	s.offset = offset
+	return offset, nil
+}
+
+// readChunk returns decrypted chunk i; every chunk but the last one must be full and only the last one is final
+func (s *decryptReadSeeker) readChunk(i int64, last bool) ([]byte, error) {
+	if i == s.chunkIndex {
+		return s.chunk, nil
+	}
+
+	if _, err := s.rs.Seek(s.start+i*s.chunkLength, io.SeekStart); err != nil {
+		return nil, err
+	}
+	s.dr.seq, s.dr.final = uint64(i), false
+	chunk, err := s.dr.readChunk()
+	switch {
+	case err == io.EOF:
+		return nil, fmt.Errorf("%w: chunk %d is missing", ErrCiphertextTooShort, i)
+	case err != nil:
+		return nil, err
+	case !last && len(chunk) != streamChunkSize:
+		return nil, fmt.Errorf("%w: chunk %d is not full", ErrCiphertextTooShort, i)
+	case s.dr.final != last:
+		return nil, fmt.Errorf("%w: chunk %d has unexpected final flag", ErrAuthenticationFailed, i)
+	}
+
+	s.chunkIndex, s.chunk = i, chunk
+	return chunk, nil
+}
+
+// EncryptFile encrypts file at srcPath with a receiver public key and writes chunked ciphertext to dstPath
+func EncryptFile(pubkey *PublicKey, srcPath, dstPath string) error {
+	return EncryptFileConf(pubkey, srcPath, dstPath, DefaultConfig())
+}
+
+// EncryptFileConf encrypts file at srcPath with a receiver public key and the passed config,
+// writes chunked ciphertext to dstPath
+func EncryptFileConf(pubkey *PublicKey, srcPath, dstPath string, config Config) error {
+	return transformFile(srcPath, dstPath, func(src io.Reader, dst io.Writer) error {
+		w, err := NewEncryptWriterConf(dst, pubkey, config)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.Copy(w, src); err != nil {
+			return err
+		}
+
+		return w.Close()
+	})
+}
+
+// DecryptFile decrypts file produced by EncryptFile with a receiver private key and writes plaintext to dstPath;
+// dstPath is removed if decryption fails
+func DecryptFile(privkey *PrivateKey, srcPath, dstPath string) error {
+	return DecryptFileConf(privkey, srcPath, dstPath, DefaultConfig())
+}
+
+// DecryptFileConf decrypts file produced by EncryptFileConf with a receiver private key and the passed config
+func DecryptFileConf(privkey *PrivateKey, srcPath, dstPath string, config Config) error {
+	return transformFile(srcPath, dstPath, func(src io.Reader, dst io.Writer) error {
+		r, err := NewDecryptReaderConf(src, privkey, config)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(dst, r)
+		return err
+	})
+}
+
+// transformFile streams srcPath through transform into newly created dstPath, removes dstPath on failure
+func transformFile(srcPath, dstPath string, transform func(src io.Reader, dst io.Writer) error) (err error) {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("cannot open source file: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(dstPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("cannot create destination file: %w", err)
+	}
+	defer func() {
+		if closeErr := dst.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("cannot close destination file: %w", closeErr)
+		}
+		if err != nil {
+			os.Remove(dstPath)
+		}
+	}()
+
+	return transform(src, dst)
+}
@@ -0,0 +1,394 @@
+package eciesgo
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptWriterAndDecryptReader(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, size := range []int{0, 1, streamChunkSize - 1, streamChunkSize, 3*streamChunkSize + 5} {
+		for _, conf := range []Config{DEFAULT_CONFIG, NewConfig("xchacha20", 0).WithEnvelope()} {
+			msg := make([]byte, size)
+			if _, err := rand.Read(msg); !assert.NoError(t, err) {
+				return
+			}
+
+			var ct bytes.Buffer
+			w, err := NewEncryptWriterConf(&ct, privkey.PublicKey, conf)
+			if !assert.NoError(t, err) {
+				return
+			}
+			// Write in uneven parts
+			for p := msg; len(p) > 0; {
+				n := 1000
+				if n > len(p) {
+					n = len(p)
+				}
+				if _, err := w.Write(p[:n]); !assert.NoError(t, err) {
+					return
+				}
+				p = p[n:]
+			}
+			if !assert.NoError(t, w.Close()) {
+				return
+			}
+
+			r, err := NewDecryptReaderConf(&ct, privkey, conf)
+			if !assert.NoError(t, err) {
+				return
+			}
+			pt, err := ioutil.ReadAll(r)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, msg, pt)
+		}
+	}
+}
+
+func TestDecryptReaderTampered(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var ct bytes.Buffer
+	w, err := NewEncryptWriter(&ct, privkey.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if _, err := w.Write(make([]byte, 2*streamChunkSize+1)); !assert.NoError(t, err) {
+		return
+	}
+	if !assert.NoError(t, w.Close()) {
+		return
+	}
+
+	for _, tamper := range []func(b []byte) []byte{
+		func(b []byte) []byte { b[len(b)-1] ^= 1; return b },
+		func(b []byte) []byte { return b[:len(b)-1] },
+		func(b []byte) []byte { b[66] ^= 1; return b },
+	} {
+		r, err := NewDecryptReader(bytes.NewReader(tamper(append([]byte(nil), ct.Bytes()...))), privkey)
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, err = ioutil.ReadAll(r)
+		assert.Error(t, err)
+	}
+}
+
+func TestEncryptFileAndDecryptFile(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	dir, err := ioutil.TempDir("", "ecies")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	msg := make([]byte, 2*streamChunkSize+100)
+	if _, err := rand.Read(msg); !assert.NoError(t, err) {
+		return
+	}
+
+	src, enc, dec := filepath.Join(dir, "src"), filepath.Join(dir, "enc"), filepath.Join(dir, "dec")
+	if !assert.NoError(t, ioutil.WriteFile(src, msg, 0600)) {
+		return
+	}
+	if !assert.NoError(t, EncryptFile(privkey.PublicKey, src, enc)) {
+		return
+	}
+	if !assert.NoError(t, DecryptFile(privkey, enc, dec)) {
+		return
+	}
+
+	pt, err := ioutil.ReadFile(dec)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, msg, pt)
+
+	// Wrong key, destination must not be left behind
+	other, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	wrong := filepath.Join(dir, "wrong")
+	assert.Error(t, DecryptFile(other, enc, wrong))
+	_, err = os.Stat(wrong)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDecryptReadSeeker(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, size := range []int{0, 1, streamChunkSize, 3*streamChunkSize + 100} {
+		for _, conf := range []Config{DEFAULT_CONFIG, NewConfig("aes-256-cbc-hmac-sha256", 16).WithEnvelope()} {
+			msg := make([]byte, size)
+			if _, err := rand.Read(msg); !assert.NoError(t, err) {
+				return
+			}
+
+			var buf bytes.Buffer
+			w, err := NewEncryptWriterConf(&buf, privkey.PublicKey, conf)
+			if !assert.NoError(t, err) {
+				return
+			}
+			if _, err := w.Write(msg); !assert.NoError(t, err) {
+				return
+			}
+			if !assert.NoError(t, w.Close()) {
+				return
+			}
+
+			rs, err := NewDecryptReadSeekerConf(bytes.NewReader(buf.Bytes()), privkey, conf)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			end, err := rs.Seek(0, io.SeekEnd)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, int64(size), end)
+
+			// Ranges within and across chunks
+			for _, r := range [][2]int{{0, size}, {size / 2, size}, {size / 3, size / 3 * 2}, {size, size}} {
+				if _, err := rs.Seek(int64(r[0]), io.SeekStart); !assert.NoError(t, err) {
+					return
+				}
+				part := make([]byte, r[1]-r[0])
+				if _, err := io.ReadFull(rs, part); !assert.NoError(t, err) {
+					return
+				}
+				assert.Equal(t, msg[r[0]:r[1]], part)
+			}
+
+			n, err := rs.Read(make([]byte, 1))
+			assert.Equal(t, 0, n)
+			assert.Equal(t, io.EOF, err)
+		}
+	}
+}
+
+func TestDecryptReadSeekerTampered(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var buf bytes.Buffer
+	w, err := NewEncryptWriter(&buf, privkey.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if _, err := w.Write(make([]byte, 2*streamChunkSize+10)); !assert.NoError(t, err) {
+		return
+	}
+	if !assert.NoError(t, w.Close()) {
+		return
+	}
+	ct := buf.Bytes()
+
+	// Truncated last chunk
+	_, err = NewDecryptReadSeeker(bytes.NewReader(ct[:len(ct)-1]), privkey)
+	assert.Error(t, err)
+
+	// Tampered middle chunk is detected when it is read
+	tampered := append([]byte(nil), ct...)
+	tampered[len(tampered)-100] ^= 1
+	rs, err := NewDecryptReadSeeker(bytes.NewReader(tampered), privkey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = rs.Read(make([]byte, 10))
+	assert.NoError(t, err)
+	_, err = rs.Seek(streamChunkSize+10, io.SeekStart)
+	assert.NoError(t, err)
+	_, err = rs.Read(make([]byte, 10))
+	assert.Error(t, err)
+
+	_, err = rs.Seek(-1, io.SeekStart)
+	assert.Error(t, err)
+}
+
+func TestDecryptReaderChunkSequence(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var buf bytes.Buffer
+	w, err := NewEncryptWriter(&buf, privkey.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if _, err := w.Write(make([]byte, 3*streamChunkSize)); !assert.NoError(t, err) {
+		return
+	}
+	if !assert.NoError(t, w.Close()) {
+		return
+	}
+	ct := buf.Bytes()
+
+	// Three full chunks, the last one is final
+	chunkLength := 4 + 16 + 16 + streamChunkSize
+	if !assert.Len(t, ct, 65+3*chunkLength) {
+		return
+	}
+	header, chunks := ct[:65], [][]byte{ct[65 : 65+chunkLength], ct[65+chunkLength : 65+2*chunkLength], ct[65+2*chunkLength:]}
+	assert.Equal(t, byte(0x40), chunks[0][0])
+	assert.Equal(t, byte(0xc0), chunks[2][0])
+
+	join := func(parts ...[]byte) []byte {
+		return bytes.Join(append([][]byte{header}, parts...), nil)
+	}
+	for name, stream := range map[string][]byte{
+		"truncated at chunk boundary": join(chunks[0], chunks[1]),
+		"header only":                 join(),
+		"reordered":                   join(chunks[1], chunks[0], chunks[2]),
+		"duplicated":                  join(chunks[0], chunks[0], chunks[1], chunks[2]),
+		"data after final chunk":      join(chunks[0], chunks[1], chunks[2], chunks[2]),
+	} {
+		r, err := NewDecryptReader(bytes.NewReader(stream), privkey)
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, err = ioutil.ReadAll(r)
+		assert.Error(t, err, name)
+
+		_, err = NewDecryptReadSeeker(bytes.NewReader(stream), privkey)
+		assert.Error(t, err, name)
+	}
+
+	// Missing final flag is reported as tampering, so error class metrics do not count it as truncation
+	_, err = NewDecryptReadSeeker(bytes.NewReader(join(chunks[0], chunks[1])), privkey)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+
+	// Final flag is authenticated
+	unflagged := join(chunks[0], chunks[1], append([]byte{0x40}, chunks[2][1:]...))
+	_, err = ioutil.ReadAll(mustDecryptReader(t, unflagged, privkey))
+	assert.Error(t, err)
+}
+
+func TestDecryptReaderRejectsUnsequencedStream(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Chunks of streams written before sequencing have no flags and no additional data,
+	// so they can be truncated undetectably and are rejected
+	ek, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	ss, err := ek.encapsulate(privkey.PublicKey, DEFAULT_CONFIG)
+	if !assert.NoError(t, err) {
+		return
+	}
+	aead, err := generateSymmCipher(ss, DEFAULT_CONFIG)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	msg := []byte(testingMessage)
+	stream := ek.PublicKey.Bytes(false)
+	for i := 0; i < 2; i++ {
+		stream = append(stream, 0, 0, 0, byte(len(msg)))
+		stream = append(stream, sealSymm(aead, make([]byte, 16), msg, nil)...)
+	}
+
+	// Missing flags are tampering rather than truncation
+	_, err = ioutil.ReadAll(mustDecryptReader(t, stream, privkey))
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+
+	_, err = NewDecryptReadSeeker(bytes.NewReader(stream), privkey)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+}
+
+func TestEncryptWriterCurves(t *testing.T) {
+	curves := []elliptic.Curve{elliptic.P256(), elliptic.P384()}
+	if !FIPSMode() {
+		curves = append(curves, elliptic.P521())
+	}
+
+	msg := make([]byte, streamChunkSize+5)
+	for _, curve := range curves {
+		privkey, err := GenerateKeyCurve(curve)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		var ct bytes.Buffer
+		w, err := NewEncryptWriter(&ct, privkey.PublicKey)
+		if !assert.NoError(t, err) {
+			return
+		}
+		if _, err := w.Write(msg); !assert.NoError(t, err) {
+			return
+		}
+		if !assert.NoError(t, w.Close()) {
+			return
+		}
+
+		pt, err := ioutil.ReadAll(mustDecryptReader(t, ct.Bytes(), privkey))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, msg, pt)
+
+		rs, err := NewDecryptReadSeeker(bytes.NewReader(ct.Bytes()), privkey)
+		if !assert.NoError(t, err) {
+			return
+		}
+		pt, err = ioutil.ReadAll(rs)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, msg, pt)
+	}
+}
+
+func mustDecryptReader(t *testing.T, stream []byte, privkey *PrivateKey) io.Reader {
+	r, err := NewDecryptReader(bytes.NewReader(stream), privkey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
@@ -0,0 +1,128 @@
+package eciesgo
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+)
+
+// structTag is the tag of fields encrypted by EncryptStruct, e.g.
+//
+//	type User struct {
+//		ID    int
+//		Email string `ecies:"encrypt"`
+//		SSN   []byte `ecies:"encrypt"`
+//	}
+const structTag = "ecies"
+
+// EncryptStruct encrypts fields tagged `ecies:"encrypt"` of the struct pointed by v in place with a receiver
+// public key and the passed config: string fields are replaced with standard base64 of ciphertext, []byte fields
+// with ciphertext. Nested structs and non-nil pointers to structs are walked; tagged fields of other types and
+// unexported tagged fields are errors. Fields are encrypted separately, so each one is decrypted on its own;
+// the struct is modified only if all of them succeed
+func EncryptStruct(pubkey *PublicKey, v interface{}, config Config) error {
+	return transformStruct(v, false, func(field []byte) ([]byte, error) {
+		return EncryptConf(pubkey, field, config)
+	})
+}
+
+// DecryptStruct decrypts fields tagged `ecies:"encrypt"` of the struct pointed by v in place with a receiver
+// private key and the passed config, it reverses EncryptStruct
+func DecryptStruct(privkey *PrivateKey, v interface{}, config Config) error {
+	return transformStruct(v, true, func(field []byte) ([]byte, error) {
+		return DecryptConf(privkey, field, config)
+	})
+}
+
+// transformStruct replaces tagged fields with transform output once all of them are transformed;
+// string fields are base64 decoded before transform if decode is set (decryption), otherwise encoded after it
+func transformStruct(v interface{}, decode bool, transform func([]byte) ([]byte, error)) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("expected non-nil pointer to struct, got %T", v)
+	}
+
+	var updates []func()
+	if err := walkStruct(rv.Elem(), func(name string, fv reflect.Value) error {
+		switch {
+		case fv.Kind() == reflect.String:
+			in := []byte(fv.String())
+			if decode {
+				var err error
+				if in, err = base64.StdEncoding.DecodeString(fv.String()); err != nil {
+					return fmt.Errorf("cannot decode field %s: %w", name, err)
+				}
+			}
+
+			out, err := transform(in)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", name, err)
+			}
+
+			value := string(out)
+			if !decode {
+				value = base64.StdEncoding.EncodeToString(out)
+			}
+			updates = append(updates, func() { fv.SetString(value) })
+		case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.Uint8:
+			out, err := transform(fv.Bytes())
+			if err != nil {
+				return fmt.Errorf("field %s: %w", name, err)
+			}
+			updates = append(updates, func() { fv.SetBytes(out) })
+		default:
+			return fmt.Errorf("field %s of type %s cannot be encrypted", name, fv.Type())
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, update := range updates {
+		update()
+	}
+
+	return nil
+}
+
+// walkStruct calls f for every field tagged for encryption, walking nested structs and pointers to them
+func walkStruct(rv reflect.Value, f func(name string, fv reflect.Value) error) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		fv := rv.Field(i)
+		name := rt.Name() + "." + sf.Name
+
+		tag, tagged := sf.Tag.Lookup(structTag)
+		if tag == "-" {
+			continue
+		}
+		if !tagged {
+			if !fv.CanSet() {
+				continue
+			}
+			if fv.Kind() == reflect.Ptr && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				if err := walkStruct(fv, f); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if tag != "encrypt" {
+			return fmt.Errorf("field %s has unknown ecies tag %q", name, tag)
+		}
+		if !fv.CanSet() {
+			return fmt.Errorf("field %s is unexported", name)
+		}
+		if err := f(name, fv); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
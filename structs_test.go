@@ -0,0 +1,98 @@
+package eciesgo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testAddress struct {
+	Street string `ecies:"encrypt"`
+	City   string
+}
+
+type testUser struct {
+	ID      int
+	Name    string
+	Email   string `ecies:"encrypt"`
+	SSN     []byte `ecies:"encrypt"`
+	Home    testAddress
+	Work    *testAddress
+	Skipped testAddress `ecies:"-"`
+}
+
+func TestEncryptStruct(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	original := testUser{
+		ID:      1,
+		Name:    "Alice",
+		Email:   "alice@example.com",
+		SSN:     []byte("123-45-6789"),
+		Home:    testAddress{Street: "Main St 1", City: "Springfield"},
+		Work:    &testAddress{Street: "Market St 2", City: "Shelbyville"},
+		Skipped: testAddress{Street: "Elm St 3"},
+	}
+	work := *original.Work
+	user := original
+	user.Work = &work
+
+	if !assert.NoError(t, EncryptStruct(privkey.PublicKey, &user, DEFAULT_CONFIG)) {
+		return
+	}
+	assert.Equal(t, 1, user.ID)
+	assert.Equal(t, "Alice", user.Name)
+	assert.NotEqual(t, original.Email, user.Email)
+	assert.NotEqual(t, original.SSN, user.SSN)
+	assert.NotEqual(t, original.Home.Street, user.Home.Street)
+	assert.Equal(t, original.Home.City, user.Home.City)
+	assert.NotEqual(t, original.Work.Street, user.Work.Street)
+	assert.Equal(t, original.Skipped, user.Skipped)
+
+	if !assert.NoError(t, DecryptStruct(privkey, &user, DEFAULT_CONFIG)) {
+		return
+	}
+	assert.Equal(t, original, user)
+
+	// Failed decryption leaves struct unchanged
+	other, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.NoError(t, EncryptStruct(privkey.PublicKey, &user, DEFAULT_CONFIG)) {
+		return
+	}
+	encrypted := user
+	work = *user.Work
+	encrypted.Work = &work
+	err = DecryptStruct(other, &user, DEFAULT_CONFIG)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+	assert.Equal(t, encrypted, user)
+}
+
+func TestEncryptStructInvalid(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Error(t, EncryptStruct(privkey.PublicKey, testUser{}, DEFAULT_CONFIG))
+	assert.Error(t, EncryptStruct(privkey.PublicKey, (*testUser)(nil), DEFAULT_CONFIG))
+	assert.Error(t, EncryptStruct(privkey.PublicKey, &struct {
+		Age int `ecies:"encrypt"`
+	}{}, DEFAULT_CONFIG))
+	assert.Error(t, EncryptStruct(privkey.PublicKey, &struct {
+		Email string `ecies:"hash"`
+	}{}, DEFAULT_CONFIG))
+	assert.Error(t, EncryptStruct(privkey.PublicKey, &struct {
+		email string `ecies:"encrypt"`
+	}{}, DEFAULT_CONFIG))
+}
@@ -4,18 +4,60 @@ import (
 	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
-	"crypto/rand"
 	"fmt"
+	"io"
 
 	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
 )
 
+// symmKeySize returns key length of the configured symmetric algorithm, KDF output length is driven by it
+func symmKeySize(conf Config) (int, error) {
+	switch conf.symmetricAlgorithm {
+	case "aes-128-gcm":
+		return 16, nil
+	case "aes-192-gcm":
+		return 24, nil
+	case "aes-256-gcm":
+		return 32, nil
+	case "aes-256-cbc-hmac-sha256":
+		return 64, nil
+	case "xchacha20", "chacha20poly1305":
+		return chacha20poly1305.KeySize, nil
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedCipher, conf.symmetricAlgorithm)
+	}
+}
+
 func generateSymmCipher(key []byte, conf Config) (cipher.AEAD, error) {
 	var err error
 	var aead cipher.AEAD
 
+	if err := checkFIPSConfig(conf); err != nil {
+		return nil, err
+	}
+
+	keySize, err := symmKeySize(conf)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("invalid key length for %s: %d, expected %d", conf.symmetricAlgorithm, len(key), keySize)
+	}
+
+	var commitment []byte
+	if conf.kdfKeySeparation {
+		if key, commitment, err = separateKeys(key, conf); err != nil {
+			return nil, err
+		}
+	} else if conf.keyCommitment {
+		if key, commitment, err = commitKey(key); err != nil {
+			return nil, err
+		}
+	}
+
 	switch conf.symmetricAlgorithm {
-	case "aes-256-gcm":
+	case "aes-128-gcm", "aes-192-gcm", "aes-256-gcm":
 		block, err := aes.NewCipher(key)
 		if err != nil {
 			return nil, fmt.Errorf("cannot create new AES block: %w", err)
@@ -25,20 +67,79 @@ func generateSymmCipher(key []byte, conf Config) (cipher.AEAD, error) {
 		if err != nil {
 			return nil, fmt.Errorf("cannot create AES GCM: %w", err)
 		}
+	case "aes-256-cbc-hmac-sha256":
+		if aead, err = newCBCHMAC(key); err != nil {
+			return nil, err
+		}
 	case "xchacha20":
 		aead, err = chacha20poly1305.NewX(key)
 		if err != nil {
 			return nil, fmt.Errorf("cannot create XChaCha20: %w", err)
 		}
-	default:
-		return nil, fmt.Errorf("unknown cipher: %s", conf.symmetricAlgorithm)
+	case "chacha20poly1305":
+		aead, err = chacha20poly1305.New(key)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create ChaCha20-Poly1305: %w", err)
+		}
+	}
+
+	if commitment != nil {
+		aead = &committingAEAD{AEAD: aead, commitment: commitment}
 	}
 
 	return aead, nil
 }
 
+// Domain separation labels of keys expanded from the master key, see Config.WithKeySeparation
+const (
+	labelEncryptionKey = "ecies/encryption-key"
+	labelMACKey        = "ecies/mac-key"
+	labelCommitment    = "ecies/commitment"
+)
+
+// separateKeys expands master key into encryption key of the same length (AES-CBC key followed by MAC key
+// for "aes-256-cbc-hmac-sha256") and, if config is key-committing, commitment
+func separateKeys(master []byte, conf Config) (key []byte, commitment []byte, err error) {
+	h, err := kdfHash(conf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	expand := func(label string, length int) ([]byte, error) {
+		out := make([]byte, length)
+		if _, err := io.ReadFull(hkdf.Expand(h, master, []byte(label)), out); err != nil {
+			return nil, fmt.Errorf("cannot read %s from HKDF reader: %w", label, err)
+		}
+		return out, nil
+	}
+
+	if conf.symmetricAlgorithm == "aes-256-cbc-hmac-sha256" {
+		encKey, err := expand(labelEncryptionKey, 32)
+		if err != nil {
+			return nil, nil, err
+		}
+		macKey, err := expand(labelMACKey, 32)
+		if err != nil {
+			return nil, nil, err
+		}
+		key = append(encKey, macKey...)
+	} else if key, err = expand(labelEncryptionKey, len(master)); err != nil {
+		return nil, nil, err
+	}
+
+	if conf.keyCommitment {
+		if commitment, err = expand(labelCommitment, commitmentLength); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return key, commitment, nil
+}
+
 func EncryptSymm(key []byte, msg []byte, conf Config) ([]byte, error) {
-	var ct bytes.Buffer
+	if err := checkPlaintextSize(conf, len(msg)); err != nil {
+		return nil, err
+	}
 
 	aead, err := generateSymmCipher(key, conf)
 	if err != nil {
@@ -46,31 +147,47 @@ func EncryptSymm(key []byte, msg []byte, conf Config) ([]byte, error) {
 	}
 
 	nonce := make([]byte, aead.NonceSize())
-	if _, err := rand.Read(nonce); err != nil {
-		return nil, fmt.Errorf("cannot read random bytes for nonce: %w", err)
+	if err := generateNonce(conf, key, nonce); err != nil {
+		return nil, err
 	}
 
+	return sealSymm(aead, nonce, msg, nil), nil
+}
+
+// sealSymm encrypts message with the given nonce and additional data and returns nonce, tag and ciphertext concatenated
+func sealSymm(aead cipher.AEAD, nonce []byte, msg []byte, additionalData []byte) []byte {
+	var ct bytes.Buffer
+
 	ct.Write(nonce)
 
-	ciphertext := aead.Seal(nil, nonce, msg, nil)
+	ciphertext := aead.Seal(nil, nonce, msg, additionalData)
 
 	tag := ciphertext[len(ciphertext)-aead.Overhead():]
 	ct.Write(tag)
 	ciphertext = ciphertext[:len(ciphertext)-len(tag)]
 	ct.Write(ciphertext)
 
-	return ct.Bytes(), nil
+	return ct.Bytes()
 }
 
 func DecryptSymm(key []byte, msg []byte, conf Config) ([]byte, error) {
+	if err := checkSymmCiphertextSize(conf, len(msg)); err != nil {
+		return nil, err
+	}
+
 	aead, err := generateSymmCipher(key, conf)
 	if err != nil {
 		return nil, err
 	}
 
-	// Message cannot be less than length of public key (65) + nonce + tag (16)
-	if len(msg) <= (aead.NonceSize() + aead.Overhead()) {
-		return nil, fmt.Errorf("invalid length of message")
+	return openSymm(aead, msg, nil)
+}
+
+// openSymm decrypts message consisting of nonce, tag and ciphertext with the given additional data
+func openSymm(aead cipher.AEAD, msg []byte, additionalData []byte) ([]byte, error) {
+	// Message cannot be less than length of nonce + tag (16)
+	if len(msg) < (aead.NonceSize() + aead.Overhead()) {
+		return nil, ErrCiphertextTooShort
 	}
 
 	// Symmetrical decryption part
@@ -81,10 +198,25 @@ func DecryptSymm(key []byte, msg []byte, conf Config) ([]byte, error) {
 	// Create Golang-accepted ciphertext
 	ciphertext := bytes.Join([][]byte{msg, tag}, nil)
 
-	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
 	if err != nil {
-		return nil, fmt.Errorf("cannot decrypt ciphertext: %v", err)
+		return nil, fmt.Errorf("%w: %v", ErrAuthenticationFailed, err)
 	}
 
 	return plaintext, nil
 }
+
+// symmCiphertextLength returns length of symmetrically encrypted message (nonce, tag and ciphertext) of n bytes
+func symmCiphertextLength(conf Config, n int) (int, error) {
+	keySize, err := symmKeySize(conf)
+	if err != nil {
+		return 0, err
+	}
+
+	aead, err := generateSymmCipher(make([]byte, keySize), conf)
+	if err != nil {
+		return 0, err
+	}
+
+	return aead.NonceSize() + sealedLength(aead, n), nil
+}
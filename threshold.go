@@ -0,0 +1,175 @@
+package eciesgo
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// KeyShare is a Shamir share of private scalar over the curve order field, used for threshold decryption;
+// unlike SplitKey shares, they are never combined into the private key, only their decapsulations are
+type KeyShare struct {
+	Index int
+	D     *big.Int
+}
+
+// PartialDecapsulation is a key share holder contribution to decapsulation of a ciphertext:
+// the ephemeral public key multiplied by the share scalar
+type PartialDecapsulation struct {
+	Index     int
+	Ephemeral *PublicKey
+	Point     *PublicKey
+}
+
+// SplitKeyThreshold splits private key into Shamir shares over the curve order field,
+// any threshold of share holders can decrypt together with PartialDecapsulate and CombineDecapsulations.
+// The dealer must erase the private key after distributing shares
+func SplitKeyThreshold(privkey *PrivateKey, threshold, shares int) ([]*KeyShare, error) {
+	if err := privkey.Validate(); err != nil {
+		return nil, err
+	}
+	if threshold < 2 || threshold > shares || shares > 255 {
+		return nil, fmt.Errorf("invalid threshold %d of %d shares", threshold, shares)
+	}
+
+	n := privkey.Curve.Params().N
+
+	coefficients := make([]*big.Int, threshold-1)
+	for i := range coefficients {
+		c, err := randScalar(randReader, n)
+		if err != nil {
+			return nil, err
+		}
+		coefficients[i] = c
+	}
+	defer func() {
+		for _, c := range coefficients {
+			zeroBigInt(c)
+		}
+	}()
+
+	result := make([]*KeyShare, shares)
+	for i := range result {
+		x := big.NewInt(int64(i + 1))
+
+		// Horner's method from the highest coefficient
+		y := new(big.Int)
+		for k := len(coefficients) - 1; k >= 0; k-- {
+			y.Mul(y, x)
+			y.Add(y, coefficients[k])
+			y.Mod(y, n)
+		}
+		y.Mul(y, x)
+		y.Add(y, privkey.D)
+		y.Mod(y, n)
+
+		result[i] = &KeyShare{Index: i + 1, D: y}
+	}
+
+	return result, nil
+}
+
+// PartialDecapsulate multiplies ephemeral public key of a ciphertext (see EphemeralPublicKey) by the share scalar
+func (s *KeyShare) PartialDecapsulate(ephemeral *PublicKey) (*PartialDecapsulation, error) {
+	if s == nil || s.D == nil || s.Index < 1 || s.Index > 255 {
+		return nil, fmt.Errorf("%w: key share is empty", ErrInvalidPrivateKey)
+	}
+	if err := ephemeral.Validate(); err != nil {
+		return nil, err
+	}
+
+	n := ephemeral.Curve.Params().N
+	if s.D.Sign() <= 0 || s.D.Cmp(n) >= 0 {
+		return nil, fmt.Errorf("%w: key share scalar is out of range", ErrInvalidPrivateKey)
+	}
+
+	x, y := scalarMult(ephemeral.Curve, ephemeral.X, ephemeral.Y, zeroPad(s.D.Bytes(), len(n.Bytes())))
+	point, err := ephemeral.newPoint(x, y)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PartialDecapsulation{Index: s.Index, Ephemeral: ephemeral, Point: point}, nil
+}
+
+// CombineDecapsulations interpolates partial decapsulations of at least threshold share holders
+// and returns Decapsulator which can be passed to DecryptWith; invalid partials make decryption fail
+func CombineDecapsulations(parts []*PartialDecapsulation) (Decapsulator, error) {
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("at least 2 partial decapsulations are required")
+	}
+
+	seen := make(map[int]bool, len(parts))
+	for i, p := range parts {
+		if p == nil || p.Index < 1 || p.Index > 255 || seen[p.Index] {
+			return nil, fmt.Errorf("invalid or duplicate index of partial decapsulation %d", i)
+		}
+		seen[p.Index] = true
+
+		if err := p.Ephemeral.Validate(); err != nil {
+			return nil, err
+		}
+		if err := p.Point.Validate(); err != nil {
+			return nil, err
+		}
+		if !p.Ephemeral.Equals(parts[0].Ephemeral) {
+			return nil, fmt.Errorf("partial decapsulation %d is made for another ciphertext", i)
+		}
+	}
+	ephemeral := parts[0].Ephemeral
+
+	curve := ephemeral.Curve
+	n := curve.Params().N
+
+	// Lagrange interpolation at zero in the exponent: S = sum of l_i P_i, l_i = prod x_j / (x_j - x_i)
+	var sx, sy *big.Int
+	for i, pi := range parts {
+		l := big.NewInt(1)
+		for j, pj := range parts {
+			if i == j {
+				continue
+			}
+			d := big.NewInt(int64(pj.Index - pi.Index))
+			d.Mod(d, n)
+			l.Mul(l, big.NewInt(int64(pj.Index)))
+			l.Mul(l, d.ModInverse(d, n))
+			l.Mod(l, n)
+		}
+
+		x, y := curve.ScalarMult(pi.Point.X, pi.Point.Y, zeroPad(l.Bytes(), len(n.Bytes())))
+		if sx == nil {
+			sx, sy = x, y
+		} else {
+			sx, sy = curve.Add(sx, sy, x, y)
+		}
+	}
+
+	shared, err := ephemeral.newPoint(sx, sy)
+	if err != nil {
+		return nil, fmt.Errorf("%w: combined shared point is at infinity", ErrInvalidPublicKey)
+	}
+
+	return DecapsulatorFunc(func(pub *PublicKey) (x, y *big.Int, err error) {
+		if !pub.Equals(ephemeral) {
+			return nil, nil, fmt.Errorf("partial decapsulations are made for another ciphertext")
+		}
+
+		return new(big.Int).Set(shared.X), new(big.Int).Set(shared.Y), nil
+	}), nil
+}
+
+// EphemeralPublicKey returns sender ephemeral public key of a ciphertext produced by Encrypt with the passed config,
+// share holders decapsulate it with KeyShare.PartialDecapsulate
+func EphemeralPublicKey(msg []byte, config Config) (*PublicKey, error) {
+	msg, config, err := openEnvelope(msg, config)
+	if err != nil {
+		return nil, err
+	}
+	if config.hpke != nil {
+		return nil, fmt.Errorf("ephemeral public key is not available in HPKE mode")
+	}
+	if len(msg) < 1+32+32 {
+		return nil, newParseError(ErrCiphertextTooShort, "ciphertext", len(msg), "ephemeral public key is truncated")
+	}
+
+	return NewPublicKeyFromBytes(msg[:65])
+}
@@ -0,0 +1,97 @@
+package eciesgo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThresholdDecryption(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	shares, err := SplitKeyThreshold(privkey, 3, 5)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, conf := range []Config{DEFAULT_CONFIG, NewConfig("xchacha20", 0).WithEnvelope()} {
+		ct, err := EncryptConf(privkey.PublicKey, []byte(testingMessage), conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		ephemeral, err := EphemeralPublicKey(ct, conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		var parts []*PartialDecapsulation
+		for _, share := range []*KeyShare{shares[4], shares[1], shares[2]} {
+			part, err := share.PartialDecapsulate(ephemeral)
+			if !assert.NoError(t, err) {
+				return
+			}
+			parts = append(parts, part)
+		}
+
+		d, err := CombineDecapsulations(parts)
+		if !assert.NoError(t, err) {
+			return
+		}
+		pt, err := DecryptWithConf(d, ct, conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(pt))
+
+		// Fewer partials than threshold do not decrypt
+		d, err = CombineDecapsulations(parts[:2])
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, err = DecryptWithConf(d, ct, conf)
+		assert.True(t, errors.Is(err, ErrAuthenticationFailed), "%v", err)
+	}
+
+	// Partials of another ciphertext are rejected
+	ct, err := Encrypt(privkey.PublicKey, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+	ct2, err := Encrypt(privkey.PublicKey, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+	ephemeral, _ := EphemeralPublicKey(ct, DEFAULT_CONFIG)
+	ephemeral2, _ := EphemeralPublicKey(ct2, DEFAULT_CONFIG)
+
+	p1, _ := shares[0].PartialDecapsulate(ephemeral)
+	p2, _ := shares[1].PartialDecapsulate(ephemeral)
+	p3, _ := shares[2].PartialDecapsulate(ephemeral2)
+	_, err = CombineDecapsulations([]*PartialDecapsulation{p1, p2, p3})
+	assert.Error(t, err)
+	_, err = CombineDecapsulations([]*PartialDecapsulation{p1, p1, p2})
+	assert.Error(t, err)
+	_, err = CombineDecapsulations([]*PartialDecapsulation{p1, nil})
+	assert.Error(t, err)
+
+	d, err := CombineDecapsulations([]*PartialDecapsulation{p1, p2})
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = DecryptWith(d, ct2)
+	assert.Error(t, err)
+
+	_, err = SplitKeyThreshold(privkey, 1, 3)
+	assert.Error(t, err)
+	_, err = (&KeyShare{}).PartialDecapsulate(ephemeral)
+	assert.Error(t, err)
+	_, err = EphemeralPublicKey(ct[:64], DEFAULT_CONFIG)
+	assert.Error(t, err)
+}
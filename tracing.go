@@ -0,0 +1,52 @@
+package eciesgo
+
+import (
+	"context"
+)
+
+// Tracer starts spans around operations called with context: EncryptContext, DecryptContext,
+// DecryptWithContext and GenerateKeyContext. It is the seam for distributed tracing systems,
+// e.g. OpenTelemetry tracer is adapted in a few lines (see README), so the package does not depend on them
+type Tracer interface {
+	// Start starts span with the given name ("ecies.Encrypt", "ecies.Decrypt" or "ecies.GenerateKey")
+	// as a child of span in ctx; returned context is passed down, e.g. to ContextDecapsulator
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a started span of a single operation
+type Span interface {
+	// End finishes the span; op describes the finished operation, so its fields may be set as span attributes
+	// and its error as span status
+	End(op Operation)
+}
+
+// WithTracer returns copy of config starting spans with the tracer, nil disables tracing
+func (c Config) WithTracer(t Tracer) Config {
+	c.tracer = t
+	return c
+}
+
+// trace runs f in a span of the config tracer; the config passed to f captures operation reported
+// to the observer for the span, besides reporting it to the config observer
+func (c Config) trace(ctx context.Context, name, opType string, f func(ctx context.Context, c Config) error) error {
+	if c.tracer == nil {
+		return f(ctx, c)
+	}
+
+	ctx, span := c.tracer.Start(ctx, name)
+
+	op := Operation{Type: opType}
+	observer := c.observer
+	c.observer = ObserverFunc(func(o Operation) {
+		op = o
+		if observer != nil {
+			observer.Observe(o)
+		}
+	})
+
+	err := f(ctx, c)
+	op.Err = err
+	span.End(op)
+
+	return err
+}
@@ -0,0 +1,106 @@
+package eciesgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type spanKey struct{}
+
+// testTracer records ended spans, span name is stored in context
+type testTracer struct {
+	spans []testSpan
+}
+
+type testSpan struct {
+	tracer *testTracer
+	name   string
+	parent interface{}
+	op     Operation
+}
+
+func (t *testTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	s := &testSpan{tracer: t, name: name, parent: ctx.Value(spanKey{})}
+	return context.WithValue(ctx, spanKey{}, name), s
+}
+
+func (s *testSpan) End(op Operation) {
+	s.op = op
+	s.tracer.spans = append(s.tracer.spans, *s)
+}
+
+func TestTracer(t *testing.T) {
+	tracer := &testTracer{}
+	var observed []Operation
+	config := DEFAULT_CONFIG.WithCurve("P-256").WithTracer(tracer).WithObserver(ObserverFunc(func(op Operation) {
+		observed = append(observed, op)
+	}))
+	ctx := context.WithValue(context.Background(), contextKey{}, "value")
+	ctx = context.WithValue(ctx, spanKey{}, "request")
+
+	privkey, err := GenerateKeyContext(ctx, config)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ct, err := EncryptContext(ctx, privkey.PublicKey, []byte(testingMessage), config)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	d := DecapsulatorFunc(privkey.SharedPoint)
+	_, err = DecryptWithContext(ctx, testContextDecapsulator{privkey, t}, ct, config)
+	assert.NoError(t, err)
+	ct[len(ct)-1] ^= 1
+	_, err = DecryptWithContext(ctx, d, ct, config)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+
+	if !assert.Len(t, tracer.spans, 4) {
+		return
+	}
+	assert.Equal(t, "ecies.GenerateKey", tracer.spans[0].name)
+	assert.Equal(t, Operation{Type: OperationGenerateKey, Curve: "P-256"}, Operation{
+		Type:  tracer.spans[0].op.Type,
+		Curve: tracer.spans[0].op.Curve,
+	})
+	assert.Equal(t, "ecies.Encrypt", tracer.spans[1].name)
+	assert.Equal(t, OperationEncrypt, tracer.spans[1].op.Type)
+	assert.Equal(t, len(testingMessage), tracer.spans[1].op.Size)
+	assert.Equal(t, "aes-256-gcm", tracer.spans[1].op.Cipher)
+	assert.Equal(t, "ecies.Decrypt", tracer.spans[2].name)
+	assert.NoError(t, tracer.spans[2].op.Err)
+	assert.True(t, errors.Is(tracer.spans[3].op.Err, ErrAuthenticationFailed))
+	for _, s := range tracer.spans {
+		assert.Equal(t, "request", s.parent)
+	}
+
+	// Observer still receives operations
+	assert.Len(t, observed, 4)
+	assert.Equal(t, "", observed[0].Cipher)
+
+	// Cancelled context ends span with its error
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = DecryptContext(cancelled, privkey, ct, config)
+	assert.True(t, errors.Is(err, context.Canceled), err)
+	if !assert.Len(t, tracer.spans, 5) {
+		return
+	}
+	assert.Equal(t, OperationDecrypt, tracer.spans[4].op.Type)
+	assert.True(t, errors.Is(tracer.spans[4].op.Err, context.Canceled))
+}
+
+func TestGenerateKeyContext(t *testing.T) {
+	privkey, err := GenerateKeyContext(context.Background(), DEFAULT_CONFIG.WithCurve("P-384"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	name, err := CurveName(privkey.PublicKey.Curve)
+	assert.NoError(t, err)
+	assert.Equal(t, "P-384", name)
+
+	_, err = GenerateKeyContext(context.Background(), DEFAULT_CONFIG.WithCurve("unknown"))
+	assert.True(t, errors.Is(err, ErrUnsupportedCurve), err)
+}
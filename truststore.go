@@ -0,0 +1,170 @@
+package eciesgo
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TrustStore pins recipient public keys by name and fingerprint; set on config with WithTrustStore,
+// it makes encryption fail for keys which are not pinned, e.g. when a recipient key in service configuration
+// was replaced. It is safe for concurrent use
+type TrustStore struct {
+	mu     sync.RWMutex
+	keys   map[string]*PublicKey
+	byHash map[string]string
+}
+
+// NewTrustStore creates empty trust store
+func NewTrustStore() *TrustStore {
+	return &TrustStore{
+		keys:   make(map[string]*PublicKey),
+		byHash: make(map[string]string),
+	}
+}
+
+// Add pins the key under the name; the name cannot be reused for another key and the key cannot be pinned
+// under two names, adding the same pair again does nothing
+func (s *TrustStore) Add(name string, pub *PublicKey) error {
+	if name == "" {
+		return fmt.Errorf("trusted key name is empty")
+	}
+	if err := pub.Validate(); err != nil {
+		return fmt.Errorf("trusted key %s: %w", name, err)
+	}
+	fingerprint := pub.Fingerprint()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.keys[name]; ok {
+		if existing.Fingerprint() != fingerprint {
+			return fmt.Errorf("%w: %s is pinned to another key %s", ErrUntrustedKey, name, existing.Fingerprint())
+		}
+		return nil
+	}
+	if other, ok := s.byHash[fingerprint]; ok {
+		return fmt.Errorf("key %s is already pinned as %s", fingerprint, other)
+	}
+
+	s.keys[name] = pub
+	s.byHash[fingerprint] = name
+	return nil
+}
+
+// Get returns the key pinned under the name
+func (s *TrustStore) Get(name string) (*PublicKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pub, ok := s.keys[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: no key is pinned as %s", ErrUntrustedKey, name)
+	}
+
+	return pub, nil
+}
+
+// Lookup returns the key with the fingerprint (see PublicKey.Fingerprint) and its name
+func (s *TrustStore) Lookup(fingerprint string) (*PublicKey, string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	name, ok := s.byHash[strings.ToLower(fingerprint)]
+	if !ok {
+		return nil, "", fmt.Errorf("%w: key %s is not pinned", ErrUntrustedKey, fingerprint)
+	}
+
+	return s.keys[name], name, nil
+}
+
+// Verify returns name of the pinned key, or ErrUntrustedKey if the key is not pinned
+func (s *TrustStore) Verify(pub *PublicKey) (string, error) {
+	if pub == nil || pub.X == nil || pub.Y == nil {
+		return "", fmt.Errorf("%w: public key is empty", ErrInvalidPublicKey)
+	}
+
+	_, name, err := s.Lookup(pub.Fingerprint())
+	return name, err
+}
+
+// Names returns sorted names of pinned keys
+func (s *TrustStore) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.keys))
+	for name := range s.keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// LoadFile pins key from the file under the file name without extension; the file holds hex encoded key
+// (as of PublicKey.Hex) or JWK (see NewPublicKeyFromJWK)
+func (s *TrustStore) LoadFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read trusted key: %w", err)
+	}
+
+	pub, err := parseTrustedKey(data)
+	if err != nil {
+		return fmt.Errorf("trusted key %s: %w", path, err)
+	}
+
+	base := filepath.Base(path)
+	return s.Add(strings.TrimSuffix(base, filepath.Ext(base)), pub)
+}
+
+// LoadDir pins keys from regular files of the directory with LoadFile, hidden files are skipped
+func (s *TrustStore) LoadDir(dir string) error {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("cannot read trusted keys directory: %w", err)
+	}
+
+	for _, info := range infos {
+		if !info.Mode().IsRegular() || strings.HasPrefix(info.Name(), ".") {
+			continue
+		}
+		if err := s.LoadFile(filepath.Join(dir, info.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseTrustedKey parses JWK or hex encoded key
+func parseTrustedKey(data []byte) (*PublicKey, error) {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("{")) {
+		return NewPublicKeyFromJWK(data)
+	}
+
+	return NewPublicKeyFromHex(string(data))
+}
+
+// WithTrustStore returns copy of config which Encrypt family, sessions, encrypt writers and Encapsulate reject
+// receiver keys not pinned in the store with ErrUntrustedKey; nil disables the check
+func (c Config) WithTrustStore(s *TrustStore) Config {
+	c.trustStore = s
+	return c
+}
+
+// checkTrusted checks that the receiver key is pinned in the config trust store
+func checkTrusted(config Config, pub *PublicKey) error {
+	if config.trustStore == nil {
+		return nil
+	}
+
+	_, err := config.trustStore.Verify(pub)
+	return err
+}
@@ -0,0 +1,155 @@
+package eciesgo
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrustStore(t *testing.T) {
+	skipUnapproved(t)
+
+	alice, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	bob, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	mallory, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	store := NewTrustStore()
+	if !assert.NoError(t, store.Add("alice", alice.PublicKey)) {
+		return
+	}
+	assert.NoError(t, store.Add("alice", alice.PublicKey))
+	err = store.Add("alice", mallory.PublicKey)
+	assert.True(t, errors.Is(err, ErrUntrustedKey), err)
+	assert.Error(t, store.Add("alice2", alice.PublicKey))
+	assert.Error(t, store.Add("", bob.PublicKey))
+
+	pub, err := store.Get("alice")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, pub.Equals(alice.PublicKey))
+	_, err = store.Get("bob")
+	assert.True(t, errors.Is(err, ErrUntrustedKey), err)
+
+	pub, name, err := store.Lookup(alice.Fingerprint())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "alice", name)
+	assert.True(t, pub.Equals(alice.PublicKey))
+
+	name, err = store.Verify(alice.PublicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", name)
+	_, err = store.Verify(mallory.PublicKey)
+	assert.True(t, errors.Is(err, ErrUntrustedKey), err)
+
+	// Encryption to keys which are not pinned fails
+	config := DEFAULT_CONFIG.WithTrustStore(store)
+	ct, err := EncryptConf(alice.PublicKey, []byte(testingMessage), config)
+	if !assert.NoError(t, err) {
+		return
+	}
+	pt, err := DecryptConf(alice, ct, config)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(pt))
+
+	_, err = EncryptConf(mallory.PublicKey, []byte(testingMessage), config)
+	assert.True(t, errors.Is(err, ErrUntrustedKey), err)
+	_, err = EncryptWithEphemeralConf(bob, mallory.PublicKey, []byte(testingMessage), config)
+	assert.True(t, errors.Is(err, ErrUntrustedKey), err)
+	_, err = EncryptMultiConf([]*PublicKey{alice.PublicKey, mallory.PublicKey}, []byte(testingMessage), config)
+	assert.True(t, errors.Is(err, ErrUntrustedKey), err)
+
+	// Sessions, streams, files, KEM and re-encryptable messages check the receiver as well
+	_, err = NewSessionConf(alice.PublicKey, config)
+	assert.NoError(t, err)
+	_, err = NewSessionConf(mallory.PublicKey, config)
+	assert.True(t, errors.Is(err, ErrUntrustedKey), err)
+
+	var stream bytes.Buffer
+	_, err = NewEncryptWriterConf(&stream, alice.PublicKey, config)
+	assert.NoError(t, err)
+	_, err = NewEncryptWriterConf(&stream, mallory.PublicKey, config)
+	assert.True(t, errors.Is(err, ErrUntrustedKey), err)
+
+	dir, err := ioutil.TempDir("", "ecies")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "plain")
+	if !assert.NoError(t, ioutil.WriteFile(src, []byte(testingMessage), 0600)) {
+		return
+	}
+	err = EncryptFileConf(mallory.PublicKey, src, filepath.Join(dir, "encrypted"), config)
+	assert.True(t, errors.Is(err, ErrUntrustedKey), err)
+	_, err = os.Stat(filepath.Join(dir, "encrypted"))
+	assert.True(t, os.IsNotExist(err), err)
+
+	_, _, err = EncapsulateConf(mallory.PublicKey, config)
+	assert.True(t, errors.Is(err, ErrUntrustedKey), err)
+	_, _, err = EncapsulateKeys(mallory.PublicKey, config, 16, 16)
+	assert.True(t, errors.Is(err, ErrUntrustedKey), err)
+	_, err = EncryptReencryptableConf(mallory.PublicKey, []byte(testingMessage), config)
+	assert.True(t, errors.Is(err, ErrUntrustedKey), err)
+}
+
+func TestTrustStore_LoadDir(t *testing.T) {
+	skipUnapproved(t)
+
+	alice, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+	bob, err := GenerateKeyCurve(BrainpoolP256r1())
+	if !assert.NoError(t, err) {
+		return
+	}
+	bobJWK, err := bob.PublicKey.JWK()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	dir, err := ioutil.TempDir("", "ecies-trust")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "alice.pub"), []byte(alice.PublicKey.Hex(true)+"\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "bob.jwk"), bobJWK, 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, ".hidden"), []byte("garbage"), 0644))
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0755))
+
+	store := NewTrustStore()
+	if !assert.NoError(t, store.LoadDir(dir)) {
+		return
+	}
+	assert.Equal(t, []string{"alice", "bob"}, store.Names())
+	pub, err := store.Get("bob")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, pub.Equals(bob.PublicKey))
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "broken"), []byte("garbage"), 0644))
+	assert.Error(t, NewTrustStore().LoadDir(dir))
+	assert.Error(t, store.LoadFile(filepath.Join(dir, "missing")))
+}
@@ -1,23 +1,124 @@
 package eciesgo
 
 import (
+	"bytes"
+	"crypto/elliptic"
 	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
 	"fmt"
+	"hash"
 	"io"
+	"math/big"
 
+	"golang.org/x/crypto/blake2b"
 	"golang.org/x/crypto/hkdf"
 )
 
-func kdf(secret []byte) (key []byte, err error) {
-	key = make([]byte, 32)
-	kdf := hkdf.New(sha256.New, secret, nil, nil)
-	if _, err := io.ReadFull(kdf, key); err != nil {
-		return nil, fmt.Errorf("cannot read secret from HKDF reader: %w", err)
+func kdf(secret []byte, conf Config) (key []byte, err error) {
+	if err := checkFIPSConfig(conf); err != nil {
+		return nil, err
+	}
+
+	h, err := kdfHash(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	keySize := conf.kdfLength
+	if keySize == 0 {
+		if keySize, err = symmKeySize(conf); err != nil {
+			return nil, err
+		}
+	}
+
+	key = make([]byte, keySize)
+
+	switch conf.kdfFunction {
+	case "", "hkdf":
+		kdf := hkdf.New(h, secret, conf.kdfSalt, conf.kdfInfo)
+		if _, err := io.ReadFull(kdf, key); err != nil {
+			return nil, fmt.Errorf("cannot read secret from HKDF reader: %w", err)
+		}
+	case "x963":
+		x963KDF(h, secret, conf.kdfInfo, key)
+	case "scrypt", "argon2id":
+		if key, err = derivePasswordKey(secret, conf.kdfSalt, passwordKDFParams(conf), keySize); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedKDF, conf.kdfFunction)
 	}
 
 	return key, nil
 }
 
+// passwordKDFParams returns memory-hard KDF parameters set with WithPasswordKDF, or defaults of the configured KDF
+func passwordKDFParams(conf Config) PasswordKDFParams {
+	switch {
+	case conf.passwordKDF != nil && conf.passwordKDF.Algorithm == conf.kdfFunction:
+		return *conf.passwordKDF
+	case conf.kdfFunction == "scrypt":
+		return DEFAULT_SCRYPT_PARAMS
+	default:
+		return DEFAULT_ARGON2ID_PARAMS
+	}
+}
+
+// x963KDF fills key with ANSI X9.63 KDF output: Hash(Z || counter || SharedInfo) blocks,
+// 4 bytes big endian counter starts from 1
+func x963KDF(h func() hash.Hash, z, sharedInfo, key []byte) {
+	var counter [4]byte
+	for i, out := uint32(1), key; len(out) > 0; i++ {
+		binary.BigEndian.PutUint32(counter[:], i)
+
+		hh := h()
+		hh.Write(z)
+		hh.Write(counter[:])
+		hh.Write(sharedInfo)
+		out = out[copy(out, hh.Sum(nil)):]
+	}
+}
+
+func kdfHash(conf Config) (func() hash.Hash, error) {
+	switch conf.kdfHash {
+	case "", "sha256":
+		return sha256.New, nil
+	case "sha512":
+		return sha512.New, nil
+	case "blake2b":
+		return func() hash.Hash {
+			h, _ := blake2b.New512(nil)
+			return h
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w hash: %s", ErrUnsupportedKDF, conf.kdfHash)
+	}
+}
+
+// sameCurve reports whether both curves have the same domain parameters
+func sameCurve(a, b elliptic.Curve) bool {
+	if a == b {
+		return true
+	}
+
+	pa, pb := a.Params(), b.Params()
+	return pa.P.Cmp(pb.P) == 0 && pa.N.Cmp(pb.N) == 0 && pa.B.Cmp(pb.B) == 0 &&
+		pa.Gx.Cmp(pb.Gx) == 0 && pa.Gy.Cmp(pb.Gy) == 0
+}
+
+// fixedBytes returns big endian encoding of non-negative x left-padded to length bytes;
+// reports false (and returns zeros) if x does not fit
+func fixedBytes(x *big.Int, length int) ([]byte, bool) {
+	b := make([]byte, length)
+	if x.Sign() < 0 || x.BitLen() > 8*length {
+		return b, false
+	}
+
+	putBytes(b, x.Bytes())
+	return b, true
+}
+
 func zeroPad(b []byte, length int) []byte {
 	if len(b) > length {
 		panic("bytes too long")
@@ -27,3 +128,46 @@ func zeroPad(b []byte, length int) []byte {
 	}
 	return b
 }
+
+// putBytes writes big endian number b to dst left-padding it with zeros
+func putBytes(dst []byte, b []byte) {
+	if len(b) > len(dst) {
+		panic("bytes too long")
+	}
+	for i := range dst[:len(dst)-len(b)] {
+		dst[i] = 0
+	}
+	copy(dst[len(dst)-len(b):], b)
+}
+
+// sliceForAppend extends in by n bytes reusing its capacity if possible;
+// returns whole resulting slice and its appended tail
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
+
+func pkcs7Pad(msg []byte, blockSize int) []byte {
+	padding := blockSize - len(msg)%blockSize
+	return append(append([]byte(nil), msg...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+}
+
+func pkcs7Unpad(msg []byte, blockSize int) ([]byte, error) {
+	padding := int(msg[len(msg)-1])
+	if padding == 0 || padding > blockSize || padding > len(msg) {
+		return nil, fmt.Errorf("%w: invalid padding", ErrAuthenticationFailed)
+	}
+	for _, b := range msg[len(msg)-padding:] {
+		if int(b) != padding {
+			return nil, fmt.Errorf("%w: invalid padding", ErrAuthenticationFailed)
+		}
+	}
+
+	return msg[:len(msg)-padding], nil
+}
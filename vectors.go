@@ -0,0 +1,203 @@
+package eciesgo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Version of the test vector file format
+const vectorsVersion = 1
+
+// Vector is known-answer test vector: Ciphertext decrypted with PrivateKey under the described parameters
+// must give Plaintext, and if Random is set, encryption of Plaintext consuming Random as the source of randomness
+// (ephemeral key followed by nonce) must give exactly Ciphertext.
+// Binary fields are hex encoded
+type Vector struct {
+	Description string `json:"description,omitempty"`
+
+	// Curve is a registered curve name (see CurveByName), the bundled vectors are "secp256k1"
+	Curve string `json:"curve"`
+	// Cipher and NonceLength are NewConfig arguments
+	Cipher      string `json:"cipher"`
+	NonceLength int    `json:"nonce_length"`
+	// KDF ("hkdf" or "x963"), KDFHash, KDFSalt and KDFInfo are set with Config methods of the same name,
+	// KeyCommitment, SharedPointKDF and KeySeparation with WithKeyCommitment, WithSharedPointKDF and WithKeySeparation
+	KDF            string `json:"kdf"`
+	KDFHash        string `json:"kdf_hash"`
+	KDFSalt        string `json:"kdf_salt,omitempty"`
+	KDFInfo        string `json:"kdf_info,omitempty"`
+	KeyCommitment  bool   `json:"key_commitment,omitempty"`
+	SharedPointKDF bool   `json:"shared_point_kdf,omitempty"`
+	KeySeparation  bool   `json:"key_separation,omitempty"`
+
+	PrivateKey string `json:"private_key"`
+	Random     string `json:"random,omitempty"`
+	Plaintext  string `json:"plaintext"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// vectorFile is JSON layout of test vector file
+type vectorFile struct {
+	Version int      `json:"version"`
+	Vectors []Vector `json:"vectors"`
+}
+
+// ReadVectors reads test vector file written by WriteVectors
+func ReadVectors(r io.Reader) ([]Vector, error) {
+	var f vectorFile
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("cannot decode test vectors: %w", err)
+	}
+	if f.Version != vectorsVersion {
+		return nil, fmt.Errorf("unsupported test vectors version: %d", f.Version)
+	}
+
+	return f.Vectors, nil
+}
+
+// WriteVectors writes test vectors as indented JSON
+func WriteVectors(w io.Writer, vectors []Vector) error {
+	b, err := json.MarshalIndent(vectorFile{Version: vectorsVersion, Vectors: vectors}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// VerifyVectors reads test vector file and verifies every vector in it against this implementation;
+// the returned error names the first failed vector
+func VerifyVectors(r io.Reader) error {
+	vectors, err := ReadVectors(r)
+	if err != nil {
+		return err
+	}
+
+	for i := range vectors {
+		if err := vectors[i].Verify(); err != nil {
+			return fmt.Errorf("vector %d (%s): %w", i, vectors[i].Description, err)
+		}
+	}
+
+	return nil
+}
+
+// Config returns config described by the vector
+func (v *Vector) Config() (Config, error) {
+	if _, err := CurveByName(v.Curve); err != nil {
+		return Config{}, err
+	}
+
+	config := NewConfig(v.Cipher, v.NonceLength).WithKDF(v.KDF).WithKDFHash(v.KDFHash).WithCurve(v.Curve)
+	if v.KDFSalt != "" {
+		salt, err := hex.DecodeString(v.KDFSalt)
+		if err != nil {
+			return Config{}, fmt.Errorf("cannot decode KDF salt: %w", err)
+		}
+		config = config.WithKDFSalt(salt)
+	}
+	if v.KDFInfo != "" {
+		info, err := hex.DecodeString(v.KDFInfo)
+		if err != nil {
+			return Config{}, fmt.Errorf("cannot decode KDF info: %w", err)
+		}
+		config = config.WithKDFInfo(info)
+	}
+	if v.KeyCommitment {
+		config = config.WithKeyCommitment()
+	}
+	if v.SharedPointKDF {
+		config = config.WithSharedPointKDF()
+	}
+	if v.KeySeparation {
+		config = config.WithKeySeparation()
+	}
+
+	return config, nil
+}
+
+// Generate fills Ciphertext by encrypting Plaintext with the public key of PrivateKey, consuming Random
+func (v *Vector) Generate() error {
+	config, priv, random, plaintext, err := v.decode()
+	if err != nil {
+		return err
+	}
+	if random == nil {
+		return fmt.Errorf("random is required to generate test vector")
+	}
+
+	ct, err := EncryptConf(priv.PublicKey, plaintext, config.WithRand(bytes.NewReader(random)))
+	if err != nil {
+		return err
+	}
+	v.Ciphertext = hex.EncodeToString(ct)
+
+	return nil
+}
+
+// Verify decrypts Ciphertext and compares it with Plaintext, then reproduces Ciphertext if Random is set
+func (v *Vector) Verify() error {
+	config, priv, random, plaintext, err := v.decode()
+	if err != nil {
+		return err
+	}
+	ct, err := hex.DecodeString(v.Ciphertext)
+	if err != nil {
+		return fmt.Errorf("cannot decode ciphertext: %w", err)
+	}
+
+	pt, err := DecryptConf(priv, ct, config)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(pt, plaintext) {
+		return fmt.Errorf("decrypted plaintext mismatch")
+	}
+
+	if random == nil {
+		return nil
+	}
+
+	generated, err := EncryptConf(priv.PublicKey, plaintext, config.WithRand(bytes.NewReader(random)))
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(generated, ct) {
+		return fmt.Errorf("encrypted ciphertext mismatch")
+	}
+
+	return nil
+}
+
+func (v *Vector) decode() (config Config, priv *PrivateKey, random, plaintext []byte, err error) {
+	if config, err = v.Config(); err != nil {
+		return
+	}
+	curve, err := CurveByName(v.Curve)
+	if err != nil {
+		return
+	}
+	b, err := hex.DecodeString(v.PrivateKey)
+	if err != nil {
+		err = fmt.Errorf("cannot decode private key: %w", err)
+		return
+	}
+	if priv, err = NewPrivateKeyFromBytesCurve(curve, b); err != nil {
+		return
+	}
+	if v.Random != "" {
+		if random, err = hex.DecodeString(v.Random); err != nil {
+			err = fmt.Errorf("cannot decode random: %w", err)
+			return
+		}
+	}
+	if plaintext, err = hex.DecodeString(v.Plaintext); err != nil {
+		err = fmt.Errorf("cannot decode plaintext: %w", err)
+	}
+
+	return
+}
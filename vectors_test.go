@@ -0,0 +1,182 @@
+package eciesgo
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var updateVectors = flag.Bool("update-vectors", false, "regenerate testdata/vectors.json")
+
+var testingVectorsPath = filepath.Join("testdata", "vectors.json")
+
+// generateTestingVectors produces vectors for every cipher and KDF combination from fixed key and randomness
+func generateTestingVectors() ([]Vector, error) {
+	ciphers := []struct {
+		cipher      string
+		nonceLength int
+	}{
+		{"aes-256-gcm", 16},
+		{"aes-256-gcm", 12},
+		{"aes-192-gcm", 12},
+		{"aes-128-gcm", 12},
+		{"xchacha20", 24},
+		{"chacha20poly1305", 12},
+		{"aes-256-cbc-hmac-sha256", 16},
+	}
+	kdfs := []struct {
+		kdf, hash string
+	}{
+		{"hkdf", "sha256"},
+		{"hkdf", "sha512"},
+		{"hkdf", "blake2b"},
+		{"x963", "sha256"},
+		{"x963", "sha512"},
+	}
+
+	var vectors []Vector
+	for _, c := range ciphers {
+		for _, k := range kdfs {
+			vectors = append(vectors, Vector{
+				Description: strings.Join([]string{c.cipher, k.kdf, k.hash}, "/"),
+				Cipher:      c.cipher,
+				NonceLength: c.nonceLength,
+				KDF:         k.kdf,
+				KDFHash:     k.hash,
+			})
+		}
+	}
+	vectors = append(vectors, Vector{
+		Description:   "aes-256-gcm/hkdf/sha256 with salt, info and key commitment",
+		Cipher:        "aes-256-gcm",
+		NonceLength:   16,
+		KDF:           "hkdf",
+		KDFHash:       "sha256",
+		KDFSalt:       hex.EncodeToString([]byte("salt")),
+		KDFInfo:       hex.EncodeToString([]byte("info")),
+		KeyCommitment: true,
+	}, Vector{
+		Description:    "aes-256-gcm/hkdf/sha256 with shared point KDF",
+		Cipher:         "aes-256-gcm",
+		NonceLength:    16,
+		KDF:            "hkdf",
+		KDFHash:        "sha256",
+		SharedPointKDF: true,
+	}, Vector{
+		Description:   "aes-256-cbc-hmac-sha256/hkdf/sha256 with key separation and key commitment",
+		Cipher:        "aes-256-cbc-hmac-sha256",
+		NonceLength:   16,
+		KDF:           "hkdf",
+		KDFHash:       "sha256",
+		KeyCommitment: true,
+		KeySeparation: true,
+	})
+
+	for i := range vectors {
+		v := &vectors[i]
+		seed := sha512.Sum512([]byte(v.Description))
+		random := sha512.Sum512(seed[:])
+
+		v.Curve = "secp256k1"
+		v.PrivateKey = hex.EncodeToString(seed[:32])
+		v.Random = hex.EncodeToString(random[:])
+		v.Plaintext = hex.EncodeToString([]byte("known answer test: " + v.Description))
+		if err := v.Generate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return vectors, nil
+}
+
+func TestVerifyVectors(t *testing.T) {
+	skipUnapproved(t)
+
+	if *updateVectors {
+		vectors, err := generateTestingVectors()
+		if !assert.NoError(t, err) {
+			return
+		}
+		var buf bytes.Buffer
+		if !assert.NoError(t, WriteVectors(&buf, vectors)) {
+			return
+		}
+		if !assert.NoError(t, ioutil.WriteFile(testingVectorsPath, buf.Bytes(), 0644)) {
+			return
+		}
+	}
+
+	f, err := os.Open(testingVectorsPath)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+	assert.NoError(t, VerifyVectors(f))
+
+	// Shipped vectors match the generator
+	vectors, err := generateTestingVectors()
+	if !assert.NoError(t, err) {
+		return
+	}
+	b, err := ioutil.ReadFile(testingVectorsPath)
+	if !assert.NoError(t, err) {
+		return
+	}
+	shipped, err := ReadVectors(bytes.NewReader(b))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, vectors, shipped)
+}
+
+func TestVector_Verify(t *testing.T) {
+	skipUnapproved(t)
+
+	vectors, err := generateTestingVectors()
+	if !assert.NoError(t, err) {
+		return
+	}
+	v := vectors[0]
+	assert.NoError(t, v.Verify())
+
+	// Vector without randomness is verified by decryption only
+	noRandom := v
+	noRandom.Random = ""
+	assert.NoError(t, noRandom.Verify())
+	assert.Error(t, noRandom.Generate())
+
+	tampered := v
+	tampered.Ciphertext = tampered.Ciphertext[:len(tampered.Ciphertext)-2] + "00"
+	assert.Error(t, tampered.Verify())
+
+	wrongPlaintext := v
+	wrongPlaintext.Plaintext = "00"
+	assert.Error(t, wrongPlaintext.Verify())
+
+	wrongRandom := v
+	wrongRandom.Random = strings.Repeat("01", 64)
+	assert.Error(t, wrongRandom.Verify())
+
+	wrongCurve := v
+	wrongCurve.Curve = "P-256"
+	assert.Error(t, wrongCurve.Verify())
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, WriteVectors(&buf, []Vector{v, tampered})) {
+		return
+	}
+	err = VerifyVectors(&buf)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "vector 1")
+	}
+
+	assert.Error(t, VerifyVectors(strings.NewReader(`{"version":2,"vectors":[]}`)))
+}
@@ -0,0 +1,61 @@
+package eciesgo
+
+import "fmt"
+
+// BitcoinNetwork selects WIF version byte
+type BitcoinNetwork byte
+
+const (
+	BitcoinMainnet BitcoinNetwork = 0x80
+	BitcoinTestnet BitcoinNetwork = 0xef
+)
+
+// WIF encodes private key in Bitcoin Wallet Import Format;
+// compressed marks that the key corresponds to compressed public key encoding
+func (k *PrivateKey) WIF(compressed bool, network BitcoinNetwork) string {
+	payload := append([]byte{byte(network)}, k.Bytes()...)
+	if compressed {
+		payload = append(payload, 0x01)
+	}
+
+	return base58CheckEncode(payload)
+}
+
+// ParseWIF decodes private key in Wallet Import Format, returns compressed public key flag and network
+func ParseWIF(wif string) (*PrivateKey, bool, BitcoinNetwork, error) {
+	b, err := base58CheckDecode(wif)
+	if err != nil {
+		return nil, false, 0, fmt.Errorf("cannot decode WIF: %w", err)
+	}
+
+	if len(b) == 0 {
+		return nil, false, 0, fmt.Errorf("%w: empty WIF payload", ErrInvalidPrivateKey)
+	}
+
+	network := BitcoinNetwork(b[0])
+	if network != BitcoinMainnet && network != BitcoinTestnet {
+		return nil, false, 0, fmt.Errorf("unknown WIF version: %#x", b[0])
+	}
+
+	var compressed bool
+	switch {
+	case len(b) == 1+32:
+	case len(b) == 1+32+1 && b[33] == 0x01:
+		compressed = true
+	default:
+		return nil, false, 0, fmt.Errorf("%w: invalid WIF payload length %d", ErrInvalidPrivateKey, len(b))
+	}
+
+	k, err := NewPrivateKeyFromBytes(b[1:33])
+	if err != nil {
+		return nil, false, 0, err
+	}
+
+	return k, compressed, network, nil
+}
+
+// NewPrivateKeyFromWIF decodes private key in Wallet Import Format of any supported network
+func NewPrivateKeyFromWIF(wif string) (*PrivateKey, error) {
+	k, _, _, err := ParseWIF(wif)
+	return k, err
+}
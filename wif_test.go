@@ -0,0 +1,64 @@
+package eciesgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testingWIFVectors = []struct {
+	privkey, wif string
+	compressed   bool
+	network      BitcoinNetwork
+}{
+	{
+		"0c28fca386c7a227600b2fe50b7cae11ec86d3bf1fbe471be89827e19d72aa1d",
+		"5HueCGU8rMjxEXxiPuD5BDku4MkFqeZyd4dZ1jvhTVqvbTLvyTJ",
+		false,
+		BitcoinMainnet,
+	},
+	{
+		"0c28fca386c7a227600b2fe50b7cae11ec86d3bf1fbe471be89827e19d72aa1d",
+		"KwdMAjGmerYanjeui5SHS7JkmpZvVipYvB2LJGU1ZxJwYvP98617",
+		true,
+		BitcoinMainnet,
+	},
+	{
+		"0000000000000000000000000000000000000000000000000000000000000001",
+		"cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87JcbXMTcA",
+		true,
+		BitcoinTestnet,
+	},
+}
+
+func TestWIF(t *testing.T) {
+	for _, v := range testingWIFVectors {
+		privkey, err := NewPrivateKeyFromHex(v.privkey)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, v.wif, privkey.WIF(v.compressed, v.network))
+
+		parsed, compressed, network, err := ParseWIF(v.wif)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.True(t, privkey.Equals(parsed))
+		assert.Equal(t, v.compressed, compressed)
+		assert.Equal(t, v.network, network)
+	}
+}
+
+func TestWIFErrors(t *testing.T) {
+	for _, wif := range []string{
+		"",
+		base58CheckEncode(nil),
+		"5HueCGU8rMjxEXxiPuD5BDku4MkFqeZyd4dZ1jvhTVqvbTLvyTK",
+		base58CheckEncode(append([]byte{0x00}, make([]byte, 32)...)),
+		base58CheckEncode(append([]byte{0x80}, testingReceiverPrivkey[:31]...)),
+		base58CheckEncode(append(append([]byte{0x80}, testingReceiverPrivkey...), 0x02)),
+	} {
+		_, err := NewPrivateKeyFromWIF(wif)
+		assert.Error(t, err, wif)
+	}
+}
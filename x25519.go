@@ -0,0 +1,211 @@
+package eciesgo
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+// X25519PublicKey is an instance of Curve25519 public key (Montgomery u-coordinate)
+type X25519PublicKey struct {
+	b []byte
+}
+
+// X25519PrivateKey is an instance of Curve25519 private key with nested public key
+type X25519PrivateKey struct {
+	*X25519PublicKey
+	d []byte
+}
+
+// GenerateX25519Key generates Curve25519 key pair
+func GenerateX25519Key() (*X25519PrivateKey, error) {
+	d := make([]byte, curve25519.ScalarSize)
+	if err := randomBytes(d); err != nil {
+		return nil, fmt.Errorf("cannot generate key pair: %w", err)
+	}
+
+	return NewX25519PrivateKeyFromBytes(d)
+}
+
+// NewX25519PrivateKeyFromBytes decodes 32 bytes private key, computes public key and returns X25519PrivateKey instance
+func NewX25519PrivateKeyFromBytes(priv []byte) (*X25519PrivateKey, error) {
+	if len(priv) != curve25519.ScalarSize {
+		return nil, fmt.Errorf("%w: length is %d, expected %d", ErrInvalidPrivateKey, len(priv), curve25519.ScalarSize)
+	}
+
+	pub, err := curve25519.X25519(priv, curve25519.Basepoint)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
+	}
+
+	return &X25519PrivateKey{
+		X25519PublicKey: &X25519PublicKey{b: pub},
+		d:               append([]byte(nil), priv...),
+	}, nil
+}
+
+// NewX25519PrivateKeyFromHex decodes hex form of private key raw bytes and returns X25519PrivateKey instance
+func NewX25519PrivateKeyFromHex(s string) (*X25519PrivateKey, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode hex string: %w", err)
+	}
+
+	return NewX25519PrivateKeyFromBytes(b)
+}
+
+// NewX25519PublicKeyFromBytes decodes 32 bytes public key and returns X25519PublicKey instance
+func NewX25519PublicKeyFromBytes(b []byte) (*X25519PublicKey, error) {
+	if len(b) != curve25519.PointSize {
+		return nil, fmt.Errorf("%w: length is %d, expected %d", ErrInvalidPublicKey, len(b), curve25519.PointSize)
+	}
+
+	return &X25519PublicKey{b: append([]byte(nil), b...)}, nil
+}
+
+// NewX25519PublicKeyFromHex decodes hex form of public key raw bytes and returns X25519PublicKey instance
+func NewX25519PublicKeyFromHex(s string) (*X25519PublicKey, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode hex string: %w", err)
+	}
+
+	return NewX25519PublicKeyFromBytes(b)
+}
+
+// Bytes returns public key raw bytes
+func (k *X25519PublicKey) Bytes() []byte {
+	return append([]byte(nil), k.b...)
+}
+
+// Hex returns public key bytes in hex form
+func (k *X25519PublicKey) Hex() string {
+	return hex.EncodeToString(k.b)
+}
+
+// Equals compares two public keys with constant time (to resist timing attacks)
+func (k *X25519PublicKey) Equals(pub *X25519PublicKey) bool {
+	return subtle.ConstantTimeCompare(k.b, pub.b) == 1
+}
+
+// Bytes returns private key raw bytes
+func (k *X25519PrivateKey) Bytes() []byte {
+	return append([]byte(nil), k.d...)
+}
+
+// Hex returns private key bytes in hex form
+func (k *X25519PrivateKey) Hex() string {
+	return hex.EncodeToString(k.d)
+}
+
+// Equals compares two private keys with constant time (to resist timing attacks)
+func (k *X25519PrivateKey) Equals(priv *X25519PrivateKey) bool {
+	return subtle.ConstantTimeCompare(k.d, priv.d) == 1
+}
+
+// ECDH derives raw X25519 shared secret; low order public keys are rejected.
+// Must not be used as encryption key, use Encapsulate instead
+func (k *X25519PrivateKey) ECDH(pub *X25519PublicKey) ([]byte, error) {
+	if pub == nil {
+		return nil, fmt.Errorf("%w: public key is empty", ErrInvalidPublicKey)
+	}
+
+	ss, err := curve25519.X25519(k.d, pub.b)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+
+	return ss, nil
+}
+
+// Encapsulate derives symmetric key with ephemeral private key k for the receiver public key
+func (k *X25519PrivateKey) Encapsulate(pub *X25519PublicKey) ([]byte, error) {
+	return k.encapsulate(pub, DefaultConfig())
+}
+
+func (k *X25519PrivateKey) encapsulate(pub *X25519PublicKey, conf Config) ([]byte, error) {
+	ss, err := k.ECDH(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	return kdf(append(k.X25519PublicKey.Bytes(), ss...), conf)
+}
+
+// Decapsulate derives symmetric key from ephemeral public key k with the receiver private key
+func (k *X25519PublicKey) Decapsulate(priv *X25519PrivateKey) ([]byte, error) {
+	return k.decapsulate(priv, DefaultConfig())
+}
+
+func (k *X25519PublicKey) decapsulate(priv *X25519PrivateKey, conf Config) ([]byte, error) {
+	if priv == nil {
+		return nil, fmt.Errorf("%w: private key is empty", ErrInvalidPrivateKey)
+	}
+
+	ss, err := priv.ECDH(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return kdf(append(k.Bytes(), ss...), conf)
+}
+
+// EncryptX25519Conf encrypts a passed message with a receiver X25519 public key;
+// ciphertext is ephemeral public key (32 bytes) followed by nonce, tag and encrypted message
+func EncryptX25519Conf(pubkey *X25519PublicKey, msg []byte, config Config) ([]byte, error) {
+	if err := checkFIPS("X25519"); err != nil {
+		return nil, err
+	}
+	if config.hpke != nil || config.envelope {
+		return nil, fmt.Errorf("X25519 mode does not support HPKE and envelope")
+	}
+
+	ek, err := GenerateX25519Key()
+	if err != nil {
+		return nil, err
+	}
+
+	ss, err := ek.encapsulate(pubkey, config)
+	if err != nil {
+		return nil, err
+	}
+
+	ct, err := EncryptSymm(ss, msg, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(ek.X25519PublicKey.Bytes(), ct...), nil
+}
+
+func EncryptX25519(pubkey *X25519PublicKey, msg []byte) ([]byte, error) {
+	return EncryptX25519Conf(pubkey, msg, DefaultConfig())
+}
+
+// DecryptX25519Conf decrypts a passed message with a receiver X25519 private key
+func DecryptX25519Conf(privkey *X25519PrivateKey, msg []byte, config Config) ([]byte, error) {
+	if err := checkFIPS("X25519"); err != nil {
+		return nil, err
+	}
+	if config.hpke != nil || config.envelope {
+		return nil, fmt.Errorf("X25519 mode does not support HPKE and envelope")
+	}
+
+	if len(msg) <= curve25519.PointSize {
+		return nil, ErrCiphertextTooShort
+	}
+
+	ephemeral := &X25519PublicKey{b: msg[:curve25519.PointSize]}
+	ss, err := ephemeral.decapsulate(privkey, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecryptSymm(ss, msg[curve25519.PointSize:], config)
+}
+
+func DecryptX25519(privkey *X25519PrivateKey, msg []byte) ([]byte, error) {
+	return DecryptX25519Conf(privkey, msg, DefaultConfig())
+}
@@ -0,0 +1,72 @@
+package eciesgo
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestX25519ECDH(t *testing.T) {
+	// RFC 7748, section 6.1
+	alice, err := NewX25519PrivateKeyFromHex("77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a")
+	if !assert.NoError(t, err) {
+		return
+	}
+	bob, err := NewX25519PrivateKeyFromHex("5dab087e624a8a4b79e17f8b83800ee66f3bb1292618b6fd1c2f8b27ff88e0eb")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a", alice.X25519PublicKey.Hex())
+	assert.Equal(t, "de9edb7d7b7dc1b4d35b61c2ece435373f8343c85b78674dadfc7e146f882b4f", bob.X25519PublicKey.Hex())
+
+	ss, err := alice.ECDH(bob.X25519PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742", hex.EncodeToString(ss))
+
+	// Low order point
+	lowOrder, err := NewX25519PublicKeyFromBytes(make([]byte, 32))
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = alice.ECDH(lowOrder)
+	assert.True(t, errors.Is(err, ErrInvalidPublicKey), err)
+}
+
+func TestEncryptX25519AndDecryptX25519(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateX25519Key()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, conf := range []Config{DEFAULT_CONFIG, NewConfig("xchacha20", 0), DEFAULT_CONFIG.WithKDFInfo([]byte("x25519"))} {
+		ciphertext, err := EncryptX25519Conf(privkey.X25519PublicKey, []byte(testingMessage), conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		plaintext, err := DecryptX25519Conf(privkey, ciphertext, conf)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, testingMessage, string(plaintext))
+	}
+
+	other, err := GenerateX25519Key()
+	if !assert.NoError(t, err) {
+		return
+	}
+	ciphertext, err := EncryptX25519(privkey.X25519PublicKey, []byte(testingMessage))
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = DecryptX25519(other, ciphertext)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed), err)
+	_, err = DecryptX25519(privkey, ciphertext[:32])
+	assert.True(t, errors.Is(err, ErrCiphertextTooShort), err)
+}
@@ -0,0 +1,40 @@
+package eciesgo
+
+import "math/big"
+
+// WithSecretWiping returns copy of config which makes Encrypt and Decrypt overwrite ephemeral private key,
+// shared point and derived symmetric keys with zeros once they are not needed
+func (c Config) WithSecretWiping() Config {
+	c.wipeSecrets = true
+	return c
+}
+
+// Zeroize overwrites private scalar with zeros, the key must not be used afterwards;
+// Go runtime may still keep copies made by allocations, so it limits secret lifetime in memory rather than guarantees erasure
+func (k *PrivateKey) Zeroize() {
+	zeroBigInt(k.D)
+}
+
+// Zeroize overwrites private scalar with zeros, the key must not be used afterwards
+func (k *X25519PrivateKey) Zeroize() {
+	zeroBytes(k.d)
+}
+
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// zeroBigInt overwrites backing words of x and sets it to zero
+func zeroBigInt(x *big.Int) {
+	if x == nil {
+		return
+	}
+
+	words := x.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	x.SetInt64(0)
+}
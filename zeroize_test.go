@@ -0,0 +1,76 @@
+package eciesgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrivateKey_Zeroize(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	words := privkey.D.Bits()
+	privkey.Zeroize()
+	assert.Equal(t, 0, privkey.D.Sign())
+	for _, w := range words[:cap(words)] {
+		assert.Zero(t, w)
+	}
+
+	x, err := GenerateX25519Key()
+	if !assert.NoError(t, err) {
+		return
+	}
+	x.Zeroize()
+	assert.Equal(t, make([]byte, 32), x.Bytes())
+}
+
+func TestEncryptAndDecryptSecretWiping(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	conf := DEFAULT_CONFIG.WithSecretWiping()
+	ciphertext, err := EncryptConf(privkey.PublicKey, []byte(testingMessage), conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	plaintext, err := DecryptConf(privkey, ciphertext, conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(plaintext))
+
+	// Receiver key is not wiped
+	plaintext, err = DecryptConf(privkey, ciphertext, conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, testingMessage, string(plaintext))
+}
+
+func TestSecretWipingEncapsulate(t *testing.T) {
+	skipUnapproved(t)
+
+	privkey, err := GenerateKey()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ss1, err := privkey.encapsulate(privkey.PublicKey, DEFAULT_CONFIG.WithSecretWiping())
+	if !assert.NoError(t, err) {
+		return
+	}
+	ss2, err := privkey.encapsulate(privkey.PublicKey, DEFAULT_CONFIG)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, ss2, ss1)
+}